@@ -0,0 +1,391 @@
+// Package analysis computes the nullability, the FIRST and FOLLOW sets and
+// the left recursion of the rules of a PEG grammar, to help ordering the
+// alternatives of the choices and placing the recovery expressions.
+package analysis
+
+import (
+	"sort"
+	"unicode"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/builder"
+)
+
+// Rule is the analysis of a rule of the grammar.
+type Rule struct {
+	Name string `json:"name"`
+	// Nullable is true if the rule may match the empty input.
+	Nullable bool `json:"nullable"`
+	// First is the set of terminals a match of the rule may start with.
+	First *Set `json:"first"`
+	// Follow is the set of terminals that may follow a match of the rule.
+	Follow *Set `json:"follow"`
+	// LeftRecursion is the set of left-recursive rules the rule belongs
+	// to, empty if the rule is not left-recursive.
+	LeftRecursion []string `json:"leftRecursion,omitempty"`
+}
+
+// Report is the analysis of a grammar.
+type Report struct {
+	// Rules is in the order of the grammar.
+	Rules []*Rule `json:"rules"`
+	// LeftRecursion is the list of the strongly connected components of
+	// the left-recursive rules, as found by the builder.
+	LeftRecursion [][]string `json:"leftRecursion"`
+}
+
+// Analyze returns the analysis of the grammar. The first rule, the
+// alternate entrypoints listed in the options of the grammar or in
+// entrypoints and the rules annotated with @entry may be followed by the
+// end of the input.
+//
+// The sets are an over-approximation: the lookahead expressions are
+// considered followed by what follows them, the text matched by the
+// back-references and the code expressions is unknown and the failure of
+// the earlier alternatives of a choice is ignored.
+func Analyze(grammar *ast.Grammar, entrypoints ...string) *Report {
+	a := &analyzer{
+		rules:    make(map[string]*ast.Rule, len(grammar.Rules)),
+		nullable: make(map[string]bool, len(grammar.Rules)),
+		first:    make(map[string]*Set, len(grammar.Rules)),
+		follow:   make(map[string]*Set, len(grammar.Rules)),
+	}
+	var order []*ast.Rule
+	for _, rule := range grammar.Rules {
+		if _, ok := a.rules[rule.Name.Val]; ok {
+			continue
+		}
+		a.rules[rule.Name.Val] = rule
+		a.first[rule.Name.Val] = &Set{}
+		a.follow[rule.Name.Val] = &Set{}
+		order = append(order, rule)
+	}
+
+	if len(grammar.Rules) > 0 {
+		entrypoints = append(entrypoints, grammar.Rules[0].Name.Val)
+	}
+	for _, opt := range grammar.Options {
+		if opt.Name.Val == "alternate-entrypoints" {
+			entrypoints = append(entrypoints, opt.Values...)
+		}
+	}
+	entrypoints = append(entrypoints, builder.EntryRules(grammar)...)
+	for _, name := range entrypoints {
+		if set := a.follow[name]; set != nil {
+			set.End = true
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range order {
+			if !a.nullable[rule.Name.Val] && a.isNullable(rule.Expr) {
+				a.nullable[rule.Name.Val] = true
+				changed = true
+			}
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range order {
+			if a.addFirst(a.first[rule.Name.Val], rule.Expr) {
+				changed = true
+			}
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range order {
+			if a.addFollow(rule.Expr, a.follow[rule.Name.Val]) {
+				changed = true
+			}
+		}
+	}
+
+	report := &Report{LeftRecursion: leftRecursion(a.rules, order)}
+	scc := make(map[string][]string)
+	for _, names := range report.LeftRecursion {
+		for _, name := range names {
+			scc[name] = names
+		}
+	}
+	for _, rule := range order {
+		name := rule.Name.Val
+		report.Rules = append(report.Rules, &Rule{
+			Name:          name,
+			Nullable:      a.nullable[name],
+			First:         a.first[name],
+			Follow:        a.follow[name],
+			LeftRecursion: scc[name],
+		})
+	}
+	return report
+}
+
+// leftRecursion returns the strongly connected components of the graph
+// of the left invocations of the rules that contain a cycle, the same
+// ones the builder reports as left recursion. The names of a component
+// and the components are in the order of the grammar.
+func leftRecursion(rules map[string]*ast.Rule, order []*ast.Rule) [][]string {
+	index := make(map[string]int, len(order))
+	vertices := make([]string, 0, len(order))
+	for i, rule := range order {
+		index[rule.Name.Val] = i
+		vertices = append(vertices, rule.Name.Val)
+	}
+
+	builder.ComputeNullables(rules)
+	graph := builder.MakeFirstGraph(rules)
+
+	sccs := [][]string{}
+	for _, scc := range builder.StronglyConnectedComponents(vertices, graph) {
+		names := make([]string, 0, len(scc))
+		for name := range scc {
+			names = append(names, name)
+		}
+		if len(names) == 1 {
+			if _, ok := graph[names[0]][names[0]]; !ok {
+				continue
+			}
+		}
+		sort.Slice(names, func(i, j int) bool { return index[names[i]] < index[names[j]] })
+		sccs = append(sccs, names)
+	}
+	sort.Slice(sccs, func(i, j int) bool { return index[sccs[i][0]] < index[sccs[j][0]] })
+	return sccs
+}
+
+type analyzer struct {
+	rules    map[string]*ast.Rule
+	nullable map[string]bool
+	first    map[string]*Set
+	follow   map[string]*Set
+}
+
+// isNullable returns true if expr may match the empty input, given the
+// nullability of the rules computed so far.
+func (a *analyzer) isNullable(expr ast.Expression) bool {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		return a.isNullable(expr.Expr)
+	case *ast.ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			if a.isNullable(alt) {
+				return true
+			}
+		}
+		return false
+	case *ast.LabeledExpr:
+		return a.isNullable(expr.Expr)
+	case *ast.LitMatcher:
+		return expr.Val == ""
+	case *ast.OneOrMoreExpr:
+		return a.isNullable(expr.Expr)
+	case *ast.PrecedenceExpr:
+		return a.isNullable(expr.Atom)
+	case *ast.RecoveryExpr:
+		return a.isNullable(expr.Expr) || a.isNullable(expr.RecoverExpr)
+	case *ast.RepeatExpr:
+		return expr.Min == 0 || a.isNullable(expr.Expr)
+	case *ast.RuleRefExpr:
+		return a.nullable[expr.Name.Val]
+	case *ast.SeparatedExpr:
+		return expr.Min == 0 || a.isNullable(expr.Expr)
+	case *ast.SeqExpr:
+		for _, e := range expr.Exprs {
+			if !a.isNullable(e) {
+				return false
+			}
+		}
+		return true
+	case *ast.TriviaExpr:
+		return a.isNullable(expr.Trivia) && a.isNullable(expr.Expr)
+	case *ast.AndCodeExpr, *ast.AndExpr, *ast.BackRefExpr, *ast.CodeExpr,
+		*ast.NotCodeExpr, *ast.NotExpr, *ast.ThrowExpr,
+		*ast.ZeroOrMoreExpr, *ast.ZeroOrOneExpr:
+		return true
+	}
+	return false
+}
+
+// addFirst adds the terminals a match of expr may start with to set, it
+// returns true if set changed.
+func (a *analyzer) addFirst(set *Set, expr ast.Expression) bool {
+	changed := false
+	add := func(ok bool) {
+		if ok {
+			changed = true
+		}
+	}
+
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		add(a.addFirst(set, expr.Expr))
+	case *ast.AnyMatcher:
+		add(set.addRanges([]Range{{0, unicode.MaxRune}}))
+	case *ast.CharClassMatcher:
+		if rs, ok := classRanges(expr); ok {
+			add(set.addRanges(rs))
+		} else {
+			add(set.addClass(expr.Val))
+		}
+	case *ast.ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			add(a.addFirst(set, alt))
+		}
+	case *ast.LabeledExpr:
+		add(a.addFirst(set, expr.Expr))
+	case *ast.LitMatcher:
+		if expr.Val != "" {
+			add(set.addLiteral(Literal{Val: expr.Val, IgnoreCase: expr.IgnoreCase}))
+		}
+	case *ast.OneOrMoreExpr:
+		add(a.addFirst(set, expr.Expr))
+	case *ast.PrecedenceExpr:
+		add(a.addFirst(set, expr.Atom))
+		for _, l := range expr.Levels {
+			if l.Kind == ast.Prefix || a.isNullable(expr.Atom) {
+				for _, op := range l.Operators {
+					add(a.addFirst(set, op.Expr))
+				}
+			}
+		}
+	case *ast.RecoveryExpr:
+		add(a.addFirst(set, expr.Expr))
+		add(a.addFirst(set, expr.RecoverExpr))
+	case *ast.RepeatExpr:
+		add(a.addFirst(set, expr.Expr))
+	case *ast.RuleRefExpr:
+		add(set.addSet(a.first[expr.Name.Val]))
+	case *ast.SeparatedExpr:
+		add(a.addFirst(set, expr.Expr))
+		if a.isNullable(expr.Expr) {
+			add(a.addFirst(set, expr.Sep))
+		}
+	case *ast.SeqExpr:
+		for _, e := range expr.Exprs {
+			add(a.addFirst(set, e))
+			if !a.isNullable(e) {
+				break
+			}
+		}
+	case *ast.TriviaExpr:
+		add(a.addFirst(set, expr.Trivia))
+		if a.isNullable(expr.Trivia) {
+			add(a.addFirst(set, expr.Expr))
+		}
+	case *ast.ZeroOrMoreExpr:
+		add(a.addFirst(set, expr.Expr))
+	case *ast.ZeroOrOneExpr:
+		add(a.addFirst(set, expr.Expr))
+	}
+	return changed
+}
+
+// firstThen returns the terminals a match of expr may start with, and the
+// ones of follow if expr is nullable.
+func (a *analyzer) firstThen(expr ast.Expression, follow *Set) *Set {
+	set := &Set{}
+	a.addFirst(set, expr)
+	if a.isNullable(expr) {
+		set.addSet(follow)
+	}
+	return set
+}
+
+// loop returns the terminals a match of expr may start with, and the ones
+// of follow: what follows an expression that may repeat.
+func (a *analyzer) loop(expr ast.Expression, follow *Set) *Set {
+	set := &Set{}
+	a.addFirst(set, expr)
+	set.addSet(follow)
+	return set
+}
+
+// addFollow adds follow, the terminals that may follow expr, to the
+// FOLLOW sets of the rules expr references, it returns true if a set
+// changed.
+func (a *analyzer) addFollow(expr ast.Expression, follow *Set) bool {
+	changed := false
+	add := func(ok bool) {
+		if ok {
+			changed = true
+		}
+	}
+
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		add(a.addFollow(expr.Expr, follow))
+	case *ast.AndExpr:
+		add(a.addFollow(expr.Expr, follow))
+	case *ast.ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			add(a.addFollow(alt, follow))
+		}
+	case *ast.LabeledExpr:
+		add(a.addFollow(expr.Expr, follow))
+	case *ast.NotExpr:
+		add(a.addFollow(expr.Expr, follow))
+	case *ast.OneOrMoreExpr:
+		add(a.addFollow(expr.Expr, a.loop(expr.Expr, follow)))
+	case *ast.PrecedenceExpr:
+		// an operand follows the prefix and infix operators, the infix
+		// and postfix operators follow an operand
+		operand, operator := &Set{}, &Set{}
+		a.addFirst(operand, expr.Atom)
+		for _, l := range expr.Levels {
+			for _, op := range l.Operators {
+				if l.Kind == ast.Prefix {
+					a.addFirst(operand, op.Expr)
+				} else {
+					a.addFirst(operator, op.Expr)
+				}
+			}
+		}
+		operator.addSet(follow)
+		add(a.addFollow(expr.Atom, operator))
+		for _, l := range expr.Levels {
+			for _, op := range l.Operators {
+				if l.Kind == ast.Postfix {
+					add(a.addFollow(op.Expr, operator))
+				} else {
+					add(a.addFollow(op.Expr, operand))
+				}
+			}
+		}
+	case *ast.RecoveryExpr:
+		add(a.addFollow(expr.Expr, follow))
+		add(a.addFollow(expr.RecoverExpr, follow))
+	case *ast.RepeatExpr:
+		if expr.Max >= 0 && expr.Max <= 1 {
+			add(a.addFollow(expr.Expr, follow))
+		} else {
+			add(a.addFollow(expr.Expr, a.loop(expr.Expr, follow)))
+		}
+	case *ast.RuleRefExpr:
+		if set := a.follow[expr.Name.Val]; set != nil {
+			add(set.addSet(follow))
+		}
+	case *ast.SeparatedExpr:
+		afterSep := &Set{}
+		a.addFirst(afterSep, expr.Expr)
+		if expr.AllowTrailing {
+			afterSep.addSet(follow)
+		}
+		add(a.addFollow(expr.Expr, a.loop(expr.Sep, follow)))
+		add(a.addFollow(expr.Sep, afterSep))
+	case *ast.SeqExpr:
+		for i := len(expr.Exprs) - 1; i >= 0; i-- {
+			add(a.addFollow(expr.Exprs[i], follow))
+			follow = a.firstThen(expr.Exprs[i], follow)
+		}
+	case *ast.TriviaExpr:
+		add(a.addFollow(expr.Trivia, a.firstThen(expr.Expr, follow)))
+		add(a.addFollow(expr.Expr, follow))
+	case *ast.ZeroOrMoreExpr:
+		add(a.addFollow(expr.Expr, a.loop(expr.Expr, follow)))
+	case *ast.ZeroOrOneExpr:
+		add(a.addFollow(expr.Expr, follow))
+	}
+	return changed
+}
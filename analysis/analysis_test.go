@@ -0,0 +1,104 @@
+package analysis_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/analysis"
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+)
+
+func parse(t *testing.T, text string) *ast.Grammar {
+	t.Helper()
+
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestAnalyze(t *testing.T) {
+	t.Parallel()
+
+	g := parse(t, `
+start = (expr ';')* !.
+expr = term (('+' / '-') term)* / call
+term = [0-9]+ / '(' expr ')' / ident
+ident = [a-z] [a-z0-9_]i* !'_'
+call = expr "()"
+str = '"' [^"\n]* '"'
+kw = "if"i / "else"?
+`)
+	report := analysis.Analyze(g, "str")
+
+	type want struct {
+		nullable bool
+		first    string
+		follow   string
+	}
+	wants := map[string]want{
+		"start": {nullable: true, first: `"(" [0-9a-z]`, follow: "end of input"},
+		"expr":  {first: `"(" [0-9a-z]`, follow: `"()" ")" ";"`},
+		"term":  {first: `"(" [0-9a-z]`, follow: `"()" ")" "+" "-" ";"`},
+		"ident": {first: `[a-z]`, follow: `"()" ")" "+" "-" ";"`},
+		"call":  {first: `"(" [0-9a-z]`, follow: `"()" ")" ";"`},
+		"str":   {first: `"\""`, follow: "end of input"},
+		"kw":    {nullable: true, first: `"else" "if"i`},
+	}
+	if len(report.Rules) != len(wants) {
+		t.Fatalf("want %d rules, got %d", len(wants), len(report.Rules))
+	}
+	for _, rule := range report.Rules {
+		w := wants[rule.Name]
+		if rule.Nullable != w.nullable {
+			t.Errorf("%s: want nullable %t, got %t", rule.Name, w.nullable, rule.Nullable)
+		}
+		if got := rule.First.String(); got != w.first {
+			t.Errorf("%s: want first %s, got %s", rule.Name, w.first, got)
+		}
+		if got := rule.Follow.String(); got != w.follow {
+			t.Errorf("%s: want follow %s, got %s", rule.Name, w.follow, got)
+		}
+	}
+
+	wantLR := [][]string{{"expr", "call"}}
+	if !reflect.DeepEqual(report.LeftRecursion, wantLR) {
+		t.Errorf("want left recursion %v, got %v", wantLR, report.LeftRecursion)
+	}
+	for _, rule := range report.Rules {
+		lr := rule.Name == "expr" || rule.Name == "call"
+		if lr != (len(rule.LeftRecursion) > 0) {
+			t.Errorf("%s: want left-recursive %t, got %v", rule.Name, lr, rule.LeftRecursion)
+		}
+	}
+}
+
+func TestAnalyzeSets(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		expr  string
+		first string
+	}{
+		{expr: "'a' / 'b' / [c-e] / \"ab\"", first: `"a" "ab" "b" [c-e]`},
+		{expr: "[a-c]i", first: `[A-Ca-c]`},
+		{expr: "[^\\n]", first: `[^\n]`},
+		{expr: ". / 'a'", first: `"a" .`},
+		{expr: "[\\pL] / [0-9]", first: `[0-9] [\pL]`},
+		{expr: "'a'? 'b'* ('c' / 'd') 'e'", first: `"a" "b" "c" "d"`},
+		{expr: "&'a' 'b'", first: `"b"`},
+		{expr: "[\\]-] / ' '", first: `" " [\-\]]`},
+	}
+
+	for _, tc := range cases {
+		g := parse(t, "start = "+tc.expr+"\n")
+		report := analysis.Analyze(g)
+		if got := report.Rules[0].First.String(); got != tc.first {
+			t.Errorf("%s: want first %s, got %s", tc.expr, tc.first, got)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package analysis
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// Literal is a literal of a Set.
+type Literal struct {
+	Val        string `json:"val"`
+	IgnoreCase bool   `json:"ignoreCase,omitempty"`
+}
+
+// String returns the literal as written in a grammar.
+func (l Literal) String() string {
+	if l.IgnoreCase {
+		return strconv.Quote(l.Val) + "i"
+	}
+	return strconv.Quote(l.Val)
+}
+
+// Range is the range of runes from Lo to Hi, inclusive.
+type Range struct {
+	Lo rune `json:"lo"`
+	Hi rune `json:"hi"`
+}
+
+// Set is a set of terminals: the literals, the runes and the character
+// classes an input may start with, and the end of the input.
+type Set struct {
+	// Literals is sorted.
+	Literals []Literal `json:"literals,omitempty"`
+	// Ranges is sorted, the ranges neither overlap nor are adjacent.
+	Ranges []Range `json:"ranges,omitempty"`
+	// Classes are the character classes that use Unicode classes, as
+	// written in the grammar, they are not expanded to ranges. Classes is
+	// sorted.
+	Classes []string `json:"classes,omitempty"`
+	// End is true if the set contains the end of the input.
+	End bool `json:"end,omitempty"`
+}
+
+// IsEmpty returns true if the set contains no terminal.
+func (s *Set) IsEmpty() bool {
+	return len(s.Literals) == 0 && len(s.Ranges) == 0 && len(s.Classes) == 0 && !s.End
+}
+
+// String returns the terminals of the set separated by spaces. The ranges
+// are written as a single character class, "." if it matches any rune.
+func (s *Set) String() string {
+	var parts []string
+	for _, l := range s.Literals {
+		parts = append(parts, l.String())
+	}
+	if len(s.Ranges) > 0 {
+		parts = append(parts, classString(s.Ranges))
+	}
+	parts = append(parts, s.Classes...)
+	if s.End {
+		parts = append(parts, "end of input")
+	}
+	return strings.Join(parts, " ")
+}
+
+// addSet adds the terminals of o to s, it returns true if s changed.
+func (s *Set) addSet(o *Set) bool {
+	if o == nil || o == s {
+		return false
+	}
+	changed := false
+	for _, l := range o.Literals {
+		if s.addLiteral(l) {
+			changed = true
+		}
+	}
+	if s.addRanges(o.Ranges) {
+		changed = true
+	}
+	for _, c := range o.Classes {
+		if s.addClass(c) {
+			changed = true
+		}
+	}
+	if o.End && !s.End {
+		s.End = true
+		changed = true
+	}
+	return changed
+}
+
+func (s *Set) addLiteral(l Literal) bool {
+	i := sort.Search(len(s.Literals), func(i int) bool {
+		li := s.Literals[i]
+		return li.Val > l.Val || li.Val == l.Val && (li.IgnoreCase || !l.IgnoreCase)
+	})
+	if i < len(s.Literals) && s.Literals[i] == l {
+		return false
+	}
+	s.Literals = append(s.Literals, Literal{})
+	copy(s.Literals[i+1:], s.Literals[i:])
+	s.Literals[i] = l
+	return true
+}
+
+func (s *Set) addClass(c string) bool {
+	i := sort.SearchStrings(s.Classes, c)
+	if i < len(s.Classes) && s.Classes[i] == c {
+		return false
+	}
+	s.Classes = append(s.Classes, "")
+	copy(s.Classes[i+1:], s.Classes[i:])
+	s.Classes[i] = c
+	return true
+}
+
+func (s *Set) addRanges(rs []Range) bool {
+	if len(rs) == 0 {
+		return false
+	}
+	merged := mergeRanges(append(append([]Range(nil), s.Ranges...), rs...))
+	if equalRanges(merged, s.Ranges) {
+		return false
+	}
+	s.Ranges = merged
+	return true
+}
+
+// mergeRanges sorts the ranges and merges the ones that overlap or are
+// adjacent.
+func mergeRanges(rs []Range) []Range {
+	sort.Slice(rs, func(i, j int) bool { return rs[i].Lo < rs[j].Lo })
+	var out []Range
+	for _, r := range rs {
+		if n := len(out); n > 0 && r.Lo <= out[n-1].Hi+1 {
+			if r.Hi > out[n-1].Hi {
+				out[n-1].Hi = r.Hi
+			}
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func equalRanges(a, b []Range) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// invertRanges returns the runes that are not in the merged ranges rs.
+func invertRanges(rs []Range) []Range {
+	var out []Range
+	lo := rune(0)
+	for _, r := range rs {
+		if r.Lo > lo {
+			out = append(out, Range{lo, r.Lo - 1})
+		}
+		lo = r.Hi + 1
+	}
+	if lo <= unicode.MaxRune {
+		out = append(out, Range{lo, unicode.MaxRune})
+	}
+	return out
+}
+
+// foldRanges adds the other cases of the runes of the merged ranges rs.
+func foldRanges(rs []Range) []Range {
+	out := append([]Range(nil), rs...)
+	for _, r := range rs {
+		for c := r.Lo; c <= r.Hi; c++ {
+			for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+				out = append(out, Range{f, f})
+			}
+		}
+	}
+	return mergeRanges(out)
+}
+
+// classRanges returns the ranges of the runes matched by the character
+// class, or false if it uses Unicode classes.
+func classRanges(c *ast.CharClassMatcher) ([]Range, bool) {
+	if len(c.UnicodeClasses) > 0 {
+		return nil, false
+	}
+	var rs []Range
+	for _, r := range c.Chars {
+		rs = append(rs, Range{r, r})
+	}
+	for i := 0; i+1 < len(c.Ranges); i += 2 {
+		rs = append(rs, Range{c.Ranges[i], c.Ranges[i+1]})
+	}
+	if t := c.Table; t != nil {
+		for _, r := range t.R16 {
+			for lo := rune(r.Lo); lo <= rune(r.Hi); lo += rune(r.Stride) {
+				hi := lo
+				if r.Stride == 1 {
+					hi = rune(r.Hi)
+				}
+				rs = append(rs, Range{lo, hi})
+			}
+		}
+		for _, r := range t.R32 {
+			for lo := rune(r.Lo); lo <= rune(r.Hi); lo += rune(r.Stride) {
+				hi := lo
+				if r.Stride == 1 {
+					hi = rune(r.Hi)
+				}
+				rs = append(rs, Range{lo, hi})
+			}
+		}
+	}
+	rs = mergeRanges(rs)
+	if c.IgnoreCase {
+		rs = foldRanges(rs)
+	}
+	if c.Inverted {
+		rs = invertRanges(rs)
+	}
+	return rs, true
+}
+
+// classString returns the character class matching the runes of the
+// merged ranges rs, inverted if it matches more than half of the runes.
+func classString(rs []Range) string {
+	if len(rs) == 1 && rs[0] == (Range{0, unicode.MaxRune}) {
+		return "."
+	}
+
+	var n int
+	for _, r := range rs {
+		n += int(r.Hi-r.Lo) + 1
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	if n > (unicode.MaxRune+1)/2 {
+		b.WriteByte('^')
+		rs = invertRanges(rs)
+	}
+	for _, r := range rs {
+		b.WriteString(escapeRune(r.Lo))
+		switch {
+		case r.Hi == r.Lo+1:
+			b.WriteString(escapeRune(r.Hi))
+		case r.Hi > r.Lo:
+			b.WriteByte('-')
+			b.WriteString(escapeRune(r.Hi))
+		}
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// escapeRune returns r as written in a character class.
+func escapeRune(r rune) string {
+	switch r {
+	case ']', '\\', '-', '^':
+		return `\` + string(r)
+	}
+	if unicode.IsPrint(r) {
+		return string(r)
+	}
+	q := strconv.QuoteRune(r)
+	return q[1 : len(q)-1]
+}
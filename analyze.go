@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oskoi/pigeon/analysis"
+	"github.com/oskoi/pigeon/builder"
+)
+
+// analyzeMain implements the analyze command, it prints the nullability,
+// the FIRST and FOLLOW sets and the left recursion of the rules.
+func analyzeMain(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" analyze", flag.ExitOnError)
+
+	var (
+		shortHelpFlag = fs.Bool("h", false, "show help page")
+		longHelpFlag  = fs.Bool("help", false, "show help page")
+		jsonFlag      = fs.Bool("json", false, "print the analysis as JSON")
+
+		altEntrypointsFlag ruleNamesFlag
+	)
+	fs.Var(&altEntrypointsFlag, "alternate-entrypoints", "comma-separated list of rule names that may be used as entrypoints")
+
+	usage := func() {
+		fmt.Printf(analyzeUsagePage, os.Args[0])
+	}
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, "args parse error:\n", err)
+		exit(6)
+	}
+
+	if *shortHelpFlag || *longHelpFlag {
+		usage()
+		exit(0)
+	}
+
+	if fs.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "expected one argument, got %q\n", strings.Join(fs.Args(), " "))
+		usage()
+		exit(1)
+	}
+
+	_, grammar := parseGrammar(fs.Arg(0))
+	// the trivia is part of the sets, as in the generated parser
+	if err := builder.InsertTrivia(grammar); err != nil {
+		fmt.Fprintln(os.Stderr, "grammar error:\n", err)
+		exit(3)
+	}
+	report := analysis.Analyze(grammar, altEntrypointsFlag...)
+
+	if *jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:\n", err)
+			exit(7)
+		}
+		return
+	}
+	if _, err := os.Stdout.WriteString(analysisText(report)); err != nil {
+		fmt.Fprintln(os.Stderr, "write error:\n", err)
+		exit(7)
+	}
+}
+
+// analysisText returns the textual representation of the report, a
+// paragraph per rule.
+func analysisText(report *analysis.Report) string {
+	set := func(s *analysis.Set) string {
+		if s.IsEmpty() {
+			return "none"
+		}
+		return s.String()
+	}
+
+	var b strings.Builder
+	for i, rule := range report.Rules {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		nullable := "no"
+		if rule.Nullable {
+			nullable = "yes"
+		}
+		fmt.Fprintf(&b, "%s\n", rule.Name)
+		fmt.Fprintf(&b, "\tnullable: %s\n", nullable)
+		fmt.Fprintf(&b, "\tfirst:    %s\n", set(rule.First))
+		fmt.Fprintf(&b, "\tfollow:   %s\n", set(rule.Follow))
+		if len(rule.LeftRecursion) > 0 {
+			fmt.Fprintf(&b, "\tleft recursion: %s\n", strings.Join(rule.LeftRecursion, ", "))
+		}
+	}
+	return b.String()
+}
+
+var analyzeUsagePage = `usage: %s analyze [options] [GRAMMAR_FILE]
+
+Analyze prints, for each rule of a PEG grammar, whether it may match the
+empty input, its FIRST set, the terminals a match may start with, and
+its FOLLOW set, the terminals that may follow a match. The literals are
+printed as in the grammar and the runes as a character class, the end
+of the input follows the entrypoints. The rules that are left-recursive
+are listed with the other rules of their cycles. The grammar is read
+from stdin if GRAMMAR_FILE is not specified.
+
+The sets are over-approximations: the lookahead expressions are assumed
+to be followed by what follows them, the text matched by the back-
+references and the code expressions is unknown, and the trivia skipped
+before the rules annotated with @token is included.
+
+	-alternate-entrypoints RULE[,RULE...]
+		comma-separated list of rule names that may be used as
+		entrypoints, in addition to the first rule, the ones listed
+		in the options of the grammar and the ones annotated with
+		@entry.
+	-h -help
+		display this help message.
+	-json
+		print the analysis as a JSON object with the "rules" and the
+		"leftRecursion" members.
+`
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Pos represents a position in a source file.
@@ -30,9 +31,10 @@ func (p Pos) String() string {
 
 // Grammar is the top-level node of the AST for the PEG grammar.
 type Grammar struct {
-	p     Pos
-	Init  *CodeBlock
-	Rules []*Rule
+	p       Pos
+	Init    *CodeBlock
+	Options []*Option
+	Rules   []*Rule
 }
 
 var _ Expression = (*Grammar)(nil)
@@ -73,15 +75,57 @@ func (g *Grammar) InitialNames() map[string]struct{} {
 	panic("InitialNames should not be called on the Grammar")
 }
 
+// Option represents an option set in the options block of the grammar.
+// An option has a name and one or more values.
+type Option struct {
+	p      Pos
+	Name   *Identifier
+	Values []string
+}
+
+var _ Expression = (*Option)(nil)
+
+// NewOption creates a new option at the specified position and with the
+// specified name.
+func NewOption(p Pos, name *Identifier) *Option {
+	return &Option{p: p, Name: name}
+}
+
+// Pos returns the starting position of the node.
+func (o *Option) Pos() Pos { return o.p }
+
+// String returns the textual representation of a node.
+func (o *Option) String() string {
+	return fmt.Sprintf("%s: %T{Name: %v, Values: %q}", o.p, o, o.Name, o.Values)
+}
+
+// NullableVisit recursively determines whether an object is nullable.
+func (o *Option) NullableVisit(rules map[string]*Rule) bool {
+	panic("NullableVisit should not be called on the Option")
+}
+
+// IsNullable returns the nullable attribute of the node.
+func (o *Option) IsNullable() bool {
+	panic("IsNullable should not be called on the Option")
+}
+
+// InitialNames returns names of nodes with which an expression can begin.
+func (o *Option) InitialNames() map[string]struct{} {
+	panic("InitialNames should not be called on the Option")
+}
+
 // Rule represents a rule in the PEG grammar. It has a name, an optional
-// display name to be used in error messages, and an expression.
+// display name to be used in error messages, optional annotations and an
+// expression.
 type Rule struct {
 	p           Pos
 	Name        *Identifier
 	DisplayName *StringLit
+	Annotations []*Identifier
 	Expr        Expression
 
 	IsLabelExists bool
+	HasBackRef    bool
 
 	// Fields below to work with left recursion.
 	Visited       bool
@@ -101,6 +145,17 @@ func NewRule(p Pos, name *Identifier) *Rule {
 // Pos returns the starting position of the node.
 func (r *Rule) Pos() Pos { return r.p }
 
+// HasAnnotation returns true if the rule is annotated with the specified
+// annotation name (without the leading "@").
+func (r *Rule) HasAnnotation(name string) bool {
+	for _, a := range r.Annotations {
+		if a.Val == name {
+			return true
+		}
+	}
+	return false
+}
+
 // String returns the textual representation of a node.
 func (r *Rule) String() string {
 	return fmt.Sprintf("%s: %T{Name: %v, DisplayName: %v, Expr: %v}",
@@ -367,6 +422,10 @@ type LabeledExpr struct {
 	Label       *Identifier
 	Expr        Expression
 	TextCapture bool
+
+	// BackRef is set if the captured text is matched again by a
+	// BackRefExpr.
+	BackRef bool
 }
 
 var _ Expression = (*LabeledExpr)(nil)
@@ -588,6 +647,311 @@ func (o *OneOrMoreExpr) InitialNames() map[string]struct{} {
 	return o.Expr.InitialNames()
 }
 
+// RepeatExpr is an expression that must be matched at least Min times
+// and at most Max times. A negative Max means there is no upper bound.
+type RepeatExpr struct {
+	p    Pos
+	Expr Expression
+	Min  int
+	Max  int
+
+	Nullable bool
+}
+
+var _ Expression = (*RepeatExpr)(nil)
+
+// NewRepeatExpr creates a new bounded repetition expression at the
+// specified position.
+func NewRepeatExpr(p Pos) *RepeatExpr {
+	return &RepeatExpr{p: p, Max: -1}
+}
+
+// Pos returns the starting position of the node.
+func (r *RepeatExpr) Pos() Pos { return r.p }
+
+// String returns the textual representation of a node.
+func (r *RepeatExpr) String() string {
+	return fmt.Sprintf("%s: %T{Expr: %v, Min: %d, Max: %d}", r.p, r, r.Expr, r.Min, r.Max)
+}
+
+// NullableVisit recursively determines whether an object is nullable.
+func (r *RepeatExpr) NullableVisit(rules map[string]*Rule) bool {
+	r.Nullable = r.Expr.NullableVisit(rules) || r.Min == 0
+	return r.Nullable
+}
+
+// IsNullable returns the nullable attribute of the node.
+func (r *RepeatExpr) IsNullable() bool {
+	return r.Nullable
+}
+
+// InitialNames returns names of nodes with which an expression can begin.
+func (r *RepeatExpr) InitialNames() map[string]struct{} {
+	return r.Expr.InitialNames()
+}
+
+// SeparatedExpr is an expression that matches a list of Expr separated by
+// Sep. Min is 1 for the "++" operator and 0 for the "**" operator. If
+// AllowTrailing is true, a separator may follow the last Expr.
+type SeparatedExpr struct {
+	p             Pos
+	Expr          Expression
+	Sep           Expression
+	Min           int
+	AllowTrailing bool
+
+	Nullable bool
+}
+
+var _ Expression = (*SeparatedExpr)(nil)
+
+// NewSeparatedExpr creates a new separated list expression at the specified
+// position.
+func NewSeparatedExpr(p Pos) *SeparatedExpr {
+	return &SeparatedExpr{p: p}
+}
+
+// Pos returns the starting position of the node.
+func (s *SeparatedExpr) Pos() Pos { return s.p }
+
+// String returns the textual representation of a node.
+func (s *SeparatedExpr) String() string {
+	return fmt.Sprintf("%s: %T{Expr: %v, Sep: %v, Min: %d, AllowTrailing: %t}",
+		s.p, s, s.Expr, s.Sep, s.Min, s.AllowTrailing)
+}
+
+// NullableVisit recursively determines whether an object is nullable.
+func (s *SeparatedExpr) NullableVisit(rules map[string]*Rule) bool {
+	s.Sep.NullableVisit(rules)
+	s.Nullable = s.Expr.NullableVisit(rules) || s.Min == 0
+	return s.Nullable
+}
+
+// IsNullable returns the nullable attribute of the node.
+func (s *SeparatedExpr) IsNullable() bool {
+	return s.Nullable
+}
+
+// InitialNames returns names of nodes with which an expression can begin.
+func (s *SeparatedExpr) InitialNames() map[string]struct{} {
+	return s.Expr.InitialNames()
+}
+
+// OperatorKind is the kind of the operators of a precedence level.
+type OperatorKind string
+
+// List of operator kinds.
+const (
+	LeftAssoc  OperatorKind = "left"
+	RightAssoc OperatorKind = "right"
+	Prefix     OperatorKind = "prefix"
+	Postfix    OperatorKind = "postfix"
+)
+
+// PrecedenceExpr is an expression that matches Atom combined with the
+// operators of Levels, using precedence climbing. Levels are sorted from
+// the lowest to the highest precedence.
+type PrecedenceExpr struct {
+	p      Pos
+	Atom   Expression
+	Levels []*PrecedenceLevel
+
+	Nullable bool
+}
+
+var _ Expression = (*PrecedenceExpr)(nil)
+
+// NewPrecedenceExpr creates a new precedence expression at the specified
+// position.
+func NewPrecedenceExpr(p Pos) *PrecedenceExpr {
+	return &PrecedenceExpr{p: p}
+}
+
+// Pos returns the starting position of the node.
+func (p *PrecedenceExpr) Pos() Pos { return p.p }
+
+// String returns the textual representation of a node.
+func (p *PrecedenceExpr) String() string {
+	var buf bytes.Buffer
+
+	buf.WriteString(fmt.Sprintf("%s: %T{Atom: %v, Levels: [\n", p.p, p, p.Atom))
+	for _, l := range p.Levels {
+		buf.WriteString(fmt.Sprintf("%s,\n", l))
+	}
+	buf.WriteString("]}")
+	return buf.String()
+}
+
+// NullableVisit recursively determines whether an object is nullable.
+func (p *PrecedenceExpr) NullableVisit(rules map[string]*Rule) bool {
+	for _, l := range p.Levels {
+		for _, op := range l.Operators {
+			op.Expr.NullableVisit(rules)
+		}
+	}
+	p.Nullable = p.Atom.NullableVisit(rules)
+	return p.Nullable
+}
+
+// IsNullable returns the nullable attribute of the node.
+func (p *PrecedenceExpr) IsNullable() bool {
+	return p.Nullable
+}
+
+// InitialNames returns names of nodes with which an expression can begin.
+func (p *PrecedenceExpr) InitialNames() map[string]struct{} {
+	names := make(map[string]struct{})
+	for name := range p.Atom.InitialNames() {
+		names[name] = struct{}{}
+	}
+	for _, l := range p.Levels {
+		if l.Kind != Prefix {
+			continue
+		}
+		for _, op := range l.Operators {
+			for name := range op.Expr.InitialNames() {
+				names[name] = struct{}{}
+			}
+		}
+	}
+	return names
+}
+
+// PrecedenceLevel is a set of operators of the same kind and precedence
+// in a PrecedenceExpr.
+type PrecedenceLevel struct {
+	p         Pos
+	Kind      OperatorKind
+	Operators []*Operator
+}
+
+// NewPrecedenceLevel creates a new precedence level at the specified
+// position and with the specified kind of operators.
+func NewPrecedenceLevel(p Pos, kind OperatorKind) *PrecedenceLevel {
+	return &PrecedenceLevel{p: p, Kind: kind}
+}
+
+// Pos returns the starting position of the node.
+func (l *PrecedenceLevel) Pos() Pos { return l.p }
+
+// String returns the textual representation of a node.
+func (l *PrecedenceLevel) String() string {
+	return fmt.Sprintf("%s: %T{Kind: %s, Operators: %v}", l.p, l, l.Kind, l.Operators)
+}
+
+// Operator is an operator of a PrecedenceLevel. Its optional code block
+// computes the value of the operator applied to its operands.
+type Operator struct {
+	p      Pos
+	Expr   Expression
+	Code   *CodeBlock
+	FuncIx int
+}
+
+// NewOperator creates a new operator at the specified position.
+func NewOperator(p Pos) *Operator {
+	return &Operator{p: p}
+}
+
+// Pos returns the starting position of the node.
+func (o *Operator) Pos() Pos { return o.p }
+
+// String returns the textual representation of a node.
+func (o *Operator) String() string {
+	return fmt.Sprintf("%s: %T{Expr: %v, Code: %v}", o.p, o, o.Expr, o.Code)
+}
+
+// TriviaExpr is an expression that matches Trivia, discarding its value,
+// and then Expr. It is inserted by the builder before the references to
+// token rules and is not part of the grammar syntax.
+type TriviaExpr struct {
+	p      Pos
+	Trivia Expression
+	Expr   Expression
+
+	Nullable bool
+}
+
+var _ Expression = (*TriviaExpr)(nil)
+
+// NewTriviaExpr creates a new trivia expression at the specified position.
+func NewTriviaExpr(p Pos) *TriviaExpr {
+	return &TriviaExpr{p: p}
+}
+
+// Pos returns the starting position of the node.
+func (t *TriviaExpr) Pos() Pos { return t.p }
+
+// String returns the textual representation of a node.
+func (t *TriviaExpr) String() string {
+	return fmt.Sprintf("%s: %T{Trivia: %v, Expr: %v}", t.p, t, t.Trivia, t.Expr)
+}
+
+// NullableVisit recursively determines whether an object is nullable.
+func (t *TriviaExpr) NullableVisit(rules map[string]*Rule) bool {
+	trivia := t.Trivia.NullableVisit(rules)
+	t.Nullable = t.Expr.NullableVisit(rules) && trivia
+	return t.Nullable
+}
+
+// IsNullable returns the nullable attribute of the node.
+func (t *TriviaExpr) IsNullable() bool {
+	return t.Nullable
+}
+
+// InitialNames returns names of nodes with which an expression can begin.
+func (t *TriviaExpr) InitialNames() map[string]struct{} {
+	names := make(map[string]struct{})
+	for name := range t.Trivia.InitialNames() {
+		names[name] = struct{}{}
+	}
+	if t.Trivia.IsNullable() {
+		for name := range t.Expr.InitialNames() {
+			names[name] = struct{}{}
+		}
+	}
+	return names
+}
+
+// BackRefExpr is an expression that matches the text captured by the
+// text capture label Label of the current rule.
+type BackRefExpr struct {
+	p     Pos
+	Label *Identifier
+}
+
+var _ Expression = (*BackRefExpr)(nil)
+
+// NewBackRefExpr creates a new back-reference expression at the specified
+// position.
+func NewBackRefExpr(p Pos) *BackRefExpr {
+	return &BackRefExpr{p: p}
+}
+
+// Pos returns the starting position of the node.
+func (b *BackRefExpr) Pos() Pos { return b.p }
+
+// String returns the textual representation of a node.
+func (b *BackRefExpr) String() string {
+	return fmt.Sprintf("%s: %T{Label: %v}", b.p, b, b.Label)
+}
+
+// NullableVisit recursively determines whether an object is nullable.
+func (b *BackRefExpr) NullableVisit(rules map[string]*Rule) bool {
+	// the captured text may be empty
+	return true
+}
+
+// IsNullable returns the nullable attribute of the node.
+func (b *BackRefExpr) IsNullable() bool {
+	return true
+}
+
+// InitialNames returns names of nodes with which an expression can begin.
+func (b *BackRefExpr) InitialNames() map[string]struct{} {
+	return make(map[string]struct{})
+}
+
 // RuleRefExpr is an expression that references a rule by name.
 type RuleRefExpr struct {
 	p    Pos
@@ -643,10 +1007,14 @@ type StateCodeExpr struct {
 
 // LitMatcher is a string literal matcher. The value to match may be a
 // double-quoted string, a single-quoted single character, or a back-tick
-// quoted raw string.
+// quoted raw string. A keyword literal only matches if it is not followed
+// by a character of the rule annotated with @word, its Boundary is set to
+// a reference to this rule by the builder.
 type LitMatcher struct {
 	posValue   // can be str, rstr or char
 	IgnoreCase bool
+	Keyword    bool
+	Boundary   *RuleRefExpr
 }
 
 var _ Expression = (*LitMatcher)(nil)
@@ -691,6 +1059,11 @@ type CharClassMatcher struct {
 	Chars          []rune
 	Ranges         []rune // pairs of low/high range
 	UnicodeClasses []string
+
+	// Table is the set of characters of a class that uses set operators,
+	// evaluated at build time. Chars, Ranges and UnicodeClasses are empty
+	// for such a class.
+	Table *unicode.RangeTable
 }
 
 var _ Expression = (*CharClassMatcher)(nil)
@@ -725,6 +1098,17 @@ func (c *CharClassMatcher) parse() {
 		}
 	}
 
+	if runes := []rune(raw); hasClassSetOp(runes) {
+		s := &classScanner{s: runes}
+		c.Table = s.class(false).rangeTable()
+		return
+	}
+	c.parseItems(raw)
+}
+
+// parseItems parses the characters, ranges and Unicode classes of the
+// content of a character class.
+func (c *CharClassMatcher) parseItems(raw string) {
 	// content of char class is necessarily valid, so escapes are correct
 	r := strings.NewReader(raw)
 	var chars []rune
@@ -825,7 +1209,9 @@ func (c *CharClassMatcher) NullableVisit(rules map[string]*Rule) bool {
 
 // IsNullable returns the nullable attribute of the node.
 func (c *CharClassMatcher) IsNullable() bool {
-	return len(c.Chars) == 0 && len(c.Ranges) == 0 && len(c.UnicodeClasses) == 0
+	// a character class consumes a character whenever it matches, an empty
+	// class never matches.
+	return false
 }
 
 // InitialNames returns names of nodes with which an expression can begin.
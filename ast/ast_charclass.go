@@ -0,0 +1,270 @@
+package ast
+
+import (
+	"sort"
+	"unicode"
+)
+
+// Set operators of character classes. An operator is followed either by
+// a nested character class or by a Unicode class escape.
+const (
+	classSubtract  = "--"
+	classIntersect = "&&"
+)
+
+// hasClassSetOp returns true if the content of a character class contains
+// a set operator.
+func hasClassSetOp(raw []rune) bool {
+	s := &classScanner{s: raw}
+	for s.i < len(s.s) {
+		if s.op() != "" {
+			return true
+		}
+		s.skipRune()
+	}
+	return false
+}
+
+// classScanner evaluates the content of a character class that contains
+// set operators. Operators are evaluated from left to right.
+type classScanner struct {
+	s []rune
+	i int
+}
+
+// class evaluates a character class up to its closing bracket if nested,
+// or up to the end of the content otherwise.
+func (s *classScanner) class(nested bool) runeSet {
+	inverted := false
+	if nested && s.i < len(s.s) && s.s[s.i] == '^' {
+		inverted = true
+		s.i++
+	}
+
+	set := s.items()
+	for {
+		op := s.op()
+		if op == "" {
+			break
+		}
+		s.i += len(op)
+
+		var operand runeSet
+		if s.s[s.i] == '[' {
+			s.i++
+			operand = s.class(true)
+		} else {
+			operand = s.items()
+		}
+		if op == classSubtract {
+			set = set.subtract(operand)
+		} else {
+			set = set.intersect(operand)
+		}
+	}
+
+	if nested {
+		// skip the closing bracket
+		s.i++
+		if inverted {
+			set = set.complement()
+		}
+	}
+	return set
+}
+
+// items evaluates the characters, ranges and Unicode classes up to the
+// next set operator or closing bracket.
+func (s *classScanner) items() runeSet {
+	start := s.i
+	for s.i < len(s.s) && s.s[s.i] != ']' && s.op() == "" {
+		s.skipRune()
+	}
+
+	var c CharClassMatcher
+	c.parseItems(string(s.s[start:s.i]))
+	return newRuneSet(c.Chars, c.Ranges, c.UnicodeClasses)
+}
+
+// op returns the set operator at the current position, if any.
+func (s *classScanner) op() string {
+	if s.i+2 >= len(s.s) {
+		return ""
+	}
+	op := string(s.s[s.i : s.i+2])
+	if op != classSubtract && op != classIntersect {
+		return ""
+	}
+	next := s.s[s.i+2]
+	if next == '[' || next == '\\' && s.i+3 < len(s.s) && s.s[s.i+3] == 'p' {
+		return op
+	}
+	return ""
+}
+
+// skipRune skips the rune at the current position, or the whole escape
+// sequence if it starts one.
+func (s *classScanner) skipRune() {
+	if s.s[s.i] != '\\' {
+		s.i++
+		return
+	}
+	s.i += 2
+	if s.s[s.i-1] == 'p' && s.i < len(s.s) && s.s[s.i] == '{' {
+		for s.i < len(s.s) && s.s[s.i] != '}' {
+			s.i++
+		}
+		s.i++
+	}
+}
+
+// runeRange is an inclusive range of runes.
+type runeRange struct {
+	lo, hi rune
+}
+
+// runeSet is a set of runes as sorted, non-overlapping and non-adjacent
+// ranges.
+type runeSet []runeRange
+
+// newRuneSet returns the set of the chars, the pairs of low/high ranges
+// and the Unicode classes.
+func newRuneSet(chars, ranges []rune, classes []string) runeSet {
+	var set runeSet
+	for _, c := range chars {
+		set = append(set, runeRange{c, c})
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		set = append(set, runeRange{ranges[i], ranges[i+1]})
+	}
+	for _, name := range classes {
+		set = append(set, rangeTableSet(unicodeClass(name))...)
+	}
+	return set.normalize()
+}
+
+// unicodeClass returns the Unicode category, script or property with the
+// specified name.
+func unicodeClass(name string) *unicode.RangeTable {
+	if t, ok := unicode.Categories[name]; ok {
+		return t
+	}
+	if t, ok := unicode.Scripts[name]; ok {
+		return t
+	}
+	return unicode.Properties[name]
+}
+
+// rangeTableSet returns the ranges of the runes in the range table t.
+func rangeTableSet(t *unicode.RangeTable) runeSet {
+	if t == nil {
+		return nil
+	}
+	var set runeSet
+	add := func(lo, hi, stride rune) {
+		if stride == 1 {
+			set = append(set, runeRange{lo, hi})
+			return
+		}
+		for r := lo; r <= hi; r += stride {
+			set = append(set, runeRange{r, r})
+		}
+	}
+	for _, r := range t.R16 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	for _, r := range t.R32 {
+		add(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	return set
+}
+
+// normalize sorts and merges the ranges of the set.
+func (set runeSet) normalize() runeSet {
+	if len(set) == 0 {
+		return nil
+	}
+	sort.Slice(set, func(i, j int) bool { return set[i].lo < set[j].lo })
+
+	res := runeSet{set[0]}
+	for _, r := range set[1:] {
+		last := &res[len(res)-1]
+		if r.lo <= last.hi+1 {
+			if r.hi > last.hi {
+				last.hi = r.hi
+			}
+			continue
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// complement returns the runes that are not in the set.
+func (set runeSet) complement() runeSet {
+	var res runeSet
+	next := rune(0)
+	for _, r := range set {
+		if r.lo > next {
+			res = append(res, runeRange{next, r.lo - 1})
+		}
+		next = r.hi + 1
+	}
+	if next <= unicode.MaxRune {
+		res = append(res, runeRange{next, unicode.MaxRune})
+	}
+	return res
+}
+
+// intersect returns the runes that are both in set and other.
+func (set runeSet) intersect(other runeSet) runeSet {
+	var res runeSet
+	for i, j := 0, 0; i < len(set) && j < len(other); {
+		lo, hi := set[i].lo, set[i].hi
+		if other[j].lo > lo {
+			lo = other[j].lo
+		}
+		if other[j].hi < hi {
+			hi = other[j].hi
+		}
+		if lo <= hi {
+			res = append(res, runeRange{lo, hi})
+		}
+		if set[i].hi < other[j].hi {
+			i++
+		} else {
+			j++
+		}
+	}
+	return res
+}
+
+// subtract returns the runes of set that are not in other.
+func (set runeSet) subtract(other runeSet) runeSet {
+	return set.intersect(other.complement())
+}
+
+// rangeTable returns the range table of the runes of the set.
+func (set runeSet) rangeTable() *unicode.RangeTable {
+	t := &unicode.RangeTable{}
+	for _, r := range set {
+		if r.lo <= 0xFFFF {
+			hi := r.hi
+			if hi > 0xFFFF {
+				hi = 0xFFFF
+			}
+			t.R16 = append(t.R16, unicode.Range16{Lo: uint16(r.lo), Hi: uint16(hi), Stride: 1})
+			if hi <= unicode.MaxLatin1 {
+				t.LatinOffset++
+			}
+		}
+		if r.hi > 0xFFFF {
+			lo := r.lo
+			if lo <= 0xFFFF {
+				lo = 0x10000
+			}
+			t.R32 = append(t.R32, unicode.Range32{Lo: uint32(lo), Hi: uint32(r.hi), Stride: 1})
+		}
+	}
+	return t
+}
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -12,6 +13,7 @@ type grammarOptimizer struct {
 	rules           map[string]*Rule
 	ruleUsesRules   map[string]map[string]struct{}
 	ruleUsedByRules map[string]map[string]struct{}
+	refCount        map[string]int
 	visitor         func(expr Expression) Visitor
 	optimized       bool
 }
@@ -27,6 +29,7 @@ func newGrammarOptimizer(protectedRules []string) *grammarOptimizer {
 		rules:           make(map[string]*Rule),
 		ruleUsesRules:   make(map[string]map[string]struct{}),
 		ruleUsedByRules: make(map[string]map[string]struct{}),
+		refCount:        make(map[string]int),
 	}
 	r.visitor = r.init
 	return &r
@@ -52,6 +55,7 @@ func (r *grammarOptimizer) init(expr Expression) Visitor {
 		// Fill ruleUsesRules and ruleUsedByRules for every RuleRefExpr
 		set(r.ruleUsesRules, r.rule, expr.Name.Val)
 		set(r.ruleUsedByRules, expr.Name.Val, r.rule)
+		r.refCount[expr.Name.Val]++
 	}
 	return r
 }
@@ -65,6 +69,25 @@ func set(m map[string]map[string]struct{}, src, dst string) {
 	m[src][dst] = struct{}{}
 }
 
+// RuleUses returns, for each rule of the grammar that references other
+// rules, the sorted names of the rules it references. It is the rule-use
+// data collected by Optimize, the grammar is not modified.
+func RuleUses(g *Grammar) map[string][]string {
+	r := newGrammarOptimizer(nil)
+	Walk(r, g)
+
+	uses := make(map[string][]string, len(r.ruleUsesRules))
+	for rule, used := range r.ruleUsesRules {
+		names := make([]string, 0, len(used))
+		for name := range used {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		uses[rule] = names
+	}
+	return uses
+}
+
 // optimize is a Visitor, which is used with the Walk function
 // The purpose of this function is to perform the actual optimizations.
 // See Optimize for a detailed list of the performed optimizations.
@@ -94,13 +117,16 @@ func (r *grammarOptimizer) optimize(expr0 Expression) Visitor {
 				l1, lok1 := expr.Alternatives[i].(*LitMatcher)
 				c0, cok0 := expr.Alternatives[i-1].(*CharClassMatcher)
 				c1, cok1 := expr.Alternatives[i].(*CharClassMatcher)
+				// classes evaluated from set operators are not combined
+				cok0 = cok0 && c0.Table == nil
+				cok1 = cok1 && c1.Table == nil
 
 				combined := false
 
 				switch {
 				// Combine two LitMatcher to CharClassMatcher
 				// "a" / "b" => [ab]
-				case lok0 && lok1 && len([]rune(l0.Val)) == 1 && len([]rune(l1.Val)) == 1 && l0.IgnoreCase == l1.IgnoreCase:
+				case lok0 && lok1 && !l0.Keyword && !l1.Keyword && len([]rune(l0.Val)) == 1 && len([]rune(l1.Val)) == 1 && l0.IgnoreCase == l1.IgnoreCase:
 					combined = true
 					cm := CharClassMatcher{
 						Chars:      append([]rune(l0.Val), []rune(l1.Val)...),
@@ -111,14 +137,14 @@ func (r *grammarOptimizer) optimize(expr0 Expression) Visitor {
 
 				// Combine LitMatcher with CharClassMatcher
 				// "a" / [bc] => [abc]
-				case lok0 && cok1 && len([]rune(l0.Val)) == 1 && l0.IgnoreCase == c1.IgnoreCase && !c1.Inverted:
+				case lok0 && cok1 && !l0.Keyword && len([]rune(l0.Val)) == 1 && l0.IgnoreCase == c1.IgnoreCase && !c1.Inverted:
 					combined = true
 					c1.Chars = append(c1.Chars, []rune(l0.Val)...)
 					expr.Alternatives[i-1] = c1
 
 				// Combine CharClassMatcher with LitMatcher
 				// [ab] / "c" => [abc]
-				case cok0 && lok1 && len([]rune(l1.Val)) == 1 && c0.IgnoreCase == l1.IgnoreCase && !c0.Inverted:
+				case cok0 && lok1 && !l1.Keyword && len([]rune(l1.Val)) == 1 && c0.IgnoreCase == l1.IgnoreCase && !c0.Inverted:
 					combined = true
 					c0.Chars = append(c0.Chars, []rune(l1.Val)...)
 
@@ -177,9 +203,24 @@ func (r *grammarOptimizer) optimize(expr0 Expression) Visitor {
 		expr.Expr = r.optimizeRule(expr.Expr)
 	case *OneOrMoreExpr:
 		expr.Expr = r.optimizeRule(expr.Expr)
+	case *PrecedenceExpr:
+		expr.Atom = r.optimizeRule(expr.Atom)
+		for _, l := range expr.Levels {
+			for _, op := range l.Operators {
+				op.Expr = r.optimizeRule(op.Expr)
+			}
+		}
+	case *RecoveryExpr:
+		expr.Expr = r.optimizeRule(expr.Expr)
+		expr.RecoverExpr = r.optimizeRule(expr.RecoverExpr)
+	case *RepeatExpr:
+		expr.Expr = r.optimizeRule(expr.Expr)
 	case *Rule:
 		r.rule = expr.Name.Val
 		expr.Expr = r.optimizeRule(expr.Expr)
+	case *SeparatedExpr:
+		expr.Expr = r.optimizeRule(expr.Expr)
+		expr.Sep = r.optimizeRule(expr.Sep)
 	case *SeqExpr:
 		expr.Exprs = r.optimizeRules(expr.Exprs)
 
@@ -198,10 +239,15 @@ func (r *grammarOptimizer) optimize(expr0 Expression) Visitor {
 			if i > 0 {
 				l0, ok0 := expr.Exprs[i-1].(*LitMatcher)
 				l1, ok1 := expr.Exprs[i].(*LitMatcher)
-				if ok0 && ok1 && l0.IgnoreCase == l1.IgnoreCase {
+				// a keyword literal only ends a combined literal
+				if ok0 && ok1 && l0.IgnoreCase == l1.IgnoreCase && !l0.Keyword {
 					r.optimized = true
-					l0.Val += l1.Val
-					expr.Exprs[i-1] = l0
+					// the literals are not cloned with the inlined rules,
+					// the combined literal is a new one
+					combined := *l0
+					combined.Val += l1.Val
+					combined.Keyword, combined.Boundary = l1.Keyword, l1.Boundary
+					expr.Exprs[i-1] = &combined
 					if i+1 < len(expr.Exprs) {
 						expr.Exprs = append(expr.Exprs[:i], expr.Exprs[i+1:]...)
 					} else {
@@ -211,6 +257,9 @@ func (r *grammarOptimizer) optimize(expr0 Expression) Visitor {
 			}
 		}
 
+	case *TriviaExpr:
+		expr.Trivia = r.optimizeRule(expr.Trivia)
+		expr.Expr = r.optimizeRule(expr.Expr)
 	case *ZeroOrMoreExpr:
 		expr.Expr = r.optimizeRule(expr.Expr)
 	case *ZeroOrOneExpr:
@@ -228,20 +277,23 @@ func (r *grammarOptimizer) optimizeRules(exprs []Expression) []Expression {
 
 func (r *grammarOptimizer) optimizeRule(expr Expression) Expression {
 	// Optimize RuleRefExpr
-	if ruleRef, ok := expr.(*RuleRefExpr); ok {
-		if _, ok := r.ruleUsesRules[ruleRef.Name.Val]; !ok {
-			r.optimized = true
-			delete(r.ruleUsedByRules[ruleRef.Name.Val], r.rule)
-			if len(r.ruleUsedByRules[ruleRef.Name.Val]) == 0 {
-				delete(r.ruleUsedByRules, ruleRef.Name.Val)
-			}
-			delete(r.ruleUsesRules[r.rule], ruleRef.Name.Val)
-			if len(r.ruleUsesRules[r.rule]) == 0 {
-				delete(r.ruleUsesRules, r.rule)
-			}
-			// TODO: Check if reference exists, otherwise raise an error, which reference is missing!
-			return cloneExpr(r.rules[ruleRef.Name.Val].Expr)
+	if ruleRef, ok := expr.(*RuleRefExpr); ok && r.inlinable(ruleRef.Name.Val) {
+		name := ruleRef.Name.Val
+		r.optimized = true
+		delete(r.ruleUsedByRules[name], r.rule)
+		if len(r.ruleUsedByRules[name]) == 0 {
+			delete(r.ruleUsedByRules, name)
 		}
+		delete(r.ruleUsesRules[r.rule], name)
+		if len(r.ruleUsesRules[r.rule]) == 0 {
+			delete(r.ruleUsesRules, r.rule)
+		}
+		// the current rule now uses the rules used by the inlined rule
+		for used := range r.ruleUsesRules[name] {
+			set(r.ruleUsesRules, r.rule, used)
+			set(r.ruleUsedByRules, used, r.rule)
+		}
+		return cloneExpr(r.rules[name].Expr)
 	}
 
 	// Remove Choices with only one Alternative left
@@ -263,6 +315,100 @@ func (r *grammarOptimizer) optimizeRule(expr Expression) Expression {
 	return expr
 }
 
+// inlinable returns true if the references to the rule can be replaced by
+// a copy of its expression: the rule references no other rule, or it is
+// referenced only once, is not an entrypoint and is not recursive. The
+// annotated rules and the rules with a display name are never inlined, as
+// it would change the behaviour of the parser or its error messages, and
+// no rule is inlined into an annotated rule, whose annotations may change
+// the meaning of the inlined expression, e.g. the trivia is not inserted
+// in a @token rule. A rule with labels or back-references is never inlined
+// either, its labels would be visible to the caller and could shadow or
+// capture its labels.
+func (r *grammarOptimizer) inlinable(name string) bool {
+	rule := r.rules[name]
+	if rule == nil || len(rule.Annotations) > 0 || rule.DisplayName != nil {
+		return false
+	}
+	if caller := r.rules[r.rule]; caller == nil || len(caller.Annotations) > 0 {
+		return false
+	}
+	labeled := false
+	Inspect(rule.Expr, func(expr Expression) bool {
+		switch expr.(type) {
+		case *LabeledExpr, *BackRefExpr:
+			labeled = true
+		}
+		return !labeled
+	})
+	if labeled {
+		return false
+	}
+	if _, uses := r.ruleUsesRules[name]; !uses {
+		return true
+	}
+	_, protected := r.protectedRules[name]
+	return !protected && r.refCount[name] == 1 && !r.reaches(name, name)
+}
+
+// reaches returns true if the rule to is referenced by the rule from,
+// directly or through other rules.
+func (r *grammarOptimizer) reaches(from, to string) bool {
+	seen := make(map[string]bool)
+	var visit func(string) bool
+	visit = func(name string) bool {
+		for used := range r.ruleUsesRules[name] {
+			if used == to {
+				return true
+			}
+			if !seen[used] {
+				seen[used] = true
+				if visit(used) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return visit(from)
+}
+
+// removeUnreachable removes the rules that cannot be reached from the
+// protected rules, including the cycles of rules that only reference each
+// other.
+func (r *grammarOptimizer) removeUnreachable(g *Grammar) {
+	uses := RuleUses(g)
+	reachable := make(map[string]bool)
+	var visit func(string)
+	visit = func(name string) {
+		if reachable[name] {
+			return
+		}
+		reachable[name] = true
+		for _, used := range uses[name] {
+			visit(used)
+		}
+	}
+	for name := range r.protectedRules {
+		visit(name)
+	}
+
+	rules := g.Rules[:0]
+	for _, rule := range g.Rules {
+		if reachable[rule.Name.Val] {
+			rules = append(rules, rule)
+		}
+	}
+	g.Rules = rules
+}
+
+// Clone returns a copy of the expression that can be used in another rule.
+// The nodes with code blocks are copied, so that each copy gets its own
+// generated function.
+func Clone(expr Expression) Expression {
+	return cloneExpr(expr)
+}
+
 // cloneExpr takes an Expression and deep clones it (including all children)
 // This is necessary because referenced Rules are denormalized and therefore
 // have to become independent from their original Expression.
@@ -294,6 +440,14 @@ func cloneExpr(expr Expression) Expression {
 			posValue:       expr.posValue,
 			Ranges:         append([]rune{}, expr.Ranges...),
 			UnicodeClasses: append([]string{}, expr.UnicodeClasses...),
+			Table:          expr.Table,
+		}
+	case *CodeExpr:
+		return &CodeExpr{
+			Code:    expr.Code,
+			FuncIx:  expr.FuncIx,
+			NotSkip: expr.NotSkip,
+			p:       expr.p,
 		}
 	case *ChoiceExpr:
 		alts := make([]Expression, 0, len(expr.Alternatives))
@@ -306,9 +460,11 @@ func cloneExpr(expr Expression) Expression {
 		}
 	case *LabeledExpr:
 		return &LabeledExpr{
-			Expr:  cloneExpr(expr.Expr),
-			Label: expr.Label,
-			p:     expr.p,
+			Expr:        cloneExpr(expr.Expr),
+			Label:       expr.Label,
+			TextCapture: expr.TextCapture,
+			BackRef:     expr.BackRef,
+			p:           expr.p,
 		}
 	case *NotExpr:
 		return &NotExpr{
@@ -326,6 +482,51 @@ func cloneExpr(expr Expression) Expression {
 			Expr: cloneExpr(expr.Expr),
 			p:    expr.p,
 		}
+	case *PrecedenceExpr:
+		levels := make([]*PrecedenceLevel, 0, len(expr.Levels))
+		for _, l := range expr.Levels {
+			ops := make([]*Operator, 0, len(l.Operators))
+			for _, op := range l.Operators {
+				ops = append(ops, &Operator{
+					Expr:   cloneExpr(op.Expr),
+					Code:   op.Code,
+					FuncIx: op.FuncIx,
+					p:      op.p,
+				})
+			}
+			levels = append(levels, &PrecedenceLevel{
+				Kind:      l.Kind,
+				Operators: ops,
+				p:         l.p,
+			})
+		}
+		return &PrecedenceExpr{
+			Atom:   cloneExpr(expr.Atom),
+			Levels: levels,
+			p:      expr.p,
+		}
+	case *RecoveryExpr:
+		return &RecoveryExpr{
+			Expr:        cloneExpr(expr.Expr),
+			RecoverExpr: cloneExpr(expr.RecoverExpr),
+			Labels:      expr.Labels,
+			p:           expr.p,
+		}
+	case *RepeatExpr:
+		return &RepeatExpr{
+			Expr: cloneExpr(expr.Expr),
+			Min:  expr.Min,
+			Max:  expr.Max,
+			p:    expr.p,
+		}
+	case *SeparatedExpr:
+		return &SeparatedExpr{
+			Expr:          cloneExpr(expr.Expr),
+			Sep:           cloneExpr(expr.Sep),
+			Min:           expr.Min,
+			AllowTrailing: expr.AllowTrailing,
+			p:             expr.p,
+		}
 	case *SeqExpr:
 		exprs := make([]Expression, 0, len(expr.Exprs))
 		for i := 0; i < len(expr.Exprs); i++ {
@@ -335,6 +536,12 @@ func cloneExpr(expr Expression) Expression {
 			Exprs: exprs,
 			p:     expr.p,
 		}
+	case *TriviaExpr:
+		return &TriviaExpr{
+			Trivia: cloneExpr(expr.Trivia),
+			Expr:   cloneExpr(expr.Expr),
+			p:      expr.p,
+		}
 	case *ZeroOrMoreExpr:
 		return &ZeroOrMoreExpr{
 			Expr: cloneExpr(expr.Expr),
@@ -356,7 +563,7 @@ func cloneExpr(expr Expression) Expression {
 // correct content for the Val field (string representation of the CharClassMatcher).
 func (r *grammarOptimizer) cleanupCharClassMatcher(expr0 Expression) Visitor {
 	// We are only interested in nodes of type *CharClassMatcher
-	if chr, ok := expr0.(*CharClassMatcher); ok {
+	if chr, ok := expr0.(*CharClassMatcher); ok && chr.Table == nil {
 		// Remove redundancies in Chars
 		chars := make([]rune, 0, len(chr.Chars))
 		charsMap := make(map[rune]struct{})
@@ -435,9 +642,13 @@ func escapeRune(r rune) string {
 
 // Optimize walks a given grammar and optimizes the grammar in regards
 // of parsing performance. This is done with several optimizations:
-//   - removal of unreferenced rules
+//   - removal of the rules that cannot be reached from the first rule and
+//     the alternate entrypoints
 //   - replace rule references with a copy of the referenced Rule, if the
-//     referenced rule it self has no references.
+//     referenced rule it self has no references, or if it is referenced
+//     only once and is not recursive. Annotated rules, rules with a
+//     display name, labels or back-references are never inlined, and no
+//     rule is inlined into an annotated rule.
 //   - resolve nested choice expressions
 //   - resolve choice expressions with only one alternative
 //   - resolve nested sequences expression
@@ -458,6 +669,8 @@ func Optimize(g *Grammar, alternateEntrypoints ...string) {
 		Walk(r, g)
 	}
 
+	r.removeUnreachable(g)
+
 	r.visitor = r.cleanupCharClassMatcher
 	Walk(r, g)
 }
@@ -360,3 +360,103 @@ func TestOptimize(t *testing.T) {
 		}
 	}
 }
+
+func TestRuleUses(t *testing.T) {
+	ref := func(name string) *RuleRefExpr {
+		return &RuleRefExpr{Name: NewIdentifier(Pos{}, name)}
+	}
+	g := &Grammar{
+		Rules: []*Rule{
+			{Name: NewIdentifier(Pos{}, "a"), Expr: &SeqExpr{Exprs: []Expression{ref("c"), ref("b"), &ZeroOrMoreExpr{Expr: ref("c")}}}},
+			{Name: NewIdentifier(Pos{}, "b"), Expr: &ChoiceExpr{Alternatives: []Expression{ref("b"), &LitMatcher{}}}},
+			{Name: NewIdentifier(Pos{}, "c"), Expr: &AnyMatcher{}},
+		},
+	}
+
+	want := map[string][]string{
+		"a": {"b", "c"},
+		"b": {"b"},
+	}
+	if got := RuleUses(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestOptimizeInlining(t *testing.T) {
+	ref := func(name string) *RuleRefExpr {
+		return &RuleRefExpr{Name: NewIdentifier(Pos{}, name)}
+	}
+	lit := func(val string) *LitMatcher {
+		return &LitMatcher{posValue: posValue{Val: val}}
+	}
+	g := &Grammar{
+		Rules: []*Rule{
+			{Name: NewIdentifier(Pos{}, "start"), Expr: &SeqExpr{Exprs: []Expression{ref("once"), ref("twice"), ref("labeled"), ref("labeled")}}},
+			{Name: NewIdentifier(Pos{}, "once"), Expr: &SeqExpr{Exprs: []Expression{ref("twice"), lit("a")}}},
+			{Name: NewIdentifier(Pos{}, "twice"), Expr: &ChoiceExpr{Alternatives: []Expression{lit("b"), ref("twice")}}},
+			{Name: NewIdentifier(Pos{}, "labeled"), Expr: &LabeledExpr{Label: NewIdentifier(Pos{}, "x"), Expr: ref("once")}},
+			{Name: NewIdentifier(Pos{}, "cycle"), Expr: &SeqExpr{Exprs: []Expression{lit("c"), ref("loop")}}},
+			{Name: NewIdentifier(Pos{}, "loop"), Expr: &ZeroOrOneExpr{Expr: ref("cycle")}},
+		},
+	}
+	Optimize(g)
+
+	var names []string
+	for _, rule := range g.Rules {
+		names = append(names, rule.Name.Val)
+	}
+	if want := []string{"start", "once", "twice", "labeled"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("want rules %v, got %v", want, names)
+	}
+	// once is used by start and by labeled, so it is not inlined, labeled is
+	// used twice and the recursive twice is never inlined.
+	if uses := RuleUses(g); !reflect.DeepEqual(uses["start"], []string{"labeled", "once", "twice"}) {
+		t.Errorf("want start to use labeled, once and twice, got %v", uses["start"])
+	}
+
+	g = &Grammar{
+		Rules: []*Rule{
+			{Name: NewIdentifier(Pos{}, "start"), Expr: &SeqExpr{Exprs: []Expression{ref("once"), lit("c")}}},
+			{Name: NewIdentifier(Pos{}, "once"), Expr: &SeqExpr{Exprs: []Expression{lit("a"), ref("leaf"), ref("leaf")}}},
+			{Name: NewIdentifier(Pos{}, "leaf"), Expr: lit("b")},
+		},
+	}
+	Optimize(g)
+
+	if len(g.Rules) != 1 {
+		t.Fatalf("want the used rules inlined, got %d rules", len(g.Rules))
+	}
+	if l, ok := g.Rules[0].Expr.(*LitMatcher); !ok || l.Val != "abbc" {
+		t.Errorf("want the literal %q, got %#v", "abbc", g.Rules[0].Expr)
+	}
+
+	// the rules with labels or back-references are not inlined, even if
+	// they reference no other rule, and no rule is inlined into an
+	// annotated rule
+	g = &Grammar{
+		Rules: []*Rule{
+			{Name: NewIdentifier(Pos{}, "start"), Expr: &SeqExpr{Exprs: []Expression{ref("labeled"), ref("backref"), ref("token")}}},
+			{Name: NewIdentifier(Pos{}, "labeled"), Expr: &LabeledExpr{Label: NewIdentifier(Pos{}, "x"), Expr: lit("a")}},
+			{Name: NewIdentifier(Pos{}, "backref"), Expr: &BackRefExpr{Label: NewIdentifier(Pos{}, "x")}},
+			{
+				Name:        NewIdentifier(Pos{}, "token"),
+				Annotations: []*Identifier{NewIdentifier(Pos{}, "token")},
+				Expr:        &SeqExpr{Exprs: []Expression{ref("inner"), lit("b")}},
+			},
+			{Name: NewIdentifier(Pos{}, "inner"), Expr: &SeqExpr{Exprs: []Expression{ref("leaf"), ref("leaf")}}},
+			{Name: NewIdentifier(Pos{}, "leaf"), Expr: lit("c")},
+		},
+	}
+	Optimize(g)
+
+	uses := RuleUses(g)
+	if want := []string{"backref", "labeled", "token"}; !reflect.DeepEqual(uses["start"], want) {
+		t.Errorf("want start to use %v, got %v", want, uses["start"])
+	}
+	if want := []string{"inner"}; !reflect.DeepEqual(uses["token"], want) {
+		t.Errorf("want token to use %v, got %v", want, uses["token"])
+	}
+	if want := []string(nil); !reflect.DeepEqual(uses["inner"], want) {
+		t.Errorf("want leaf inlined in inner, got %v", uses["inner"])
+	}
+}
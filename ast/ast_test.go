@@ -3,6 +3,7 @@ package ast
 import (
 	"strings"
 	"testing"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -105,3 +106,77 @@ func TestCharClassParse(t *testing.T) {
 		}
 	}
 }
+
+func TestCharClassSetOps(t *testing.T) {
+	cases := []struct {
+		class string
+		in    string
+		out   string
+	}{
+		{`[\pL--[aeiou]]`, "bzBAΩ", "aeiou1 "},
+		{`[\p{Greek}&&\p{Lu}]`, "ΑΩΔ", "αωaA"},
+		{`[a-z--[aeiou]--[xyz]]`, "bcw", "aexyzB"},
+		{`[a-z&&[^aeiou]]`, "bcz", "aeiB"},
+		{`[\pN--[0-8]]`, "9٣", "08a"},
+		{`[a-z--\p{Lu}]`, "az", "A"},
+		{`[a-z--[a-z]]`, "", "az"},
+		{`[\p{Latin}--[a-zA-Z]]`, "éÅ", "aZ1"},
+		{`[A-Z--[AEIOU]]i`, "bz", "ae"},
+		{`[^\pL--[aeiou]]`, "ae1", "bz"},
+		{`[a\]--[\]]`, "a", "]"},
+	}
+	for _, tc := range cases {
+		m := NewCharClassMatcher(Pos{}, tc.class)
+		if m.Table == nil {
+			t.Errorf("%q: want range table", tc.class)
+			continue
+		}
+		if len(m.Chars) != 0 || len(m.Ranges) != 0 || len(m.UnicodeClasses) != 0 {
+			t.Errorf("%q: want only a range table, got %v, %v, %v", tc.class, m.Chars, m.Ranges, m.UnicodeClasses)
+		}
+		is := func(r rune) bool {
+			in := unicode.Is(m.Table, r)
+			if m.IgnoreCase {
+				for f := unicode.SimpleFold(r); f != r && !in; f = unicode.SimpleFold(f) {
+					in = unicode.Is(m.Table, f)
+				}
+			}
+			return in != m.Inverted
+		}
+		for _, r := range tc.in {
+			if !is(r) {
+				t.Errorf("%q: want %q in class", tc.class, r)
+			}
+		}
+		for _, r := range tc.out {
+			if is(r) {
+				t.Errorf("%q: want %q not in class", tc.class, r)
+			}
+		}
+	}
+}
+
+func TestCharClassNullable(t *testing.T) {
+	// a character class consumes a character whenever it matches, even an
+	// empty one, which never matches, or an inverted empty one, which
+	// matches any character.
+	for _, c := range []string{"[]", "[^]", "[]i", "[a]", "[^a]", `[\pL]`, "[a-z--[a-z]]", "[^a-z--[a-z]]"} {
+		m := NewCharClassMatcher(Pos{}, c)
+		if m.IsNullable() {
+			t.Errorf("%q: want not nullable", c)
+		}
+		if m.NullableVisit(nil) {
+			t.Errorf("%q: want not nullable when visited", c)
+		}
+
+		// so a rule reference that follows it is not a left recursion,
+		// e.g. in A = [^] A.
+		ref := NewRuleRefExpr(Pos{})
+		ref.Name = NewIdentifier(Pos{}, "A")
+		seq := NewSeqExpr(Pos{})
+		seq.Exprs = []Expression{m, ref}
+		if _, ok := seq.InitialNames()["A"]; ok {
+			t.Errorf("%q A: want A not among the initial names", c)
+		}
+	}
+}
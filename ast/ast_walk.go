@@ -29,6 +29,8 @@ func Walk(v Visitor, expr Expression) {
 		Walk(v, expr.Expr)
 	case *AnyMatcher:
 		// Nothing to do
+	case *BackRefExpr:
+		// Nothing to do
 	case *CharClassMatcher:
 		// Nothing to do
 	case *ChoiceExpr:
@@ -42,23 +44,45 @@ func Walk(v Visitor, expr Expression) {
 	case *LabeledExpr:
 		Walk(v, expr.Expr)
 	case *LitMatcher:
-		// Nothing to do
+		if expr.Boundary != nil {
+			Walk(v, expr.Boundary)
+		}
 	case *NotCodeExpr:
 		// Nothing to do
 	case *NotExpr:
 		Walk(v, expr.Expr)
 	case *OneOrMoreExpr:
 		Walk(v, expr.Expr)
+	case *PrecedenceExpr:
+		Walk(v, expr.Atom)
+		for _, l := range expr.Levels {
+			for _, op := range l.Operators {
+				Walk(v, op.Expr)
+			}
+		}
+	case *RecoveryExpr:
+		Walk(v, expr.Expr)
+		Walk(v, expr.RecoverExpr)
+	case *RepeatExpr:
+		Walk(v, expr.Expr)
 	case *Rule:
 		Walk(v, expr.Expr)
 	case *RuleRefExpr:
 		// Nothing to do
+	case *SeparatedExpr:
+		Walk(v, expr.Expr)
+		Walk(v, expr.Sep)
 	case *SeqExpr:
 		for _, e := range expr.Exprs {
 			Walk(v, e)
 		}
 	case *CodeExpr:
 		// Nothing to do
+	case *ThrowExpr:
+		// Nothing to do
+	case *TriviaExpr:
+		Walk(v, expr.Trivia)
+		Walk(v, expr.Expr)
 	case *ZeroOrMoreExpr:
 		Walk(v, expr.Expr)
 	case *ZeroOrOneExpr:
@@ -0,0 +1,79 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+const (
+	// MemoAnnotation marks a rule whose results are always memoized.
+	MemoAnnotation = "memo"
+	// NoMemoAnnotation marks a rule whose results are never memoized, even
+	// if the memoize option of the generated parser is set.
+	NoMemoAnnotation = "nomemo"
+	// NoTraceAnnotation marks a rule that is excluded, with the rules it
+	// references, from the debug output of the generated parser.
+	NoTraceAnnotation = "notrace"
+	// EntryAnnotation marks a rule that may be used as an entrypoint of
+	// the generated parser, in addition to the first rule.
+	EntryAnnotation = "entry"
+)
+
+// RuleAnnotations is the set of annotations that may precede a rule.
+var RuleAnnotations = map[string]bool{
+	EntryAnnotation:   true,
+	InlineAnnotation:  true,
+	MemoAnnotation:    true,
+	NoMemoAnnotation:  true,
+	NoTraceAnnotation: true,
+	TokenAnnotation:   true,
+	TriviaAnnotation:  true,
+	WordAnnotation:    true,
+}
+
+// conflictingAnnotations lists the pairs of annotations that cannot be
+// set on the same rule.
+var conflictingAnnotations = [][2]string{
+	{MemoAnnotation, NoMemoAnnotation},
+	{InlineAnnotation, MemoAnnotation},
+	{InlineAnnotation, NoTraceAnnotation},
+}
+
+// CheckAnnotations returns an error if a rule of the grammar has an
+// unknown annotation, the same annotation twice or conflicting
+// annotations.
+func CheckAnnotations(grammar *ast.Grammar) error {
+	for _, rule := range grammar.Rules {
+		seen := make(map[string]bool, len(rule.Annotations))
+		for _, a := range rule.Annotations {
+			if !RuleAnnotations[a.Val] {
+				return fmt.Errorf("%s: rule %s: unknown annotation @%s",
+					a.Pos(), rule.Name.Val, a.Val)
+			}
+			if seen[a.Val] {
+				return fmt.Errorf("%s: rule %s: duplicate annotation @%s",
+					a.Pos(), rule.Name.Val, a.Val)
+			}
+			seen[a.Val] = true
+		}
+		for _, pair := range conflictingAnnotations {
+			if seen[pair[0]] && seen[pair[1]] {
+				return fmt.Errorf("%s: rule %s: conflicting annotations @%s and @%s",
+					rule.Pos(), rule.Name.Val, pair[0], pair[1])
+			}
+		}
+	}
+	return nil
+}
+
+// EntryRules returns the names of the rules annotated with @entry.
+func EntryRules(grammar *ast.Grammar) []string {
+	var names []string
+	for _, rule := range grammar.Rules {
+		if rule.HasAnnotation(EntryAnnotation) {
+			names = append(names, rule.Name.Val)
+		}
+	}
+	return names
+}
@@ -0,0 +1,51 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// ResolveBackRefs checks that every back-reference of the grammar refers
+// to a text capture label of the same rule. It marks these labels, so
+// that their text is captured even inside predicates, and the rules that
+// contain back-references.
+func ResolveBackRefs(grammar *ast.Grammar) error {
+	for _, rule := range grammar.Rules {
+		r := &backRefResolver{labels: make(map[string][]*ast.LabeledExpr)}
+		ast.Walk(r, rule.Expr)
+		for _, ref := range r.refs {
+			labels := r.labels[ref.Label.Val]
+			if len(labels) == 0 {
+				return fmt.Errorf("%s: rule %s: back-reference $%s to undefined label",
+					ref.Pos(), rule.Name.Val, ref.Label.Val)
+			}
+			for _, lab := range labels {
+				if !lab.TextCapture {
+					return fmt.Errorf("%s: rule %s: back-reference $%s to label defined at %s without text capture",
+						ref.Pos(), rule.Name.Val, ref.Label.Val, lab.Pos())
+				}
+				lab.BackRef = true
+			}
+			rule.HasBackRef = true
+		}
+	}
+	return nil
+}
+
+// backRefResolver is a Visitor that collects the labels and the
+// back-references of a rule.
+type backRefResolver struct {
+	labels map[string][]*ast.LabeledExpr
+	refs   []*ast.BackRefExpr
+}
+
+func (r *backRefResolver) Visit(expr ast.Expression) ast.Visitor {
+	switch expr := expr.(type) {
+	case *ast.LabeledExpr:
+		r.labels[expr.Label.Val] = append(r.labels[expr.Label.Val], expr)
+	case *ast.BackRefExpr:
+		r.refs = append(r.refs, expr)
+	}
+	return r
+}
@@ -0,0 +1,66 @@
+package builder_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/builder"
+)
+
+// backRefGrammar returns a grammar with a single rule matching
+// label:<'a'> $ref, the label captures text if textCapture is set.
+func backRefGrammar(label, ref string, textCapture bool) *ast.Grammar {
+	lab := ast.NewLabeledExpr(ast.Pos{Line: 1, Col: 5})
+	lab.Label = ast.NewIdentifier(ast.Pos{Line: 1, Col: 5}, label)
+	lab.Expr = ast.NewLitMatcher(ast.Pos{Line: 1, Col: 12}, "a")
+	lab.TextCapture = textCapture
+
+	backRef := ast.NewBackRefExpr(ast.Pos{Line: 1, Col: 17})
+	backRef.Label = ast.NewIdentifier(ast.Pos{Line: 1, Col: 18}, ref)
+
+	seq := ast.NewSeqExpr(ast.Pos{Line: 1, Col: 5})
+	seq.Exprs = []ast.Expression{lab, backRef}
+
+	rule := ast.NewRule(ast.Pos{Line: 1, Col: 1}, ast.NewIdentifier(ast.Pos{Line: 1, Col: 1}, "a"))
+	rule.Expr = seq
+
+	grammar := ast.NewGrammar(ast.Pos{})
+	grammar.Rules = []*ast.Rule{rule}
+	return grammar
+}
+
+func TestResolveBackRefs(t *testing.T) {
+	t.Parallel()
+
+	grammar := backRefGrammar("tag", "tag", true)
+	if err := builder.ResolveBackRefs(grammar); err != nil {
+		t.Fatal(err)
+	}
+	rule := grammar.Rules[0]
+	if !rule.HasBackRef {
+		t.Errorf("want rule with back-reference")
+	}
+	lab := rule.Expr.(*ast.SeqExpr).Exprs[0].(*ast.LabeledExpr)
+	if !lab.BackRef {
+		t.Errorf("want label referenced by a back-reference")
+	}
+}
+
+func TestResolveBackRefsErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		grammar *ast.Grammar
+		err     string
+	}{
+		{backRefGrammar("tag", "other", true), "1:17 (0): rule a: back-reference $other to undefined label"},
+		{backRefGrammar("tag", "tag", false), "1:17 (0): rule a: back-reference $tag to label defined at 1:5 (0) without text capture"},
+	}
+	for _, tc := range cases {
+		err := builder.ResolveBackRefs(tc.grammar)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("want error %q, got %v", tc.err, err)
+		}
+	}
+}
@@ -50,14 +50,14 @@ func (b *Builder) TemplateRender(text string, trim bool) string {
 // generated function templates
 var (
 	callCodeFuncTemplate = `func (p *parser) call{{.FuncName}}() any {
-{{ if .useStack }} stack := p.vstack[len(p.vstack)-1]; {{ end }} return (func (c *current, {{.paramsDef}}) any {
+{{ if .useStack }} stack := p.vstack[len(p.vstack)-1]; {{ end }} return (func ({{.recvName}} *current, {{.paramsDef}}) any {
 		{{.code}}
-		return nil
-	})(&p.cur, {{.paramsCall}})
+{{ if not .terminating }}		return nil
+{{ end }}	})(&p.cur, {{.paramsCall}})
 }
 `
 	callPredFuncTemplate = `func (p *parser) call{{.FuncName}}() bool {
-{{ if .useStack }} stack := p.vstack[len(p.vstack)-1]; {{ end }}	return (func (c *current, {{.paramsDef}}) bool {
+{{ if .useStack }} stack := p.vstack[len(p.vstack)-1]; {{ end }}	return (func ({{.recvName}} *current, {{.paramsDef}}) bool {
 		{{.code}}
 	})(&p.cur, {{.paramsCall}})
 }
@@ -122,6 +122,17 @@ func Optimize(optimize bool) Option {
 	}
 }
 
+// ASCIIFold returns an option that specifies the ASCIIFold option.
+// If ASCIIFold is true, case-insensitive matchers only fold the case of
+// ASCII letters instead of using the Unicode simple case folding.
+func ASCIIFold(enable bool) Option {
+	return func(b *Builder) Option {
+		prev := b.ASCIIFold
+		b.ASCIIFold = enable
+		return ASCIIFold(prev)
+	}
+}
+
 // Nolint returns an option that specifies the Nolint option
 // If Nolint is true, special '// Nolint: ...' comments are added
 // to the generated parser to suppress warnings by gometalinter or golangci-lint.
@@ -133,11 +144,89 @@ func Nolint(nolint bool) Option {
 	}
 }
 
+// CodeFuncs returns an option that records in funcs the code block of
+// each generated function, by function name, e.g. "call_onRule_1". It
+// is used to map the positions of the generated code to the grammar.
+func CodeFuncs(funcs map[string]*ast.CodeBlock) Option {
+	return func(b *Builder) Option {
+		prev := b.CodeFuncs
+		b.CodeFuncs = funcs
+		return CodeFuncs(prev)
+	}
+}
+
+// LineDirectives returns an option that specifies the LineDirectives
+// option. If grammarFile is not empty, the body of each code block is
+// preceded by a //line directive to its position in grammarFile and
+// followed by a //line directive back to parserFile, the name of the
+// generated file. The directives back to parserFile must be set to their
+// line once the code is formatted, see ResolveLineDirectives.
+func LineDirectives(grammarFile, parserFile string) Option {
+	return func(b *Builder) Option {
+		prevGrammar, prevParser := b.LineGrammarFile, b.LineParserFile
+		b.LineGrammarFile, b.LineParserFile = grammarFile, parserFile
+		return LineDirectives(prevGrammar, prevParser)
+	}
+}
+
+// FuzzTest returns an option that specifies the FuzzTest option. If w is
+// not nil, a fuzz test of the generated parser is written to w, to be
+// saved alongside the parser as a _test.go file of its package. Its
+// FuzzParse function checks that the parser terminates without panicking
+// and that the positions of its errors are within the input, the files of
+// corpusDir, relative to the package directory, seed its corpus if it is
+// not empty.
+func FuzzTest(w io.Writer, corpusDir string) Option {
+	return func(b *Builder) Option {
+		prevW, prevDir := b.FuzzTestW, b.FuzzCorpusDir
+		b.FuzzTestW, b.FuzzCorpusDir = w, corpusDir
+		return FuzzTest(prevW, prevDir)
+	}
+}
+
+// Resolve checks the grammar and resolves its annotations and its
+// extensions, as done before generating the parser: the trivia is
+// inserted, the rules annotated with @inline are inlined, the keywords and
+// the back-references are resolved, and the grammar is rejected if it is
+// left-recursive. The grammar is modified in place.
+func Resolve(grammar *ast.Grammar) error {
+	steps := []func(*ast.Grammar) error{
+		CheckRuleRefs,
+		CheckAnnotations,
+		InsertTrivia,
+		InlineRules,
+		ResolveKeywords,
+		ResolveBackRefs,
+		CheckRepetitions,
+	}
+	for _, step := range steps {
+		if err := step(grammar); err != nil {
+			return err
+		}
+	}
+
+	haveLeftRecursion, err := PrepareGrammar(grammar)
+	if err != nil {
+		return err
+	}
+	if haveLeftRecursion {
+		return ErrHaveLeftRecursion
+	}
+	return nil
+}
+
 // BuildParser builds the PEG parser using the provider grammar. The code is
-// written to the specified W.
+// written to the specified W. The options set in the options block of the
+// grammar are applied first, opts take precedence over them.
 func BuildParser(w io.Writer, g *ast.Grammar, opts ...Option) error {
-	b := &Builder{W: w, RecvName: "c", Target: "go"}
+	grammarOpts, err := GrammarOptions(g)
+	if err != nil {
+		return err
+	}
+
+	b := &Builder{W: w, RecvName: "c", Target: "go", GrammarName: "g"}
 	b.Init()
+	b.SetOptions(grammarOpts)
 	b.SetOptions(opts)
 	return b.BuildParser(g)
 }
@@ -152,6 +241,7 @@ type Builder struct {
 	Nolint            bool
 	SetRulePos        bool
 	HaveLeftRecursion bool
+	ASCIIFold         bool
 
 	RuleName  string
 	ExprIndex int
@@ -171,6 +261,20 @@ type Builder struct {
 
 	RuleName2Index map[string]*ExprInfo
 
+	// CodeFuncs records the code block of each generated function, by
+	// function name, if not nil.
+	CodeFuncs map[string]*ast.CodeBlock
+
+	// LineGrammarFile and LineParserFile are the file names used in the
+	// line directives around the code blocks, if LineGrammarFile is set.
+	LineGrammarFile string
+	LineParserFile  string
+
+	// FuzzTestW receives the fuzz test of the parser if not nil, seeded
+	// with the files of FuzzCorpusDir if set.
+	FuzzTestW     io.Writer
+	FuzzCorpusDir string
+
 	Shims       OverrideShims
 	GetExprInfo func(expr ast.Expression) *ExprInfo
 
@@ -190,6 +294,8 @@ func GetExprInfo(expr ast.Expression) *ExprInfo {
 		return &ExprInfo{ExprType: "anyMatcher"}
 	case *ast.CharClassMatcher:
 		return &ExprInfo{ExprType: "charClassMatcher"}
+	case *ast.BackRefExpr:
+		return &ExprInfo{ExprType: "backRefExpr"}
 	case *ast.ChoiceExpr:
 		return &ExprInfo{ExprType: "choiceExpr"}
 	case *ast.LabeledExpr:
@@ -202,16 +308,24 @@ func GetExprInfo(expr ast.Expression) *ExprInfo {
 		return &ExprInfo{ExprType: "notExpr"}
 	case *ast.OneOrMoreExpr:
 		return &ExprInfo{ExprType: "oneOrMoreExpr"}
+	case *ast.PrecedenceExpr:
+		return &ExprInfo{ExprType: "precedenceExpr"}
 	case *ast.RecoveryExpr:
 		return &ExprInfo{ExprType: "recoveryExpr"}
+	case *ast.RepeatExpr:
+		return &ExprInfo{ExprType: "repeatExpr"}
 	case *ast.RuleRefExpr:
 		return &ExprInfo{ExprType: "ruleRefExpr"}
+	case *ast.SeparatedExpr:
+		return &ExprInfo{ExprType: "separatedExpr"}
 	case *ast.SeqExpr:
 		return &ExprInfo{ExprType: "seqExpr"}
 	case *ast.CodeExpr:
 		return &ExprInfo{ExprType: "codeExpr"}
 	case *ast.ThrowExpr:
 		return &ExprInfo{ExprType: "throwExpr"}
+	case *ast.TriviaExpr:
+		return &ExprInfo{ExprType: "triviaExpr"}
 	case *ast.ZeroOrMoreExpr:
 		return &ExprInfo{ExprType: "zeroOrMoreExpr"}
 	case *ast.ZeroOrOneExpr:
@@ -245,21 +359,16 @@ func (r *RuleLabelCheck) Visit(expr ast.Expression) ast.Visitor {
 }
 
 func (b *Builder) BuildParser(grammar *ast.Grammar) error {
+	if err := Resolve(grammar); err != nil {
+		return fmt.Errorf("incorrect grammar: %w", err)
+	}
+
 	for index, rule := range grammar.Rules {
 		r := &RuleLabelCheck{}
 		ast.Walk(r, rule.Expr)
 		grammar.Rules[index].IsLabelExists = r.IsLabelExists
 	}
 
-	haveLeftRecursion, err := PrepareGrammar(grammar)
-	if err != nil {
-		return fmt.Errorf("incorrect grammar: %W", err)
-	}
-	if haveLeftRecursion {
-		return fmt.Errorf("incorrect grammar: %W", ErrHaveLeftRecursion)
-	}
-	b.HaveLeftRecursion = haveLeftRecursion
-
 	b.writeInit(grammar.Init)
 	if !b.GrammarMap {
 		b.writeGrammar(grammar)
@@ -274,6 +383,9 @@ func (b *Builder) BuildParser(grammar *ast.Grammar) error {
 		b.writeStaticCodeWrap()
 	}
 
+	if b.Err == nil && b.FuzzTestW != nil {
+		return b.writeFuzzTest(grammar)
+	}
 	return b.Err
 }
 
@@ -310,6 +422,8 @@ func (b *Builder) WriteExpr(expr ast.Expression) {
 		b.writeAnyMatcher(expr)
 	case *ast.CharClassMatcher:
 		b.writeCharClassMatcher(expr)
+	case *ast.BackRefExpr:
+		b.writeBackRefExpr(expr)
 	case *ast.ChoiceExpr:
 		b.writeChoiceExpr(expr)
 	case *ast.LabeledExpr:
@@ -322,16 +436,24 @@ func (b *Builder) WriteExpr(expr ast.Expression) {
 		b.writeNotExpr(expr)
 	case *ast.OneOrMoreExpr:
 		b.writeOneOrMoreExpr(expr)
+	case *ast.PrecedenceExpr:
+		b.writePrecedenceExpr(expr)
 	case *ast.RecoveryExpr:
 		b.writeRecoveryExpr(expr)
+	case *ast.RepeatExpr:
+		b.writeRepeatExpr(expr)
 	case *ast.RuleRefExpr:
 		b.writeRuleRefExpr(expr)
+	case *ast.SeparatedExpr:
+		b.writeSeparatedExpr(expr)
 	case *ast.SeqExpr:
 		b.writeSeqExpr(expr)
 	case *ast.CodeExpr:
 		b.writeCodeExpr(expr)
 	case *ast.ThrowExpr:
 		b.writeThrowExpr(expr)
+	case *ast.TriviaExpr:
+		b.writeTriviaExpr(expr)
 	case *ast.ZeroOrMoreExpr:
 		b.writeZeroOrMoreExpr(expr)
 	case *ast.ZeroOrOneExpr:
@@ -357,6 +479,10 @@ func (b *Builder) writeAndCodeExpr(and *ast.AndCodeExpr) {
 	b.Shims.WriteAndCodeExpr(b, and)
 }
 
+func (b *Builder) writeBackRefExpr(ref *ast.BackRefExpr) {
+	b.Shims.WriteBackRefExpr(b, ref)
+}
+
 func (b *Builder) writeCharClassMatcher(ch *ast.CharClassMatcher) {
 	b.Shims.WriteCharClassMatcher(b, ch)
 }
@@ -389,14 +515,26 @@ func (b *Builder) writeOneOrMoreExpr(one *ast.OneOrMoreExpr) {
 	b.Shims.WriteOneOrMoreExpr(b, one)
 }
 
+func (b *Builder) writePrecedenceExpr(prec *ast.PrecedenceExpr) {
+	b.Shims.WritePrecedenceExpr(b, prec)
+}
+
 func (b *Builder) writeRecoveryExpr(recover *ast.RecoveryExpr) {
 	b.Shims.WriteRecoveryExpr(b, recover)
 }
 
+func (b *Builder) writeRepeatExpr(rep *ast.RepeatExpr) {
+	b.Shims.WriteRepeatExpr(b, rep)
+}
+
 func (b *Builder) writeRuleRefExpr(ref *ast.RuleRefExpr) {
 	b.Shims.WriteRuleRefExpr(b, ref)
 }
 
+func (b *Builder) writeSeparatedExpr(sep *ast.SeparatedExpr) {
+	b.Shims.WriteSeparatedExpr(b, sep)
+}
+
 func (b *Builder) writeSeqExpr(seq *ast.SeqExpr) {
 	b.Shims.WriteSeqExpr(b, seq)
 }
@@ -405,6 +543,10 @@ func (b *Builder) writeThrowExpr(throw *ast.ThrowExpr) {
 	b.Shims.WriteThrowExpr(b, throw)
 }
 
+func (b *Builder) writeTriviaExpr(trivia *ast.TriviaExpr) {
+	b.Shims.WriteTriviaExpr(b, trivia)
+}
+
 func (b *Builder) writeZeroOrMoreExpr(zero *ast.ZeroOrMoreExpr) {
 	b.Shims.WriteZeroOrMoreExpr(b, zero)
 }
@@ -482,12 +624,36 @@ func (b *Builder) writeExprCode(expr ast.Expression) {
 		b.writeExprCode(expr.Expr)
 		b.popArgsSet()
 
+	case *ast.PrecedenceExpr:
+		b.pushArgsSet()
+		b.writeExprCode(expr.Atom)
+		b.popArgsSet()
+		for _, level := range expr.Levels {
+			for _, op := range level.Operators {
+				b.pushArgsSet()
+				b.writeExprCode(op.Expr)
+				b.popArgsSet()
+				b.writeOperatorCode(level.Kind, op)
+			}
+		}
+
 	case *ast.RecoveryExpr:
 		b.pushArgsSet()
 		b.writeExprCode(expr.Expr)
 		b.writeExprCode(expr.RecoverExpr)
 		b.popArgsSet()
 
+	case *ast.RepeatExpr:
+		b.pushArgsSet()
+		b.writeExprCode(expr.Expr)
+		b.popArgsSet()
+
+	case *ast.SeparatedExpr:
+		b.pushArgsSet()
+		b.writeExprCode(expr.Expr)
+		b.writeExprCode(expr.Sep)
+		b.popArgsSet()
+
 	case *ast.SeqExpr:
 		for _, sub := range expr.Exprs {
 			b.writeExprCode(sub)
@@ -496,6 +662,10 @@ func (b *Builder) writeExprCode(expr ast.Expression) {
 	case *ast.CodeExpr:
 		b.writeCodeExprCode(expr)
 
+	case *ast.TriviaExpr:
+		b.writeExprCode(expr.Trivia)
+		b.writeExprCode(expr.Expr)
+
 	case *ast.ZeroOrMoreExpr:
 		b.pushArgsSet()
 		b.writeExprCode(expr.Expr)
@@ -538,6 +708,54 @@ func (b *Builder) writeNotCodeExprCode(not *ast.NotCodeExpr) {
 	}
 }
 
+// operatorArgs are the names of the operands available to the code block
+// of an operator, by kind of operator.
+var operatorArgs = map[ast.OperatorKind][]string{
+	ast.LeftAssoc:  {"l", "op", "r"},
+	ast.RightAssoc: {"l", "op", "r"},
+	ast.Prefix:     {"op", "x"},
+	ast.Postfix:    {"x", "op"},
+}
+
+func (b *Builder) writeOperatorCode(kind ast.OperatorKind, op *ast.Operator) {
+	if op == nil {
+		return
+	}
+	if op.FuncIx > 0 {
+		b.pushArgsSet()
+		for _, arg := range operatorArgs[kind] {
+			b.addArg(ast.NewIdentifier(op.Pos(), arg))
+		}
+		b.writeFunc(op.FuncIx, op.Code, b.CallCodeFuncTemplate)
+		b.popArgsSet()
+		op.FuncIx = 0 // already rendered, prevent duplicates
+	}
+}
+
+// writeRangeTable writes the composite literal of the range table t of a
+// character class evaluated from set operators.
+func (b *Builder) writeRangeTable(t *unicode.RangeTable) {
+	b.Writelnf("{")
+	if len(t.R16) > 0 {
+		b.Writelnf("R16: []unicode.Range16{")
+		for _, r := range t.R16 {
+			b.Writelnf("{Lo: %#04x, Hi: %#04x, Stride: %d},", r.Lo, r.Hi, r.Stride)
+		}
+		b.Writelnf("},")
+	}
+	if len(t.R32) > 0 {
+		b.Writelnf("R32: []unicode.Range32{")
+		for _, r := range t.R32 {
+			b.Writelnf("{Lo: %#x, Hi: %#x, Stride: %d},", r.Lo, r.Hi, r.Stride)
+		}
+		b.Writelnf("},")
+	}
+	if t.LatinOffset > 0 {
+		b.Writelnf("LatinOffset: %d,", t.LatinOffset)
+	}
+	b.Writelnf("},")
+}
+
 func (b *Builder) writeCodeExprCode(code *ast.CodeExpr) {
 	if code == nil {
 		return
@@ -561,6 +779,9 @@ func StringArrayUniq(items []string) []string {
 }
 
 func (b *Builder) writeFunc(funcIx int, code *ast.CodeBlock, funcTpl string) {
+	if b.CodeFuncs != nil && code != nil {
+		b.CodeFuncs["call"+b.FuncName(funcIx)] = code
+	}
 	b.Shims.WriteFunc(b, funcIx, code, funcTpl)
 }
 
@@ -573,6 +794,7 @@ func (b *Builder) WriteStaticCode(code string) {
 	params := struct {
 		Optimize       bool
 		Nolint         bool
+		ASCIIFold      bool
 		SetRulePos     bool
 		Entrypoint     string
 		GrammarMap     bool
@@ -584,6 +806,7 @@ func (b *Builder) WriteStaticCode(code string) {
 	}{
 		Optimize:       b.Optimize,
 		Nolint:         b.Nolint,
+		ASCIIFold:      b.ASCIIFold,
 		SetRulePos:     b.SetRulePos,
 		Entrypoint:     b.Entrypoint,
 		GrammarMap:     b.GrammarMap,
@@ -667,6 +890,7 @@ type OverrideShims struct {
 	WriteAnyMatcher       func(b *Builder, any *ast.AnyMatcher)
 	WriteActionExpr       func(b *Builder, act *ast.ActionExpr)
 	WriteAndCodeExpr      func(b *Builder, and *ast.AndCodeExpr)
+	WriteBackRefExpr      func(b *Builder, ref *ast.BackRefExpr)
 	WriteCharClassMatcher func(b *Builder, ch *ast.CharClassMatcher)
 	WriteCodeExpr         func(b *Builder, state *ast.CodeExpr)
 	WriteChoiceExpr       func(b *Builder, ch *ast.ChoiceExpr)
@@ -675,10 +899,14 @@ type OverrideShims struct {
 	WriteNotCodeExpr      func(b *Builder, not *ast.NotCodeExpr)
 	WriteNotExpr          func(b *Builder, not *ast.NotExpr)
 	WriteOneOrMoreExpr    func(b *Builder, one *ast.OneOrMoreExpr)
+	WritePrecedenceExpr   func(b *Builder, prec *ast.PrecedenceExpr)
 	WriteRecoveryExpr     func(b *Builder, recover *ast.RecoveryExpr)
+	WriteRepeatExpr       func(b *Builder, rep *ast.RepeatExpr)
 	WriteRuleRefExpr      func(b *Builder, ref *ast.RuleRefExpr)
+	WriteSeparatedExpr    func(b *Builder, sep *ast.SeparatedExpr)
 	WriteSeqExpr          func(b *Builder, seq *ast.SeqExpr)
 	WriteThrowExpr        func(b *Builder, throw *ast.ThrowExpr)
+	WriteTriviaExpr       func(b *Builder, trivia *ast.TriviaExpr)
 	WriteZeroOrMoreExpr   func(b *Builder, zero *ast.ZeroOrMoreExpr)
 	WriteZeroOrOneExpr    func(b *Builder, zero *ast.ZeroOrOneExpr)
 	WriteFunc             func(b *Builder, funcIx int, code *ast.CodeBlock, funcTpl string)
@@ -764,6 +992,20 @@ func (b *Builder) Init() {
 		if r.IsLabelExists {
 			b.Writelnf("\tvarExists: %t,", r.IsLabelExists)
 		}
+		if r.HasBackRef {
+			b.Writelnf("\tbackRef: %t,", r.HasBackRef)
+		}
+		if r.HasAnnotation(MemoAnnotation) {
+			b.Writelnf("\tmemo: true,")
+		}
+		if !b.Optimize {
+			if r.HasAnnotation(NoMemoAnnotation) {
+				b.Writelnf("\tnoMemo: true,")
+			}
+			if r.HasAnnotation(NoTraceAnnotation) {
+				b.Writelnf("\tnoTrace: true,")
+			}
+		}
 		b.WriteRulePos(r.Pos())
 		b.Writef("\texpr: ")
 		b.WriteExpr(r.Expr)
@@ -839,6 +1081,17 @@ func (b *Builder) Init() {
 		})
 	}
 
+	b.Shims.WriteBackRefExpr = func(b *Builder, ref *ast.BackRefExpr) {
+		if ref == nil {
+			b.WriteNilLine()
+			return
+		}
+		b.WriteExprBlock("backRefExpr", true, func() {
+			b.WriteRulePos(ref.Pos())
+			b.Writelnf("\tlabel: %q,", ref.Label.Val)
+		})
+	}
+
 	b.Shims.WriteCharClassMatcher = func(b *Builder, ch *ast.CharClassMatcher) {
 		if ch == nil {
 			b.WriteNilLine()
@@ -852,11 +1105,7 @@ func (b *Builder) Init() {
 				b.Writef("\tchars:")
 				b.WriteArray("rune", false, func() {
 					for _, rn := range ch.Chars {
-						if ch.IgnoreCase {
-							b.Writef("%q,", unicode.ToLower(rn))
-						} else {
-							b.Writef("%q,", rn)
-						}
+						b.Writef("%q,", rn)
 					}
 				})
 			}
@@ -864,11 +1113,7 @@ func (b *Builder) Init() {
 				b.Writef("\tranges:")
 				b.WriteArray("rune", false, func() {
 					for _, rn := range ch.Ranges {
-						if ch.IgnoreCase {
-							b.Writef("%q,", unicode.ToLower(rn))
-						} else {
-							b.Writef("%q,", rn)
-						}
+						b.Writef("%q,", rn)
 					}
 				})
 			}
@@ -882,6 +1127,13 @@ func (b *Builder) Init() {
 					}
 				})
 			}
+			if ch.Table != nil {
+				b.RangeTable = true
+				b.Writef("\tclasses: ")
+				b.WriteArray("*unicode.RangeTable", true, func() {
+					b.writeRangeTable(ch.Table)
+				})
+			}
 			if ch.IgnoreCase {
 				b.Writelnf("\tignoreCase: %t,", ch.IgnoreCase)
 			}
@@ -944,6 +1196,9 @@ func (b *Builder) Init() {
 			if lab.TextCapture {
 				b.Writelnf("\ttextCapture: %v,", lab.TextCapture)
 			}
+			if lab.BackRef {
+				b.Writelnf("\tbackRef: %v,", lab.BackRef)
+			}
 		})
 	}
 
@@ -953,17 +1208,18 @@ func (b *Builder) Init() {
 			return
 		}
 
+		newline := b.SetRulePos || lit.Boundary != nil
 		writeFunc := b.Writef
-		if b.SetRulePos {
+		if newline {
 			writeFunc = b.Writelnf
 		}
 
 		// writeFunc("&litMatcher{")
-		b.WriteExprBlock("litMatcher", b.SetRulePos, func() {
+		b.WriteExprBlock("litMatcher", newline, func() {
 			pos := lit.Pos()
 			b.WriteRulePos(pos)
 			if lit.IgnoreCase {
-				writeFunc("\tval: %q,", strings.ToLower(lit.Val))
+				writeFunc("\tval: %q,", foldString(lit.Val, b.ASCIIFold))
 			} else {
 				writeFunc("\tval: %q,", lit.Val)
 			}
@@ -975,6 +1231,10 @@ func (b *Builder) Init() {
 				ignoreCaseFlag = "i"
 			}
 			writeFunc("\twant: %q,", strconv.Quote(lit.Val)+ignoreCaseFlag)
+			if lit.Boundary != nil {
+				b.Writef("\tboundary: ")
+				b.WriteExpr(lit.Boundary)
+			}
 		})
 	}
 
@@ -1025,6 +1285,55 @@ func (b *Builder) Init() {
 		})
 	}
 
+	b.Shims.WritePrecedenceExpr = func(b *Builder, prec *ast.PrecedenceExpr) {
+		if prec == nil {
+			b.WriteNilLine()
+			return
+		}
+
+		// operators are grouped by kind, their precedence is the index of
+		// their level, starting at 1 for the lowest one.
+		writeOps := func(name string, match func(kind ast.OperatorKind) bool) {
+			b.Writelnf("	%s: ", name)
+			b.WriteArray("*precedenceOp", true, func() {
+				for i, level := range prec.Levels {
+					if !match(level.Kind) {
+						continue
+					}
+					for _, op := range level.Operators {
+						if op.Code != nil && op.FuncIx == 0 {
+							b.ExprIndex++
+							op.FuncIx = b.ExprIndex
+						}
+						b.WriteExprBlock("precedenceOp", true, func() {
+							b.WriteRulePos(op.Pos())
+							b.Writef("	expr: ")
+							b.WriteExpr(op.Expr)
+							b.Writelnf("	prec: %d,", i+1)
+							if level.Kind == ast.RightAssoc {
+								b.Writelnf("	rightAssoc: true,")
+							}
+							if op.FuncIx > 0 {
+								b.Writelnf("	run: (*parser).call%s,", b.FuncName(op.FuncIx))
+							}
+						})
+					}
+				}
+			})
+		}
+
+		b.WriteExprBlock("precedenceExpr", true, func() {
+			b.WriteRulePos(prec.Pos())
+			b.Writef("	atom: ")
+			b.WriteExpr(prec.Atom)
+			writeOps("prefix", func(kind ast.OperatorKind) bool { return kind == ast.Prefix })
+			writeOps("infix", func(kind ast.OperatorKind) bool {
+				return kind == ast.LeftAssoc || kind == ast.RightAssoc
+			})
+			writeOps("postfix", func(kind ast.OperatorKind) bool { return kind == ast.Postfix })
+		})
+	}
+
 	b.Shims.WriteRecoveryExpr = func(b *Builder, recover *ast.RecoveryExpr) {
 		if recover == nil {
 			b.WriteNilLine()
@@ -1049,6 +1358,40 @@ func (b *Builder) Init() {
 		})
 	}
 
+	b.Shims.WriteRepeatExpr = func(b *Builder, rep *ast.RepeatExpr) {
+		if rep == nil {
+			b.WriteNilLine()
+			return
+		}
+
+		b.WriteExprBlock("repeatExpr", true, func() {
+			pos := rep.Pos()
+			b.WriteRulePos(pos)
+			b.Writef("\texpr: ")
+			b.WriteExpr(rep.Expr)
+			b.Writelnf("\tmin: %d,", rep.Min)
+			b.Writelnf("\tmax: %d,", rep.Max)
+		})
+	}
+
+	b.Shims.WriteSeparatedExpr = func(b *Builder, sep *ast.SeparatedExpr) {
+		if sep == nil {
+			b.WriteNilLine()
+			return
+		}
+
+		b.WriteExprBlock("separatedExpr", true, func() {
+			pos := sep.Pos()
+			b.WriteRulePos(pos)
+			b.Writef("\texpr: ")
+			b.WriteExpr(sep.Expr)
+			b.Writef("\tsep: ")
+			b.WriteExpr(sep.Sep)
+			b.Writelnf("\tmin: %d,", sep.Min)
+			b.Writelnf("\tallowTrailing: %t,", sep.AllowTrailing)
+		})
+	}
+
 	b.Shims.WriteRuleRefExpr = func(b *Builder, ref *ast.RuleRefExpr) {
 		if ref == nil {
 			b.WriteNilLine()
@@ -1117,6 +1460,21 @@ func (b *Builder) Init() {
 		})
 	}
 
+	b.Shims.WriteTriviaExpr = func(b *Builder, trivia *ast.TriviaExpr) {
+		if trivia == nil {
+			b.WriteNilLine()
+			return
+		}
+		b.WriteExprBlock("triviaExpr", true, func() {
+			pos := trivia.Pos()
+			b.WriteRulePos(pos)
+			b.Writef("\ttrivia: ")
+			b.WriteExpr(trivia.Trivia)
+			b.Writef("\texpr: ")
+			b.WriteExpr(trivia.Expr)
+		})
+	}
+
 	b.Shims.WriteZeroOrMoreExpr = func(b *Builder, zero *ast.ZeroOrMoreExpr) {
 		if zero == nil {
 			b.WriteNilLine()
@@ -1181,12 +1539,19 @@ func (b *Builder) Init() {
 			}
 		}
 
+		terminating := endsInTerminatingStmt(val)
+		if b.LineGrammarFile != "" {
+			val = b.lineDirectives(code, val)
+		}
+
 		b.Writelnf(b.TemplateRenderBase(funcTpl, false, map[string]any{
-			"FuncName":   b.FuncName(funcIx),
-			"paramsDef":  params,
-			"code":       val,
-			"paramsCall": args.String(),
-			"useStack":   len(argsInfo) > 0,
+			"FuncName":    b.FuncName(funcIx),
+			"recvName":    b.RecvName,
+			"paramsDef":   params,
+			"code":        val,
+			"paramsCall":  args.String(),
+			"useStack":    len(argsInfo) > 0,
+			"terminating": terminating,
 		}))
 	}
 
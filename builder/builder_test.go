@@ -1,6 +1,7 @@
 package builder
 
 import (
+	"bytes"
 	"io"
 	"strings"
 	"testing"
@@ -38,3 +39,27 @@ func TestBuildParser(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestReceiverName(t *testing.T) {
+	text := "{\npackage p\n}\nA <- \"a\" { return 1, nil }\n"
+	cases := []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "c"},
+		{[]Option{ReceiverName("r")}, "r"},
+	}
+	for _, tc := range cases {
+		g, err := bootstrap.NewParser().Parse("", strings.NewReader(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := BuildParser(&buf, g, tc.opts...); err != nil {
+			t.Fatal(err)
+		}
+		if want := "(func (" + tc.want + " *current, "; !strings.Contains(buf.String(), want) {
+			t.Errorf("want %q in the parser", want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package builder
+
+import (
+	"strings"
+	"unicode"
+)
+
+// foldRune returns the rune that stands for the case folding orbit of r
+// in case-insensitive literals, that is the smallest rune of the orbit of
+// r under unicode.SimpleFold. If ascii is true, only ASCII letters are
+// folded, to their lowercase. The generated parser folds the input the
+// same way.
+func foldRune(r rune, ascii bool) rune {
+	if ascii {
+		if 'A' <= r && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		return r
+	}
+
+	min := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
+
+// foldString returns s with every rune replaced by foldRune.
+func foldString(s string, ascii bool) string {
+	return strings.Map(func(r rune) rune {
+		return foldRune(r, ascii)
+	}, s)
+}
@@ -0,0 +1,120 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"text/template"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// FuzzMaxExprCnt is the maximum number of expressions parsed for an input
+// of the generated fuzz test, beyond which the parser is assumed to loop
+// infinitely.
+const FuzzMaxExprCnt = 1000000
+
+var fuzzTestTemplate = template.Must(template.New("").Parse(codeGeneratedComment + `package {{ .Package }}
+
+import (
+	{{- if .CorpusDir }}
+	"os"
+	"path/filepath"
+	{{- end }}
+	"testing"
+)
+
+// fuzzMaxExprCnt is the maximum number of expressions parsed for an input,
+// beyond which the parser is assumed to loop infinitely.
+const fuzzMaxExprCnt = {{ .MaxExprCnt }}
+
+// FuzzParse checks that the parser terminates without panicking on any
+// input, and that the positions of its errors are within the input.
+{{- if .CorpusDir }}
+// The files of {{ .CorpusDirQuoted }} seed its corpus, in addition to
+// testdata/fuzz/FuzzParse.
+{{- end }}
+func FuzzParse(f *testing.F) {
+	{{- if .CorpusDir }}
+	dir := {{ .CorpusDirQuoted }}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(b)
+	}
+	{{- end }}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		p := newParser("", b)
+		p.maxExprCnt = fuzzMaxExprCnt
+		// the panics are not turned into errors
+		p.recover = false
+		defer func() {
+			if e := recover(); e == errMaxExprCnt {
+				t.Fatalf("more than %d expressions parsed, the parser may loop infinitely", fuzzMaxExprCnt)
+			} else if e != nil {
+				panic(e)
+			}
+		}()
+
+		_, err := p.parse(nil)
+		if err == nil {
+			return
+		}
+		lines := 1
+		for _, c := range b {
+			if c == '\n' {
+				lines++
+			}
+		}
+		for _, err := range err.(errList) {
+			pe, ok := err.(*parserError)
+			if !ok {
+				continue
+			}
+			if pe.pos.offset < 0 || pe.pos.offset > len(b) || pe.pos.line < 1 || pe.pos.line > lines {
+				t.Errorf("error %q: position %s out of the input of %d bytes and %d lines", pe, pe.pos, len(b), lines)
+			}
+		}
+	})
+}
+`))
+
+// writeFuzzTest writes the fuzz test of the parser to b.FuzzTestW, in the
+// package of the initializer of the grammar.
+func (b *Builder) writeFuzzTest(grammar *ast.Grammar) error {
+	pkg, err := initPackage(grammar.Init)
+	if err != nil {
+		return fmt.Errorf("fuzz test: %w", err)
+	}
+	return fuzzTestTemplate.Execute(b.FuzzTestW, map[string]any{
+		"Package":         pkg,
+		"CorpusDir":       b.FuzzCorpusDir,
+		"CorpusDirQuoted": strconv.Quote(b.FuzzCorpusDir),
+		"MaxExprCnt":      FuzzMaxExprCnt,
+	})
+}
+
+// initPackage returns the name of the package declared by the initializer
+// code block.
+func initPackage(init *ast.CodeBlock) (string, error) {
+	if init == nil || len(init.Val) < 2 {
+		return "", errors.New("the grammar has no initializer to declare the package")
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "", init.Val[1:len(init.Val)-1], parser.PackageClauseOnly)
+	if err != nil {
+		return "", fmt.Errorf("the initializer does not declare the package: %w", err)
+	}
+	return f.Name.Name, nil
+}
@@ -0,0 +1,69 @@
+package builder_test
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/builder"
+)
+
+func TestFuzzTest(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		corpusDir string
+		want      []string
+		notWant   []string
+	}{
+		{
+			want:    []string{"package p\n", "func FuzzParse(f *testing.F) {", "p.parse(nil)"},
+			notWant: []string{"os.ReadDir"},
+		},
+		{
+			corpusDir: "testdata/inputs",
+			want:      []string{"package p\n", `dir := "testdata/inputs"`, "f.Add(b)"},
+		},
+	}
+	for _, tc := range cases {
+		grammar, err := bootstrap.NewParser().Parse("", strings.NewReader("{\n// parser\npackage p\n}\nA <- \"a\"\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf, fuzz bytes.Buffer
+		if err := builder.BuildParser(&buf, grammar, builder.GrammarName("g"), builder.FuzzTest(&fuzz, tc.corpusDir)); err != nil {
+			t.Fatal(err)
+		}
+		src := fuzz.String()
+		if _, err := parser.ParseFile(token.NewFileSet(), "p_fuzz_test.go", src, 0); err != nil {
+			t.Errorf("%q: invalid fuzz test: %v\n%s", tc.corpusDir, err, src)
+		}
+		for _, want := range tc.want {
+			if !strings.Contains(src, want) {
+				t.Errorf("%q: want %q in the fuzz test", tc.corpusDir, want)
+			}
+		}
+		for _, notWant := range tc.notWant {
+			if strings.Contains(src, notWant) {
+				t.Errorf("%q: want no %q in the fuzz test", tc.corpusDir, notWant)
+			}
+		}
+	}
+}
+
+func TestFuzzTestNoPackage(t *testing.T) {
+	t.Parallel()
+
+	grammar, err := bootstrap.NewParser().Parse("", strings.NewReader("A <- \"a\"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf, fuzz bytes.Buffer
+	err = builder.BuildParser(&buf, grammar, builder.GrammarName("g"), builder.FuzzTest(&fuzz, ""))
+	if err == nil || !strings.Contains(err.Error(), "fuzz test") {
+		t.Errorf("want a fuzz test error, got %v", err)
+	}
+}
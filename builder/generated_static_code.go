@@ -99,6 +99,20 @@ func debug(b bool) option {
 		return debug(old)
 	}
 }
+
+// memoize creates an option to set the memoize flag to b. When set to true,
+// the results of all the rules not annotated with @nomemo are memoized,
+// which guarantees a linear parsing time at the cost of memory. The rules
+// annotated with @memo are always memoized.
+//
+// The default is false.
+func memoize(b bool) option {
+	return func(p *parser) option {
+		old := p.memoize
+		p.memoize = b
+		return memoize(old)
+	}
+}
 // {{ end }} ==template==
 
 // Parse parses the data from b using filename as information in the
@@ -149,6 +163,12 @@ type rule struct {
 	displayName string
 	expr        any
 	varExists   bool
+	backRef     bool
+	memo        bool
+	// ==template== {{ if not .Optimize }}
+	noMemo  bool
+	noTrace bool
+	// {{ end }} ==template==
 }
 
 // {{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
@@ -202,6 +222,7 @@ type labeledExpr struct {
 	label string
 	expr  any
 	textCapture bool
+	backRef     bool
 }
 
 // {{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
@@ -222,6 +243,66 @@ type (
 	oneOrMoreExpr  expr //{{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
 )
 
+// {{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type repeatExpr struct {
+	// ==template== {{ if .SetRulePos }}
+	pos position
+	// {{ end }} ==template==
+	expr any
+	min  int
+	max  int
+}
+
+// {{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type separatedExpr struct {
+	// ==template== {{ if .SetRulePos }}
+	pos position
+	// {{ end }} ==template==
+	expr          any
+	sep           any
+	min           int
+	allowTrailing bool
+}
+
+// {{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type precedenceExpr struct {
+	// ==template== {{ if .SetRulePos }}
+	pos position
+	// {{ end }} ==template==
+	atom    any
+	prefix  []*precedenceOp
+	infix   []*precedenceOp
+	postfix []*precedenceOp
+}
+
+// {{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type precedenceOp struct {
+	// ==template== {{ if .SetRulePos }}
+	pos position
+	// {{ end }} ==template==
+	expr       any
+	prec       int
+	rightAssoc bool
+	run        func(*parser) any
+}
+
+// {{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type triviaExpr struct {
+	// ==template== {{ if .SetRulePos }}
+	pos position
+	// {{ end }} ==template==
+	trivia any
+	expr   any
+}
+
+// {{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
+type backRefExpr struct {
+	// ==template== {{ if .SetRulePos }}
+	pos position
+	// {{ end }} ==template==
+	label string
+}
+
 // {{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
 type ruleRefExpr struct {
 	// ==template== {{ if .SetRulePos }}
@@ -275,6 +356,8 @@ type litMatcher struct {
 	val        string
 	ignoreCase bool
 	want       string
+	// boundary is the expression that must not match after a keyword
+	boundary any
 }
 
 // {{ if .Nolint }} nolint: structcheck {{else}} ==template== {{ end }}
@@ -409,8 +492,11 @@ type parser struct {
 	depth   int
 	recover bool
 	// ==template== {{ if not .Optimize }}
-	debug bool
+	debug   bool
+	memoize bool
 	// {{ end }} ==template==
+	// memoization table for the rules, by offset
+	memo map[int]map[*rule]resultTuple
 
 	// rules table, maps the rule identifier to the rule node
 	rules map[string]*rule
@@ -424,6 +510,8 @@ type parser struct {
 	maxFailPos            position
 	maxFailExpected       []string
 	maxFailInvertExpected bool
+	// parse fails are not recorded while checking keyword boundaries
+	maxFailSuppressed int
 
 	// max number of expressions to be parsed
 	maxExprCnt uint64
@@ -613,7 +701,7 @@ func (p *parser) addErrAt(err error, pos position, expected []string) {
 
 func (p *parser) failAt(fail bool, pos *position, want string) {
 	// process fail if parsing fails and not inverted or parsing succeeds and invert is set
-	if fail == p.maxFailInvertExpected {
+	if fail == p.maxFailInvertExpected && p.maxFailSuppressed == 0 {
 		if pos.offset < p.maxFailPos.offset {
 			return
 		}
@@ -833,20 +921,58 @@ func listJoin(list []string, sep string, lastSep string) string {
 	}
 }
 
+// memoized returns true if the result of the rule at the current position
+// is memoized. Results are not memoized while the code blocks are skipped,
+// as the values are not computed then.
+func (p *parser) memoized(rule *rule) bool {
+	if p.checkSkipCode() {
+		return false
+	}
+	// ==template== {{ if not .Optimize }}
+	if p.memoize {
+		return !rule.noMemo
+	}
+	// {{ end }} ==template==
+	return rule.memo
+}
+
+func (p *parser) getMemoized(r *rule) (resultTuple, bool) {
+	res, ok := p.memo[p.pt.offset][r]
+	return res, ok
+}
+
+func (p *parser) setMemoized(pt savepoint, r *rule, tuple resultTuple) {
+	if p.memo == nil {
+		p.memo = make(map[int]map[*rule]resultTuple)
+	}
+	m := p.memo[pt.offset]
+	if m == nil {
+		m = make(map[*rule]resultTuple)
+		p.memo[pt.offset] = m
+	}
+	m[r] = tuple
+}
+
 // ==template== {{ if .NeedExprWrap }}
-func (p *parser) parseRuleWrap(rule *rule) (any, bool) {
+func (p *parser) parseRuleWrap(rule *rule) (val any, ok bool) {
 	// ==template== {{ if not .Optimize }}
+	if p.debug && rule.noTrace {
+		p.debug = false
+		defer func() { p.debug = true }()
+	}
 	if p.debug {
 		defer p.out(p.in("parseRule " + rule.name))
 	}
+	startMark := &p.pt
 	// {{ end }} ==template==
-	var (
-		val any
-		ok  bool
-		// ==template== {{ if not .Optimize }}
-		startMark = &p.pt
-		// {{ end }} ==template==
-	)
+	if p.memoized(rule) {
+		if res, found := p.getMemoized(rule); found {
+			p.restore(&res.end)
+			return res.v, res.b
+		}
+		start := p.pt
+		defer func() { p.setMemoized(start, rule, resultTuple{val, ok, p.pt}) }()
+	}
 
 	val, ok = p.parseRule(rule)
 
@@ -867,11 +993,18 @@ func (p *parser) parseRule(rule *rule) (any, bool) {
 	return val, ok
 }
 // {{ else }}
-func (p *parser) parseRuleWrap(rule *rule) (any, bool) {
+func (p *parser) parseRuleWrap(rule *rule) (val any, ok bool) {
+	if p.memoized(rule) {
+		if res, found := p.getMemoized(rule); found {
+			p.restore(&res.end)
+			return res.v, res.b
+		}
+		start := p.pt
+		defer func() { p.setMemoized(start, rule, resultTuple{val, ok, p.pt}) }()
+	}
+
 	p.rstack = append(p.rstack, rule)
-	var val any
-	var ok bool
-	if rule.varExists && !p.checkSkipCode() {
+	if rule.varExists && (!p.checkSkipCode() || rule.backRef) {
 		p.pushV()
 		val, ok = p.parseExprWrap(rule.expr)
 		p.popV()
@@ -908,6 +1041,8 @@ func (p *parser) {{ .ParseExprName }}(expr any) (any, bool) {
 		val, ok = p.parseAndExpr(expr)
 	case *andLogicalExpr:
 		val, ok = p.parseAndLogicalExpr(expr)
+	case *backRefExpr:
+		val, ok = p.parseBackRefExpr(expr)
 	case *anyMatcher:
 		val, ok = p.parseAnyMatcher(expr)
 	case *charClassMatcher:
@@ -928,8 +1063,12 @@ func (p *parser) {{ .ParseExprName }}(expr any) (any, bool) {
 		val, ok = p.parseNotLogicalExpr(expr)
 	case *oneOrMoreExpr:
 		val, ok = p.parseOneOrMoreExpr(expr)
+	case *precedenceExpr:
+		val, ok = p.parsePrecedenceExpr(expr)
 	case *recoveryExpr:
 		val, ok = p.parseRecoveryExpr(expr)
+	case *repeatExpr:
+		val, ok = p.parseRepeatExpr(expr)
 	case *ruleRefExpr:
 		val, ok = p.parseRuleRefExpr(expr)
 	// ==template== {{ if .IRefEnable }}
@@ -940,10 +1079,14 @@ func (p *parser) {{ .ParseExprName }}(expr any) (any, bool) {
 	case *ruleIRefExprX:
 		val, ok = p.parseRuleIRefExprX(expr)
 	// {{ end }} ==template==
+	case *separatedExpr:
+		val, ok = p.parseSeparatedExpr(expr)
 	case *seqExpr:
 		val, ok = p.parseSeqExpr(expr)
 	case *throwExpr:
 		val, ok = p.parseThrowExpr(expr)
+	case *triviaExpr:
+		val, ok = p.parseTriviaExpr(expr)
 	case *zeroOrMoreExpr:
 		val, ok = p.parseZeroOrMoreExpr(expr)
 	case *zeroOrOneExpr:
@@ -1045,7 +1188,30 @@ func (p *parser) parseAnyMatcher(any *anyMatcher) (any, bool) {
 	return nil, true
 }
 
-// {{ if .Nolint }} nolint: gocyclo {{else}} ==template== {{ end }}
+func (p *parser) parseBackRefExpr(ref *backRefExpr) (any, bool) {
+	// ==template== {{ if not .Optimize }}
+	if p.debug {
+		defer p.out(p.in("parseBackRefExpr"))
+	}
+
+	// {{ end }} ==template==
+	start := p.pt
+	want, ok := p.vstack[len(p.vstack)-1][ref.label].(string)
+	if !ok {
+		// the label did not match yet
+		return nil, false
+	}
+	if !bytes.HasPrefix(p.data[p.pt.offset:], []byte(want)) {
+		p.failAt(false, &start.position, strconv.Quote(want))
+		return nil, false
+	}
+	for p.pt.offset < start.offset+len(want) {
+		p.read()
+	}
+	p.failAt(true, &start.position, strconv.Quote(want))
+	return nil, true
+}
+
 func (p *parser) parseCharClassMatcher(chr *charClassMatcher) (any, bool) {
 	// ==template== {{ if not .Optimize }}
 	if p.debug {
@@ -1061,58 +1227,86 @@ func (p *parser) parseCharClassMatcher(chr *charClassMatcher) (any, bool) {
 		return nil, false
 	}
 
-	if chr.ignoreCase {
-		cur = unicode.ToLower(cur)
+	matched := chr.match(cur)
+	if !matched && chr.ignoreCase {
+		// ==template== {{ if .ASCIIFold }}
+		if f := asciiSwapCase(cur); f != cur {
+			matched = chr.match(f)
+		}
+		// {{ else }} ==template==
+		for f := unicode.SimpleFold(cur); f != cur && !matched; f = unicode.SimpleFold(f) {
+			matched = chr.match(f)
+		}
+		// {{ end }} ==template==
 	}
 
-	// try to match in the list of available chars
-	for _, rn := range chr.chars {
-		if rn == cur {
-			if chr.inverted {
-				p.failAt(false, &p.pt.position, chr.val)
-				return nil, false
-			}
-			p.failAt(true, &p.pt.position, chr.val)
-			p.read()
-			return nil, true
-		}
+	if matched == chr.inverted {
+		p.failAt(false, &p.pt.position, chr.val)
+		return nil, false
 	}
+	p.failAt(true, &p.pt.position, chr.val)
+	p.read()
+	return nil, true
+}
 
-	// try to match in the list of ranges
+// match returns true if rn is one of the chars, ranges or Unicode
+// classes of the matcher, regardless of the inversion.
+func (chr *charClassMatcher) match(rn rune) bool {
+	for _, c := range chr.chars {
+		if c == rn {
+			return true
+		}
+	}
 	for i := 0; i < len(chr.ranges); i += 2 {
-		if cur >= chr.ranges[i] && cur <= chr.ranges[i+1] {
-			if chr.inverted {
-				p.failAt(false, &p.pt.position, chr.val)
-				return nil, false
-			}
-			p.failAt(true, &p.pt.position, chr.val)
-			p.read()
-			return nil, true
+		if rn >= chr.ranges[i] && rn <= chr.ranges[i+1] {
+			return true
 		}
 	}
-
-	// try to match in the list of Unicode classes
 	for _, cl := range chr.classes {
-		if unicode.Is(cl, cur) {
-			if chr.inverted {
-				p.failAt(false, &p.pt.position, chr.val)
-				return nil, false
-			}
-			p.failAt(true, &p.pt.position, chr.val)
-			p.read()
-			return nil, true
+		if unicode.Is(cl, rn) {
+			return true
 		}
 	}
+	return false
+}
 
-	if chr.inverted {
-		p.failAt(true, &p.pt.position, chr.val)
-		p.read()
-		return nil, true
+// ==template== {{ if .ASCIIFold }}
+// asciiSwapCase returns the other case of rn if it is an ASCII letter,
+// rn otherwise.
+func asciiSwapCase(rn rune) rune {
+	switch {
+	case 'a' <= rn && rn <= 'z':
+		return rn - 'a' + 'A'
+	case 'A' <= rn && rn <= 'Z':
+		return rn - 'A' + 'a'
 	}
-	p.failAt(false, &p.pt.position, chr.val)
-	return nil, false
+	return rn
+}
+
+// foldRune returns the lowercase of rn if it is an ASCII letter, rn
+// otherwise.
+func foldRune(rn rune) rune {
+	if 'A' <= rn && rn <= 'Z' {
+		return rn - 'A' + 'a'
+	}
+	return rn
+}
+
+// {{ else }} ==template==
+// foldRune returns the smallest rune of the case folding orbit of rn,
+// literals of case-insensitive matchers are folded the same way.
+func foldRune(rn rune) rune {
+	min := rn
+	for f := unicode.SimpleFold(rn); f != rn; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
 }
 
+// {{ end }} ==template==
+
 // ==template== {{ if not .Optimize }}
 
 func (p *parser) incChoiceAltCnt(ch *choiceExpr, altI int) {
@@ -1163,7 +1357,7 @@ func (p *parser) parseLabeledExpr(lab *labeledExpr) (any, bool) {
 	var val any
 	var ok bool
 	val, ok = p.parseExprWrap(lab.expr)
-	if ok && lab.label != "" && !p.checkSkipCode() {
+	if ok && lab.label != "" && (!p.checkSkipCode() || lab.backRef) {
 		m := p.vstack[len(p.vstack)-1]
 		if lab.textCapture {
 			m[lab.label] = string(p.sliceFromOffset(startOffset))
@@ -1198,7 +1392,7 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (any, bool) {
 	for _, want := range lit.val {
 		cur := p.pt.rn
 		if lit.ignoreCase {
-			cur = unicode.ToLower(cur)
+			cur = foldRune(cur)
 		}
 		if cur != want {
 			p.failAt(false, &start.position, lit.want)
@@ -1207,10 +1401,30 @@ func (p *parser) parseLitMatcher(lit *litMatcher) (any, bool) {
 		}
 		p.read()
 	}
+	if lit.boundary != nil && p.matchBoundary(lit.boundary) {
+		p.failAt(false, &start.position, lit.want)
+		p.restore(&start)
+		return nil, false
+	}
 	p.failAt(true, &start.position, lit.want)
 	return nil, true
 }
 
+// matchBoundary returns true if the boundary expression of a keyword
+// matches at the current position. The position is left unchanged and
+// the parse fails of the expression are not recorded, so that the errors
+// show the keyword instead.
+func (p *parser) matchBoundary(expr any) bool {
+	pt := p.pt
+	p.maxFailSuppressed++
+	p.scStack = append(p.scStack, true)
+	_, ok := p.parseExprWrap(expr)
+	p.scStack = p.scStack[:len(p.scStack)-1]
+	p.maxFailSuppressed--
+	p.restore(&pt)
+	return ok
+}
+
 func (p *parser) parseNotCodeExpr(not *notCodeExpr) (any, bool) {
 	// ==template== {{ if not .Optimize }}
 	if p.debug {
@@ -1264,18 +1478,126 @@ func (p *parser) parseOneOrMoreExpr(expr *oneOrMoreExpr) (any, bool) {
 	var vals []any
 	var matched bool
 	for {
+		offset := p.pt.offset
 		val, ok := p.parseExprWrap(expr.expr)
 		if !ok {
-			if len(vals) > 0 {
-				return vals, matched
-			}
-			return nil, matched
+			break
 		}
 		matched = true
 		if val != nil {
 			vals = append(vals, val)
 		}
+		if p.pt.offset == offset {
+			// the expression matched without consuming any input, it
+			// would match forever
+			break
+		}
+	}
+	if len(vals) > 0 {
+		return vals, matched
+	}
+	return nil, matched
+}
+
+func (p *parser) parsePrecedenceExpr(expr *precedenceExpr) (any, bool) {
+	// ==template== {{ if not .Optimize }}
+	if p.debug {
+		defer p.out(p.in("parsePrecedenceExpr"))
 	}
+
+	// {{ end }} ==template==
+	return p.parsePrecedence(expr, 0)
+}
+
+// parsePrecedence parses an operand followed by the postfix and infix
+// operators with a precedence of at least minPrec.
+func (p *parser) parsePrecedence(expr *precedenceExpr, minPrec int) (any, bool) {
+	start := p.pt
+	lhs, ok := p.parsePrecedenceOperand(expr)
+	if !ok {
+		return nil, false
+	}
+
+loop:
+	for {
+		for _, op := range expr.postfix {
+			if op.prec < minPrec {
+				continue
+			}
+			opStart := p.pt
+			if _, ok := p.parseExprWrap(op.expr); ok {
+				lhs = p.runPrecedenceOp(op, &start, []string{"x", "op"}, lhs, string(p.sliceFrom(&opStart)))
+				continue loop
+			}
+		}
+		for _, op := range expr.infix {
+			if op.prec < minPrec {
+				continue
+			}
+			opStart := p.pt
+			if _, ok := p.parseExprWrap(op.expr); !ok {
+				continue
+			}
+			opText := string(p.sliceFrom(&opStart))
+			next := op.prec + 1
+			if op.rightAssoc {
+				next = op.prec
+			}
+			rhs, ok := p.parsePrecedence(expr, next)
+			if !ok {
+				// the operator is not part of the match
+				p.restore(&opStart)
+				continue
+			}
+			lhs = p.runPrecedenceOp(op, &start, []string{"l", "op", "r"}, lhs, opText, rhs)
+			continue loop
+		}
+		return lhs, true
+	}
+}
+
+// parsePrecedenceOperand parses either a prefix operator applied to its
+// operand or the atom of the precedence expression.
+func (p *parser) parsePrecedenceOperand(expr *precedenceExpr) (any, bool) {
+	start := p.pt
+	for _, op := range expr.prefix {
+		if _, ok := p.parseExprWrap(op.expr); !ok {
+			continue
+		}
+		opText := string(p.sliceFrom(&start))
+		x, ok := p.parsePrecedence(expr, op.prec)
+		if !ok {
+			p.restore(&start)
+			continue
+		}
+		return p.runPrecedenceOp(op, &start, []string{"op", "x"}, opText, x), true
+	}
+	return p.parseExprWrap(expr.atom)
+}
+
+// runPrecedenceOp returns the value of the operator op applied to args.
+// If op has a code block, it is called with args set in a new variable
+// set under the corresponding names, otherwise args is the value.
+func (p *parser) runPrecedenceOp(op *precedenceOp, start *savepoint, names []string, args ...any) any {
+	if op.run == nil {
+		return args
+	}
+	if p.checkSkipCode() {
+		return nil
+	}
+
+	p.pushV()
+	m := p.vstack[len(p.vstack)-1]
+	for i, name := range names {
+		m[name] = args[i]
+	}
+	p.cur.pos = start.position
+	p.cur.text = p.sliceFrom(start)
+	p._errPos = &start.position
+	val := op.run(p)
+	p._errPos = nil
+	p.popV()
+	return val
 }
 
 func (p *parser) parseRecoveryExpr(recover *recoveryExpr) (any, bool) {
@@ -1292,6 +1614,45 @@ func (p *parser) parseRecoveryExpr(recover *recoveryExpr) (any, bool) {
 	return val, ok
 }
 
+func (p *parser) parseRepeatExpr(expr *repeatExpr) (any, bool) {
+	// ==template== {{ if not .Optimize }}
+	if p.debug {
+		defer p.out(p.in("parseRepeatExpr"))
+	}
+
+	// {{ end }} ==template==
+	pt := p.pt
+	var vals []any
+	var cnt int
+	for expr.max < 0 || cnt < expr.max {
+		offset := p.pt.offset
+		val, ok := p.parseExprWrap(expr.expr)
+		if !ok {
+			break
+		}
+		cnt++
+		if val != nil {
+			vals = append(vals, val)
+		}
+		if expr.max < 0 && p.pt.offset == offset {
+			// the expression matched without consuming any input, it
+			// would match forever, so it would also reach the minimum
+			if cnt < expr.min {
+				cnt = expr.min
+			}
+			break
+		}
+	}
+	if cnt < expr.min {
+		p.restore(&pt)
+		return nil, false
+	}
+	if len(vals) > 0 {
+		return vals, true
+	}
+	return nil, true
+}
+
 func (p *parser) parseRuleRefExpr(ref *ruleRefExpr) (any, bool) {
 	// ==template== {{ if not .Optimize }}
 	if p.debug {
@@ -1319,6 +1680,50 @@ func (p *parser) parseRuleIRefExprX(ref *ruleIRefExprX) (any, bool) {
 }
 // {{ end }} ==template==
 
+func (p *parser) parseSeparatedExpr(expr *separatedExpr) (any, bool) {
+	// ==template== {{ if not .Optimize }}
+	if p.debug {
+		defer p.out(p.in("parseSeparatedExpr"))
+	}
+
+	// {{ end }} ==template==
+	val, ok := p.parseExprWrap(expr.expr)
+	if !ok {
+		return nil, expr.min == 0
+	}
+
+	var vals []any
+	if val != nil {
+		vals = append(vals, val)
+	}
+	for {
+		pt := p.pt
+		if _, ok := p.parseExprWrap(expr.sep); !ok {
+			break
+		}
+		val, ok := p.parseExprWrap(expr.expr)
+		if !ok {
+			if !expr.allowTrailing {
+				// the separator is not part of the match
+				p.restore(&pt)
+			}
+			break
+		}
+		if val != nil {
+			vals = append(vals, val)
+		}
+		if p.pt.offset == pt.offset {
+			// the separator and the expression matched without consuming
+			// any input, they would match forever
+			break
+		}
+	}
+	if len(vals) > 0 {
+		return vals, true
+	}
+	return nil, true
+}
+
 func (p *parser) parseSeqExpr(seq *seqExpr) (any, bool) {
 	// ==template== {{ if not .Optimize }}
 	if p.debug {
@@ -1365,6 +1770,25 @@ func (p *parser) parseThrowExpr(expr *throwExpr) (any, bool) {
 	return nil, false
 }
 
+func (p *parser) parseTriviaExpr(expr *triviaExpr) (any, bool) {
+	// ==template== {{ if not .Optimize }}
+	if p.debug {
+		defer p.out(p.in("parseTriviaExpr"))
+	}
+
+	// {{ end }} ==template==
+	pt := p.pt
+	if _, ok := p.parseExprWrap(expr.trivia); !ok {
+		return nil, false
+	}
+	val, ok := p.parseExprWrap(expr.expr)
+	if !ok {
+		p.restore(&pt)
+		return nil, false
+	}
+	return val, true
+}
+
 func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (any, bool) {
 	// ==template== {{ if not .Optimize }}
 	if p.debug {
@@ -1374,17 +1798,24 @@ func (p *parser) parseZeroOrMoreExpr(expr *zeroOrMoreExpr) (any, bool) {
 	// {{ end }} ==template==
 	var vals []any
 	for {
+		offset := p.pt.offset
 		val, ok := p.parseExprWrap(expr.expr)
 		if !ok {
-			if len(vals) > 0 {
-				return vals, true
-			}
-			return nil, true
+			break
 		}
 		if val != nil {
 			vals = append(vals, val)
 		}
+		if p.pt.offset == offset {
+			// the expression matched without consuming any input, it
+			// would match forever
+			break
+		}
 	}
+	if len(vals) > 0 {
+		return vals, true
+	}
+	return nil, true
 }
 
 func (p *parser) parseZeroOrOneExpr(expr *zeroOrOneExpr) (any, bool) {
@@ -0,0 +1,152 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// InlineAnnotation marks a rule whose expression replaces every reference
+// to the rule.
+const InlineAnnotation = "inline"
+
+// InlineRules replaces every reference to a rule annotated with @inline by
+// a copy of the expression of this rule. The inlined rules are kept, so
+// that they can still be used as entrypoints. It returns an error if an
+// @inline rule has a display name or labels, which would change the error
+// messages and the labels visible to the code blocks of the callers, or if
+// it references itself through other @inline rules.
+func InlineRules(grammar *ast.Grammar) error {
+	inline := make(map[string]*ast.Rule)
+	for _, rule := range grammar.Rules {
+		if !rule.HasAnnotation(InlineAnnotation) {
+			continue
+		}
+		if rule.DisplayName != nil {
+			return fmt.Errorf("%s: rule %s: @%s rule with a display name",
+				rule.Pos(), rule.Name.Val, InlineAnnotation)
+		}
+		var label *ast.LabeledExpr
+		ast.Inspect(rule.Expr, func(expr ast.Expression) bool {
+			if lab, ok := expr.(*ast.LabeledExpr); ok && label == nil {
+				label = lab
+			}
+			return label == nil
+		})
+		if label != nil {
+			return fmt.Errorf("%s: rule %s: @%s rule with label %s",
+				label.Pos(), rule.Name.Val, InlineAnnotation, label.Label.Val)
+		}
+		inline[rule.Name.Val] = rule
+	}
+	if len(inline) == 0 {
+		return nil
+	}
+
+	in := &ruleInliner{rules: inline, state: make(map[string]inlineState)}
+	for _, rule := range grammar.Rules {
+		if err := in.expand(rule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type inlineState int
+
+const (
+	inlineExpanding inlineState = iota + 1
+	inlineExpanded
+)
+
+// ruleInliner is a Visitor that replaces the references to @inline rules
+// by the expanded expression of these rules.
+type ruleInliner struct {
+	rules map[string]*ast.Rule
+	state map[string]inlineState
+	err   error
+}
+
+// expand replaces the references to @inline rules in the rule, after
+// having expanded the referenced rules.
+func (in *ruleInliner) expand(rule *ast.Rule) error {
+	name := rule.Name.Val
+	switch in.state[name] {
+	case inlineExpanding:
+		return fmt.Errorf("%s: rule %s: recursive @%s rule", rule.Pos(), name, InlineAnnotation)
+	case inlineExpanded:
+		return nil
+	}
+
+	in.state[name] = inlineExpanding
+	ast.Walk(in, rule)
+	in.state[name] = inlineExpanded
+	return in.err
+}
+
+func (in *ruleInliner) Visit(expr ast.Expression) ast.Visitor {
+	if in.err != nil {
+		return nil
+	}
+
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		expr.Expr = in.inline(expr.Expr)
+	case *ast.AndExpr:
+		expr.Expr = in.inline(expr.Expr)
+	case *ast.ChoiceExpr:
+		for i, alt := range expr.Alternatives {
+			expr.Alternatives[i] = in.inline(alt)
+		}
+	case *ast.LabeledExpr:
+		expr.Expr = in.inline(expr.Expr)
+	case *ast.NotExpr:
+		expr.Expr = in.inline(expr.Expr)
+	case *ast.OneOrMoreExpr:
+		expr.Expr = in.inline(expr.Expr)
+	case *ast.PrecedenceExpr:
+		expr.Atom = in.inline(expr.Atom)
+		for _, l := range expr.Levels {
+			for _, op := range l.Operators {
+				op.Expr = in.inline(op.Expr)
+			}
+		}
+	case *ast.RecoveryExpr:
+		expr.Expr = in.inline(expr.Expr)
+		expr.RecoverExpr = in.inline(expr.RecoverExpr)
+	case *ast.RepeatExpr:
+		expr.Expr = in.inline(expr.Expr)
+	case *ast.Rule:
+		expr.Expr = in.inline(expr.Expr)
+	case *ast.SeparatedExpr:
+		expr.Expr = in.inline(expr.Expr)
+		expr.Sep = in.inline(expr.Sep)
+	case *ast.SeqExpr:
+		for i, e := range expr.Exprs {
+			expr.Exprs[i] = in.inline(e)
+		}
+	case *ast.TriviaExpr:
+		expr.Expr = in.inline(expr.Expr)
+	case *ast.ZeroOrMoreExpr:
+		expr.Expr = in.inline(expr.Expr)
+	case *ast.ZeroOrOneExpr:
+		expr.Expr = in.inline(expr.Expr)
+	}
+	return in
+}
+
+func (in *ruleInliner) inline(expr ast.Expression) ast.Expression {
+	ref, ok := expr.(*ast.RuleRefExpr)
+	if !ok || in.err != nil {
+		return expr
+	}
+	rule := in.rules[ref.Name.Val]
+	if rule == nil {
+		return expr
+	}
+	if err := in.expand(rule); err != nil {
+		in.err = err
+		return expr
+	}
+	return ast.Clone(rule.Expr)
+}
@@ -0,0 +1,146 @@
+package builder_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/builder"
+)
+
+func TestInlineRules(t *testing.T) {
+	t.Parallel()
+
+	text := `
+	start = sign? digits (sep digits)*
+	sign = '+' / '-'
+	digits = digit+
+	digit = [0-9] { return c.text, nil }
+	sep = '_'
+	`
+	grammar := parseAnnotated(t, text, map[string]string{
+		"sign":  builder.InlineAnnotation,
+		"digit": builder.InlineAnnotation,
+		"sep":   builder.InlineAnnotation,
+	})
+	if err := builder.InlineRules(grammar); err != nil {
+		t.Fatal(err)
+	}
+
+	refs := make(map[string][]string)
+	actions := make(map[*ast.ActionExpr]string)
+	for _, rule := range grammar.Rules {
+		rule := rule
+		ast.Inspect(rule, func(expr ast.Expression) bool {
+			switch expr := expr.(type) {
+			case *ast.RuleRefExpr:
+				refs[rule.Name.Val] = append(refs[rule.Name.Val], expr.Name.Val)
+			case *ast.ActionExpr:
+				if other, ok := actions[expr]; ok {
+					t.Errorf("rule %s: action shared with rule %s", rule.Name.Val, other)
+				}
+				actions[expr] = rule.Name.Val
+			}
+			return true
+		})
+	}
+
+	want := map[string]string{
+		"start":  "digits digits",
+		"digits": "",
+		"digit":  "",
+	}
+	for name, refsWant := range want {
+		if got := strings.Join(refs[name], " "); got != refsWant {
+			t.Errorf("rule %s: want references %q, got %q", name, refsWant, got)
+		}
+	}
+	if len(actions) != 2 {
+		t.Errorf("want 2 copies of the digit action, got %d", len(actions))
+	}
+}
+
+func TestInlineRulesErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		text        string
+		annotations map[string]string
+		want        string
+	}{
+		{
+			text:        "start = a\na = 'a' a / 'b'",
+			annotations: map[string]string{"a": builder.InlineAnnotation},
+			want:        "rule a: recursive @inline rule",
+		},
+		{
+			text: "start = a\na = b\nb = 'x' a?",
+			annotations: map[string]string{
+				"a": builder.InlineAnnotation,
+				"b": builder.InlineAnnotation,
+			},
+			want: "recursive @inline rule",
+		},
+		{
+			text:        "start = a\na = x:'a'",
+			annotations: map[string]string{"a": builder.InlineAnnotation},
+			want:        "rule a: @inline rule with label x",
+		},
+		{
+			text:        "start = a\na \"A\" = 'a'",
+			annotations: map[string]string{"a": builder.InlineAnnotation},
+			want:        "rule a: @inline rule with a display name",
+		},
+	}
+	for _, tc := range cases {
+		grammar := parseAnnotated(t, tc.text, tc.annotations)
+		err := builder.InlineRules(grammar)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q: want error %q, got %v", tc.text, tc.want, err)
+		}
+	}
+}
+
+func TestCheckAnnotations(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		annotations []string
+		want        string
+	}{
+		{annotations: []string{builder.MemoAnnotation, builder.EntryAnnotation}},
+		{annotations: []string{builder.NoMemoAnnotation, builder.NoTraceAnnotation}},
+		{
+			annotations: []string{builder.MemoAnnotation, "tok"},
+			want:        "rule a: unknown annotation @tok",
+		},
+		{
+			annotations: []string{builder.MemoAnnotation, builder.MemoAnnotation},
+			want:        "rule a: duplicate annotation @memo",
+		},
+		{
+			annotations: []string{builder.NoMemoAnnotation, builder.MemoAnnotation},
+			want:        "rule a: conflicting annotations @memo and @nomemo",
+		},
+		{
+			annotations: []string{builder.InlineAnnotation, builder.NoTraceAnnotation},
+			want:        "rule a: conflicting annotations @inline and @notrace",
+		},
+	}
+	for _, tc := range cases {
+		grammar := parseAnnotated(t, `a = 'a'`, nil)
+		for _, name := range tc.annotations {
+			grammar.Rules[0].Annotations = append(grammar.Rules[0].Annotations, ast.NewIdentifier(ast.Pos{}, name))
+		}
+		err := builder.CheckAnnotations(grammar)
+		if tc.want == "" {
+			if err != nil {
+				t.Errorf("%v: want no error, got %v", tc.annotations, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: want error %q, got %v", tc.annotations, tc.want, err)
+		}
+	}
+}
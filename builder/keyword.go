@@ -0,0 +1,46 @@
+package builder
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// WordAnnotation marks the rule that matches a character continuing an
+// identifier, a keyword literal must not be followed by such a character.
+const WordAnnotation = "word"
+
+// ResolveKeywords sets the boundary of every keyword literal of the
+// grammar to a reference to the rule annotated with @word. It returns an
+// error if the grammar has keyword literals but no @word rule.
+func ResolveKeywords(grammar *ast.Grammar) error {
+	var word *ast.Rule
+	for _, rule := range grammar.Rules {
+		if !rule.HasAnnotation(WordAnnotation) {
+			continue
+		}
+		if word != nil {
+			return fmt.Errorf("%s: rule %s: more than one @%s rule, first one is %s",
+				rule.Pos(), rule.Name.Val, WordAnnotation, word.Name.Val)
+		}
+		word = rule
+	}
+
+	var err error
+	ast.Inspect(grammar, func(expr ast.Expression) bool {
+		lit, ok := expr.(*ast.LitMatcher)
+		if !ok || !lit.Keyword || err != nil {
+			return err == nil
+		}
+		if word == nil {
+			err = fmt.Errorf("%s: keyword literal %s requires a @%s rule",
+				lit.Pos(), strconv.Quote(lit.Val), WordAnnotation)
+			return false
+		}
+		lit.Boundary = ast.NewRuleRefExpr(lit.Pos())
+		lit.Boundary.Name = ast.NewIdentifier(lit.Pos(), word.Name.Val)
+		return false
+	})
+	return err
+}
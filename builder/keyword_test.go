@@ -0,0 +1,86 @@
+package builder_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/builder"
+)
+
+// markKeywords marks every literal of the grammar as a keyword literal.
+func markKeywords(grammar *ast.Grammar) {
+	ast.Inspect(grammar, func(expr ast.Expression) bool {
+		if lit, ok := expr.(*ast.LitMatcher); ok {
+			lit.Keyword = true
+		}
+		return true
+	})
+}
+
+func TestResolveKeywords(t *testing.T) {
+	t.Parallel()
+
+	text := `
+	start = "if" cond / "else"
+	cond = [a-z]+
+	part = [a-z0-9]
+	`
+	grammar := parseAnnotated(t, text, map[string]string{
+		"part": builder.WordAnnotation,
+	})
+	markKeywords(grammar)
+	if err := builder.ResolveKeywords(grammar); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	ast.Inspect(grammar.Rules[0], func(expr ast.Expression) bool {
+		if lit, ok := expr.(*ast.LitMatcher); ok {
+			if lit.Boundary == nil {
+				t.Errorf("%q: want boundary, got none", lit.Val)
+				return false
+			}
+			got = append(got, lit.Val+"!"+lit.Boundary.Name.Val)
+		}
+		return true
+	})
+	if want := "if!part else!part"; strings.Join(got, " ") != want {
+		t.Errorf("want boundaries %q, got %q", want, strings.Join(got, " "))
+	}
+}
+
+func TestResolveKeywordsErrors(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		text        string
+		annotations map[string]string
+		want        string
+	}{
+		{
+			text: `start = "if"`,
+			want: `keyword literal "if" requires a @word rule`,
+		},
+		{
+			text: `
+			start = "if"
+			a = [a-z]
+			b = [0-9]
+			`,
+			annotations: map[string]string{
+				"a": builder.WordAnnotation,
+				"b": builder.WordAnnotation,
+			},
+			want: "rule b: more than one @word rule, first one is a",
+		},
+	}
+	for _, tc := range cases {
+		grammar := parseAnnotated(t, tc.text, tc.annotations)
+		markKeywords(grammar)
+		err := builder.ResolveKeywords(grammar)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q: want error %q, got %v", tc.text, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package builder
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// lineDirectives returns the code val of the code block between a line
+// directive to the position of its first character in the grammar and a
+// line directive back to the generated file, resolved once formatted.
+func (b *Builder) lineDirectives(code *ast.CodeBlock, val string) string {
+	if val == "" || len(code.Val) < 2 {
+		return val
+	}
+
+	inner := code.Val[1:]
+	first := code.Val[:len(code.Val)-len(strings.TrimLeftFunc(inner, unicode.IsSpace))]
+	pos := code.Pos()
+	line, col := pos.Line, pos.Col
+	if i := strings.LastIndexByte(first, '\n'); i >= 0 {
+		line += strings.Count(first, "\n")
+		col = 1
+		first = first[i+1:]
+	}
+	col += utf8.RuneCountInString(first)
+
+	// the directive sets the position of the start of the next line, the
+	// formatted body is indented with two tabs
+	if col > 2 {
+		col -= 2
+	} else {
+		col = 1
+	}
+	return fmt.Sprintf("\n//line %s:%d:%d\n%s\n//line %s:1:1", b.LineGrammarFile, line, col, val, b.LineParserFile)
+}
+
+// ResolveLineDirectives sets the line directives back to parserFile in the
+// generated parser src to the line that follows them. It must be called on
+// the formatted code of a parser generated with the LineDirectives option.
+func ResolveLineDirectives(src []byte, parserFile string) []byte {
+	prefix := []byte("//line " + parserFile + ":")
+	lines := bytes.SplitAfter(src, []byte("\n"))
+	for i, line := range lines {
+		if bytes.HasPrefix(line, prefix) {
+			lines[i] = []byte(fmt.Sprintf("%s%d:1\n", prefix, i+2))
+		}
+	}
+	return bytes.Join(lines, nil)
+}
@@ -0,0 +1,64 @@
+package builder_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/imports"
+
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/builder"
+)
+
+func TestLineDirectives(t *testing.T) {
+	t.Parallel()
+
+	text := `{
+package p
+}
+start = "a" {
+	return 1
+} / "b" { return 2 }
+`
+	grammar, err := bootstrap.NewParser().Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := builder.BuildParser(&buf, grammar, builder.GrammarName("g"), builder.LineDirectives("../p.peg", "p.go")); err != nil {
+		t.Fatal(err)
+	}
+	src, err := imports.Process("p.go", buf.Bytes(), &imports.Options{TabWidth: 8, TabIndent: true, Comments: true, Fragment: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src = builder.ResolveLineDirectives(src, "p.go")
+
+	// the directives back to the generated file are set to the line that
+	// follows them
+	lines := strings.Split(string(src), "\n")
+	var got []string
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "//line ") {
+			continue
+		}
+		if strings.HasPrefix(line, "//line p.go:") {
+			if want := fmt.Sprintf("//line p.go:%d:1", i+2); line != want {
+				t.Errorf("want %q, got %q", want, line)
+			}
+			line = "//line p.go"
+		}
+		got = append(got, line, strings.TrimSpace(lines[i+1]))
+	}
+	want := []string{
+		"//line ../p.peg:5:1", "return 1",
+		"//line p.go", "})(&p.cur)",
+		"//line ../p.peg:6:9", "return 2",
+		"//line p.go", "})(&p.cur)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("want directives:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
@@ -0,0 +1,103 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// ToolOptions is the set of the options of the options block of the
+// grammar that are not builder options but options of the tool that
+// generates the parser, e.g. the command-line flags of the same name.
+// GrammarOptions accepts and ignores them.
+var ToolOptions = map[string]bool{
+	"alternate-entrypoints": true,
+	"fuzz-corpus":           true,
+	"fuzz-test":             true,
+	"line-directives":       true,
+	"optimize-grammar":      true,
+	"typecheck":             true,
+}
+
+// namedOptions maps the name of the builder options that may be set in the
+// options block of the grammar to the option set to a value.
+var namedOptions = map[string]func(value string) (Option, error){
+	"ascii-fold":                 boolOption(ASCIIFold),
+	"grammar-name":               stringOption(GrammarName),
+	"grammar-only":               boolOption(GrammarOnly),
+	"nolint":                     boolOption(Nolint),
+	"optimize-parser":            boolOption(Optimize),
+	"optimize-ref-expr-by-index": boolOption(OptimizeRefExprByIndex),
+	"receiver-name":              stringOption(ReceiverName),
+	"run-func-prefix":            stringOption(RunFuncPrefix),
+}
+
+func boolOption(opt func(bool) Option) func(string) (Option, error) {
+	return func(value string) (Option, error) {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return opt(v), nil
+	}
+}
+
+func stringOption(opt func(string) Option) func(string) (Option, error) {
+	return func(value string) (Option, error) {
+		return opt(value), nil
+	}
+}
+
+// NamedOption returns the builder option named name, as in the options
+// block of the grammar, set to value. It returns a nil option if there is
+// no builder option named name, and an error if value is invalid.
+func NamedOption(name, value string) (Option, error) {
+	opt, ok := namedOptions[name]
+	if !ok {
+		return nil, nil
+	}
+	return opt(value)
+}
+
+// GrammarOptions returns the builder options set in the options block of
+// the grammar. It returns an error listing the position of every invalid
+// option, the tool options are accepted but not returned.
+func GrammarOptions(grammar *ast.Grammar) ([]Option, error) {
+	var opts []Option
+	var errs []string
+	seen := make(map[string]bool)
+	for _, opt := range grammar.Options {
+		name := opt.Name.Val
+		_, builderOpt := namedOptions[name]
+		switch {
+		case !builderOpt && !ToolOptions[name]:
+			errs = append(errs, fmt.Sprintf("%s: unknown option %q", opt.Pos(), name))
+			continue
+		case seen[name]:
+			errs = append(errs, fmt.Sprintf("%s: option %q set more than once", opt.Pos(), name))
+			continue
+		}
+		seen[name] = true
+		if !builderOpt {
+			continue
+		}
+
+		if len(opt.Values) > 1 {
+			errs = append(errs, fmt.Sprintf("%s: option %q expects a single value", opt.Pos(), name))
+			continue
+		}
+		o, err := NamedOption(name, opt.Values[0])
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: invalid value %q for option %q", opt.Pos(), opt.Values[0], name))
+			continue
+		}
+		opts = append(opts, o)
+	}
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+	return opts, nil
+}
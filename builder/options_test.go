@@ -0,0 +1,84 @@
+package builder_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/builder"
+)
+
+// withOptions returns the grammar of text with the options set in its
+// options block, as name, value... lists.
+func withOptions(t *testing.T, text string, opts ...[]string) *ast.Grammar {
+	t.Helper()
+
+	grammar, err := bootstrap.NewParser().Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, o := range opts {
+		opt := ast.NewOption(ast.Pos{Line: i + 2, Col: 2}, ast.NewIdentifier(ast.Pos{}, o[0]))
+		opt.Values = o[1:]
+		grammar.Options = append(grammar.Options, opt)
+	}
+	return grammar
+}
+
+func TestGrammarOptions(t *testing.T) {
+	t.Parallel()
+
+	text := "{\npackage p\n}\nA <- \"a\" { return 1 }\n"
+	opts := [][]string{
+		{"receiver-name", "r"},
+		{"grammar-name", "gr"},
+		{"run-func-prefix", "pre"},
+		{"alternate-entrypoints", "A"},
+	}
+
+	var buf bytes.Buffer
+	if err := builder.BuildParser(&buf, withOptions(t, text, opts...)); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"var gr = &grammar", "func (p *parser) call_onpreA_1() any", "(func (r *current, "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %q in the parser", want)
+		}
+	}
+
+	// the options passed to the builder take precedence
+	buf.Reset()
+	if err := builder.BuildParser(&buf, withOptions(t, text, opts...), builder.GrammarName("q")); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"var q = &grammar", "func (p *parser) call_onpreA_1() any", "(func (r *current, "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("want %q in the parser", want)
+		}
+	}
+}
+
+func TestGrammarOptionsErrors(t *testing.T) {
+	t.Parallel()
+
+	grammar := withOptions(t, "A <- \"a\"\n",
+		[]string{"unknown", "x"},
+		[]string{"nolint", "maybe"},
+		[]string{"grammar-name", "a", "b"},
+		[]string{"alternate-entrypoints", "A"},
+		[]string{"alternate-entrypoints", "A"},
+	)
+	_, err := builder.GrammarOptions(grammar)
+	want := `2:2 (0): unknown option "unknown"
+3:2 (0): invalid value "maybe" for option "nolint"
+4:2 (0): option "grammar-name" expects a single value
+6:2 (0): option "alternate-entrypoints" set more than once`
+	if err == nil || err.Error() != want {
+		t.Errorf("want error:\n%s\ngot:\n%v", want, err)
+	}
+	if err := builder.BuildParser(&bytes.Buffer{}, grammar); err == nil || err.Error() != want {
+		t.Errorf("want build error:\n%s\ngot:\n%v", want, err)
+	}
+}
@@ -0,0 +1,52 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// CheckRepetitions returns an error listing the position of every
+// unbounded repetition of the grammar over an expression that can match
+// without consuming any input, as such a repetition would loop forever.
+// For a separated list, both the element and the separator must be able
+// to match the empty input.
+func CheckRepetitions(grammar *ast.Grammar) error {
+	rules := make(map[string]*ast.Rule, len(grammar.Rules))
+	for _, rule := range grammar.Rules {
+		rules[rule.Name.Val] = rule
+	}
+
+	var errs []string
+	for _, rule := range grammar.Rules {
+		rule := rule
+		ast.Inspect(rule.Expr, func(expr ast.Expression) bool {
+			var repeated ast.Expression
+			switch expr := expr.(type) {
+			case *ast.ZeroOrMoreExpr:
+				repeated = expr.Expr
+			case *ast.OneOrMoreExpr:
+				repeated = expr.Expr
+			case *ast.RepeatExpr:
+				if expr.Max < 0 {
+					repeated = expr.Expr
+				}
+			case *ast.SeparatedExpr:
+				if expr.Sep.NullableVisit(rules) {
+					repeated = expr.Expr
+				}
+			}
+			if repeated != nil && repeated.NullableVisit(rules) {
+				errs = append(errs, fmt.Sprintf("%s: rule %s: repeated expression can match the empty input, the repetition would never end",
+					repeated.Pos(), rule.Name.Val))
+			}
+			return true
+		})
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
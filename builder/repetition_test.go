@@ -0,0 +1,97 @@
+package builder_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/builder"
+)
+
+func TestCheckRepetitions(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{text: `
+		start = ( 'a' 'b'? )* [^]* [a-z]+ ( 'c' / 'd' )* !'e'
+		`},
+		{text: `
+		start = ( 'a'? )*
+		`, want: []string{"2:13 (13): rule start: repeated expression"}},
+		{text: `
+		start = x+
+		x = 'a'* 'b'? { return nil, nil }
+		`, want: []string{"2:11 (11): rule start: repeated expression"}},
+		{text: `
+		start = ( !'a' )* ( &'b' )+ ""*
+		`, want: []string{
+			"2:13 (13): rule start: repeated expression",
+			"2:23 (23): rule start: repeated expression",
+			"2:31 (31): rule start: repeated expression",
+		}},
+		{text: `
+		start = ( x / 'a' )*
+		x = y
+		y = 'b' / ""
+		`, want: []string{"2:13 (13): rule start: repeated expression"}},
+	}
+
+	for _, tc := range cases {
+		grammar := parseAnnotated(t, tc.text, nil)
+		err := builder.CheckRepetitions(grammar)
+		if len(tc.want) == 0 {
+			if err != nil {
+				t.Errorf("%q: want no error, got %v", tc.text, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%q: want error, got none", tc.text)
+			continue
+		}
+		lines := strings.Split(err.Error(), "\n")
+		if len(lines) != len(tc.want) {
+			t.Errorf("%q: want %d errors, got %d: %v", tc.text, len(tc.want), len(lines), err)
+			continue
+		}
+		for i, want := range tc.want {
+			if !strings.HasPrefix(lines[i], want) {
+				t.Errorf("%q: want error %q, got %q", tc.text, want, lines[i])
+			}
+		}
+	}
+}
+
+func TestCheckRepetitionsBounded(t *testing.T) {
+	t.Parallel()
+
+	grammar := parseAnnotated(t, `
+	start = 'a'
+	`, nil)
+	optional := func() ast.Expression {
+		opt := ast.NewZeroOrOneExpr(ast.Pos{})
+		opt.Expr = ast.NewLitMatcher(ast.Pos{}, "a")
+		return opt
+	}
+
+	bounded := ast.NewRepeatExpr(ast.Pos{})
+	bounded.Expr, bounded.Min, bounded.Max = optional(), 2, 3
+	separated := ast.NewSeparatedExpr(ast.Pos{})
+	separated.Expr, separated.Sep = optional(), ast.NewLitMatcher(ast.Pos{}, ",")
+	grammar.Rules[0].Expr = &ast.SeqExpr{Exprs: []ast.Expression{bounded, separated}}
+	if err := builder.CheckRepetitions(grammar); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+
+	unbounded := ast.NewRepeatExpr(ast.Pos{})
+	unbounded.Expr, unbounded.Min = optional(), 2
+	separated.Sep = optional()
+	grammar.Rules[0].Expr = &ast.SeqExpr{Exprs: []ast.Expression{unbounded, separated}}
+	err := builder.CheckRepetitions(grammar)
+	if err == nil || len(strings.Split(err.Error(), "\n")) != 2 {
+		t.Errorf("want 2 errors, got %v", err)
+	}
+}
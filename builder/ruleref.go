@@ -0,0 +1,76 @@
+package builder
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// CheckRuleRefs returns an error listing the position of every reference
+// to an undefined rule of the grammar, with the closest rule name as a
+// suggestion when there is one.
+func CheckRuleRefs(grammar *ast.Grammar) error {
+	defined := make(map[string]bool, len(grammar.Rules))
+	for _, rule := range grammar.Rules {
+		defined[rule.Name.Val] = true
+	}
+
+	var errs []string
+	ast.Inspect(grammar, func(expr ast.Expression) bool {
+		ref, ok := expr.(*ast.RuleRefExpr)
+		if !ok || defined[ref.Name.Val] {
+			return true
+		}
+		msg := fmt.Sprintf("%s: undefined rule: %s", ref.Pos(), ref.Name.Val)
+		if name := suggestRule(grammar, ref.Name.Val); name != "" {
+			msg += fmt.Sprintf(" (did you mean %s?)", name)
+		}
+		errs = append(errs, msg)
+		return true
+	})
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// suggestRule returns the name of the rule of the grammar closest to name,
+// or an empty string if no rule is close enough. A name that only differs
+// by case is always suggested, otherwise the edit distance must be at most
+// a third of the length of name.
+func suggestRule(grammar *ast.Grammar, name string) string {
+	best, bestDist := "", len([]rune(name))/3+1
+	for _, rule := range grammar.Rules {
+		if strings.EqualFold(rule.Name.Val, name) {
+			return rule.Name.Val
+		}
+		if dist := editDistance(rule.Name.Val, name); dist < bestDist {
+			best, bestDist = rule.Name.Val, dist
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(min(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
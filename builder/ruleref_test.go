@@ -0,0 +1,31 @@
+package builder_test
+
+import (
+	"testing"
+
+	"github.com/oskoi/pigeon/builder"
+)
+
+func TestCheckRuleRefs(t *testing.T) {
+	t.Parallel()
+
+	text := `start = expr Numbr
+expr = Number / strING / ( Nmber ) / unknown
+Number = [0-9]+
+String = "x"
+`
+	grammar := parseAnnotated(t, text, nil)
+	err := builder.CheckRuleRefs(grammar)
+	want := `1:14 (13): undefined rule: Numbr (did you mean Number?)
+2:17 (35): undefined rule: strING (did you mean String?)
+2:28 (46): undefined rule: Nmber (did you mean Number?)
+2:38 (56): undefined rule: unknown`
+	if err == nil || err.Error() != want {
+		t.Errorf("want error:\n%s\ngot:\n%v", want, err)
+	}
+
+	grammar = parseAnnotated(t, "start = a\na = 'a'\n", nil)
+	if err := builder.CheckRuleRefs(grammar); err != nil {
+		t.Errorf("want no error, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package builder
+
+import (
+	goast "go/ast"
+	"go/parser"
+	"go/token"
+)
+
+// endsInTerminatingStmt reports whether the code of an action ends in a
+// terminating statement as defined by the Go specification, e.g. a return
+// or a call to panic, in which case the implicit return that follows it
+// in the generated function would be reported as unreachable by go vet.
+func endsInTerminatingStmt(code string) bool {
+	src := "package p\nfunc _() {\n" + code + "\n}"
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil || len(f.Decls) == 0 {
+		return false
+	}
+	fn, ok := f.Decls[0].(*goast.FuncDecl)
+	if !ok || fn.Body == nil {
+		return false
+	}
+	return isTerminatingList(fn.Body.List)
+}
+
+func isTerminatingList(list []goast.Stmt) bool {
+	// the trailing empty statements are ignored
+	for len(list) > 0 {
+		if _, ok := list[len(list)-1].(*goast.EmptyStmt); !ok {
+			break
+		}
+		list = list[:len(list)-1]
+	}
+	return len(list) > 0 && isTerminating(list[len(list)-1], "")
+}
+
+// isTerminating reports whether the statement s, labeled label if not
+// empty, is a terminating statement.
+func isTerminating(s goast.Stmt, label string) bool {
+	switch s := s.(type) {
+	case *goast.ReturnStmt:
+		return true
+
+	case *goast.BranchStmt:
+		return s.Tok == token.GOTO || s.Tok == token.FALLTHROUGH
+
+	case *goast.ExprStmt:
+		call, ok := s.X.(*goast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := call.Fun.(*goast.Ident)
+		return ok && id.Name == "panic"
+
+	case *goast.BlockStmt:
+		return isTerminatingList(s.List)
+
+	case *goast.IfStmt:
+		return s.Else != nil && isTerminating(s.Body, "") && isTerminating(s.Else, "")
+
+	case *goast.ForStmt:
+		return s.Cond == nil && !hasBreakList(s.Body.List, label)
+
+	case *goast.LabeledStmt:
+		return isTerminating(s.Stmt, s.Label.Name)
+
+	case *goast.SwitchStmt:
+		return isTerminatingClauses(s.Body, label)
+
+	case *goast.TypeSwitchStmt:
+		return isTerminatingClauses(s.Body, label)
+
+	case *goast.SelectStmt:
+		for _, cc := range s.Body.List {
+			cc := cc.(*goast.CommClause)
+			if !isTerminatingList(cc.Body) || hasBreakList(cc.Body, label) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isTerminatingClauses reports whether the clauses of a switch statement
+// labeled label make it a terminating statement: there is a default
+// clause, every clause ends in a terminating statement, possibly a
+// fallthrough, and no break refers to the switch.
+func isTerminatingClauses(body *goast.BlockStmt, label string) bool {
+	hasDefault := false
+	for _, cc := range body.List {
+		cc := cc.(*goast.CaseClause)
+		if cc.List == nil {
+			hasDefault = true
+		}
+		if !isTerminatingList(cc.Body) || hasBreakList(cc.Body, label) {
+			return false
+		}
+	}
+	return hasDefault
+}
+
+// hasBreakList reports whether the statements of the body of a for,
+// switch or select statement labeled label contain a break that refers to
+// it.
+func hasBreakList(list []goast.Stmt, label string) bool {
+	for _, s := range list {
+		if hasBreakStmt(s, label, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasBreakStmt reports whether the statement s contains a break that
+// refers to the enclosing statement labeled label. If nested is true, s
+// is within a nested for, switch or select statement, to which the breaks
+// without label refer.
+func hasBreakStmt(s goast.Stmt, label string, nested bool) bool {
+	found := false
+	goast.Inspect(s, func(n goast.Node) bool {
+		if found {
+			return false
+		}
+		switch n := n.(type) {
+		case *goast.FuncLit:
+			return false
+		case *goast.BranchStmt:
+			if n.Tok == token.BREAK && (n.Label == nil && !nested || n.Label != nil && n.Label.Name == label) {
+				found = true
+			}
+		case *goast.ForStmt, *goast.RangeStmt, *goast.SwitchStmt, *goast.TypeSwitchStmt, *goast.SelectStmt:
+			if !nested {
+				found = hasBreakStmt(n.(goast.Stmt), label, true)
+				return false
+			}
+		}
+		return true
+	})
+	return found
+}
@@ -0,0 +1,61 @@
+package builder_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/imports"
+
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/builder"
+)
+
+func TestImplicitReturn(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		code     string
+		implicit bool
+	}{
+		{`return 1`, false},
+		{`panic("a")`, false},
+		{`return 1;`, false},
+		{`{ return 1 }`, false},
+		{`if c.text == nil { return 1 } else { panic("a") }`, false},
+		{`if c.text == nil { return 1 } else if len(c.text) > 1 { return 2 } else { return 3 }`, false},
+		{`for { }`, false},
+		{`L: for { for { break } }`, false},
+		{`switch len(c.text) { case 0: return 1; case 1: fallthrough; default: panic("a") }`, false},
+		{`L: goto L`, false},
+
+		{`_ = c`, true},
+		{`if c.text == nil { return 1 }`, true},
+		{`for c.text == nil { return 1 }`, true},
+		{`for { break }`, true},
+		{`L: for { for { break L } }`, true},
+		{`for { switch { default: break } ; if c.text == nil { break } }`, true},
+		{`switch len(c.text) { case 0: return 1 }`, true},
+		{`switch { default: if c.text == nil { break }; return 1 }`, true},
+		{`fn := func() { panic("a") }; fn()`, true},
+	}
+	for _, tc := range cases {
+		text := "{\npackage p\n}\nstart = \"a\" {\n" + tc.code + "\n}\n"
+		grammar, err := bootstrap.NewParser().Parse("", strings.NewReader(text))
+		if err != nil {
+			t.Fatalf("%q: %v", tc.code, err)
+		}
+		var buf bytes.Buffer
+		if err := builder.BuildParser(&buf, grammar, builder.GrammarName("g")); err != nil {
+			t.Fatalf("%q: %v", tc.code, err)
+		}
+		src, err := imports.Process("p.go", buf.Bytes(), &imports.Options{TabWidth: 8, TabIndent: true, Comments: true, Fragment: true})
+		if err != nil {
+			t.Fatalf("%q: %v", tc.code, err)
+		}
+		got := bytes.Contains(src, []byte("\n\t\treturn nil\n\t})(&p.cur)"))
+		if got != tc.implicit {
+			t.Errorf("%q: want implicit return %t, got %t", tc.code, tc.implicit, got)
+		}
+	}
+}
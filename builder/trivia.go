@@ -0,0 +1,120 @@
+package builder
+
+import (
+	"fmt"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+const (
+	// TokenAnnotation marks a lexical rule, the trivia rule is matched
+	// before every reference to such a rule from a syntactic rule.
+	TokenAnnotation = "token"
+	// TriviaAnnotation marks the rule that matches the whitespace and
+	// comments to skip before the tokens.
+	TriviaAnnotation = "trivia"
+)
+
+// InsertTrivia inserts a reference to the rule annotated with @trivia
+// before every reference to a rule annotated with @token in the syntactic
+// rules of the grammar, that is the rules that are neither annotated with
+// @token nor with @trivia. The grammar is left untouched if it has no
+// @trivia rule.
+func InsertTrivia(grammar *ast.Grammar) error {
+	var trivia *ast.Rule
+	tokens := make(map[string]bool)
+	for _, rule := range grammar.Rules {
+		if rule.HasAnnotation(TriviaAnnotation) {
+			if trivia != nil {
+				return fmt.Errorf("%s: rule %s: more than one @%s rule, first one is %s",
+					rule.Pos(), rule.Name.Val, TriviaAnnotation, trivia.Name.Val)
+			}
+			trivia = rule
+		}
+		if rule.HasAnnotation(TokenAnnotation) {
+			tokens[rule.Name.Val] = true
+		}
+	}
+	if trivia == nil {
+		return nil
+	}
+
+	ins := &triviaInserter{trivia: trivia.Name, tokens: tokens}
+	for _, rule := range grammar.Rules {
+		if rule == trivia || tokens[rule.Name.Val] {
+			continue
+		}
+		ast.Walk(ins, rule)
+	}
+	return nil
+}
+
+// triviaInserter is a Visitor that wraps the references to token rules
+// in a TriviaExpr.
+type triviaInserter struct {
+	trivia *ast.Identifier
+	tokens map[string]bool
+}
+
+func (t *triviaInserter) Visit(expr ast.Expression) ast.Visitor {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		expr.Expr = t.wrap(expr.Expr)
+	case *ast.AndExpr:
+		expr.Expr = t.wrap(expr.Expr)
+	case *ast.ChoiceExpr:
+		for i, alt := range expr.Alternatives {
+			expr.Alternatives[i] = t.wrap(alt)
+		}
+	case *ast.LabeledExpr:
+		expr.Expr = t.wrap(expr.Expr)
+	case *ast.NotExpr:
+		expr.Expr = t.wrap(expr.Expr)
+	case *ast.OneOrMoreExpr:
+		expr.Expr = t.wrap(expr.Expr)
+	case *ast.PrecedenceExpr:
+		expr.Atom = t.wrap(expr.Atom)
+		for _, l := range expr.Levels {
+			for _, op := range l.Operators {
+				op.Expr = t.wrap(op.Expr)
+			}
+		}
+	case *ast.RecoveryExpr:
+		expr.Expr = t.wrap(expr.Expr)
+		expr.RecoverExpr = t.wrap(expr.RecoverExpr)
+	case *ast.RepeatExpr:
+		expr.Expr = t.wrap(expr.Expr)
+	case *ast.Rule:
+		expr.Expr = t.wrap(expr.Expr)
+	case *ast.SeparatedExpr:
+		expr.Expr = t.wrap(expr.Expr)
+		expr.Sep = t.wrap(expr.Sep)
+	case *ast.SeqExpr:
+		for i, e := range expr.Exprs {
+			expr.Exprs[i] = t.wrap(e)
+		}
+	case *ast.TriviaExpr:
+		// already inserted, do not visit the wrapped reference
+		return nil
+	case *ast.ZeroOrMoreExpr:
+		expr.Expr = t.wrap(expr.Expr)
+	case *ast.ZeroOrOneExpr:
+		expr.Expr = t.wrap(expr.Expr)
+	}
+	return t
+}
+
+func (t *triviaInserter) wrap(expr ast.Expression) ast.Expression {
+	ref, ok := expr.(*ast.RuleRefExpr)
+	if !ok || !t.tokens[ref.Name.Val] {
+		return expr
+	}
+
+	triviaRef := ast.NewRuleRefExpr(ref.Pos())
+	triviaRef.Name = ast.NewIdentifier(ref.Pos(), t.trivia.Val)
+
+	trivia := ast.NewTriviaExpr(ref.Pos())
+	trivia.Trivia = triviaRef
+	trivia.Expr = ref
+	return trivia
+}
@@ -0,0 +1,97 @@
+package builder_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/builder"
+)
+
+func parseAnnotated(t *testing.T, text string, annotations map[string]string) *ast.Grammar {
+	t.Helper()
+
+	p := bootstrap.NewParser()
+	grammar, err := p.Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range grammar.Rules {
+		if name, ok := annotations[rule.Name.Val]; ok {
+			rule.Annotations = append(rule.Annotations, ast.NewIdentifier(rule.Pos(), name))
+		}
+	}
+	return grammar
+}
+
+func TestInsertTrivia(t *testing.T) {
+	t.Parallel()
+
+	text := `
+	start = list:(expr (COMMA expr)*) EOF
+	expr = NUMBER / LPAREN expr RPAREN
+	NUMBER = [0-9]+
+	COMMA = ','
+	LPAREN = '(' skip
+	RPAREN = ')'
+	EOF = !.
+	skip = [ \t\n]*
+	`
+	grammar := parseAnnotated(t, text, map[string]string{
+		"NUMBER": builder.TokenAnnotation,
+		"COMMA":  builder.TokenAnnotation,
+		"LPAREN": builder.TokenAnnotation,
+		"RPAREN": builder.TokenAnnotation,
+		"EOF":    builder.TokenAnnotation,
+		"skip":   builder.TriviaAnnotation,
+	})
+	if err := builder.InsertTrivia(grammar); err != nil {
+		t.Fatal(err)
+	}
+
+	refs := make(map[string][]string)
+	for _, rule := range grammar.Rules {
+		rule := rule
+		ast.Inspect(rule, func(expr ast.Expression) bool {
+			switch expr := expr.(type) {
+			case *ast.TriviaExpr:
+				ref := expr.Expr.(*ast.RuleRefExpr)
+				refs[rule.Name.Val] = append(refs[rule.Name.Val], "~"+ref.Name.Val)
+				return false
+			case *ast.RuleRefExpr:
+				refs[rule.Name.Val] = append(refs[rule.Name.Val], expr.Name.Val)
+			}
+			return true
+		})
+	}
+
+	want := map[string]string{
+		"start":  "expr ~COMMA expr ~EOF",
+		"expr":   "~NUMBER ~LPAREN expr ~RPAREN",
+		"LPAREN": "skip",
+	}
+	for name, refsWant := range want {
+		if got := strings.Join(refs[name], " "); got != refsWant {
+			t.Errorf("rule %s: want references %q, got %q", name, refsWant, got)
+		}
+	}
+}
+
+func TestInsertTriviaMultiple(t *testing.T) {
+	t.Parallel()
+
+	text := `
+	start = 'a'
+	ws = ' '*
+	comment = '#' [^\n]*
+	`
+	grammar := parseAnnotated(t, text, map[string]string{
+		"ws":      builder.TriviaAnnotation,
+		"comment": builder.TriviaAnnotation,
+	})
+	err := builder.InsertTrivia(grammar)
+	if err == nil || !strings.Contains(err.Error(), "rule comment: more than one @trivia rule, first one is ws") {
+		t.Errorf("want error for multiple @trivia rules, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"reflect"
 	"strconv"
 	"testing"
 
@@ -19,6 +20,17 @@ func compareGrammars(t *testing.T, src string, exp, got *ast.Grammar) bool {
 		}
 	}
 
+	on, om := len(exp.Options), len(got.Options)
+	if on != om {
+		t.Errorf("%q: want %d options, got %d", src, on, om)
+		return false
+	}
+	for i, o := range got.Options {
+		if !compareOption(t, src+": "+exp.Options[i].Name.Val, exp.Options[i], o) {
+			return false
+		}
+	}
+
 	rn, rm := len(exp.Rules), len(got.Rules)
 	if rn != rm {
 		t.Errorf("%q: want %d rules, got %d", src, rn, rm)
@@ -34,6 +46,24 @@ func compareGrammars(t *testing.T, src string, exp, got *ast.Grammar) bool {
 	return true
 }
 
+func compareOption(t *testing.T, prefix string, exp, got *ast.Option) bool {
+	if exp.Name.Val != got.Name.Val {
+		t.Errorf("%q: want option name %q, got %q", prefix, exp.Name.Val, got.Name.Val)
+		return false
+	}
+	if len(exp.Values) != len(got.Values) {
+		t.Errorf("%q: want %d values, got %d", prefix, len(exp.Values), len(got.Values))
+		return false
+	}
+	for i, v := range exp.Values {
+		if v != got.Values[i] {
+			t.Errorf("%q: want Values[%d] %q, got %q", prefix, i, v, got.Values[i])
+			return false
+		}
+	}
+	return true
+}
+
 func compareRule(t *testing.T, prefix string, exp, got *ast.Rule) bool {
 	if exp.Name.Val != got.Name.Val {
 		t.Errorf("%q: want rule name %q, got %q", prefix, exp.Name.Val, got.Name.Val)
@@ -49,6 +79,16 @@ func compareRule(t *testing.T, prefix string, exp, got *ast.Rule) bool {
 			return false
 		}
 	}
+	if len(exp.Annotations) != len(got.Annotations) {
+		t.Errorf("%q: want %d Annotations, got %d", prefix, len(exp.Annotations), len(got.Annotations))
+		return false
+	}
+	for i, a := range exp.Annotations {
+		if a.Val != got.Annotations[i].Val {
+			t.Errorf("%q: want Annotations[%d] %q, got %q", prefix, i, a.Val, got.Annotations[i].Val)
+			return false
+		}
+	}
 	return compareExpr(t, prefix, 0, exp.Expr, got.Expr)
 }
 
@@ -161,6 +201,11 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 			}
 		}
 
+		if !reflect.DeepEqual(exp.Table, got.Table) {
+			t.Errorf("%q: want Table %v, got %v", ixPrefix, exp.Table, got.Table)
+			return false
+		}
+
 	case *ast.ChoiceExpr:
 		got, ok := got.(*ast.ChoiceExpr)
 		if !ok {
@@ -179,6 +224,21 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 			}
 		}
 
+	case *ast.CodeExpr:
+		got, ok := got.(*ast.CodeExpr)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		if exp.Code.Val != got.Code.Val {
+			t.Errorf("%q: want code %q, got %q", ixPrefix, exp.Code.Val, got.Code.Val)
+			return false
+		}
+		if exp.NotSkip != got.NotSkip {
+			t.Errorf("%q: want NotSkip %t, got %t", ixPrefix, exp.NotSkip, got.NotSkip)
+			return false
+		}
+
 	case *ast.LabeledExpr:
 		got, ok := got.(*ast.LabeledExpr)
 		if !ok {
@@ -195,6 +255,10 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 				return false
 			}
 		}
+		if exp.TextCapture != got.TextCapture {
+			t.Errorf("%q: want TextCapture %t, got %t", ixPrefix, exp.TextCapture, got.TextCapture)
+			return false
+		}
 
 		return compareExpr(t, prefix, ix+1, exp.Expr, got.Expr)
 
@@ -208,6 +272,10 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 			t.Errorf("%q: want IgnoreCase %t, got %t", ixPrefix, exp.IgnoreCase, got.IgnoreCase)
 			return false
 		}
+		if exp.Keyword != got.Keyword {
+			t.Errorf("%q: want Keyword %t, got %t", ixPrefix, exp.Keyword, got.Keyword)
+			return false
+		}
 		if exp.Val != got.Val {
 			t.Errorf("%q: want value %q, got %q", ixPrefix, exp.Val, got.Val)
 			return false
@@ -246,6 +314,33 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 		}
 		return compareExpr(t, prefix, ix+1, exp.Expr, got.Expr)
 
+	case *ast.RecoveryExpr:
+		got, ok := got.(*ast.RecoveryExpr)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		if !reflect.DeepEqual(exp.Labels, got.Labels) {
+			t.Errorf("%q: want labels %v, got %v", ixPrefix, exp.Labels, got.Labels)
+			return false
+		}
+		if !compareExpr(t, prefix, ix+1, exp.Expr, got.Expr) {
+			return false
+		}
+		return compareExpr(t, prefix, ix+1, exp.RecoverExpr, got.RecoverExpr)
+
+	case *ast.RepeatExpr:
+		got, ok := got.(*ast.RepeatExpr)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		if exp.Min != got.Min || exp.Max != got.Max {
+			t.Errorf("%q: want bounds {%d,%d}, got {%d,%d}", ixPrefix, exp.Min, exp.Max, got.Min, got.Max)
+			return false
+		}
+		return compareExpr(t, prefix, ix+1, exp.Expr, got.Expr)
+
 	case *ast.RuleRefExpr:
 		got, ok := got.(*ast.RuleRefExpr)
 		if !ok {
@@ -263,6 +358,79 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 			}
 		}
 
+	case *ast.BackRefExpr:
+		got, ok := got.(*ast.BackRefExpr)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		if exp.Label.Val != got.Label.Val {
+			t.Errorf("%q: want label %q, got %q", ixPrefix, exp.Label.Val, got.Label.Val)
+			return false
+		}
+		return true
+
+	case *ast.PrecedenceExpr:
+		got, ok := got.(*ast.PrecedenceExpr)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		if !compareExpr(t, prefix, ix+1, exp.Atom, got.Atom) {
+			return false
+		}
+		ne, ng := len(exp.Levels), len(got.Levels)
+		if ne != ng {
+			t.Errorf("%q: want %d Levels, got %d", ixPrefix, ne, ng)
+			return false
+		}
+		for i, el := range exp.Levels {
+			gl := got.Levels[i]
+			if el.Kind != gl.Kind {
+				t.Errorf("%q: level %d: want Kind %s, got %s", ixPrefix, i, el.Kind, gl.Kind)
+				return false
+			}
+			ne, ng := len(el.Operators), len(gl.Operators)
+			if ne != ng {
+				t.Errorf("%q: level %d: want %d Operators, got %d", ixPrefix, i, ne, ng)
+				return false
+			}
+			for j, eo := range el.Operators {
+				gotOp := gl.Operators[j]
+				if (eo.Code != nil) != (gotOp.Code != nil) {
+					t.Errorf("%q: level %d: operator %d: want Code?: %t, got %t", ixPrefix, i, j, eo.Code != nil, gotOp.Code != nil)
+					return false
+				}
+				if eo.Code != nil && eo.Code.Val != gotOp.Code.Val {
+					t.Errorf("%q: level %d: operator %d: want code %q, got %q", ixPrefix, i, j, eo.Code.Val, gotOp.Code.Val)
+					return false
+				}
+				if !compareExpr(t, prefix, ix+1, eo.Expr, gotOp.Expr) {
+					return false
+				}
+			}
+		}
+		return true
+
+	case *ast.SeparatedExpr:
+		got, ok := got.(*ast.SeparatedExpr)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		if exp.Min != got.Min {
+			t.Errorf("%q: want Min %d, got %d", ixPrefix, exp.Min, got.Min)
+			return false
+		}
+		if exp.AllowTrailing != got.AllowTrailing {
+			t.Errorf("%q: want AllowTrailing %t, got %t", ixPrefix, exp.AllowTrailing, got.AllowTrailing)
+			return false
+		}
+		if !compareExpr(t, prefix, ix+1, exp.Expr, got.Expr) {
+			return false
+		}
+		return compareExpr(t, prefix, ix+1, exp.Sep, got.Sep)
+
 	case *ast.SeqExpr:
 		got, ok := got.(*ast.SeqExpr)
 		if !ok {
@@ -281,6 +449,17 @@ func compareExpr(t *testing.T, prefix string, ix int, exp, got ast.Expression) b
 			}
 		}
 
+	case *ast.ThrowExpr:
+		got, ok := got.(*ast.ThrowExpr)
+		if !ok {
+			t.Errorf("%q: want expression type %T, got %T", ixPrefix, exp, got)
+			return false
+		}
+		if exp.Label != got.Label {
+			t.Errorf("%q: want label %q, got %q", ixPrefix, exp.Label, got.Label)
+			return false
+		}
+
 	case *ast.ZeroOrMoreExpr:
 		got, ok := got.(*ast.ZeroOrMoreExpr)
 		if !ok {
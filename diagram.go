@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/diagram"
+)
+
+// diagramMain implements the diagram command, it renders the grammar as
+// railroad diagrams or as a graph of the dependencies between its rules.
+func diagramMain(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" diagram", flag.ExitOnError)
+
+	var (
+		formatFlag    = fs.String("format", "html", "output format: html, svg or dot")
+		shortHelpFlag = fs.Bool("h", false, "show help page")
+		longHelpFlag  = fs.Bool("help", false, "show help page")
+		outputFlag    = fs.String("o", "", "output file, defaults to stdout")
+		ruleFlag      = fs.String("rule", "", "rule to render with the svg format, defaults to the first rule")
+	)
+
+	usage := func() {
+		fmt.Printf(diagramUsagePage, os.Args[0])
+	}
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, "args parse error:\n", err)
+		exit(6)
+	}
+
+	if *shortHelpFlag || *longHelpFlag {
+		usage()
+		exit(0)
+	}
+
+	if fs.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "expected one argument, got %q\n", strings.Join(fs.Args(), " "))
+		usage()
+		exit(1)
+	}
+
+	switch *formatFlag {
+	case "html", "svg", "dot":
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q\n", *formatFlag)
+		usage()
+		exit(1)
+	}
+
+	nm, grammar := parseGrammar(fs.Arg(0))
+
+	var b []byte
+	switch *formatFlag {
+	case "html":
+		b = diagram.HTML(grammar, filepath.Base(nm))
+	case "svg":
+		rule := findRule(grammar, *ruleFlag)
+		if rule == nil {
+			fmt.Fprintf(os.Stderr, "%s: rule %q not found\n", nm, *ruleFlag)
+			exit(1)
+		}
+		b = diagram.RuleSVG(rule)
+	case "dot":
+		b = diagram.DOT(grammar)
+	}
+
+	out := output(*outputFlag)
+	if _, err := out.Write(b); err != nil {
+		fmt.Fprintln(os.Stderr, "write error:\n", err)
+		exit(7)
+	}
+	if err := out.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "close file error:\n", err)
+		exit(8)
+	}
+}
+
+// findRule returns the rule of the grammar with the given name, or the
+// first rule if name is empty. It returns nil if there is no such rule.
+func findRule(grammar *ast.Grammar, name string) *ast.Rule {
+	for _, rule := range grammar.Rules {
+		if name == "" || rule.Name.Val == name {
+			return rule
+		}
+	}
+	return nil
+}
+
+var diagramUsagePage = `usage: %s diagram [options] [GRAMMAR_FILE]
+
+Diagram renders a PEG grammar for its documentation. The grammar is read
+from stdin if GRAMMAR_FILE is not specified. The formats are:
+
+	html
+		a self-contained HTML page with the SVG railroad diagram of
+		each rule, the references to a rule link to its diagram.
+	svg
+		the SVG railroad diagram of a single rule.
+	dot
+		the Graphviz DOT graph of the dependencies between the rules,
+		e.g. rendered with "dot -Tsvg".
+
+	-format FORMAT
+		output format, html, svg or dot, defaults to html.
+	-h -help
+		display this help message.
+	-o OUTPUT_FILE
+		write the output to OUTPUT_FILE. Defaults to stdout.
+	-rule RULE
+		rule rendered by the svg format, defaults to the first rule.
+`
@@ -0,0 +1,302 @@
+// Package diagram renders a PEG grammar for its documentation: as SVG
+// railroad diagrams of its rules, gathered in an HTML page with links
+// between the rules, and as a Graphviz DOT graph of the dependencies
+// between its rules.
+package diagram
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// RuleSVG returns the SVG document of the railroad diagram of the rule.
+// The references to other rules are not links, as the document stands
+// alone.
+func RuleSVG(rule *ast.Rule) []byte {
+	return render(build(rule.Expr, nil))
+}
+
+// HTML returns a self-contained HTML page with the railroad diagram of
+// each rule of the grammar. The references to a rule link to its diagram,
+// and each diagram lists the rules that reference it.
+func HTML(grammar *ast.Grammar, title string) []byte {
+	uses := ast.RuleUses(grammar)
+	usedBy := make(map[string][]string)
+	defined := make(map[string]bool, len(grammar.Rules))
+	for _, rule := range grammar.Rules {
+		defined[rule.Name.Val] = true
+	}
+	for _, rule := range grammar.Rules {
+		for _, name := range uses[rule.Name.Val] {
+			usedBy[name] = append(usedBy[name], rule.Name.Val)
+		}
+	}
+	link := func(name string) string {
+		if !defined[name] {
+			return ""
+		}
+		return "#" + anchor(name)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+section { margin-bottom: 2em; }
+h2 { font-family: monospace; }
+.display-name { color: #555; font-weight: normal; }
+p.refs { font-size: 90%%; color: #555; }
+</style>
+</head>
+<body>
+<h1>%[1]s</h1>
+`, html.EscapeString(title))
+
+	buf.WriteString("<nav>\n")
+	for i, rule := range grammar.Rules {
+		if i > 0 {
+			buf.WriteString(" · ")
+		}
+		fmt.Fprintf(&buf, `<a href="#%s">%s</a>`, anchor(rule.Name.Val), html.EscapeString(rule.Name.Val))
+	}
+	buf.WriteString("\n</nav>\n")
+
+	for _, rule := range grammar.Rules {
+		name := rule.Name.Val
+		fmt.Fprintf(&buf, "<section id=\"%s\">\n<h2>%s", anchor(name), html.EscapeString(name))
+		if rule.DisplayName != nil {
+			fmt.Fprintf(&buf, ` <span class="display-name">%s</span>`, html.EscapeString(displayName(rule.DisplayName.Val)))
+		}
+		buf.WriteString("</h2>\n")
+		buf.Write(render(build(rule.Expr, link)))
+		refs(&buf, "References", uses[name], link)
+		refs(&buf, "Referenced by", usedBy[name], link)
+		buf.WriteString("</section>\n")
+	}
+
+	buf.WriteString("</body>\n</html>\n")
+	return buf.Bytes()
+}
+
+// refs writes the list of the rules names, linked to their diagram.
+func refs(buf *bytes.Buffer, title string, names []string, link func(string) string) {
+	if len(names) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, `<p class="refs">%s: `, title)
+	for i, name := range names {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if href := link(name); href != "" {
+			fmt.Fprintf(buf, `<a href="%s">%s</a>`, href, html.EscapeString(name))
+		} else {
+			buf.WriteString(html.EscapeString(name))
+		}
+	}
+	buf.WriteString("</p>\n")
+}
+
+func anchor(name string) string {
+	return "rule-" + name
+}
+
+// displayName returns the display name without its quotes, it is raw in
+// a parsed grammar.
+func displayName(s string) string {
+	if v, err := strconv.Unquote(s); err == nil {
+		return v
+	}
+	return s
+}
+
+// build returns the railroad node of expr. If link is not nil, it returns
+// the link to the diagram of a rule, or an empty string if the rule has no
+// diagram.
+func build(expr ast.Expression, link func(string) string) node {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		return build(expr.Expr, link)
+
+	case *ast.LabeledExpr:
+		return build(expr.Expr, link)
+
+	case *ast.TriviaExpr:
+		return build(expr.Expr, link)
+
+	case *ast.ChoiceExpr:
+		c := make(choice, len(expr.Alternatives))
+		for i, alt := range expr.Alternatives {
+			c[i] = build(alt, link)
+		}
+		return c
+
+	case *ast.SeqExpr:
+		var s sequence
+		for _, e := range expr.Exprs {
+			// the code blocks of a sequence match nothing
+			if _, ok := e.(*ast.CodeExpr); ok {
+				continue
+			}
+			s = append(s, build(e, link))
+		}
+		if len(s) == 0 {
+			return skip{}
+		}
+		if len(s) == 1 {
+			return s[0]
+		}
+		return s
+
+	case *ast.CodeExpr:
+		return skip{}
+
+	case *ast.RecoveryExpr:
+		labels := make([]string, len(expr.Labels))
+		for i, l := range expr.Labels {
+			labels[i] = string(l)
+		}
+		return group{
+			n:     choice{build(expr.Expr, link), build(expr.RecoverExpr, link)},
+			label: "recover " + strings.Join(labels, ", "),
+		}
+
+	case *ast.ThrowExpr:
+		return &box{text: "throw " + expr.Label, class: "special"}
+
+	case *ast.AndExpr:
+		return group{n: build(expr.Expr, link), label: "followed by"}
+
+	case *ast.NotExpr:
+		return group{n: build(expr.Expr, link), label: "not followed by"}
+
+	case *ast.ZeroOrOneExpr:
+		return optional{build(expr.Expr, link)}
+
+	case *ast.ZeroOrMoreExpr:
+		return optional{loop{n: build(expr.Expr, link)}}
+
+	case *ast.OneOrMoreExpr:
+		return loop{n: build(expr.Expr, link)}
+
+	case *ast.RepeatExpr:
+		return repeat(build(expr.Expr, link), expr.Min, expr.Max)
+
+	case *ast.SeparatedExpr:
+		var n node = loop{n: build(expr.Expr, link), sep: build(expr.Sep, link)}
+		if expr.AllowTrailing {
+			n = sequence{n, optional{build(expr.Sep, link)}}
+		}
+		if expr.Min == 0 {
+			n = optional{n}
+		}
+		return n
+
+	case *ast.PrecedenceExpr:
+		return precedence(expr, link)
+
+	case *ast.AndCodeExpr:
+		return &box{text: "&{…}", class: "special"}
+
+	case *ast.NotCodeExpr:
+		return &box{text: "!{…}", class: "special"}
+
+	case *ast.RuleRefExpr:
+		b := &box{text: expr.Name.Val, class: "nonterminal"}
+		if link != nil {
+			b.href = link(expr.Name.Val)
+		}
+		return b
+
+	case *ast.BackRefExpr:
+		return &box{text: "$" + expr.Label.Val, class: "special"}
+
+	case *ast.LitMatcher:
+		text := strconv.Quote(expr.Val)
+		if expr.IgnoreCase {
+			text += "i"
+		}
+		if expr.Keyword {
+			text += "k"
+		}
+		return &box{text: text, class: "terminal"}
+
+	case *ast.CharClassMatcher:
+		return &box{text: expr.Val, class: "terminal"}
+
+	case *ast.AnyMatcher:
+		return &box{text: "any character", class: "special"}
+
+	default:
+		panic(fmt.Sprintf("unexpected expression type %T", expr))
+	}
+}
+
+// repeat returns the node of n repeated between lo and hi times, or at
+// least lo times if hi is negative.
+func repeat(n node, lo, hi int) node {
+	switch {
+	case hi == 0:
+		return skip{}
+	case hi == 1 && lo == 0:
+		return optional{n}
+	case hi == 1:
+		return n
+	}
+
+	var label string
+	switch {
+	case hi < 0 && lo <= 1:
+		// same as * or +
+	case hi < 0:
+		label = fmt.Sprintf("at least %d times", lo)
+	case lo == hi:
+		label = fmt.Sprintf("%d times", lo)
+	default:
+		label = fmt.Sprintf("%d to %d times", lo, hi)
+	}
+	var r node = loop{n: n, label: label}
+	if lo == 0 {
+		r = optional{r}
+	}
+	return r
+}
+
+// precedence returns the node of a precedence expression: the atom,
+// preceded by any number of prefix operators and followed by any number of
+// postfix operators or of binary operators with their right operand.
+func precedence(expr *ast.PrecedenceExpr, link func(string) string) node {
+	var prefix, suffix choice
+	for _, l := range expr.Levels {
+		for _, op := range l.Operators {
+			n := build(op.Expr, link)
+			switch l.Kind {
+			case ast.Prefix:
+				prefix = append(prefix, n)
+			case ast.Postfix:
+				suffix = append(suffix, n)
+			default:
+				suffix = append(suffix, sequence{n, build(expr.Atom, link)})
+			}
+		}
+	}
+
+	var s sequence
+	if len(prefix) > 0 {
+		s = append(s, optional{loop{n: prefix}})
+	}
+	s = append(s, build(expr.Atom, link))
+	if len(suffix) > 0 {
+		s = append(s, optional{loop{n: suffix}})
+	}
+	return group{n: s, label: "by precedence"}
+}
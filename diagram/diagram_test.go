@@ -0,0 +1,114 @@
+package diagram_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/diagram"
+)
+
+const grammar = `
+start = (expr ';')* !.
+expr "expression" = term (('+' / '-') term)*
+term = [0-9]+ / '(' expr ')' / ident
+ident = [a-z] [a-z0-9]i* !'_'
+`
+
+func parse(t *testing.T, text string) *ast.Grammar {
+	t.Helper()
+
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+// wellFormed checks that b is a well-formed XML document.
+func wellFormed(t *testing.T, b []byte) {
+	t.Helper()
+
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("%v\n%s", err, b)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	t.Parallel()
+
+	g := parse(t, grammar+"misc = undefined\n")
+	want := `digraph grammar {
+	rankdir=LR;
+	node [shape=box, fontname=monospace];
+	"start" [peripheries=2];
+	"expr";
+	"term";
+	"ident";
+	"misc";
+	"undefined" [style=dashed];
+	"start" -> "expr";
+	"expr" -> "term";
+	"term" -> "expr";
+	"term" -> "ident";
+	"misc" -> "undefined";
+}
+`
+	if got := string(diagram.DOT(g)); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestHTML(t *testing.T) {
+	t.Parallel()
+
+	g := parse(t, grammar)
+	got := diagram.HTML(g, "calc & co")
+	wellFormed(t, got)
+
+	for _, want := range []string{
+		`<title>calc &amp; co</title>`,
+		`<section id="rule-expr">`,
+		`<span class="display-name">expression</span>`,
+		`<a href="#rule-term"><g class="nonterminal">`,
+		`<p class="refs">References: <a href="#rule-expr">expr</a>, <a href="#rule-ident">ident</a></p>`,
+		`<p class="refs">Referenced by: <a href="#rule-start">start</a>, <a href="#rule-term">term</a></p>`,
+		`<text x="`,
+		`>&#34;(&#34;</text>`,
+		`>[0-9]</text>`,
+		`>not followed by</text>`,
+	} {
+		if !bytes.Contains(got, []byte(want)) {
+			t.Errorf("want %q in the page", want)
+		}
+	}
+}
+
+func TestRuleSVG(t *testing.T) {
+	t.Parallel()
+
+	g := parse(t, grammar)
+	for _, rule := range g.Rules {
+		got := diagram.RuleSVG(rule)
+		wellFormed(t, got)
+		if !bytes.HasPrefix(got, []byte(`<svg xmlns="http://www.w3.org/2000/svg"`)) {
+			t.Errorf("%s: want an SVG document, got\n%s", rule.Name.Val, got)
+		}
+		if bytes.Contains(got, []byte("<a ")) {
+			t.Errorf("%s: want no link, got\n%s", rule.Name.Val, got)
+		}
+	}
+}
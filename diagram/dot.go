@@ -0,0 +1,48 @@
+package diagram
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// DOT returns the Graphviz DOT graph of the dependencies between the rules
+// of the grammar: a node per rule and an edge from each rule to each rule
+// it references. The first rule, the default entrypoint, is drawn with a
+// double border, and the referenced rules that are not defined are drawn
+// dashed.
+func DOT(grammar *ast.Grammar) []byte {
+	uses := ast.RuleUses(grammar)
+
+	var buf bytes.Buffer
+	buf.WriteString("digraph grammar {\n\trankdir=LR;\n\tnode [shape=box, fontname=monospace];\n")
+
+	defined := make(map[string]bool, len(grammar.Rules))
+	for i, rule := range grammar.Rules {
+		defined[rule.Name.Val] = true
+		fmt.Fprintf(&buf, "\t%s", strconv.Quote(rule.Name.Val))
+		if i == 0 {
+			buf.WriteString(" [peripheries=2]")
+		}
+		buf.WriteString(";\n")
+	}
+	undefined := make(map[string]bool)
+	for _, rule := range grammar.Rules {
+		for _, name := range uses[rule.Name.Val] {
+			if !defined[name] && !undefined[name] {
+				undefined[name] = true
+				fmt.Fprintf(&buf, "\t%s [style=dashed];\n", strconv.Quote(name))
+			}
+		}
+	}
+
+	for _, rule := range grammar.Rules {
+		for _, name := range uses[rule.Name.Val] {
+			fmt.Fprintf(&buf, "\t%s -> %s;\n", strconv.Quote(rule.Name.Val), strconv.Quote(name))
+		}
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
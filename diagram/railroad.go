@@ -0,0 +1,318 @@
+package diagram
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"unicode/utf8"
+)
+
+// Dimensions of the railroad diagrams, in pixels.
+const (
+	arc       = 10 // radius of the arcs of the tracks
+	gap       = 10 // length of the track between two items of a sequence
+	vgap      = 8  // vertical space between two tracks
+	boxHalf   = 11 // half of the height of a box
+	boxPad    = 10 // horizontal padding of the text in a box
+	charWidth = 8  // width of a character of the text of a box
+	labelLine = 14 // height of the line of a label
+	margin    = 20 // space around the diagram
+)
+
+// A node is an element of a railroad diagram. Its track enters on the
+// left at the height of its baseline and leaves on the right at the same
+// height, up and down are the extents of the node above and below its
+// baseline.
+type node interface {
+	size() (width, up, down int)
+	draw(w *svgWriter, x, y int)
+}
+
+func textWidth(s string) int {
+	return utf8.RuneCountInString(s) * charWidth
+}
+
+// box is a terminal or a non-terminal, a reference to a rule. A box with
+// an href links to the diagram of the rule.
+type box struct {
+	text  string
+	class string
+	href  string
+}
+
+func (b *box) size() (int, int, int) {
+	return textWidth(b.text) + 2*boxPad, boxHalf, boxHalf
+}
+
+func (b *box) draw(w *svgWriter, x, y int) {
+	width, _, _ := b.size()
+	if b.href != "" {
+		w.printf(`<a href="%s">`, html.EscapeString(b.href))
+	}
+	rx := 0
+	if b.class == "terminal" {
+		rx = boxHalf
+	}
+	w.printf(`<g class="%s"><rect x="%d" y="%d" width="%d" height="%d" rx="%d"/>`,
+		b.class, x, y-boxHalf, width, 2*boxHalf, rx)
+	w.printf(`<text x="%d" y="%d">%s</text></g>`, x+width/2, y+4, html.EscapeString(b.text))
+	if b.href != "" {
+		w.printf(`</a>`)
+	}
+	w.printf("\n")
+}
+
+// skip is an empty track.
+type skip struct{}
+
+func (skip) size() (int, int, int) { return 0, 0, 0 }
+
+func (skip) draw(w *svgWriter, x, y int) {}
+
+// sequence is a list of nodes matched one after the other.
+type sequence []node
+
+func (s sequence) size() (width, up, down int) {
+	for i, n := range s {
+		w, u, d := n.size()
+		if i > 0 {
+			width += gap
+		}
+		width += w
+		up, down = max(up, u), max(down, d)
+	}
+	return width, up, down
+}
+
+func (s sequence) draw(w *svgWriter, x, y int) {
+	for i, n := range s {
+		if i > 0 {
+			w.hline(x, y, gap)
+			x += gap
+		}
+		n.draw(w, x, y)
+		width, _, _ := n.size()
+		x += width
+	}
+}
+
+// choice is a list of alternatives, stacked from the first one, on the
+// track, to the last one.
+type choice []node
+
+// offsets returns the distance between the track and the baseline of each
+// alternative.
+func (c choice) offsets() []int {
+	offsets := make([]int, len(c))
+	_, _, prevDown := c[0].size()
+	for i := 1; i < len(c); i++ {
+		_, up, down := c[i].size()
+		offsets[i] = offsets[i-1] + max(prevDown+vgap+up, 2*arc)
+		prevDown = down
+	}
+	return offsets
+}
+
+func (c choice) size() (width, up, down int) {
+	for _, n := range c {
+		w, _, _ := n.size()
+		width = max(width, w)
+	}
+	_, up, down = c[0].size()
+	if len(c) > 1 {
+		_, _, last := c[len(c)-1].size()
+		down = c.offsets()[len(c)-1] + last
+	}
+	return width + 4*arc, up, down
+}
+
+func (c choice) draw(w *svgWriter, x, y int) {
+	width, _, _ := c.size()
+	inner := width - 4*arc
+	for i, n := range c {
+		off := c.offsets()[i]
+		if i == 0 {
+			w.hline(x, y, 2*arc)
+		} else {
+			w.printf(`<path d="M%d %d a%d %d 0 0 1 %d %d v%d a%d %d 0 0 0 %d %d"/>`+"\n",
+				x, y, arc, arc, arc, arc, off-2*arc, arc, arc, arc, arc)
+			w.printf(`<path d="M%d %d a%d %d 0 0 0 %d %d v%d a%d %d 0 0 1 %d %d"/>`+"\n",
+				x+width-2*arc, y+off, arc, arc, arc, -arc, -(off - 2*arc), arc, arc, arc, -arc)
+		}
+		drawPadded(w, n, x+2*arc, y+off, inner)
+		if i == 0 {
+			w.hline(x+width-2*arc, y, 2*arc)
+		}
+	}
+}
+
+// optional is a node that may be skipped, the skipping track goes above
+// it.
+type optional struct {
+	n node
+}
+
+func (o optional) rise() int {
+	_, up, _ := o.n.size()
+	return max(up+vgap, 2*arc)
+}
+
+func (o optional) size() (int, int, int) {
+	width, _, down := o.n.size()
+	return width + 4*arc, o.rise(), down
+}
+
+func (o optional) draw(w *svgWriter, x, y int) {
+	width, _, _ := o.size()
+	rise := o.rise()
+	w.hline(x, y, 2*arc)
+	o.n.draw(w, x+2*arc, y)
+	w.hline(x+width-2*arc, y, 2*arc)
+	w.printf(`<path d="M%d %d a%d %d 0 0 0 %d %d v%d a%d %d 0 0 1 %d %d h%d a%d %d 0 0 1 %d %d v%d a%d %d 0 0 0 %d %d"/>`+"\n",
+		x, y, arc, arc, arc, -arc, -(rise - 2*arc), arc, arc, arc, -arc,
+		width-4*arc, arc, arc, arc, arc, rise-2*arc, arc, arc, arc, arc)
+}
+
+// loop is a node that is repeated, the returning track goes below it,
+// through the separator if any, and shows the label if any.
+type loop struct {
+	n     node
+	sep   node
+	label string
+}
+
+func (l loop) drop() int {
+	_, _, down := l.n.size()
+	sepUp := 0
+	if l.sep != nil {
+		_, sepUp, _ = l.sep.size()
+	}
+	return max(down+vgap+sepUp, 2*arc)
+}
+
+func (l loop) size() (width, up, down int) {
+	width, up, _ = l.n.size()
+	down = l.drop()
+	if l.sep != nil {
+		sepWidth, _, sepDown := l.sep.size()
+		width = max(width, sepWidth)
+		down += sepDown
+	}
+	if l.label != "" {
+		width = max(width, textWidth(l.label))
+		down += labelLine
+	}
+	return width + 2*arc, up, down
+}
+
+func (l loop) draw(w *svgWriter, x, y int) {
+	width, _, down := l.size()
+	drop := l.drop()
+	inner := width - 2*arc
+	drawPadded(w, l.n, x+arc, y, inner)
+	w.hline(x, y, arc)
+	w.hline(x+width-arc, y, arc)
+
+	w.printf(`<path d="M%d %d a%d %d 0 0 1 %d %d v%d a%d %d 0 0 1 %d %d"/>`+"\n",
+		x+width-arc, y, arc, arc, arc, arc, drop-2*arc, arc, arc, -arc, arc)
+	w.printf(`<path d="M%d %d a%d %d 0 0 1 %d %d v%d a%d %d 0 0 1 %d %d"/>`+"\n",
+		x+arc, y+drop, arc, arc, -arc, -arc, -(drop - 2*arc), arc, arc, arc, -arc)
+	var sep node = skip{}
+	if l.sep != nil {
+		sep = l.sep
+	}
+	drawPadded(w, sep, x+arc, y+drop, inner)
+	if l.label != "" {
+		w.printf(`<text class="label" x="%d" y="%d">%s</text>`+"\n",
+			x+width/2, y+down-3, html.EscapeString(l.label))
+	}
+}
+
+// group is a node in a dashed frame, with a label above it.
+type group struct {
+	n     node
+	label string
+}
+
+func (g group) size() (int, int, int) {
+	width, up, down := g.n.size()
+	width = max(width, textWidth(g.label))
+	return width + 2*gap, up + vgap + labelLine, down + vgap
+}
+
+func (g group) draw(w *svgWriter, x, y int) {
+	width, up, down := g.size()
+	w.printf(`<rect class="group" x="%d" y="%d" width="%d" height="%d" rx="%d"/>`+"\n",
+		x, y-up+labelLine, width, up+down-labelLine, arc/2)
+	w.printf(`<text class="label" x="%d" y="%d">%s</text>`+"\n",
+		x+width/2, y-up+labelLine-4, html.EscapeString(g.label))
+	drawPadded(w, g.n, x, y, width)
+}
+
+// drawPadded draws n at the center of a track of the given width.
+func drawPadded(w *svgWriter, n node, x, y, width int) {
+	nw, _, _ := n.size()
+	left := (width - nw) / 2
+	w.hline(x, y, left)
+	n.draw(w, x+left, y)
+	w.hline(x+left+nw, y, width-left-nw)
+}
+
+// svgWriter writes the elements of a diagram.
+type svgWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *svgWriter) printf(format string, args ...any) {
+	fmt.Fprintf(&w.buf, format, args...)
+}
+
+// hline draws a horizontal track of the given length.
+func (w *svgWriter) hline(x, y, length int) {
+	if length > 0 {
+		w.printf(`<path d="M%d %d h%d"/>`+"\n", x, y, length)
+	}
+}
+
+const svgStyle = `<style>
+path { fill: none; stroke: #333; stroke-width: 2; }
+rect { fill: #f4f4f4; stroke: #333; stroke-width: 2; }
+.terminal rect { fill: #fff7d6; }
+.nonterminal rect { fill: #dde9ff; }
+.special rect { fill: #eee; stroke-dasharray: 4 2; }
+rect.group { fill: none; stroke: #999; stroke-width: 1; stroke-dasharray: 4 3; }
+text { font: 14px monospace; text-anchor: middle; }
+text.label { font: 12px sans-serif; fill: #555; }
+a text { fill: #0645ad; text-decoration: underline; }
+</style>
+`
+
+// render returns the SVG document of the diagram of n, with the markers of
+// the start and the end of the rule.
+func render(n node) []byte {
+	width, up, down := n.size()
+	w := &svgWriter{}
+	fullWidth := width + 2*margin + 2*gap
+	height := up + down + 2*margin
+	w.printf(`<svg xmlns="http://www.w3.org/2000/svg" class="railroad" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		fullWidth, height, fullWidth, height)
+	w.buf.WriteString(svgStyle)
+
+	x, y := margin, margin+up
+	w.printf(`<path d="M%d %d v%d m%d 0 v%d"/>`+"\n", x, y-boxHalf/2, boxHalf, boxHalf/2, -boxHalf)
+	w.hline(x, y, gap)
+	n.draw(w, x+gap, y)
+	x += gap + width
+	w.hline(x, y, gap)
+	x += gap
+	w.printf(`<path d="M%d %d v%d m%d 0 v%d"/>`+"\n", x, y-boxHalf/2, boxHalf, -boxHalf/2, -boxHalf)
+	w.printf("</svg>\n")
+	return w.buf.Bytes()
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
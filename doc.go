@@ -40,6 +40,31 @@ The following options can be specified:
 	warnings by gometalinter (https://github.com/alecthomas/gometalinter) or
 	golangci-lint (https://golangci-lint.run/).
 
+	-ascii-fold : boolean, if set, case-insensitive literals and character
+	classes only fold the case of ASCII letters, which is faster, instead of
+	using the Unicode simple case folding (default: false).
+
+	-fuzz-test : boolean, if set, a fuzz test of the generated parser is
+	written alongside the output file, e.g. parser_fuzz_test.go for
+	parser.go, which requires -o. Its FuzzParse function runs the parser on
+	the inputs generated by "go test -fuzz FuzzParse" and fails if it panics,
+	if it parses more than a million expressions, which catches the infinite
+	loops and the exponential parsing times, or if the position of an error
+	is out of the input. Its corpus is seeded with testdata/fuzz/FuzzParse,
+	see the gen-samples command (default: false).
+
+	-fuzz-corpus=DIR : string, directory of the inputs, one per file, that
+	also seed the corpus of the fuzz test, relative to the directory of the
+	output file (default: none).
+
+	-line-directives : boolean, if set, the body of each code block except the
+	initializer is preceded by a //line directive to its position in the
+	grammar and followed by a //line directive back to the generated file,
+	so that the compiler, go vet, the panics and the debuggers refer to the
+	grammar. The grammar is referred to relative to the directory of the
+	output file, which is assumed to be named after the grammar if the parser
+	is written to stdout (default: false).
+
 	-no-recover : boolean, if set, do not recover from a panic. Useful
 	to access the panic stack when debugging, otherwise the panic
 	is converted to an error (default: false).
@@ -47,6 +72,18 @@ The following options can be specified:
 	-o=FILE : string, output file where the generated parser will be
 	written (default: stdout).
 
+	-optimize-grammar : boolean, if set, the grammar is optimized before the
+	parser is generated: the rules that reference no other rule or that are
+	referenced only once are inlined, the nested choices and sequences are
+	flattened, the character classes and the literals are combined and the
+	rules that cannot be reached from the entrypoints are removed. Inlined
+	rules are no longer memoized nor reported in the debug output on their
+	own (EXPERIMENTAL FEATURE, default: false).
+
+	-dump-optimized : boolean, if set, the optimized grammar is printed
+	instead of the generated parser, implies -optimize-grammar (default:
+	false).
+
 	-optimize-parser : boolean, if set, the options Debug, Memoize and Statistics are
 	removed	from the resulting parser. The global "state" is optimized as well by
 	either removing all related code if no state change expression is present in the
@@ -54,12 +91,22 @@ The following options can be specified:
 	and predicate code blocks. This saves a few cpu cycles, when using the generated
 	parser (default: false).
 
+	-typecheck : boolean, if set, the generated parser is type-checked with
+	the other Go files of its package in the directory of the output file,
+	or alone if it is written to stdout, and the errors of the code blocks
+	are reported at their position in the grammar rather than in the
+	generated file. The imported packages are type-checked from source
+	(default: false). The syntax errors of the code blocks that prevent
+	the formatting of the generated parser are always reported in the
+	grammar.
+
 	-x : boolean, if set, do not build the parser, just parse the input grammar
-	(default: false).
+	and check that every referenced rule is defined (default: false).
 
 	-receiver-name=NAME : string, name of the receiver variable for the generated
 	code blocks. Non-initializer code blocks in the grammar end up as methods on the
-	*current type, and this option sets the name of the receiver (default: c).
+	*current type, and this option sets the name of the receiver (default: c),
+	by which the code blocks refer to it.
 
 	-alternate-entrypoints=RULE[,RULE...] : string, comma-separated list of rule names
 	that may be used as alternate entrypoints for the parser, in addition to the
@@ -69,6 +116,10 @@ The following options can be specified:
 	necessary if the -optimize-parser flag is set, as some rules may be optimized
 	out of the resulting parser.
 
+Most of those options can also be set in the grammar itself, see the
+section "Options" below. Options set on the command line take precedence
+over the ones set in the grammar.
+
 If the code blocks in the grammar (see below, section "Code block") are golint-
 and go vet-compliant, then the resulting generated code will also be golint-
 and go vet-compliant.
@@ -76,6 +127,115 @@ and go vet-compliant.
 The generated code doesn't use any third-party dependency unless code blocks
 in the grammar require such a dependency.
 
+Commands
+
+Besides generating a parser, the pigeon tool provides commands that work
+on a grammar. A command is called with its name as first argument, followed
+by its own options:
+
+	pigeon lint [options] [GRAMMAR_FILE]
+
+The lint command reports the likely mistakes of the grammar, one per line
+as FILE:LINE:COL: SEVERITY: MESSAGE (CHECK). The checks are:
+
+	duplicate-rule : a rule is defined more than once (error).
+
+	unused-rule : a rule cannot be reached from the first rule, the alternate
+	entrypoints or the rules annotated with @entry, @trivia or @word (warning).
+
+	unused-label : a label is used neither by a code block of its rule nor
+	by a back-reference (warning).
+
+	single-use-rule : a rule is only referenced once and could be annotated
+	with @inline (info).
+
+	useless-display-name : a rule has a display name, but never fails, so the
+	display name never appears in an error message (warning).
+
+	unrecovered-throw : a label is thrown, but no recovery expression of the
+	grammar handles it (warning).
+
+	shadowed-alternative : an alternative of a choice can never match,
+	because an earlier alternative always matches, or because an earlier
+	alternative only matches a literal that is a prefix of the literal the
+	alternative starts with, e.g. the second alternative of "<" / "<=". The
+	literals are also found through references to rules (warning).
+
+The -json option prints the diagnostics as a JSON array instead, and the
+-alternate-entrypoints option adds roots to the unused-rule check. The exit
+code is 1 if an error or a warning is reported.
+
+The shadowed-alternative check also runs when a parser is generated, its
+warnings are printed to stderr and do not prevent the generation.
+
+	pigeon fmt [-d] [-w] [GRAMMAR_FILE...]
+
+The fmt command prints the grammars in the canonical format: the rules are
+defined with the ← operator, the tokens are separated by a single space and
+the continuation lines are indented by four spaces per level of nesting.
+The comments and the code blocks are kept verbatim, as are the line breaks
+between the alternatives of a choice and between the elements of a
+sequence. The -w option writes the result back to the grammar files instead
+of stdout, the -d option prints the differences as a unified diff.
+
+	pigeon diagram [-format html|svg|dot] [-rule RULE] [-o OUTPUT_FILE] [GRAMMAR_FILE]
+
+The diagram command renders the grammar for its documentation. The html
+format, the default, is a self-contained HTML page with the SVG railroad
+diagram of each rule, where the references to a rule link to its diagram
+and each rule lists the rules that reference it. The svg format is the
+railroad diagram of the rule set by -rule, the first rule by default. The
+dot format is the Graphviz DOT graph of the dependencies between the rules.
+
+	pigeon ebnf [-style iso|w3c] [-markdown] [-o OUTPUT_FILE] [GRAMMAR_FILE]
+
+The ebnf command exports the grammar as EBNF, in the notation of ISO/IEC
+14977 by default or in the notation of the W3C specifications, so that the
+reference documentation of a language can be generated from its grammar.
+The actions, the code predicates, the labels and the error recovery are
+stripped, the display names and the comments that precede the rules are
+kept. The lookahead is a special sequence in the ISO notation and a comment
+in the W3C notation. The -markdown option writes a Markdown document with a
+section per rule instead.
+
+	pigeon analyze [-json] [-alternate-entrypoints RULE[,RULE...]] [GRAMMAR_FILE]
+
+The analyze command prints, for each rule, whether it may match the empty
+input, its FIRST set, the literals and the runes a match may start with,
+and its FOLLOW set, the literals and the runes that may follow a match,
+the end of the input following the entrypoints. The rules that are
+left-recursive are listed with the other rules of their cycles. This
+helps ordering the alternatives of the choices and placing the recovery
+expressions. The -json option prints the analysis as JSON.
+
+	pigeon lsp
+
+The lsp command runs a language server for the grammar files, that speaks
+the Language Server Protocol on stdin and stdout. It publishes the parse
+errors, the errors of the parser generation and the lint diagnostics of
+the open grammars, and supports go to definition, find references and
+rename of the rules, hover, showing the display name, the nullability and
+the comment of a rule, document symbols and formatting.
+
+	pigeon run [-entrypoint RULE] [-cache] [-json] GRAMMAR_FILE [INPUT_FILE]
+
+The run command runs the grammar on an input, stdin by default, without
+generating and building its parser, to try out a change of the grammar. The
+code blocks are not run: the actions have no value and the code predicates
+always succeed. It prints whether the input matches, the farthest failure
+with the expected terminals and the parse tree, the span of each match of a
+rule. The exit code is 1 if the input does not match.
+
+	pigeon gen-samples [-n N] [-near-misses N] [-seed N] [-corpus DIR] [GRAMMAR_FILE]
+
+The gen-samples command generates random inputs from the grammar: samples
+that it matches entirely and near misses, mutations of the samples that it
+rejects. The samples are generated by walking the grammar with a bounded
+depth, and the candidates rejected by the grammar, run as by the run
+command, are discarded, which handles the lookahead expressions. The
+-corpus option writes them as the corpus files of a fuzz test, e.g. in
+testdata/fuzz/FuzzParse, to seed it.
+
 PEG syntax
 
 The accepted syntax for the grammar is formally defined in the
@@ -98,6 +258,29 @@ by the \n character (U+000A). Space (U+0020), horizontal tabs (U+0009) and
 carriage returns (U+000D) are considered whitespace and are ignored except
 to separate tokens.
 
+Options
+
+The rules of the grammar may be preceded by an options block, after the
+optional initializer code block. The options block sets the command-line
+options that apply to the grammar, so that the generated parser does not
+depend on the flags used to call pigeon. Each option is set by its name,
+the same as the command-line flag, followed by "=" and its value, which
+is either a string literal or an identifier. E.g.:
+	@options {
+		receiver-name = p
+		grammar-name = "exprGrammar"
+		nolint = true
+		alternate-entrypoints = Expr, Stmt
+	}
+
+The supported options are alternate-entrypoints (which accepts a
+comma-separated list of values), ascii-fold, fuzz-corpus, fuzz-test,
+grammar-name, grammar-only, line-directives, nolint, optimize-grammar,
+optimize-parser, optimize-ref-expr-by-index, receiver-name, run-func-prefix
+and typecheck. An unknown option or an invalid value is an error. The
+options are applied when the parser is built, so they also apply to the
+parsers built with the builder package.
+
 Rules
 
 A PEG grammar consists of a set of rules. A rule is an identifier followed
@@ -109,6 +292,49 @@ be specified after the rule identifier. E.g.:
 The rule definition operator can be any one of those:
 	=, <-, ← (U+2190), ⟵ (U+27F5)
 
+A rule may be preceded by one or more annotations, an identifier prefixed
+with "@", that change how the rule is generated. An unknown annotation is
+an error.
+
+Token rules
+
+Instead of referencing a whitespace rule between every token of the
+grammar, rules can be split between lexical rules, annotated with @token,
+and syntactic rules, which are not annotated. A single rule annotated with
+@trivia defines what is skipped before each token, typically whitespace and
+comments. The generated parser matches the @trivia rule before every
+reference to a @token rule from a syntactic rule, so that the position and
+text of the current match in the code blocks of a token rule never include
+the leading trivia. The value of the @trivia rule is discarded. E.g.:
+	Sum = Number ( Plus Number )*
+	@token Number = [0-9]+ { return strconv.Atoi(string(c.text)) }
+	@token Plus = '+'
+	@trivia _ = ( [ \t\r\n] / Comment )*
+
+The references inside @token and @trivia rules are left untouched. Without
+a @trivia rule, the @token annotation has no effect.
+
+Other rule annotations
+
+The following annotations are also available, @word is described with the
+keyword literals:
+	- @inline: every reference to the rule is replaced by a copy of its
+	expression. The rule cannot have labels nor a display name, and cannot
+	reference itself through other @inline rules. It is still generated,
+	so that it can be used as an entrypoint.
+	- @memo: the results of the rule are memoized, so that it is matched
+	only once at a given position. The code blocks of the rule must not
+	rely on the state changes of the code blocks tried before.
+	- @nomemo: the results of the rule are never memoized, even if the
+	memoize option of the generated parser is set.
+	- @notrace: the rule, and the rules it references, are excluded from
+	the debug output of the generated parser.
+	- @entry: the rule is an alternate entrypoint, as if it was listed in
+	the -alternate-entrypoints option.
+
+A rule cannot have the same annotation twice, @memo and @nomemo together,
+nor @inline with @memo or @notrace.
+
 Expressions
 
 A rule is defined by an expression. The following sections describe the
@@ -180,6 +406,22 @@ is used. E.g.:
 	}
 	RuleB = label:RuleA { // label is int }
 
+If the expression that follows the colon is enclosed in angle brackets,
+the label captures the matched text instead and the variable is a string.
+E.g.:
+	Rule = label:<[a-z]+> { // label is string }
+
+Back-references
+
+A dollar sign "$" followed by the name of a text capture label matches
+the exact text captured by that label, which must be defined in the same
+rule. It does not match if the label did not match yet. The captured text
+is available to back-references inside predicates, and each invocation of
+the rule has its own captures, so that nested matches do not interfere.
+E.g.:
+	Element = '<' name:<Ident> '>' Content* "</" $name '>'
+	Heredoc = "<<" tag:<Ident> '\n' ( !( '\n' $tag ) . )* '\n' $tag
+
 And and not expressions
 
 An expression prefixed with the ampersand "&" is the "and" predicate
@@ -210,6 +452,62 @@ occurs zero or more times ("*"), zero or one time "?" or one or more times
 possible. E.g.
 	ZeroOrMoreAs = "A"*
 
+An expression immediately followed by a bounded repetition in curly braces
+is a match if the expression occurs a specific number of times: "{n}" means
+exactly n times, "{n,}" means at least n times and "{n,m}" means at least n
+and at most m times. The match is greedy and n must not be greater than m.
+The value of the match is a slice of the values of each matched occurrence.
+E.g.
+	Year = [0-9]{4}
+	Octet = [0-9]{1,3}
+
+Separated lists
+
+An expression followed by "++" or "**" and a separator expression matches
+a list of the expression separated by the separator, one or more times
+("++") or zero or more times ("**"). The operator may be followed by "?"
+to allow an optional trailing separator after the last element. Both
+operands are primary expressions, use parentheses for anything more
+complex. The value of the match is a flat slice of the values of the
+matched elements, the values of the separators are discarded. E.g.
+	Args = Arg ** ( _ ',' _ )
+	Array = '[' _ Value ++? ( _ ',' _ ) _ ']'
+
+A repetition without upper bound ("*", "+", "{n,}" or a separated list)
+over an expression that can match without consuming any input, such as
+( "a"? )*, would never end, so pigeon refuses to build such a grammar and
+reports the position of the repeated expression. For a separated list, this
+is the case only if both the expression and the separator can match the
+empty input. As a safeguard, the generated parser also stops a repetition
+as soon as an occurrence matches without consuming any input.
+
+Operator precedence
+
+The "%precedence" expression matches operands combined with operators
+using precedence climbing, without having to write one rule per level of
+precedence. It is followed by the primary expression that matches an
+operand and by the levels of operators inside curly braces, from the
+lowest to the highest precedence. Each level starts with the kind of its
+operators, "left" or "right" for infix operators with that associativity,
+"prefix" or "postfix", followed by its operators separated by "/". E.g.
+	Expr = %precedence Number {
+		left '+' { return l.(int) + r.(int) } / '-' { return l.(int) - r.(int) }
+		left '*' { return l.(int) * r.(int) }
+		prefix '-' { return -x.(int) }
+		right '^' { return pow(l.(int), r.(int)) }
+		postfix '!' { return fact(x.(int)) }
+	}
+
+An operator is a primary expression, optionally followed by a code block
+that returns the value of the operator applied to its operands. The code
+block has access to the operands "l" and "r" for infix operators or "x"
+for prefix and postfix operators, and to the matched text of the
+operator in "op". The c.text of the code block is the text of the whole
+operation. An operator without code block has the value []any{l, op, r},
+[]any{op, x} or []any{x, op} depending on its kind. The operand of a
+prefix operator is parsed with the precedence of the operator, so that
+in the example above "-2^2" is -4.
+
 Literal matcher
 
 A literal matcher tries to match the input against a single character or a
@@ -221,6 +519,25 @@ The literal may be followed by a lowercase "i" (outside the ending quote)
 to indicate that the match is case-insensitive. E.g.:
 	LiteralMatch = "Awesome\n"i // matches "awesome" followed by a newline
 
+Case-insensitive matching uses the Unicode simple case folding, where
+all the characters of a case folding orbit (as returned by repeated calls
+to unicode.SimpleFold) match each other, e.g. "k"i matches "k", "K" and
+the Kelvin sign "\u212A", and "ß"i matches "ẞ". Locale-specific mappings
+such as the Turkish dotted and dotless i are not applied, "i"i does not
+match "İ". If the -ascii-fold option is set, only the case of ASCII
+letters is folded.
+
+The literal may also be followed by a lowercase "k", after the "i" if
+both are present, to indicate a keyword. A keyword only matches if it is
+not followed by a character matched by the rule annotated with @word,
+which must then be defined exactly once in the grammar. E.g.:
+	If = "if"k Cond "then"k Stmt // does not match "iffy" nor "thenceforth"
+	@word IdentPart = [\pL\pNd_]
+
+The @word rule is matched without recording its failures, so a keyword
+followed by an identifier character reports the keyword itself as
+expected instead of the negative lookahead.
+
 Character class matcher
 
 A character class matcher tries to match the input against a class of characters
@@ -241,6 +558,15 @@ is inverted (it is a match if the input does not match the character class
 matcher). E.g.:
 	NotAZ = [^a-z]i
 
+The characters of a class may be followed by set operators, "--" for the
+subtraction and "&&" for the intersection, each followed by either a
+nested character class or Unicode classes. Operators are applied from
+left to right, the nested class may be inverted and may use operators
+itself. The resulting set is computed when the parser is generated. E.g.:
+	Consonant = [a-z--[aeiou]]i
+	UpperGreek = [\p{Greek}&&\p{Lu}]
+	NotDigitNorUnderscore = [\pL\pN--[0-9_]]
+
 Any matcher
 
 The any matcher is represented by the dot ".". It matches any character
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/ebnf"
+)
+
+// ebnfMain implements the ebnf command, it exports the grammar as EBNF.
+func ebnfMain(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" ebnf", flag.ExitOnError)
+
+	var (
+		markdownFlag  = fs.Bool("markdown", false, "write a Markdown document with a section per rule")
+		shortHelpFlag = fs.Bool("h", false, "show help page")
+		longHelpFlag  = fs.Bool("help", false, "show help page")
+		outputFlag    = fs.String("o", "", "output file, defaults to stdout")
+		styleFlag     = fs.String("style", "iso", "EBNF notation: iso or w3c")
+	)
+
+	usage := func() {
+		fmt.Printf(ebnfUsagePage, os.Args[0])
+	}
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, "args parse error:\n", err)
+		exit(6)
+	}
+
+	if *shortHelpFlag || *longHelpFlag {
+		usage()
+		exit(0)
+	}
+
+	if fs.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "expected one argument, got %q\n", strings.Join(fs.Args(), " "))
+		usage()
+		exit(1)
+	}
+
+	var style ebnf.Style
+	switch *styleFlag {
+	case "iso":
+		style = ebnf.ISO
+	case "w3c":
+		style = ebnf.W3C
+	default:
+		fmt.Fprintf(os.Stderr, "unknown style %q\n", *styleFlag)
+		usage()
+		exit(1)
+	}
+
+	nm, rc := input(fs.Arg(0))
+	src, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "read error:\n", err)
+		exit(2)
+	}
+	g, err := Parse(nm, src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse error(s):\n", err)
+		exit(3)
+	}
+	grammar := g.(*ast.Grammar)
+
+	var b []byte
+	if *markdownFlag {
+		b = ebnf.Markdown(grammar, src, style, filepath.Base(nm))
+	} else {
+		b = ebnf.Export(grammar, src, style)
+	}
+
+	out := output(*outputFlag)
+	if _, err := out.Write(b); err != nil {
+		fmt.Fprintln(os.Stderr, "write error:\n", err)
+		exit(7)
+	}
+	if err := out.Close(); err != nil {
+		fmt.Fprintln(os.Stderr, "close file error:\n", err)
+		exit(8)
+	}
+}
+
+var ebnfUsagePage = `usage: %s ebnf [options] [GRAMMAR_FILE]
+
+Ebnf exports a PEG grammar as EBNF, for the reference documentation of
+the language it parses. The actions, the code predicates, the labels and
+the error recovery are stripped, the display names and the comments that
+precede the rules are kept. The grammar is read from stdin if GRAMMAR_FILE
+is not specified. The styles are:
+
+	iso
+		the notation of ISO/IEC 14977, "rule = a, { b } | c ;". The
+		lookahead and the character classes are special sequences.
+	w3c
+		the notation of the W3C specifications, "rule ::= a b* | c".
+		The lookahead is a comment.
+
+	-h -help
+		display this help message.
+	-markdown
+		write a Markdown document with a section per rule, holding its
+		comment and its EBNF.
+	-o OUTPUT_FILE
+		write the output to OUTPUT_FILE. Defaults to stdout.
+	-style STYLE
+		EBNF notation, iso or w3c, defaults to iso.
+`
@@ -0,0 +1,682 @@
+// Package ebnf exports a PEG grammar as EBNF, for the reference
+// documentation of the language it parses.
+//
+// The actions, the code predicates, the labels and the error recovery are
+// stripped, as they do not change the language. The display names and the
+// comments that precede the rules are kept as comments. The PEG operators
+// that have no EBNF equivalent, such as the lookahead, are written as
+// special sequences in the ISO style and as comments in the W3C style.
+package ebnf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// Style is a notation of EBNF.
+type Style int
+
+const (
+	// ISO is the notation of the ISO/IEC 14977 standard: "rule = a, b | c ;".
+	ISO Style = iota
+	// W3C is the notation of the W3C specifications, e.g. XML:
+	// "rule ::= a b | c".
+	W3C
+)
+
+// String returns the name of the style.
+func (s Style) String() string {
+	if s == W3C {
+		return "w3c"
+	}
+	return "iso"
+}
+
+// maxWidth is the width beyond which the alternatives of a rule are
+// written on their own line.
+const maxWidth = 80
+
+// Levels of the EBNF productions, an expression printed where a tighter
+// level is expected is parenthesized.
+const (
+	lvlChoice = iota
+	lvlSeq
+	lvlPrimary
+)
+
+// Export returns the EBNF of the grammar in the given style. The src
+// argument is the source text the grammar was parsed from, it is used to
+// keep the comments that precede the rules. It may be nil.
+func Export(grammar *ast.Grammar, src []byte, style Style) []byte {
+	e := newExporter(grammar, style)
+	var buf bytes.Buffer
+	for i, rule := range grammar.Rules {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		if lines := RuleComment(rule, src); len(lines) > 0 {
+			buf.WriteString(e.comment(strings.Join(lines, "\n")))
+			buf.WriteString("\n")
+		}
+		buf.WriteString(e.rule(rule))
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+// Markdown returns a Markdown reference document of the grammar, with the
+// given title and a section per rule, holding the comment of the rule and
+// its EBNF in the given style.
+func Markdown(grammar *ast.Grammar, src []byte, style Style, title string) []byte {
+	e := newExporter(grammar, style)
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# %s\n", title)
+	for _, rule := range grammar.Rules {
+		fmt.Fprintf(&buf, "\n## %s\n\n", rule.Name.Val)
+		if rule.DisplayName != nil {
+			fmt.Fprintf(&buf, "*%s*\n\n", displayName(rule.DisplayName.Val))
+		}
+		if lines := RuleComment(rule, src); len(lines) > 0 {
+			buf.WriteString(strings.Join(lines, "\n"))
+			buf.WriteString("\n\n")
+		}
+		fmt.Fprintf(&buf, "```ebnf\n%s\n```\n", e.rule(rule))
+	}
+	return buf.Bytes()
+}
+
+// RuleComment returns the lines of the comments that immediately precede
+// the rule in src, without the comment markers, or nil.
+func RuleComment(rule *ast.Rule, src []byte) []string {
+	off := rule.Pos().Off
+	if src == nil || off > len(src) {
+		return nil
+	}
+	lines := strings.Split(string(src[:off]), "\n")
+	// the last line is the indentation of the rule
+	if strings.TrimSpace(lines[len(lines)-1]) != "" {
+		return nil
+	}
+	lines = lines[:len(lines)-1]
+
+	var comment []string
+	for len(lines) > 0 {
+		line := strings.TrimSpace(lines[len(lines)-1])
+		switch {
+		case strings.HasPrefix(line, "//"):
+			comment = append([]string{strings.TrimSpace(line[2:])}, comment...)
+			lines = lines[:len(lines)-1]
+			continue
+
+		case strings.HasPrefix(line, "/*") && strings.HasSuffix(line, "*/") && len(line) >= 4:
+			comment = append([]string{strings.TrimSpace(line[2 : len(line)-2])}, comment...)
+			lines = lines[:len(lines)-1]
+			continue
+
+		case strings.HasSuffix(line, "*/"):
+			// multiline block comment, up to the line that opens it
+			block := []string{strings.TrimSpace(strings.TrimSuffix(line, "*/"))}
+			i := len(lines) - 2
+			for ; i >= 0; i-- {
+				l := strings.TrimSpace(lines[i])
+				if strings.HasPrefix(l, "/*") {
+					block = append([]string{strings.TrimSpace(l[2:])}, block...)
+					break
+				}
+				if strings.Contains(l, "/*") {
+					return trimComment(comment)
+				}
+				block = append([]string{strings.TrimPrefix(strings.TrimPrefix(l, "*"), " ")}, block...)
+			}
+			if i < 0 {
+				return trimComment(comment)
+			}
+			comment = append(block, comment...)
+			lines = lines[:i]
+			continue
+		}
+		break
+	}
+	return trimComment(comment)
+}
+
+// trimComment removes the empty lines at the start and the end of the
+// comment.
+func trimComment(lines []string) []string {
+	for len(lines) > 0 && lines[0] == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return lines
+}
+
+// displayName returns the display name without its quotes, it is raw in
+// a parsed grammar.
+func displayName(s string) string {
+	if v, err := strconv.Unquote(s); err == nil {
+		return v
+	}
+	return s
+}
+
+type exporter struct {
+	style Style
+	rules map[string]*ast.Rule
+}
+
+func newExporter(grammar *ast.Grammar, style Style) *exporter {
+	e := &exporter{style: style, rules: make(map[string]*ast.Rule, len(grammar.Rules))}
+	for _, rule := range grammar.Rules {
+		e.rules[rule.Name.Val] = rule
+	}
+	return e
+}
+
+func (e *exporter) comment(text string) string {
+	if e.style == W3C {
+		return "/* " + strings.ReplaceAll(text, "*/", "* /") + " */"
+	}
+	return "(* " + strings.ReplaceAll(text, "*)", "* )") + " *)"
+}
+
+// special returns the description of an expression that has no EBNF
+// equivalent.
+func (e *exporter) special(text string) string {
+	if e.style == W3C {
+		return e.comment(text)
+	}
+	return "? " + text + " ?"
+}
+
+func (e *exporter) rule(rule *ast.Rule) string {
+	name := rule.Name.Val
+	if rule.DisplayName != nil {
+		name += " " + e.comment(displayName(rule.DisplayName.Val))
+	}
+	def, end := " = ", " ;"
+	if e.style == W3C {
+		def, end = " ::= ", ""
+	}
+
+	text := e.expr(rule.Expr, lvlChoice)
+	choice, ok := unwrap(rule.Expr).(*ast.ChoiceExpr)
+	if !ok || len(name)+len(def)+len(text)+len(end) <= maxWidth {
+		return name + def + text + end
+	}
+
+	// one alternative per line, aligned on the definition operator
+	alts := choice.Alternatives
+	var buf strings.Builder
+	indent := strings.Repeat(" ", len(name)+len(def)-2)
+	for i, alt := range alts {
+		if i == 0 {
+			buf.WriteString(name + def)
+		} else {
+			buf.WriteString("\n" + indent + "| ")
+		}
+		buf.WriteString(e.expr(alt, lvlSeq))
+	}
+	if e.style == ISO {
+		buf.WriteString("\n" + indent + ";")
+	}
+	return buf.String()
+}
+
+// unwrap returns the expression matched by expr, without its label or its
+// action.
+func unwrap(expr ast.Expression) ast.Expression {
+	for {
+		switch e := expr.(type) {
+		case *ast.ActionExpr:
+			expr = e.Expr
+		case *ast.LabeledExpr:
+			expr = e.Expr
+		case *ast.RecoveryExpr:
+			expr = e.Expr
+		case *ast.TriviaExpr:
+			expr = e.Expr
+		default:
+			return expr
+		}
+	}
+}
+
+// wrap returns the text of an expression of level lvl where an expression
+// of level ctx is expected.
+func (e *exporter) wrap(text string, lvl, ctx int) string {
+	if lvl < ctx {
+		return "( " + text + " )"
+	}
+	return text
+}
+
+// stripped returns true if the expression is removed from the EBNF, it
+// matches no input.
+func stripped(expr ast.Expression) bool {
+	switch expr := unwrap(expr).(type) {
+	case *ast.CodeExpr, *ast.AndCodeExpr, *ast.NotCodeExpr:
+		return true
+	case *ast.SeqExpr:
+		for _, e := range expr.Exprs {
+			if !stripped(e) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (e *exporter) expr(expr ast.Expression, ctx int) string {
+	switch expr := unwrap(expr).(type) {
+	case *ast.ChoiceExpr:
+		alts := make([]string, len(expr.Alternatives))
+		for i, alt := range expr.Alternatives {
+			alts[i] = e.expr(alt, lvlSeq)
+		}
+		return e.wrap(strings.Join(alts, " | "), lvlChoice, ctx)
+
+	case *ast.SeqExpr:
+		return e.seq(expr.Exprs, ctx)
+
+	case *ast.CodeExpr, *ast.AndCodeExpr, *ast.NotCodeExpr:
+		return e.empty()
+
+	case *ast.ThrowExpr:
+		return e.special("error " + expr.Label)
+
+	case *ast.AndExpr:
+		return e.special("followed by " + e.expr(expr.Expr, lvlChoice))
+
+	case *ast.NotExpr:
+		if _, ok := unwrap(expr.Expr).(*ast.AnyMatcher); ok {
+			return e.special("end of input")
+		}
+		return e.special("not followed by " + e.expr(expr.Expr, lvlChoice))
+
+	case *ast.ZeroOrOneExpr:
+		return e.optional(e.expr(expr.Expr, e.operandLevel()))
+
+	case *ast.ZeroOrMoreExpr:
+		return e.many(e.expr(expr.Expr, e.operandLevel()))
+
+	case *ast.OneOrMoreExpr:
+		if e.style == W3C {
+			return e.expr(expr.Expr, lvlPrimary) + "+"
+		}
+		x := e.expr(expr.Expr, lvlSeq)
+		return e.wrap(x+", "+e.many(e.expr(expr.Expr, lvlChoice)), lvlSeq, ctx)
+
+	case *ast.RepeatExpr:
+		return e.repeat(expr, ctx)
+
+	case *ast.SeparatedExpr:
+		x, sep := e.expr(expr.Expr, lvlSeq), e.expr(expr.Sep, lvlSeq)
+		text := e.concat(x, e.many(e.wrap(e.concat(sep, x), lvlSeq, e.operandLevel())))
+		if expr.AllowTrailing {
+			text = e.concat(text, e.optional(e.expr(expr.Sep, e.operandLevel())))
+		}
+		if expr.Min == 0 {
+			return e.optional(e.wrap(text, lvlSeq, e.operandLevel()))
+		}
+		return e.wrap(text, lvlSeq, ctx)
+
+	case *ast.PrecedenceExpr:
+		return e.precedence(expr, ctx)
+
+	case *ast.RuleRefExpr:
+		return expr.Name.Val
+
+	case *ast.BackRefExpr:
+		return e.special("same text as " + expr.Label.Val)
+
+	case *ast.LitMatcher:
+		return e.wrap(e.literal(expr), e.literalLevel(expr), ctx)
+
+	case *ast.CharClassMatcher:
+		return e.charClass(expr)
+
+	case *ast.AnyMatcher:
+		if e.style == W3C {
+			return "[#x0-#x10FFFF]"
+		}
+		return e.special("any character")
+
+	default:
+		panic(fmt.Sprintf("unexpected expression type %T", expr))
+	}
+}
+
+// operandLevel is the level of the operand of the repetitions: ISO
+// encloses it in brackets, W3C appends an operator to it.
+func (e *exporter) operandLevel() int {
+	if e.style == W3C {
+		return lvlPrimary
+	}
+	return lvlChoice
+}
+
+func (e *exporter) optional(x string) string {
+	if e.style == W3C {
+		return x + "?"
+	}
+	return "[ " + x + " ]"
+}
+
+func (e *exporter) many(x string) string {
+	if e.style == W3C {
+		return x + "*"
+	}
+	return "{ " + x + " }"
+}
+
+func (e *exporter) concat(a, b string) string {
+	if e.style == W3C {
+		return a + " " + b
+	}
+	return a + ", " + b
+}
+
+func (e *exporter) empty() string {
+	if e.style == W3C {
+		return `""`
+	}
+	return e.special("empty")
+}
+
+func (e *exporter) seq(exprs []ast.Expression, ctx int) string {
+	var parts []string
+	for i := 0; i < len(exprs); i++ {
+		if stripped(exprs[i]) {
+			continue
+		}
+		// !x . matches a character that is not matched by x
+		if not, ok := unwrap(exprs[i]).(*ast.NotExpr); ok && i+1 < len(exprs) && e.singleChar(not.Expr, make(map[string]bool)) {
+			if _, ok := unwrap(exprs[i+1]).(*ast.AnyMatcher); ok {
+				char := e.expr(exprs[i+1], lvlPrimary)
+				parts = append(parts, char+" - "+e.expr(not.Expr, lvlPrimary))
+				i++
+				continue
+			}
+		}
+		parts = append(parts, e.expr(exprs[i], lvlSeq))
+	}
+	switch len(parts) {
+	case 0:
+		return e.empty()
+	case 1:
+		return e.wrap(parts[0], lvlSeq, ctx)
+	}
+	sep := ", "
+	if e.style == W3C {
+		sep = " "
+	}
+	return e.wrap(strings.Join(parts, sep), lvlSeq, ctx)
+}
+
+// singleChar returns true if expr only matches a single character, so that
+// !expr . can be written as an exception.
+func (e *exporter) singleChar(expr ast.Expression, visiting map[string]bool) bool {
+	switch expr := unwrap(expr).(type) {
+	case *ast.CharClassMatcher:
+		return true
+	case *ast.LitMatcher:
+		return len([]rune(expr.Val)) == 1
+	case *ast.ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			if !e.singleChar(alt, visiting) {
+				return false
+			}
+		}
+		return true
+	case *ast.RuleRefExpr:
+		rule := e.rules[expr.Name.Val]
+		if rule == nil || visiting[rule.Name.Val] {
+			return false
+		}
+		visiting[rule.Name.Val] = true
+		defer delete(visiting, rule.Name.Val)
+		return e.singleChar(rule.Expr, visiting)
+	}
+	return false
+}
+
+func (e *exporter) repeat(expr *ast.RepeatExpr, ctx int) string {
+	if e.style == ISO {
+		var parts []string
+		x := e.expr(expr.Expr, lvlPrimary)
+		if expr.Min > 0 {
+			parts = append(parts, fmt.Sprintf("%d * %s", expr.Min, x))
+		}
+		switch {
+		case expr.Max < 0:
+			parts = append(parts, e.many(e.expr(expr.Expr, lvlChoice)))
+		case expr.Max > expr.Min:
+			parts = append(parts, fmt.Sprintf("%d * %s", expr.Max-expr.Min, e.optional(e.expr(expr.Expr, lvlChoice))))
+		}
+		if len(parts) == 0 {
+			return e.empty()
+		}
+		return e.wrap(strings.Join(parts, ", "), lvlSeq, ctx)
+	}
+
+	// W3C has no repetition count, the operand is repeated
+	x := e.expr(expr.Expr, lvlPrimary)
+	var parts []string
+	for i := 0; i < expr.Min; i++ {
+		parts = append(parts, x)
+	}
+	switch {
+	case expr.Max < 0:
+		parts = append(parts, x+"*")
+	default:
+		for i := expr.Min; i < expr.Max; i++ {
+			parts = append(parts, x+"?")
+		}
+	}
+	if len(parts) == 0 {
+		return e.empty()
+	}
+	return e.wrap(strings.Join(parts, " "), lvlSeq, ctx)
+}
+
+// precedence returns the text of a precedence expression: the atom,
+// preceded by any number of prefix operators and followed by any number of
+// postfix operators or of binary operators with their right operand.
+func (e *exporter) precedence(expr *ast.PrecedenceExpr, ctx int) string {
+	atom := e.expr(expr.Atom, lvlSeq)
+	var prefix, suffix []string
+	for _, l := range expr.Levels {
+		for _, op := range l.Operators {
+			switch l.Kind {
+			case ast.Prefix:
+				prefix = append(prefix, e.expr(op.Expr, lvlSeq))
+			case ast.Postfix:
+				suffix = append(suffix, e.expr(op.Expr, lvlSeq))
+			default:
+				suffix = append(suffix, e.concat(e.expr(op.Expr, lvlSeq), atom))
+			}
+		}
+	}
+
+	text := atom
+	if len(prefix) > 0 {
+		text = e.concat(e.many(e.wrap(strings.Join(prefix, " | "), lvlChoice, e.operandLevel())), text)
+	}
+	if len(suffix) > 0 {
+		text = e.concat(text, e.many(e.wrap(strings.Join(suffix, " | "), lvlChoice, e.operandLevel())))
+	}
+	return e.wrap(text, lvlSeq, ctx)
+}
+
+// literalLevel returns the level of the text of the literal, a sequence if
+// it is split in several parts.
+func (e *exporter) literalLevel(lit *ast.LitMatcher) int {
+	if len(e.literalParts(lit)) > 1 {
+		return lvlSeq
+	}
+	return lvlPrimary
+}
+
+func (e *exporter) literal(lit *ast.LitMatcher) string {
+	if len(lit.Val) == 0 {
+		return e.empty()
+	}
+	sep := ", "
+	if e.style == W3C {
+		sep = " "
+	}
+	return strings.Join(e.literalParts(lit), sep)
+}
+
+// literalParts returns the quoted runs of printable characters of the
+// literal and the code points of the other characters. A case-insensitive
+// literal is a special sequence in the ISO style, and its letters are
+// character classes of their cases in the W3C style.
+func (e *exporter) literalParts(lit *ast.LitMatcher) []string {
+	if lit.IgnoreCase && e.style == ISO {
+		return []string{e.special("case-insensitive " + strconv.Quote(lit.Val))}
+	}
+
+	var parts []string
+	var run []rune
+	flush := func() {
+		if len(run) > 0 {
+			parts = append(parts, quote(string(run)))
+			run = run[:0]
+		}
+	}
+	for _, r := range lit.Val {
+		switch {
+		case lit.IgnoreCase && unicode.SimpleFold(r) != r:
+			flush()
+			cases := string(r)
+			for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+				cases += string(f)
+			}
+			parts = append(parts, "["+cases+"]")
+		case !unicode.IsPrint(r):
+			flush()
+			parts = append(parts, e.codePoint(r))
+		case r == '"' && strings.ContainsRune(string(run), '\''),
+			r == '\'' && strings.ContainsRune(string(run), '"'):
+			// a terminal cannot hold both kinds of quotes
+			flush()
+			run = append(run, r)
+		default:
+			run = append(run, r)
+		}
+	}
+	flush()
+	return parts
+}
+
+// quote returns s in double quotes, or in single quotes if it contains a
+// double quote.
+func quote(s string) string {
+	if strings.ContainsRune(s, '"') {
+		return "'" + s + "'"
+	}
+	return `"` + s + `"`
+}
+
+func (e *exporter) codePoint(r rune) string {
+	if e.style == W3C {
+		return fmt.Sprintf("#x%X", r)
+	}
+	return e.special(fmt.Sprintf("U+%04X", r))
+}
+
+// charClass returns the text of the character class, a special sequence
+// in the ISO style, a character class of the W3C notation otherwise.
+func (e *exporter) charClass(c *ast.CharClassMatcher) string {
+	if e.style == ISO {
+		return e.special(c.Val)
+	}
+
+	var buf strings.Builder
+	buf.WriteString("[")
+	if c.Inverted {
+		buf.WriteString("^")
+	}
+	if c.Table != nil {
+		for _, r := range c.Table.R16 {
+			classRange(&buf, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+		}
+		for _, r := range c.Table.R32 {
+			classRange(&buf, rune(r.Lo), rune(r.Hi), rune(r.Stride))
+		}
+	}
+	chars := c.Chars
+	ranges := c.Ranges
+	if c.IgnoreCase {
+		chars, ranges = foldClass(chars, ranges)
+	}
+	for _, r := range chars {
+		buf.WriteString(classChar(r))
+	}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		classRange(&buf, ranges[i], ranges[i+1], 1)
+	}
+	for _, class := range c.UnicodeClasses {
+		buf.WriteString(`\p{` + class + "}")
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+// foldClass returns the characters and the ranges of a case-insensitive
+// character class, with the other cases of its characters and of its
+// ranges of ASCII letters.
+func foldClass(chars, ranges []rune) ([]rune, []rune) {
+	var folded []rune
+	seen := make(map[rune]bool)
+	for _, r := range chars {
+		for f := r; !seen[f]; f = unicode.SimpleFold(f) {
+			seen[f] = true
+			folded = append(folded, f)
+		}
+	}
+	out := append([]rune(nil), ranges...)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		switch {
+		case lo >= 'a' && hi <= 'z':
+			out = append(out, lo-'a'+'A', hi-'a'+'A')
+		case lo >= 'A' && hi <= 'Z':
+			out = append(out, lo-'A'+'a', hi-'A'+'a')
+		}
+	}
+	return folded, out
+}
+
+func classRange(buf *strings.Builder, lo, hi, stride rune) {
+	if stride != 1 {
+		for r := lo; r <= hi; r += stride {
+			buf.WriteString(classChar(r))
+		}
+		return
+	}
+	buf.WriteString(classChar(lo))
+	if hi != lo {
+		buf.WriteString("-" + classChar(hi))
+	}
+}
+
+// classChar returns the character as written in a W3C character class.
+func classChar(r rune) string {
+	if !unicode.IsPrint(r) || r == ' ' || strings.ContainsRune(`[]^-\`, r) {
+		return fmt.Sprintf("#x%X", r)
+	}
+	return string(r)
+}
@@ -0,0 +1,140 @@
+package ebnf_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/ebnf"
+)
+
+func parse(t *testing.T, text string) *ast.Grammar {
+	t.Helper()
+
+	p := bootstrap.NewParser()
+	g, err := p.Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		in   string
+		iso  string
+		w3c  string
+	}{
+		{
+			name: "sequence and choice",
+			in:   "start = a:'a' b { return nil, nil } / 'c'\nb = \"b\"\n",
+			iso:  "start = \"a\", b | \"c\" ;\n\nb = \"b\" ;\n",
+			w3c:  "start ::= \"a\" b | \"c\"\n\nb ::= \"b\"\n",
+		},
+		{
+			name: "repetitions",
+			in:   "start = a? (b c)* d+\n",
+			iso:  "start = [ a ], { b, c }, d, { d } ;\n",
+			w3c:  "start ::= a? ( b c )* d+\n",
+		},
+		{
+			name: "grouping",
+			in:   "start = (a / b) c / (d e)\n",
+			iso:  "start = ( a | b ), c | d, e ;\n",
+			w3c:  "start ::= ( a | b ) c | d e\n",
+		},
+		{
+			name: "lookahead",
+			in:   "start = &a !b c !.\n",
+			iso:  "start = ? followed by a ?, ? not followed by b ?, c, ? end of input ? ;\n",
+			w3c:  "start ::= /* followed by a */ /* not followed by b */ c /* end of input */\n",
+		},
+		{
+			name: "exception",
+			in:   "start = (!quote .)*\nquote = '\"' / \"'\"\n",
+			iso:  "start = { ? any character ? - quote } ;\n\nquote = '\"' | \"'\" ;\n",
+			w3c:  "start ::= ( [#x0-#x10FFFF] - quote )*\n\nquote ::= '\"' | \"'\"\n",
+		},
+		{
+			name: "literals",
+			in:   "start = \"a\\nb\" / \"ab\"i / \"'\\\"\"\n",
+			iso:  "start = \"a\", ? U+000A ?, \"b\" | ? case-insensitive \"ab\" ? | \"'\", '\"' ;\n",
+			w3c:  "start ::= \"a\" #xA \"b\" | [aA] [bB] | \"'\" '\"'\n",
+		},
+		{
+			name: "character classes",
+			in:   "start = [a-z_] [^\\n-]i [\\pL]\n",
+			iso:  "start = ? [a-z_] ?, ? [^\\n-]i ?, ? [\\pL] ? ;\n",
+			w3c:  "start ::= [_a-z] [^#xA#x2D] [\\p{L}]\n",
+		},
+		{
+			name: "display name",
+			in:   "start \"the start\" = 'a'\n",
+			iso:  "start (* the start *) = \"a\" ;\n",
+			w3c:  "start /* the start */ ::= \"a\"\n",
+		},
+		{
+			name: "long choice",
+			in:   "start = aaaaaaaaaaaaaaaa bbbbbbbbbbbbbbbbbbbb / cccccccccccccccccccc dddddddddddddddddddd / e\n",
+			iso:  "start = aaaaaaaaaaaaaaaa, bbbbbbbbbbbbbbbbbbbb\n      | cccccccccccccccccccc, dddddddddddddddddddd\n      | e\n      ;\n",
+			w3c:  "start ::= aaaaaaaaaaaaaaaa bbbbbbbbbbbbbbbbbbbb\n        | cccccccccccccccccccc dddddddddddddddddddd\n        | e\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			g := parse(t, tc.in)
+			if got := string(ebnf.Export(g, nil, ebnf.ISO)); got != tc.iso {
+				t.Errorf("iso: want\n%s\ngot\n%s", tc.iso, got)
+			}
+			if got := string(ebnf.Export(g, nil, ebnf.W3C)); got != tc.w3c {
+				t.Errorf("w3c: want\n%s\ngot\n%s", tc.w3c, got)
+			}
+		})
+	}
+}
+
+func TestRuleComment(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		src  string
+		want []string
+	}{
+		{src: "", want: nil},
+		{src: "a = 'a'\n\n", want: nil},
+		{src: "// a comment\n", want: []string{"a comment"}},
+		{src: "// first\n//\n// second\n  ", want: []string{"first", "", "second"}},
+		{src: "// not this one\n\n// this one\n", want: []string{"this one"}},
+		{src: "/* block */\n", want: []string{"block"}},
+		{src: "/*\n * first\n * second\n */\n", want: []string{"first", "second"}},
+		{src: "a = 'a' // trailing\n", want: nil},
+		{src: "x ", want: nil},
+	}
+
+	for _, tc := range cases {
+		rule := ast.NewRule(ast.Pos{Off: len(tc.src)}, ast.NewIdentifier(ast.Pos{}, "rule"))
+		src := []byte(tc.src + "rule = 'b'\n")
+		if got := ebnf.RuleComment(rule, src); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: want %q, got %q", tc.src, tc.want, got)
+		}
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+
+	g := parse(t, "start \"the start\" = a\na = 'a'\n")
+	want := "# doc\n\n## start\n\n*the start*\n\n```ebnf\nstart (* the start *) = a ;\n```\n\n## a\n\n```ebnf\na = \"a\" ;\n```\n"
+	if got := string(ebnf.Markdown(g, nil, ebnf.ISO, "doc")); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
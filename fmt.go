@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/format"
+)
+
+// fmtMain implements the fmt command, it prints the grammars in the
+// canonical format.
+func fmtMain(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" fmt", flag.ExitOnError)
+
+	var (
+		diffFlag      = fs.Bool("d", false, "print the diffs instead of the formatted grammars")
+		shortHelpFlag = fs.Bool("h", false, "show help page")
+		longHelpFlag  = fs.Bool("help", false, "show help page")
+		writeFlag     = fs.Bool("w", false, "write the formatted grammars to their files")
+	)
+
+	usage := func() {
+		fmt.Printf(fmtUsagePage, os.Args[0])
+	}
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, "args parse error:\n", err)
+		exit(6)
+	}
+
+	if *shortHelpFlag || *longHelpFlag {
+		usage()
+		exit(0)
+	}
+
+	if fs.NArg() == 0 {
+		if *writeFlag {
+			fmt.Fprintln(os.Stderr, "cannot use -w with stdin")
+			usage()
+			exit(1)
+		}
+		nm, rc := input("")
+		src, err := io.ReadAll(rc)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "read error:\n", err)
+			exit(2)
+		}
+		if !fmtFile(nm, src, *diffFlag, false) {
+			exit(3)
+		}
+		return
+	}
+
+	ok := true
+	for _, filename := range fs.Args() {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exit(2)
+		}
+		ok = fmtFile(filename, src, *diffFlag, *writeFlag) && ok
+	}
+	if !ok {
+		exit(3)
+	}
+}
+
+// fmtFile formats the grammar src read from filename and prints it, or
+// its diff, or writes it back to filename. It returns false if the grammar
+// cannot be parsed.
+func fmtFile(filename string, src []byte, diff, write bool) bool {
+	formatted, err := formatGrammar(filename, src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "parse error(s):\n %v\n", err)
+		return false
+	}
+
+	if diff {
+		os.Stdout.Write(unifiedDiff(filename, src, formatted))
+	}
+	if write {
+		if !bytes.Equal(src, formatted) {
+			if err := os.WriteFile(filename, formatted, 0o644); err != nil {
+				fmt.Fprintln(os.Stderr, "write error:\n", err)
+				exit(7)
+			}
+		}
+	}
+	if !diff && !write {
+		if _, err := os.Stdout.Write(formatted); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:\n", err)
+			exit(7)
+		}
+	}
+	return true
+}
+
+// formatGrammar parses the grammar src and returns it in the canonical
+// format.
+func formatGrammar(filename string, src []byte) ([]byte, error) {
+	g, err := Parse(filename, src)
+	if err != nil {
+		return nil, err
+	}
+	grammar, ok := g.(*ast.Grammar)
+	if !ok {
+		return nil, errors.New("no grammar")
+	}
+	return format.Format(grammar, src), nil
+}
+
+var fmtUsagePage = `usage: %s fmt [options] [GRAMMAR_FILE...]
+
+Fmt prints the grammars in the canonical format: the rules are defined
+with the ← operator, with a single space between the tokens, and the
+continuation lines are indented by four spaces per level of nesting.
+The comments, the code blocks and the line breaks between the
+alternatives of a choice and between the elements of a sequence are
+kept. The grammar is read from stdin if GRAMMAR_FILE is not specified.
+
+	-d
+		print the diff between each grammar and its formatted
+		version instead of the formatted grammar.
+	-h -help
+		display this help message.
+	-w
+		write the formatted grammar to its file instead of stdout,
+		if it differs.
+`
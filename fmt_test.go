@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// TestFormatCorpus formats the grammars of the tests and the examples and
+// checks that the formatted grammar is equivalent to the original one, and
+// that formatting it again does not change it.
+func TestFormatCorpus(t *testing.T) {
+	var files []string
+	for _, pattern := range []string{"grammar/*.peg", "examples/*/*.peg", "test/*/*.peg", "test/*/*/*.peg"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		t.Fatal("no grammar found")
+	}
+
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g, err := Parse(file, src)
+		if err != nil {
+			// some grammars of the tests do not parse on purpose
+			continue
+		}
+
+		once, err := formatGrammar(file, src)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		got, err := Parse(file, once)
+		if err != nil {
+			t.Errorf("%s: formatted grammar: %v", file, err)
+			continue
+		}
+		if !compareGrammars(t, file, g.(*ast.Grammar), got.(*ast.Grammar)) {
+			continue
+		}
+
+		twice, err := formatGrammar(file, once)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if !bytes.Equal(once, twice) {
+			t.Errorf("%s: formatting is not idempotent:\n%s", file, unifiedDiff(file, once, twice))
+		}
+	}
+}
+
+func TestFormatGrammar(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "spacing",
+			in:   "start   =  'a'   b\nb \"B\" <- [a-z]i* / !c . &'x'+\nc ← \"c\"i?",
+			want: "start ← 'a' b\nb \"B\" ← [a-z]i* / !c . &'x'+\nc ← \"c\"i?\n",
+		},
+		{
+			name: "line breaks",
+			in:   "start = a\n   / b\n  c\n\n\n\nd = e\n",
+			want: "start ← a\n    / b\n    c\n\nd ← e\n",
+		},
+		{
+			name: "comments",
+			in:   "// header\n\n\nstart = a // trailing\n  /* before b */ / b\n// footer\n",
+			want: "// header\n\nstart ← a // trailing\n    /* before b */\n    / b\n// footer\n",
+		},
+		{
+			name: "code",
+			in:   "start = a:x { return a, nil } / &{ return true, nil } y\n",
+			want: "start ← a:x { return a, nil } / &{ return true, nil } y\n",
+		},
+		{
+			name: "repetitions",
+			in:   "start = a{2,}  b{ 1 , 3 }  c++ ','  d**?',' \n",
+			want: "start ← a{2,} b{1,3} c ++ ',' d **? ','\n",
+		},
+	}
+
+	for _, tc := range cases {
+		got, err := formatGrammar("", []byte(tc.in))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: want\n%s\ngot\n%s", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestFmtMain(t *testing.T) {
+	silenceMain(t)
+
+	dir := t.TempDir()
+	cases := []struct {
+		grammar string
+		args    string
+		code    int
+		want    string
+	}{
+		{grammar: "start = 'a'\n", code: 0, want: "start = 'a'\n"},
+		{grammar: "start = 'a'\n", args: "-d", code: 0, want: "start = 'a'\n"},
+		{grammar: "start = 'a'\n", args: "-w", code: 0, want: "start ← 'a'\n"},
+		{grammar: "start ←   'a'  b\n\n\nb = \"b\"", args: "-w", code: 0, want: "start ← 'a' b\n\nb ← \"b\"\n"},
+		{grammar: "start = 'a' (\n", args: "-w", code: 3, want: "start = 'a' (\n"},
+	}
+
+	for i, tc := range cases {
+		file := filepath.Join(dir, "grammar.peg")
+		if err := os.WriteFile(file, []byte(tc.grammar), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		os.Args = append(append([]string{"pigeon", "fmt"}, strings.Fields(tc.args)...), file)
+
+		got := runMainRecover()
+		if got != tc.code {
+			t.Errorf("%d: %q %s: want code %d, got %d", i, tc.grammar, tc.args, tc.code, got)
+		}
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != tc.want {
+			t.Errorf("%d: %q %s: want file %q, got %q", i, tc.grammar, tc.args, tc.want, b)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	cases := []struct {
+		old, new string
+		want     string
+	}{
+		{old: "a\n", new: "a\n", want: ""},
+		{old: "a\nb\nc\n", new: "a\nB\nc\n", want: `diff f.orig f
+--- f.orig
++++ f
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+`},
+		{old: "a\n", new: "a\nb", want: `diff f.orig f
+--- f.orig
++++ f
+@@ -1 +1,2 @@
+ a
++b
+\ No newline at end of file
+`},
+		{old: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n", new: "0\n1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", want: `diff f.orig f
+--- f.orig
++++ f
+@@ -1,3 +1,4 @@
++0
+ 1
+ 2
+ 3
+@@ -8,4 +9,3 @@
+ 8
+ 9
+ 10
+-11
+`},
+	}
+
+	for _, tc := range cases {
+		got := string(unifiedDiff("f", []byte(tc.old), []byte(tc.new)))
+		if got != tc.want {
+			t.Errorf("%q -> %q: want\n%s\ngot\n%s", tc.old, tc.new, tc.want, got)
+		}
+	}
+}
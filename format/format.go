@@ -0,0 +1,628 @@
+// Package format implements the canonical formatting of a PEG grammar.
+//
+// The rules are printed with the ← definition operator and a single space
+// between the tokens, the comments and the code blocks are kept verbatim.
+// The line breaks between the alternatives of a choice and between the
+// elements of a sequence are kept, so that the author decides where the
+// long rules are split, the continuation lines are indented by four spaces
+// per level of nesting.
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// indentUnit is the indentation of a continuation line, per level of
+// nesting.
+const indentUnit = "    "
+
+// Format returns the canonical text of the grammar. The src argument is
+// the source text the grammar was parsed from, it is used to keep the
+// comments, the spelling of the literals and the line breaks. It may be
+// nil for a grammar that was not parsed, e.g. an optimized grammar.
+func Format(grammar *ast.Grammar, src []byte) []byte {
+	p := &printer{src: src}
+	if src != nil {
+		p.comments = scanComments(src, opaqueSpans(grammar))
+	}
+	p.grammar(grammar)
+	return p.buf.Bytes()
+}
+
+// Levels of the grammar productions, from the loosest to the tightest. An
+// expression printed where a tighter level is expected is parenthesized.
+const (
+	lvlRecovery  = iota // Expression
+	lvlChoice           // ChoiceExpr
+	lvlActionSeq        // alternative of a choice
+	lvlAction           // element of a sequence of actions
+	lvlSeq              // expression of an action
+	lvlLabeled          // element of a sequence
+	lvlPrefixed         // operand of a label
+	lvlSuffixed         // operand of a prefix operator
+	lvlPrimary          // operand of a suffix operator
+)
+
+type printer struct {
+	src      []byte
+	comments []comment
+	next     int // index of the next comment to print
+
+	buf bytes.Buffer
+	// lastEnd is the offset in src of the end of the last printed token or
+	// comment, tokEnd the one of the last printed token.
+	lastEnd, tokEnd int
+	// nl is the number of line breaks to write before the next text.
+	nl     int
+	indent int
+}
+
+// write writes s after the pending line breaks and the indentation.
+func (p *printer) write(s string) {
+	if p.nl > 0 {
+		p.buf.WriteString(strings.Repeat("\n", p.nl))
+		p.buf.WriteString(strings.Repeat(indentUnit, p.indent))
+		p.nl = 0
+	}
+	p.buf.WriteString(s)
+}
+
+// token writes the text of the token that spans [off, end) in src.
+func (p *printer) token(off, end int, s string) {
+	p.flush(off, false)
+	p.write(s)
+	if p.src != nil && end > p.tokEnd {
+		p.lastEnd, p.tokEnd = end, end
+	}
+}
+
+// newline requests a line break before the next text, or a blank line if
+// blank is true.
+func (p *printer) newline(blank bool) {
+	n := 1
+	if blank {
+		n = 2
+	}
+	if p.buf.Len() > 0 && p.nl < n {
+		p.nl = n
+	}
+}
+
+// sep writes the separator before the expression at off, a line break if
+// there is one in src since the last token, a space otherwise.
+func (p *printer) sep(off int) {
+	p.flush(off, false)
+	if p.src != nil && off <= len(p.src) && p.tokEnd <= off &&
+		bytes.IndexByte(p.src[p.tokEnd:off], '\n') >= 0 {
+		p.newline(false)
+		return
+	}
+	if p.nl == 0 {
+		p.write(" ")
+	}
+}
+
+// blankBefore returns true if there is a blank line in src between the
+// last printed token or comment and off. Without src, the top-level items
+// are always separated by a blank line.
+func (p *printer) blankBefore(off int) bool {
+	if p.buf.Len() == 0 {
+		return false
+	}
+	if p.src == nil {
+		return true
+	}
+	if p.lastEnd > off || off > len(p.src) {
+		return false
+	}
+	return bytes.Count(p.src[p.lastEnd:off], []byte("\n")) > 1
+}
+
+// flush writes the comments that start before off. A comment that follows
+// a token on its line stays at the end of the line of the last printed
+// text, the others are written on their own line. If top is true, the
+// blank lines before the comments are kept.
+func (p *printer) flush(off int, top bool) {
+	for p.next < len(p.comments) && p.comments[p.next].off < off {
+		c := p.comments[p.next]
+		p.next++
+		if c.trailing && p.buf.Len() > 0 && p.buf.Bytes()[p.buf.Len()-1] != '\n' {
+			p.buf.WriteString(" " + c.text)
+		} else {
+			p.newline(top && p.blankBefore(c.off))
+			p.write(c.text)
+			p.nl = 1
+		}
+		if strings.HasPrefix(c.text, "//") && p.nl == 0 {
+			p.nl = 1
+		}
+		p.lastEnd = c.end
+	}
+}
+
+func (p *printer) grammar(g *ast.Grammar) {
+	if g.Init != nil {
+		p.topLevel(g.Init.Pos().Off)
+		p.code(g.Init)
+	}
+
+	if len(g.Options) > 0 {
+		off := g.Options[0].Pos().Off
+		if p.src != nil {
+			if i := bytes.LastIndex(p.src[:off], []byte("@options")); i >= 0 {
+				off = i
+			}
+		}
+		p.topLevel(off)
+		p.write("@options {")
+		p.indent++
+		for _, opt := range g.Options {
+			p.newline(false)
+			p.flush(opt.Pos().Off, false)
+			p.token(opt.Pos().Off, opt.Pos().Off+len(opt.Name.Val), opt.Name.Val)
+			p.write(" = ")
+			for i, val := range opt.Values {
+				if i > 0 {
+					p.write(", ")
+				}
+				p.write(optionValue(val))
+			}
+		}
+		p.indent--
+		p.newline(false)
+		p.write("}")
+	}
+
+	for _, rule := range g.Rules {
+		p.topLevel(rule.Pos().Off)
+		p.rule(rule)
+	}
+
+	p.flush(len(p.src)+1, true)
+	p.buf.WriteString("\n")
+}
+
+// topLevel starts a top-level item of the grammar at off, on its own line
+// with its leading comments.
+func (p *printer) topLevel(off int) {
+	p.newline(false)
+	p.flush(off, true)
+	p.newline(p.blankBefore(off))
+}
+
+func (p *printer) rule(r *ast.Rule) {
+	for i, a := range r.Annotations {
+		off := a.Pos().Off
+		p.token(off-1, off+len(a.Val), "@"+a.Val)
+		if i < len(r.Annotations)-1 {
+			p.sep(r.Annotations[i+1].Pos().Off - 1)
+		} else {
+			p.sep(r.Name.Pos().Off)
+		}
+	}
+	p.token(r.Name.Pos().Off, r.Name.Pos().Off+len(r.Name.Val), r.Name.Val)
+	if r.DisplayName != nil {
+		p.write(" ")
+		name := r.DisplayName.Val
+		if _, err := strconv.Unquote(name); err != nil {
+			// the display name of a grammar that was not parsed may
+			// be unquoted
+			name = strconv.Quote(name)
+		}
+		p.token(r.DisplayName.Pos().Off, r.DisplayName.Pos().Off+len(r.DisplayName.Val), name)
+	}
+	p.write(" ←")
+
+	p.indent++
+	p.sep(r.Expr.Pos().Off)
+	p.expr(r.Expr, lvlRecovery)
+	p.indent--
+}
+
+// level returns the level of the production that parses expr.
+func level(expr ast.Expression) int {
+	switch expr := expr.(type) {
+	case *ast.RecoveryExpr:
+		return lvlRecovery
+	case *ast.ChoiceExpr:
+		return lvlChoice
+	case *ast.SeqExpr:
+		return lvlActionSeq
+	case *ast.ActionExpr:
+		return lvlAction
+	case *ast.CodeExpr:
+		if !expr.NotSkip {
+			return lvlAction
+		}
+	case *ast.LabeledExpr, *ast.ThrowExpr:
+		return lvlLabeled
+	case *ast.AndExpr, *ast.NotExpr:
+		return lvlPrefixed
+	case *ast.ZeroOrOneExpr, *ast.ZeroOrMoreExpr, *ast.OneOrMoreExpr,
+		*ast.RepeatExpr, *ast.SeparatedExpr:
+		return lvlSuffixed
+	}
+	return lvlPrimary
+}
+
+// isAction returns true if expr is an expression followed by a code block
+// or a code block alone, the elements of a sequence of actions.
+func isAction(expr ast.Expression) bool {
+	return level(expr) == lvlAction
+}
+
+// expr prints expr where the production at level ctx is expected.
+func (p *printer) expr(expr ast.Expression, ctx int) {
+	if seq, ok := expr.(*ast.SeqExpr); ok && ctx == lvlSeq {
+		p.seq(seq.Exprs, false)
+		return
+	}
+	if level(expr) < ctx {
+		p.flush(expr.Pos().Off, false)
+		p.write("( ")
+		p.indent++
+		p.expr(expr, lvlRecovery)
+		p.indent--
+		p.write(" )")
+		return
+	}
+
+	switch expr := expr.(type) {
+	case *ast.RecoveryExpr:
+		p.expr(expr.Expr, lvlRecovery)
+		p.sep(expr.RecoverExpr.Pos().Off)
+		labels := make([]string, len(expr.Labels))
+		for i, l := range expr.Labels {
+			labels[i] = string(l)
+		}
+		p.write("//{" + strings.Join(labels, ", ") + "} ")
+		p.expr(expr.RecoverExpr, lvlChoice)
+
+	case *ast.ChoiceExpr:
+		for i, alt := range expr.Alternatives {
+			if i > 0 {
+				p.sep(alt.Pos().Off)
+				p.write("/ ")
+			}
+			p.expr(alt, lvlActionSeq)
+		}
+
+	case *ast.SeqExpr:
+		p.seq(expr.Exprs, actionSeq(expr.Exprs))
+
+	case *ast.ActionExpr:
+		p.expr(expr.Expr, lvlSeq)
+		p.sep(expr.Code.Pos().Off)
+		p.code(expr.Code)
+
+	case *ast.CodeExpr:
+		if expr.NotSkip {
+			p.token(expr.Pos().Off, expr.Pos().Off+1, "*")
+		}
+		p.code(expr.Code)
+
+	case *ast.LabeledExpr:
+		p.token(expr.Label.Pos().Off, expr.Label.Pos().Off+len(expr.Label.Val), expr.Label.Val+":")
+		if expr.TextCapture {
+			p.write("<")
+			p.expr(expr.Expr, lvlPrefixed)
+			p.write(">")
+		} else {
+			p.expr(expr.Expr, lvlPrefixed)
+		}
+
+	case *ast.ThrowExpr:
+		text := "%{" + expr.Label + "}"
+		p.token(expr.Pos().Off, expr.Pos().Off+len(text), text)
+
+	case *ast.AndExpr:
+		op := "&"
+		if expr.Logical {
+			op = "&&"
+		}
+		p.token(expr.Pos().Off, expr.Pos().Off+len(op), op)
+		p.expr(expr.Expr, lvlSuffixed)
+
+	case *ast.NotExpr:
+		op := "!"
+		if expr.Logical {
+			op = "!!"
+		}
+		p.token(expr.Pos().Off, expr.Pos().Off+len(op), op)
+		p.expr(expr.Expr, lvlSuffixed)
+
+	case *ast.ZeroOrOneExpr:
+		p.expr(expr.Expr, lvlPrimary)
+		p.write("?")
+
+	case *ast.ZeroOrMoreExpr:
+		p.expr(expr.Expr, lvlPrimary)
+		p.write("*")
+
+	case *ast.OneOrMoreExpr:
+		p.expr(expr.Expr, lvlPrimary)
+		p.write("+")
+
+	case *ast.RepeatExpr:
+		p.expr(expr.Expr, lvlPrimary)
+		switch {
+		case expr.Max < 0:
+			p.write(fmt.Sprintf("{%d,}", expr.Min))
+		case expr.Min == expr.Max:
+			p.write(fmt.Sprintf("{%d}", expr.Min))
+		default:
+			p.write(fmt.Sprintf("{%d,%d}", expr.Min, expr.Max))
+		}
+
+	case *ast.SeparatedExpr:
+		p.expr(expr.Expr, lvlPrimary)
+		op := " **"
+		if expr.Min > 0 {
+			op = " ++"
+		}
+		if expr.AllowTrailing {
+			op += "?"
+		}
+		p.write(op + " ")
+		p.expr(expr.Sep, lvlPrimary)
+
+	case *ast.PrecedenceExpr:
+		p.token(expr.Pos().Off, expr.Pos().Off+len("%precedence"), "%precedence ")
+		p.expr(expr.Atom, lvlPrimary)
+		// the levels are at the indentation of the continuation lines,
+		// the closing brace at the one of the first line
+		p.write(" {")
+		for _, level := range expr.Levels {
+			p.newline(false)
+			p.token(level.Pos().Off, level.Pos().Off+len(level.Kind), string(level.Kind))
+			for i, op := range level.Operators {
+				if i > 0 {
+					p.sep(op.Pos().Off)
+					p.write("/ ")
+				} else {
+					p.write(" ")
+				}
+				p.expr(op.Expr, lvlPrimary)
+				if op.Code != nil {
+					p.sep(op.Code.Pos().Off)
+					p.code(op.Code)
+				}
+			}
+		}
+		p.indent--
+		p.newline(false)
+		p.write("}")
+		p.indent++
+
+	case *ast.AndCodeExpr:
+		p.token(expr.Pos().Off, expr.Pos().Off+1, "&")
+		p.code(expr.Code)
+
+	case *ast.NotCodeExpr:
+		p.token(expr.Pos().Off, expr.Pos().Off+1, "!")
+		p.code(expr.Code)
+
+	case *ast.RuleRefExpr:
+		p.token(expr.Pos().Off, expr.Pos().Off+len(expr.Name.Val), expr.Name.Val)
+
+	case *ast.BackRefExpr:
+		p.token(expr.Pos().Off, expr.Label.Pos().Off+len(expr.Label.Val), "$"+expr.Label.Val)
+
+	case *ast.LitMatcher:
+		text, end := p.literal(expr)
+		if expr.IgnoreCase {
+			text += "i"
+		}
+		if expr.Keyword {
+			text += "k"
+		}
+		p.token(expr.Pos().Off, end, text)
+
+	case *ast.CharClassMatcher:
+		p.token(expr.Pos().Off, expr.Pos().Off+len(expr.Val), expr.Val)
+
+	case *ast.AnyMatcher:
+		p.token(expr.Pos().Off, expr.Pos().Off+1, ".")
+
+	case *ast.TriviaExpr:
+		// only inserted by the builder, print the expression it wraps
+		p.expr(expr.Expr, ctx)
+
+	default:
+		panic(fmt.Sprintf("unexpected expression type %T", expr))
+	}
+}
+
+// actionSeq returns true if the elements can be printed as a sequence of
+// actions, that is every element but the last one is an action.
+func actionSeq(exprs []ast.Expression) bool {
+	for _, e := range exprs[:len(exprs)-1] {
+		if !isAction(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// seq prints the elements of a sequence. In a sequence of actions, each
+// action ends where its code block ends, and a last element that is not an
+// action is a sequence on its own, otherwise the actions are parenthesized.
+func (p *printer) seq(exprs []ast.Expression, actions bool) {
+	for i, e := range exprs {
+		if i > 0 {
+			p.sep(e.Pos().Off)
+		}
+		switch {
+		case actions && isAction(e):
+			p.expr(e, lvlAction)
+		case actions && i == len(exprs)-1:
+			if seq, ok := e.(*ast.SeqExpr); ok {
+				p.seq(seq.Exprs, false)
+				break
+			}
+			p.expr(e, lvlLabeled)
+		default:
+			p.expr(e, lvlLabeled)
+		}
+	}
+}
+
+// code prints the code block verbatim.
+func (p *printer) code(code *ast.CodeBlock) {
+	p.token(code.Pos().Off, code.Pos().Off+len(code.Val), code.Val)
+}
+
+// literal returns the text of the literal as spelled in src, or quoted if
+// it is not available, and the offset of its end in src.
+func (p *printer) literal(lit *ast.LitMatcher) (string, int) {
+	off := lit.Pos().Off
+	if p.src != nil && off < len(p.src) {
+		if end := stringEnd(p.src, off); end > 0 {
+			text := string(p.src[off:end])
+			if s, err := strconv.Unquote(text); err == nil && s == lit.Val {
+				return text, end
+			}
+		}
+	}
+	return strconv.Quote(lit.Val), off
+}
+
+// optionValue returns the text of the value of an option, quoted unless it
+// is an identifier.
+func optionValue(val string) string {
+	for i, r := range val {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r))) {
+			return strconv.Quote(val)
+		}
+	}
+	if val == "" {
+		return `""`
+	}
+	return val
+}
+
+// comment is a comment of the source outside of the code blocks.
+type comment struct {
+	off, end int
+	text     string
+	// trailing is true if the comment follows a token on its line.
+	trailing bool
+}
+
+// span is the [start, end) range of offsets of a code block or a character
+// class, in which the comment delimiters are not comments.
+type span struct{ start, end int }
+
+func opaqueSpans(grammar *ast.Grammar) []span {
+	var spans []span
+	addCode := func(code *ast.CodeBlock) {
+		if code != nil {
+			spans = append(spans, span{code.Pos().Off, code.Pos().Off + len(code.Val)})
+		}
+	}
+	addCode(grammar.Init)
+	ast.Inspect(grammar, func(expr ast.Expression) bool {
+		switch expr := expr.(type) {
+		case *ast.ActionExpr:
+			addCode(expr.Code)
+		case *ast.AndCodeExpr:
+			addCode(expr.Code)
+		case *ast.NotCodeExpr:
+			addCode(expr.Code)
+		case *ast.CodeExpr:
+			addCode(expr.Code)
+		case *ast.PrecedenceExpr:
+			for _, level := range expr.Levels {
+				for _, op := range level.Operators {
+					addCode(op.Code)
+				}
+			}
+		case *ast.CharClassMatcher:
+			spans = append(spans, span{expr.Pos().Off, expr.Pos().Off + len(expr.Val)})
+		}
+		return true
+	})
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	return spans
+}
+
+// scanComments returns the comments of src, skipping the string literals
+// and the opaque spans.
+func scanComments(src []byte, spans []span) []comment {
+	var comments []comment
+	for i := 0; i < len(src); {
+		if len(spans) > 0 && i >= spans[0].start {
+			if i < spans[0].end {
+				i = spans[0].end
+			}
+			spans = spans[1:]
+			continue
+		}
+
+		switch {
+		case src[i] == '"' || src[i] == '\'' || src[i] == '`':
+			if end := stringEnd(src, i); end > 0 {
+				i = end
+				continue
+			}
+		case bytes.HasPrefix(src[i:], []byte("//{")):
+			i += 3
+			continue
+		case bytes.HasPrefix(src[i:], []byte("//")):
+			end := bytes.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			comments = append(comments, newComment(src, i, i+end))
+			i += end
+			continue
+		case bytes.HasPrefix(src[i:], []byte("/*")):
+			end := bytes.Index(src[i+2:], []byte("*/"))
+			if end < 0 {
+				end = len(src) - i
+			} else {
+				end += 4
+			}
+			comments = append(comments, newComment(src, i, i+end))
+			i += end
+			continue
+		}
+		i++
+	}
+	return comments
+}
+
+func newComment(src []byte, start, end int) comment {
+	line := bytes.LastIndexByte(src[:start], '\n') + 1
+	return comment{
+		off:      start,
+		end:      end,
+		text:     strings.TrimRight(string(src[start:end]), " \t\r"),
+		trailing: len(bytes.TrimSpace(src[line:start])) > 0,
+	}
+}
+
+// stringEnd returns the offset of the end of the string literal that
+// starts at off in src, or -1 if it is not terminated on its line.
+func stringEnd(src []byte, off int) int {
+	q := src[off]
+	for i := off + 1; i < len(src); i++ {
+		switch {
+		case src[i] == q:
+			return i + 1
+		case q == '`':
+		case src[i] == '\n':
+			return -1
+		case src[i] == '\\':
+			i++
+		}
+	}
+	return -1
+}
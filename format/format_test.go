@@ -0,0 +1,67 @@
+package format_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/format"
+)
+
+func parse(t *testing.T, text string) *ast.Grammar {
+	t.Helper()
+
+	p := bootstrap.NewParser()
+	grammar, err := p.Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grammar
+}
+
+// TestFormat formats grammars built without source text, the formatting
+// of the comments and line breaks is tested with the grammar parser in
+// the pigeon command.
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "operators",
+			in:   "start = 'a'   b\nb \"B\" <-  [a-z]i* / !c . &'x'+\nc = \"c\"i?",
+			want: "start ← \"a\" b\n\nb \"B\" ← [a-z]i* / !c . &\"x\"+\n\nc ← \"c\"i?\n",
+		},
+		{
+			name: "parentheses",
+			in:   "start = (a / b) (c d)* !(e) ((f))\n",
+			want: "start ← ( a / b ) ( c d )* !e f\n",
+		},
+		{
+			name: "actions",
+			in:   "start = a:x b:(y z) { return nil } / w\n",
+			want: "start ← a:x b:( y z ) { return nil } / w\n",
+		},
+		{
+			name: "init",
+			in:   "{\npackage main\n}\nstart = 'a'\n",
+			want: "{\npackage main\n}\n\nstart ← \"a\"\n",
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := string(format.Format(parse(t, tc.in), nil))
+			if got != tc.want {
+				t.Errorf("want\n%s\ngot\n%s", tc.want, got)
+			}
+		})
+	}
+}
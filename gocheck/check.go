@@ -0,0 +1,167 @@
+// Package gocheck checks the Go code of the generated parsers, and reports
+// the errors of the code blocks at their position in the grammar.
+package gocheck
+
+import (
+	"errors"
+	"fmt"
+	goast "go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Error is an error of the generated code, at its position in the grammar
+// if it is in a code block, in the generated code otherwise.
+type Error struct {
+	Pos token.Position
+	Msg string
+}
+
+// Error returns the position and the message of the error.
+func (e Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Msg)
+}
+
+// Parse parses the generated parser src and returns its syntax errors.
+// filename is the name of the generated file, "parser.go" if empty.
+func Parse(filename string, src []byte, m *SourceMap) []Error {
+	_, errs := parse(token.NewFileSet(), genFilename(filename), src, m)
+	return errs
+}
+
+// Check type-checks the generated parser src and returns its errors, or
+// its syntax errors if it cannot be parsed. filename is the name of the
+// generated file, src is checked with the other Go files of the package
+// in its directory, ignoring the existing content of filename, or alone
+// if filename is empty. The imported packages are type-checked from
+// source. The returned error is set if the package cannot be loaded.
+func Check(filename string, src []byte, m *SourceMap) ([]Error, error) {
+	fset := token.NewFileSet()
+	name := genFilename(filename)
+	f, errs := parse(fset, name, src, m)
+	if len(errs) > 0 {
+		return errs, nil
+	}
+
+	files := []*goast.File{f}
+	if filename != "" {
+		others, errs, err := parseDir(fset, name, f.Name.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(errs) > 0 {
+			return errs, nil
+		}
+		files = append(files, others...)
+	}
+
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			var terr types.Error
+			if !errors.As(err, &terr) {
+				return
+			}
+			// the generated file is mapped from its actual positions,
+			// ignoring its line directives
+			if pos := fset.PositionFor(terr.Pos, false); pos.Filename == name {
+				errs = append(errs, m.error(pos, fset.Position(terr.Pos), terr.Msg))
+				return
+			}
+			errs = append(errs, Error{Pos: fset.Position(terr.Pos), Msg: terr.Msg})
+		},
+	}
+	// the errors are reported by conf.Error
+	_, _ = conf.Check(f.Name.Name, fset, files, nil)
+	return errs, nil
+}
+
+// parse parses the generated file, its syntax errors are mapped to the
+// grammar.
+func parse(fset *token.FileSet, filename string, src []byte, m *SourceMap) (*goast.File, []Error) {
+	f, err := parser.ParseFile(fset, filename, src, parser.AllErrors|parser.ParseComments)
+	if err == nil {
+		return f, nil
+	}
+
+	var list scanner.ErrorList
+	if !errors.As(err, &list) {
+		return nil, []Error{{Pos: token.Position{Filename: filename}, Msg: err.Error()}}
+	}
+	errs := make([]Error, 0, len(list))
+	for _, e := range list {
+		// the offset is the actual offset of the error, even if its line
+		// is set by a line directive
+		errs = append(errs, m.error(e.Pos, e.Pos, e.Msg))
+	}
+	return nil, errs
+}
+
+// parseDir parses the other Go files of package pkg in the directory of
+// filename, the test files and the files excluded by build constraints
+// are ignored.
+func parseDir(fset *token.FileSet, filename, pkg string) ([]*goast.File, []Error, error) {
+	dir := filepath.Dir(filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		files []*goast.File
+		errs  []Error
+	)
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == filepath.Base(filename) {
+			continue
+		}
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if f != nil && f.Name.Name != pkg {
+			continue
+		}
+		if err != nil {
+			var list scanner.ErrorList
+			if !errors.As(err, &list) {
+				return nil, nil, err
+			}
+			for _, e := range list {
+				errs = append(errs, Error{Pos: e.Pos, Msg: e.Msg})
+			}
+			continue
+		}
+		files = append(files, f)
+	}
+	return files, errs, nil
+}
+
+// error returns the error at the position of the generated file, mapped
+// to the grammar if it is in a code block, at its position adjusted by the
+// line directives otherwise.
+func (m *SourceMap) error(pos, adjusted token.Position, msg string) Error {
+	if m != nil {
+		if p, ok := m.Position(pos.Offset); ok {
+			return Error{Pos: p, Msg: msg}
+		}
+	}
+	return Error{Pos: adjusted, Msg: msg}
+}
+
+func genFilename(filename string) string {
+	if filename == "" {
+		return "parser.go"
+	}
+	return filename
+}
@@ -0,0 +1,142 @@
+package gocheck_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/imports"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/builder"
+	"github.com/oskoi/pigeon/gocheck"
+)
+
+// generate returns the grammar of text, its parser formatted by goimports
+// if format is true and its source map.
+func generate(t *testing.T, text string, format bool) ([]byte, *gocheck.SourceMap) {
+	t.Helper()
+
+	grammar, err := bootstrap.NewParser().Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	funcs := make(map[string]*ast.CodeBlock)
+	if err := builder.BuildParser(&buf, grammar, builder.GrammarName("g"), builder.CodeFuncs(funcs)); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.Bytes()
+	if format {
+		src, err = imports.Process("parser.go", src, &imports.Options{TabWidth: 8, TabIndent: true, Comments: true, Fragment: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return src, gocheck.NewSourceMap("test.peg", src, grammar.Init, funcs)
+}
+
+func messages(errs []gocheck.Error) string {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+const initCode = `{
+package p
+
+import "strings"
+
+type ParserCustomData struct{}
+
+func count() int {
+	return "n"
+}
+}
+`
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	grammar := initCode + `
+A <- a:"a" {
+	return strings.ToUpper(a) + undefined
+}
+B <- "b" {
+	n := len(c.text); return nil
+}
+`
+	src, m := generate(t, grammar, true)
+	errs, err := gocheck.Check("", src, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `test.peg:9:9: cannot use "n" (untyped string constant) as int value in return statement
+test.peg:14:25: cannot use a (variable of interface type any) as string value in argument to strings.ToUpper: need type assertion
+test.peg:14:30: undefined: undefined
+test.peg:17:2: declared and not used: n`
+	if got := messages(errs); got != want {
+		t.Errorf("want errors:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestCheckDir(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"data.go":      "package p\n\ntype Data struct{}\n",
+		"data_test.go": "package p\n\nvar x int = \"\"\n",
+		"other.go":     "package q\n",
+		"ignored.go":   "//go:build ignore\n\npackage p\n\nvar x int = \"\"\n",
+		"parser.go":    "package p\n\nfunc x() {}\n",
+	}
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	grammar := `{
+package p
+
+type ParserCustomData struct{}
+}
+A <- "a" { return Data{} }
+B <- "b" { return Missing{} }
+`
+	src, m := generate(t, grammar, true)
+	errs, err := gocheck.Check(filepath.Join(dir, "parser.go"), src, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "test.peg:7:19: undefined: Missing"
+	if got := messages(errs); got != want {
+		t.Errorf("want errors:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	grammar := `{
+package p
+}
+A <- "a" {
+	x := ;
+	return x
+}
+`
+	// the syntax errors prevent formatting
+	src, m := generate(t, grammar, false)
+	want := `test.peg:5:7: expected operand, found ';'
+test.peg:6:2: expected ';', found 'return'`
+	if got := messages(gocheck.Parse("", src, m)); got != want {
+		t.Errorf("want errors:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,213 @@
+package gocheck
+
+import (
+	"bytes"
+	"go/scanner"
+	"go/token"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// SourceMap maps the positions of a generated parser to the positions of
+// the code blocks of its grammar.
+//
+// The generated code may be reformatted, e.g. by goimports, so the code
+// blocks are not located by text: the tokens of a code block and of its
+// generated function are scanned and matched by rank, formatting does not
+// change the sequence of tokens.
+type SourceMap struct {
+	filename string
+	segments []segment
+}
+
+// segment is a code block of the grammar and its tokens in the generated
+// code, gen[i] is the token of the generated code starting at offset
+// src[i] of the code block.
+type segment struct {
+	code *ast.CodeBlock
+	gen  []tok
+	src  []int
+	end  int // offset in the generated code of the end of the segment
+}
+
+// tok is a token, without the semicolons, that formatting may add or
+// remove.
+type tok struct {
+	kind token.Token
+	off  int
+	len  int
+}
+
+// NewSourceMap returns the source map of the parser src generated from
+// the grammar read from filename. init is the init code block of the
+// grammar, funcs the code blocks of the generated functions, by function
+// name, as recorded by the builder.CodeFuncs option.
+func NewSourceMap(filename string, src []byte, init *ast.CodeBlock, funcs map[string]*ast.CodeBlock) *SourceMap {
+	m := &SourceMap{filename: filename}
+	gen := scan(src, 0)
+
+	if init != nil {
+		// the imports of the init code block are rewritten by goimports
+		codeToks := withoutImports(scan([]byte(inner(init)), 1))
+		genToks := withoutImports(gen)
+		m.add(init, genToks, codeToks)
+	}
+
+	for name, code := range funcs {
+		i := bytes.Index(src, []byte("func (p *parser) "+name+"("))
+		if i < 0 {
+			continue
+		}
+		// the code is the body of the function literal, the second
+		// function of the generated code
+		toks := gen[sort.Search(len(gen), func(j int) bool { return gen[j].off >= i }):]
+		n := 0
+		for len(toks) > 0 && n < 2 {
+			if toks[0].kind == token.FUNC {
+				n++
+			}
+			toks = toks[1:]
+		}
+		for len(toks) > 0 && toks[0].kind != token.LBRACE {
+			toks = toks[1:]
+		}
+		if len(toks) == 0 {
+			continue
+		}
+		body := toks[1:]
+		codeToks := scan([]byte(inner(code)), 1)
+		if len(codeToks) > len(body) {
+			codeToks = codeToks[:len(body)]
+		}
+
+		// the closing brace of the function literal is the closing brace
+		// of the code block, e.g. for a missing return
+		depth := 0
+		for _, t := range toks {
+			switch t.kind {
+			case token.LBRACE:
+				depth++
+			case token.RBRACE:
+				depth--
+			}
+			if depth == 0 {
+				body = append(body[:len(codeToks):len(codeToks)], t)
+				codeToks = append(codeToks, tok{kind: token.RBRACE, off: strings.LastIndexByte(code.Val, '}'), len: 1})
+				break
+			}
+		}
+		m.add(code, body, codeToks)
+	}
+
+	sort.Slice(m.segments, func(i, j int) bool { return m.segments[i].gen[0].off < m.segments[j].gen[0].off })
+	return m
+}
+
+// add adds the segment of the code block, the tokens are matched by rank.
+func (m *SourceMap) add(code *ast.CodeBlock, gen, src []tok) {
+	n := len(src)
+	if len(gen) < n {
+		n = len(gen)
+	}
+	if n == 0 {
+		return
+	}
+	seg := segment{code: code, end: gen[n-1].off + gen[n-1].len}
+	for i := 0; i < n; i++ {
+		seg.gen = append(seg.gen, gen[i])
+		seg.src = append(seg.src, src[i].off)
+	}
+	m.segments = append(m.segments, seg)
+}
+
+// Position returns the position in the grammar of the byte offset of the
+// generated code, false if it is not in a code block.
+func (m *SourceMap) Position(off int) (token.Position, bool) {
+	for _, seg := range m.segments {
+		if off < seg.gen[0].off || off >= seg.end {
+			continue
+		}
+		i := sort.Search(len(seg.gen), func(i int) bool { return seg.gen[i].off > off }) - 1
+		// between two tokens, e.g. at a semicolon, the offset is kept
+		// if it is before the next token of the code block
+		codeOff := seg.src[i] + off - seg.gen[i].off
+		if i+1 < len(seg.src) && codeOff >= seg.src[i+1] || codeOff >= len(seg.code.Val) {
+			codeOff = seg.src[i]
+		}
+		return m.codePosition(seg.code, codeOff), true
+	}
+	return token.Position{}, false
+}
+
+// codePosition returns the position in the grammar of the byte offset of
+// the code block, the columns are counted in runes as in the grammar.
+func (m *SourceMap) codePosition(code *ast.CodeBlock, off int) token.Position {
+	pos := code.Pos()
+	p := token.Position{Filename: m.filename, Offset: pos.Off + off, Line: pos.Line, Column: pos.Col}
+	val := code.Val[:off]
+	if i := strings.LastIndexByte(val, '\n'); i >= 0 {
+		p.Line += strings.Count(val, "\n")
+		p.Column = 1
+		val = val[i+1:]
+	}
+	p.Column += utf8.RuneCountInString(val)
+	return p
+}
+
+// inner returns the code of the code block without its braces, at offset
+// 1 of the code block.
+func inner(code *ast.CodeBlock) string {
+	end := strings.LastIndexByte(code.Val, '}')
+	if end < 1 || code.Val[0] != '{' {
+		return ""
+	}
+	return code.Val[1:end]
+}
+
+// scan returns the tokens of src, the offsets start at base. The errors
+// are ignored, the code blocks are not necessarily valid.
+func scan(src []byte, base int) []tok {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, src, func(token.Position, string) {}, 0)
+
+	var toks []tok
+	for {
+		pos, kind, lit := s.Scan()
+		if kind == token.EOF {
+			return toks
+		}
+		if kind == token.SEMICOLON {
+			continue
+		}
+		n := len(lit)
+		if n == 0 {
+			n = len(kind.String())
+		}
+		toks = append(toks, tok{kind: kind, off: base + file.Offset(pos), len: n})
+	}
+}
+
+// withoutImports returns the tokens without the import declarations.
+func withoutImports(toks []tok) []tok {
+	var res []tok
+	for i := 0; i < len(toks); i++ {
+		if toks[i].kind != token.IMPORT {
+			res = append(res, toks[i])
+			continue
+		}
+		end := token.STRING
+		if i+1 < len(toks) && toks[i+1].kind == token.LPAREN {
+			end = token.RPAREN
+		}
+		for i+1 < len(toks) && toks[i+1].kind != end {
+			i++
+		}
+		i++
+	}
+	return res
+}
@@ -0,0 +1,695 @@
+// Package interp runs a PEG grammar directly on an input, without
+// generating its parser. The code blocks are not run: the actions and the
+// code expressions have no value and the predicates always succeed.
+package interp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/builder"
+)
+
+// Position is a position in the input, the columns are counted in runes.
+type Position struct {
+	Line   int `json:"line"`
+	Col    int `json:"col"`
+	Offset int `json:"offset"`
+}
+
+// String returns the position as LINE:COL (OFFSET).
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d (%d)", p.Line, p.Col, p.Offset)
+}
+
+// Node is a match of a rule, with the matches of the rules it references.
+type Node struct {
+	Rule     string   `json:"rule"`
+	Start    Position `json:"start"`
+	End      Position `json:"end"`
+	Children []*Node  `json:"children,omitempty"`
+}
+
+// Failure is the farthest position at which a terminal of the grammar
+// failed to match, with the terminals expected at this position.
+type Failure struct {
+	Pos      Position `json:"pos"`
+	Expected []string `json:"expected"`
+}
+
+// Error returns the failure as the error of a generated parser.
+func (f *Failure) Error() string {
+	return fmt.Sprintf("%s: no match found, expected: %s", f.Pos, listJoin(f.Expected, ", ", "or"))
+}
+
+// Result is the result of running a grammar.
+type Result struct {
+	// Match is the match of the entrypoint, nil if it does not match. It
+	// may not span the whole input.
+	Match *Node `json:"match"`
+	// Failure is the farthest failure, the error of the parser if the
+	// entrypoint does not match.
+	Failure *Failure `json:"failure"`
+}
+
+// Interpreter runs a grammar.
+type Interpreter struct {
+	// Memoize memoizes the results of all the rules, not only of the ones
+	// annotated with @memo, as the -cache flag of the generated parsers.
+	Memoize bool
+
+	// ASCIIFold folds the case of the ASCII letters only in the
+	// case-insensitive matchers, as the -ascii-fold flag of the generator.
+	// It is set from the options block of the grammar by New.
+	ASCIIFold bool
+
+	grammar *ast.Grammar
+	rules   map[string]*ast.Rule
+	classes map[*ast.CharClassMatcher][]*unicode.RangeTable
+}
+
+// New returns an interpreter of the grammar. The grammar is resolved as
+// for the generation of its parser, see builder.Resolve, and is modified.
+func New(grammar *ast.Grammar) (*Interpreter, error) {
+	opts, err := builder.GrammarOptions(grammar)
+	if err != nil {
+		return nil, err
+	}
+	// the builder options of the grammar that apply to the interpreter
+	var b builder.Builder
+	b.SetOptions(opts)
+	if err := builder.Resolve(grammar); err != nil {
+		return nil, err
+	}
+
+	in := &Interpreter{
+		ASCIIFold: b.ASCIIFold,
+		grammar:   grammar,
+		rules:     make(map[string]*ast.Rule, len(grammar.Rules)),
+		classes:   make(map[*ast.CharClassMatcher][]*unicode.RangeTable),
+	}
+	for _, rule := range grammar.Rules {
+		if _, ok := in.rules[rule.Name.Val]; !ok {
+			in.rules[rule.Name.Val] = rule
+		}
+	}
+
+	ast.Inspect(grammar, func(expr ast.Expression) bool {
+		chr, ok := expr.(*ast.CharClassMatcher)
+		if !ok || err != nil {
+			return err == nil
+		}
+		var tables []*unicode.RangeTable
+		for _, name := range chr.UnicodeClasses {
+			table := unicodeClass(name)
+			if table == nil {
+				err = fmt.Errorf("%s: unknown Unicode class %q", chr.Pos(), name)
+				return false
+			}
+			tables = append(tables, table)
+		}
+		if chr.Table != nil {
+			tables = append(tables, chr.Table)
+		}
+		in.classes[chr] = tables
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// unicodeClass returns the Unicode category, script or property named
+// name, nil if there is none.
+func unicodeClass(name string) *unicode.RangeTable {
+	if table, ok := unicode.Categories[name]; ok {
+		return table
+	}
+	if table, ok := unicode.Scripts[name]; ok {
+		return table
+	}
+	return unicode.Properties[name]
+}
+
+// Run matches the rule entrypoint, the first rule of the grammar if
+// empty, at the start of input.
+func (in *Interpreter) Run(entrypoint string, input []byte) (*Result, error) {
+	if len(in.grammar.Rules) == 0 {
+		return nil, errors.New("grammar has no rule")
+	}
+	if entrypoint == "" {
+		entrypoint = in.grammar.Rules[0].Name.Val
+	}
+	rule, ok := in.rules[entrypoint]
+	if !ok {
+		return nil, fmt.Errorf("invalid entrypoint %q", entrypoint)
+	}
+
+	r := &run{in: in, input: input}
+	var res Result
+	if r.rule(rule) {
+		res.Match = r.nodes[0]
+	}
+	r.setPositions(res.Match)
+	res.Failure = r.failure()
+	return &res, nil
+}
+
+// run is the state of a run of the interpreter, the functions matching
+// the expressions follow the ones of the generated parsers.
+type run struct {
+	in    *Interpreter
+	input []byte
+	pos   int
+
+	// nodes are the matches of the rules referenced by the current rule
+	nodes []*Node
+	// vars are the text captured by the labels of the back-references,
+	// for each rule being matched
+	vars []map[string]string
+	// recovery are the recovery expressions by failure label
+	recovery []map[string]ast.Expression
+	// memo are the matches of the memoized rules, nil if they do not
+	// match
+	memo map[memoKey]*Node
+	// lines are the offsets of the start of the lines
+	lines []int
+
+	maxFailPos            int
+	maxFailExpected       []string
+	maxFailInvertExpected bool
+	maxFailSuppressed     int
+}
+
+type memoKey struct {
+	rule *ast.Rule
+	pos  int
+}
+
+// rune returns the rune at the current position and its width, 0 at the
+// end of the input.
+func (r *run) rune() (rune, int) {
+	return utf8.DecodeRune(r.input[r.pos:])
+}
+
+func (r *run) failAt(fail bool, pos int, want string) {
+	if fail != r.maxFailInvertExpected || r.maxFailSuppressed > 0 || pos < r.maxFailPos {
+		return
+	}
+	if pos > r.maxFailPos {
+		r.maxFailPos = pos
+		r.maxFailExpected = r.maxFailExpected[:0]
+	}
+	if r.maxFailInvertExpected {
+		want = "!" + want
+	}
+	r.maxFailExpected = append(r.maxFailExpected, want)
+}
+
+// failure returns the farthest failure, the expected terminals are sorted
+// and the end of the input is last.
+func (r *run) failure() *Failure {
+	seen := make(map[string]bool, len(r.maxFailExpected))
+	var expected []string
+	eof := false
+	for _, want := range r.maxFailExpected {
+		switch {
+		case want == "!.":
+			eof = true
+		case !seen[want]:
+			seen[want] = true
+			expected = append(expected, want)
+		}
+	}
+	sort.Strings(expected)
+	if eof {
+		expected = append(expected, "EOF")
+	}
+	return &Failure{Pos: r.position(r.maxFailPos), Expected: expected}
+}
+
+// position returns the position of the offset, as counted by the
+// generated parsers.
+func (r *run) position(off int) Position {
+	if r.lines == nil {
+		r.lines = []int{0}
+		for i, b := range r.input {
+			if b == '\n' {
+				r.lines = append(r.lines, i+1)
+			}
+		}
+	}
+	line := sort.SearchInts(r.lines, off+1) - 1
+	col := utf8.RuneCount(r.input[r.lines[line]:off]) + 1
+	return Position{Line: line + 1, Col: col, Offset: off}
+}
+
+// setPositions sets the line and column of the positions of the nodes,
+// which only have their offset while running.
+func (r *run) setPositions(node *Node) {
+	if node == nil {
+		return
+	}
+	node.Start = r.position(node.Start.Offset)
+	node.End = r.position(node.End.Offset)
+	for _, child := range node.Children {
+		r.setPositions(child)
+	}
+}
+
+func (r *run) memoized(rule *ast.Rule) bool {
+	if r.in.Memoize {
+		return !rule.HasAnnotation(builder.NoMemoAnnotation)
+	}
+	return rule.HasAnnotation(builder.MemoAnnotation)
+}
+
+// rule matches the rule, its node is appended to the nodes of the
+// current rule.
+func (r *run) rule(rule *ast.Rule) bool {
+	if !r.memoized(rule) {
+		return r.ruleMatch(rule)
+	}
+
+	key := memoKey{rule, r.pos}
+	if node, ok := r.memo[key]; ok {
+		if node == nil {
+			return false
+		}
+		r.pos = node.End.Offset
+		r.nodes = append(r.nodes, node)
+		return true
+	}
+	if r.memo == nil {
+		r.memo = make(map[memoKey]*Node)
+	}
+	ok := r.ruleMatch(rule)
+	r.memo[key] = nil
+	if ok {
+		r.memo[key] = r.nodes[len(r.nodes)-1]
+	}
+	return ok
+}
+
+func (r *run) ruleMatch(rule *ast.Rule) bool {
+	start := r.pos
+	nodes := r.nodes
+	r.nodes = nil
+	r.vars = append(r.vars, nil)
+	ok := r.expr(rule.Expr)
+	r.vars = r.vars[:len(r.vars)-1]
+	node := &Node{Rule: rule.Name.Val, Start: Position{Offset: start}, End: Position{Offset: r.pos}, Children: r.nodes}
+	r.nodes = nodes
+	if ok {
+		r.nodes = append(r.nodes, node)
+	}
+	return ok
+}
+
+// expr matches the expression, the position and the nodes are restored
+// if it does not match.
+func (r *run) expr(expr ast.Expression) bool {
+	pos, n := r.pos, len(r.nodes)
+	if ok := r.match(expr); ok {
+		return true
+	}
+	r.pos, r.nodes = pos, r.nodes[:n]
+	return false
+}
+
+// lookahead matches the expression without consuming the input nor
+// keeping the nodes, it returns whether it matched and the end of the
+// match.
+func (r *run) lookahead(expr ast.Expression) (bool, int) {
+	pos, n := r.pos, len(r.nodes)
+	ok := r.expr(expr)
+	end := r.pos
+	r.pos, r.nodes = pos, r.nodes[:n]
+	return ok, end
+}
+
+func (r *run) match(expr ast.Expression) bool {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		return r.expr(expr.Expr)
+	case *ast.AndCodeExpr, *ast.NotCodeExpr, *ast.CodeExpr:
+		return true
+	case *ast.AndExpr:
+		ok, end := r.lookahead(expr.Expr)
+		if expr.Logical {
+			return ok && end != r.pos
+		}
+		return ok
+	case *ast.NotExpr:
+		r.maxFailInvertExpected = !r.maxFailInvertExpected
+		ok, end := r.lookahead(expr.Expr)
+		r.maxFailInvertExpected = !r.maxFailInvertExpected
+		if expr.Logical {
+			return !ok && end != r.pos
+		}
+		return !ok
+	case *ast.AnyMatcher:
+		if _, w := r.rune(); w == 0 {
+			r.failAt(false, r.pos, ".")
+			return false
+		}
+		r.failAt(true, r.pos, ".")
+		_, w := r.rune()
+		r.pos += w
+		return true
+	case *ast.BackRefExpr:
+		want, ok := r.vars[len(r.vars)-1][expr.Label.Val]
+		if !ok {
+			return false
+		}
+		if !bytes.HasPrefix(r.input[r.pos:], []byte(want)) {
+			r.failAt(false, r.pos, strconv.Quote(want))
+			return false
+		}
+		r.failAt(true, r.pos, strconv.Quote(want))
+		r.pos += len(want)
+		return true
+	case *ast.CharClassMatcher:
+		return r.charClass(expr)
+	case *ast.ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			if r.expr(alt) {
+				return true
+			}
+		}
+		return false
+	case *ast.LabeledExpr:
+		start := r.pos
+		if !r.expr(expr.Expr) {
+			return false
+		}
+		if expr.TextCapture && expr.BackRef {
+			vars := r.vars[len(r.vars)-1]
+			if vars == nil {
+				vars = make(map[string]string)
+				r.vars[len(r.vars)-1] = vars
+			}
+			vars[expr.Label.Val] = string(r.input[start:r.pos])
+		}
+		return true
+	case *ast.LitMatcher:
+		return r.lit(expr)
+	case *ast.OneOrMoreExpr:
+		return r.repeat(expr.Expr, 1, -1)
+	case *ast.PrecedenceExpr:
+		return r.precedence(expr, 0)
+	case *ast.RecoveryExpr:
+		labels := make(map[string]ast.Expression, len(expr.Labels))
+		for _, label := range expr.Labels {
+			labels[string(label)] = expr.RecoverExpr
+		}
+		r.recovery = append(r.recovery, labels)
+		ok := r.expr(expr.Expr)
+		r.recovery = r.recovery[:len(r.recovery)-1]
+		return ok
+	case *ast.RepeatExpr:
+		return r.repeat(expr.Expr, expr.Min, expr.Max)
+	case *ast.RuleRefExpr:
+		return r.rule(r.in.rules[expr.Name.Val])
+	case *ast.SeparatedExpr:
+		return r.separated(expr)
+	case *ast.SeqExpr:
+		for _, e := range expr.Exprs {
+			if !r.expr(e) {
+				return false
+			}
+		}
+		return true
+	case *ast.ThrowExpr:
+		for i := len(r.recovery) - 1; i >= 0; i-- {
+			if recoverExpr, ok := r.recovery[i][expr.Label]; ok && r.expr(recoverExpr) {
+				return true
+			}
+		}
+		return false
+	case *ast.TriviaExpr:
+		return r.expr(expr.Trivia) && r.expr(expr.Expr)
+	case *ast.ZeroOrMoreExpr:
+		return r.repeat(expr.Expr, 0, -1)
+	case *ast.ZeroOrOneExpr:
+		r.expr(expr.Expr)
+		return true
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+}
+
+func (r *run) charClass(chr *ast.CharClassMatcher) bool {
+	cur, w := r.rune()
+	if w == 0 {
+		r.failAt(false, r.pos, chr.Val)
+		return false
+	}
+
+	matched := r.classMatch(chr, cur)
+	if !matched && chr.IgnoreCase {
+		if r.in.ASCIIFold {
+			if f := asciiSwapCase(cur); f != cur {
+				matched = r.classMatch(chr, f)
+			}
+		} else {
+			for f := unicode.SimpleFold(cur); f != cur && !matched; f = unicode.SimpleFold(f) {
+				matched = r.classMatch(chr, f)
+			}
+		}
+	}
+	if matched == chr.Inverted {
+		r.failAt(false, r.pos, chr.Val)
+		return false
+	}
+	r.failAt(true, r.pos, chr.Val)
+	r.pos += w
+	return true
+}
+
+// classMatch returns true if rn is one of the chars, ranges or Unicode
+// classes of the matcher, regardless of the inversion.
+func (r *run) classMatch(chr *ast.CharClassMatcher, rn rune) bool {
+	for _, c := range chr.Chars {
+		if c == rn {
+			return true
+		}
+	}
+	for i := 0; i+1 < len(chr.Ranges); i += 2 {
+		if rn >= chr.Ranges[i] && rn <= chr.Ranges[i+1] {
+			return true
+		}
+	}
+	for _, table := range r.in.classes[chr] {
+		if unicode.Is(table, rn) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *run) lit(lit *ast.LitMatcher) bool {
+	start := r.pos
+	want := strconv.Quote(lit.Val)
+	if lit.IgnoreCase {
+		want += "i"
+	}
+	for _, rn := range lit.Val {
+		cur, w := r.rune()
+		if lit.IgnoreCase {
+			cur, rn = r.in.foldRune(cur), r.in.foldRune(rn)
+		}
+		if w == 0 || cur != rn {
+			r.failAt(false, start, want)
+			r.pos = start
+			return false
+		}
+		r.pos += w
+	}
+	if lit.Boundary != nil {
+		r.maxFailSuppressed++
+		ok, _ := r.lookahead(lit.Boundary)
+		r.maxFailSuppressed--
+		if ok {
+			r.failAt(false, start, want)
+			r.pos = start
+			return false
+		}
+	}
+	r.failAt(true, start, want)
+	return true
+}
+
+// repeat matches expr at least min times and at most max times, without
+// limit if max is negative.
+func (r *run) repeat(expr ast.Expression, min, max int) bool {
+	cnt := 0
+	for max < 0 || cnt < max {
+		pos := r.pos
+		if !r.expr(expr) {
+			break
+		}
+		cnt++
+		if r.pos == pos {
+			// the expression matched without consuming any input, it
+			// would match forever, so it would also reach the minimum
+			if max < 0 && cnt < min {
+				cnt = min
+			}
+			if max < 0 {
+				break
+			}
+		}
+	}
+	return cnt >= min
+}
+
+func (r *run) separated(expr *ast.SeparatedExpr) bool {
+	if !r.expr(expr.Expr) {
+		return expr.Min == 0
+	}
+	for {
+		pos, n := r.pos, len(r.nodes)
+		if !r.expr(expr.Sep) {
+			break
+		}
+		if !r.expr(expr.Expr) {
+			if !expr.AllowTrailing {
+				// the separator is not part of the match
+				r.pos, r.nodes = pos, r.nodes[:n]
+			}
+			break
+		}
+		if r.pos == pos {
+			break
+		}
+	}
+	return true
+}
+
+// precedenceOp is an operator of a precedence expression with its
+// precedence, the index of its level starting at 1.
+type precedenceOp struct {
+	op   *ast.Operator
+	prec int
+	kind ast.OperatorKind
+}
+
+func operators(expr *ast.PrecedenceExpr, kinds ...ast.OperatorKind) []precedenceOp {
+	var ops []precedenceOp
+	for i, level := range expr.Levels {
+		for _, kind := range kinds {
+			if level.Kind != kind {
+				continue
+			}
+			for _, op := range level.Operators {
+				ops = append(ops, precedenceOp{op: op, prec: i + 1, kind: kind})
+			}
+		}
+	}
+	return ops
+}
+
+// precedence matches an operand followed by the postfix and infix
+// operators with a precedence of at least minPrec.
+func (r *run) precedence(expr *ast.PrecedenceExpr, minPrec int) bool {
+	if !r.precedenceOperand(expr) {
+		return false
+	}
+
+loop:
+	for {
+		for _, op := range operators(expr, ast.Postfix) {
+			if op.prec >= minPrec && r.expr(op.op.Expr) {
+				continue loop
+			}
+		}
+		for _, op := range operators(expr, ast.LeftAssoc, ast.RightAssoc) {
+			if op.prec < minPrec {
+				continue
+			}
+			pos, n := r.pos, len(r.nodes)
+			if !r.expr(op.op.Expr) {
+				continue
+			}
+			next := op.prec + 1
+			if op.kind == ast.RightAssoc {
+				next = op.prec
+			}
+			if !r.precedence(expr, next) {
+				// the operator is not part of the match
+				r.pos, r.nodes = pos, r.nodes[:n]
+				continue
+			}
+			continue loop
+		}
+		return true
+	}
+}
+
+// precedenceOperand matches either a prefix operator applied to its
+// operand or the atom of the precedence expression.
+func (r *run) precedenceOperand(expr *ast.PrecedenceExpr) bool {
+	for _, op := range operators(expr, ast.Prefix) {
+		pos, n := r.pos, len(r.nodes)
+		if !r.expr(op.op.Expr) {
+			continue
+		}
+		if !r.precedence(expr, op.prec) {
+			r.pos, r.nodes = pos, r.nodes[:n]
+			continue
+		}
+		return true
+	}
+	return r.expr(expr.Atom)
+}
+
+// foldRune returns the smallest rune of the case folding orbit of rn, or
+// the lowercase of rn if it is an ASCII letter and ASCIIFold is set.
+func (in *Interpreter) foldRune(rn rune) rune {
+	if in.ASCIIFold {
+		if 'A' <= rn && rn <= 'Z' {
+			rn += 'a' - 'A'
+		}
+		return rn
+	}
+
+	min := rn
+	for f := unicode.SimpleFold(rn); f != rn; f = unicode.SimpleFold(f) {
+		if f < min {
+			min = f
+		}
+	}
+	return min
+}
+
+// asciiSwapCase returns the other case of rn if it is an ASCII letter,
+// rn otherwise.
+func asciiSwapCase(rn rune) rune {
+	switch {
+	case 'a' <= rn && rn <= 'z':
+		return rn - 'a' + 'A'
+	case 'A' <= rn && rn <= 'Z':
+		return rn - 'A' + 'a'
+	}
+	return rn
+}
+
+func listJoin(list []string, sep string, lastSep string) string {
+	switch len(list) {
+	case 0:
+		return ""
+	case 1:
+		return list[0]
+	default:
+		return strings.Join(list[:len(list)-1], sep) + " " + lastSep + " " + list[len(list)-1]
+	}
+}
@@ -0,0 +1,163 @@
+package interp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/interp"
+)
+
+func newInterpreter(t *testing.T, text string) *interp.Interpreter {
+	t.Helper()
+
+	grammar, err := bootstrap.NewParser().Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	in, err := interp.New(grammar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return in
+}
+
+// tree returns the nodes of the match as RULE START-END lines, indented by
+// depth.
+func tree(node *interp.Node, depth int) string {
+	if node == nil {
+		return ""
+	}
+	s := strings.Repeat(" ", depth) + node.Rule + " " + node.Start.String() + "-" + node.End.String() + "\n"
+	for _, child := range node.Children {
+		s += tree(child, depth+1)
+	}
+	return s
+}
+
+const listGrammar = `
+Start <- List !.
+List <- '[' _ items:(Item (',' _ Item)*)? ']' _ { return nil, nil }
+Item <- Number / List
+Number <- [0-9]+ _
+_ <- [ \n]*
+`
+
+func TestRunMatch(t *testing.T) {
+	t.Parallel()
+
+	in := newInterpreter(t, listGrammar)
+	res, err := in.Run("", []byte("[1, [2]\n, 34]"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Start 1:1 (0)-2:6 (13)
+ List 1:1 (0)-2:6 (13)
+  _ 1:2 (1)-1:2 (1)
+  Item 1:2 (1)-1:3 (2)
+   Number 1:2 (1)-1:3 (2)
+    _ 1:3 (2)-1:3 (2)
+  _ 1:4 (3)-1:5 (4)
+  Item 1:5 (4)-2:1 (8)
+   List 1:5 (4)-2:1 (8)
+    _ 1:6 (5)-1:6 (5)
+    Item 1:6 (5)-1:7 (6)
+     Number 1:6 (5)-1:7 (6)
+      _ 1:7 (6)-1:7 (6)
+    _ 1:8 (7)-2:1 (8)
+  _ 2:2 (9)-2:3 (10)
+  Item 2:3 (10)-2:5 (12)
+   Number 2:3 (10)-2:5 (12)
+    _ 2:5 (12)-2:5 (12)
+  _ 2:6 (13)-2:6 (13)
+`
+	if got := tree(res.Match, 0); got != want {
+		t.Errorf("want tree:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestRunNoMatch(t *testing.T) {
+	t.Parallel()
+
+	in := newInterpreter(t, listGrammar)
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"", `1:1 (0): no match found, expected: "["`},
+		{"[1 2]", `1:4 (3): no match found, expected: ",", "]" or [ \n]`},
+		{"[1,]", `1:4 (3): no match found, expected: "[", [ \n] or [0-9]`},
+		{"[1] x", `1:5 (4): no match found, expected: [ \n] or EOF`},
+	}
+	for _, tc := range cases {
+		res, err := in.Run("", []byte(tc.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Match != nil {
+			t.Errorf("%q: want no match", tc.input)
+		}
+		if got := res.Failure.Error(); got != tc.want {
+			t.Errorf("%q: want failure %q, got %q", tc.input, tc.want, got)
+		}
+	}
+}
+
+func TestRunEntrypoint(t *testing.T) {
+	t.Parallel()
+
+	in := newInterpreter(t, listGrammar)
+	res, err := in.Run("Number", []byte("12 x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Match == nil || res.Match.End.Offset != 3 {
+		t.Errorf("want a match up to offset 3, got %+v", res.Match)
+	}
+	if _, err := in.Run("Missing", nil); err == nil {
+		t.Error("want an invalid entrypoint error")
+	}
+}
+
+func TestRunASCIIFold(t *testing.T) {
+	t.Parallel()
+
+	const kelvin = "\u212a"
+	text := `
+Lit <- "k"i !.
+Class <- [k]i !.
+`
+	for _, asciiFold := range []bool{false, true} {
+		grammar, err := bootstrap.NewParser().Parse("", strings.NewReader(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if asciiFold {
+			opt := ast.NewOption(ast.Pos{}, ast.NewIdentifier(ast.Pos{}, "ascii-fold"))
+			opt.Values = []string{"true"}
+			grammar.Options = append(grammar.Options, opt)
+		}
+		in, err := interp.New(grammar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if in.ASCIIFold != asciiFold {
+			t.Errorf("want ASCIIFold %t, got %t", asciiFold, in.ASCIIFold)
+		}
+
+		for _, rule := range []string{"Lit", "Class"} {
+			for _, input := range []string{"k", "K", kelvin} {
+				res, err := in.Run(rule, []byte(input))
+				if err != nil {
+					t.Fatal(err)
+				}
+				// the Kelvin sign folds to k with the Unicode case folding only
+				want := input != kelvin || !asciiFold
+				if got := res.Match != nil; got != want {
+					t.Errorf("ascii-fold %t: %s %q: want match %t, got %t", asciiFold, rule, input, want, got)
+				}
+			}
+		}
+	}
+}
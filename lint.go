@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/lint"
+)
+
+// jsonDiagnostic is the JSON representation of a lint diagnostic.
+type jsonDiagnostic struct {
+	File     string        `json:"file"`
+	Line     int           `json:"line"`
+	Col      int           `json:"col"`
+	Severity lint.Severity `json:"severity"`
+	Check    string        `json:"check"`
+	Message  string        `json:"message"`
+}
+
+// lintMain implements the lint command, it prints the diagnostics of the
+// static checks of the grammar.
+func lintMain(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" lint", flag.ExitOnError)
+
+	var (
+		shortHelpFlag = fs.Bool("h", false, "show help page")
+		longHelpFlag  = fs.Bool("help", false, "show help page")
+		jsonFlag      = fs.Bool("json", false, "print the diagnostics as JSON")
+
+		altEntrypointsFlag ruleNamesFlag
+	)
+	fs.Var(&altEntrypointsFlag, "alternate-entrypoints", "comma-separated list of rule names that may be used as entrypoints")
+
+	usage := func() {
+		fmt.Printf(lintUsagePage, os.Args[0])
+	}
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, "args parse error:\n", err)
+		exit(6)
+	}
+
+	if *shortHelpFlag || *longHelpFlag {
+		usage()
+		exit(0)
+	}
+
+	if fs.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "expected one argument, got %q\n", strings.Join(fs.Args(), " "))
+		usage()
+		exit(1)
+	}
+
+	nm, grammar := parseGrammar(fs.Arg(0))
+	diags := lint.Lint(grammar, altEntrypointsFlag...)
+
+	if *jsonFlag {
+		out := make([]jsonDiagnostic, 0, len(diags))
+		for _, d := range diags {
+			out = append(out, jsonDiagnostic{
+				File:     nm,
+				Line:     d.Pos.Line,
+				Col:      d.Pos.Col,
+				Severity: d.Severity,
+				Check:    d.Check,
+				Message:  d.Message,
+			})
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(out); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:\n", err)
+			exit(7)
+		}
+	} else {
+		for _, d := range diags {
+			fmt.Printf("%s:%s\n", nm, d)
+		}
+	}
+
+	for _, d := range diags {
+		if d.Severity != lint.Info {
+			exit(1)
+		}
+	}
+}
+
+// parseGrammar parses the grammar read from filename, or from stdin if
+// filename is empty. It returns the name of the input and the grammar.
+func parseGrammar(filename string) (string, *ast.Grammar) {
+	nm, rc := input(filename)
+	defer rc.Close()
+
+	g, err := ParseReader(nm, rc)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "parse error(s):\n", err)
+		exit(3)
+	}
+	return nm, g.(*ast.Grammar)
+}
+
+var lintUsagePage = `usage: %s lint [options] [GRAMMAR_FILE]
+
+Lint reports the likely mistakes of a PEG grammar, one per line as
+FILE:LINE:COL: SEVERITY: MESSAGE (CHECK). The grammar is read from
+stdin if GRAMMAR_FILE is not specified. The checks are:
+
+	duplicate-rule
+		a rule is defined more than once.
+	unused-rule
+		a rule cannot be reached from the first rule, the alternate
+		entrypoints or the rules annotated with @entry, @trivia or @word.
+	unused-label
+		a label is used neither by a code block of its rule nor by a
+		back-reference.
+	single-use-rule
+		a rule is only referenced once and could be annotated with
+		@inline.
+	useless-display-name
+		a rule has a display name, but never fails, so the display name
+		never appears in an error message.
+	unrecovered-throw
+		a label is thrown, but no recovery expression handles it.
+	shadowed-alternative
+		an alternative of a choice can never match, as an earlier
+		alternative always matches or only matches a literal that is
+		a prefix of the literal the alternative starts with.
+
+The exit code is 1 if a diagnostic other than single-use-rule, which is
+only informative, is reported.
+
+	-alternate-entrypoints RULE[,RULE...]
+		comma-separated list of rule names that may be used as
+		entrypoints, in addition to the ones of the grammar options.
+	-h -help
+		display this help message.
+	-json
+		print the diagnostics as a JSON array of objects with the
+		file, line, col, severity, check and message keys.
+`
@@ -0,0 +1,373 @@
+// Package lint implements static checks of a PEG grammar, to report the
+// rules, labels and annotations that are most likely mistakes.
+package lint
+
+import (
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"sort"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/builder"
+)
+
+// Severity is the severity of a diagnostic.
+type Severity string
+
+// List of severities, from the most to the least severe.
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Info    Severity = "info"
+)
+
+// Names of the checks, as reported in the diagnostics.
+const (
+	DuplicateRule       = "duplicate-rule"
+	UnusedRule          = "unused-rule"
+	UnusedLabel         = "unused-label"
+	SingleUseRule       = "single-use-rule"
+	UselessDisplayName  = "useless-display-name"
+	UnrecoveredThrow    = "unrecovered-throw"
+	ShadowedAlternative = "shadowed-alternative"
+)
+
+// Diagnostic is a problem reported by a check at a position of the
+// grammar.
+type Diagnostic struct {
+	Pos      ast.Pos
+	Severity Severity
+	Check    string
+	Message  string
+}
+
+// String returns the textual representation of the diagnostic.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s (%s)", d.Pos.Line, d.Pos.Col, d.Severity, d.Message, d.Check)
+}
+
+// Annotations of the rules that are referenced by the generated parser
+// even if the grammar does not reference them.
+var rootAnnotations = []string{
+	builder.EntryAnnotation,
+	builder.TriviaAnnotation,
+	builder.WordAnnotation,
+}
+
+// Annotations of the rules that must not be reported as candidates for
+// inlining.
+var noInlineAnnotations = []string{
+	builder.EntryAnnotation,
+	builder.InlineAnnotation,
+	builder.MemoAnnotation,
+	builder.TokenAnnotation,
+	builder.TriviaAnnotation,
+	builder.WordAnnotation,
+}
+
+// Lint runs all the checks on the grammar and returns the diagnostics
+// sorted by position. The first rule, the alternate entrypoints listed in
+// the options of the grammar or in entrypoints and the rules annotated
+// with @entry, @trivia or @word are the roots of the grammar.
+func Lint(grammar *ast.Grammar, entrypoints ...string) []Diagnostic {
+	l := newLinter(grammar, entrypoints)
+	l.duplicateRules()
+	l.unusedRules()
+	l.unusedLabels()
+	l.singleUseRules()
+	l.uselessDisplayNames()
+	l.unrecoveredThrows()
+	l.shadowedAlternatives()
+
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		return l.diags[i].Pos.Off < l.diags[j].Pos.Off
+	})
+	return l.diags
+}
+
+type linter struct {
+	grammar *ast.Grammar
+	rules   map[string]*ast.Rule
+	roots   map[string]bool
+	// refs lists the names of the rules referenced by each rule.
+	refs  map[string][]string
+	diags []Diagnostic
+}
+
+func newLinter(grammar *ast.Grammar, entrypoints []string) *linter {
+	l := &linter{
+		grammar: grammar,
+		rules:   make(map[string]*ast.Rule, len(grammar.Rules)),
+		roots:   make(map[string]bool),
+		refs:    make(map[string][]string, len(grammar.Rules)),
+	}
+	for _, rule := range grammar.Rules {
+		if _, ok := l.rules[rule.Name.Val]; !ok {
+			l.rules[rule.Name.Val] = rule
+		}
+		ast.Inspect(rule.Expr, func(expr ast.Expression) bool {
+			if ref, ok := expr.(*ast.RuleRefExpr); ok {
+				l.refs[rule.Name.Val] = append(l.refs[rule.Name.Val], ref.Name.Val)
+			}
+			return true
+		})
+		for _, name := range rootAnnotations {
+			if rule.HasAnnotation(name) {
+				l.roots[rule.Name.Val] = true
+			}
+		}
+	}
+	if len(grammar.Rules) > 0 {
+		l.roots[grammar.Rules[0].Name.Val] = true
+	}
+	for _, opt := range grammar.Options {
+		if opt.Name.Val == "alternate-entrypoints" {
+			entrypoints = append(entrypoints, opt.Values...)
+		}
+	}
+	for _, name := range entrypoints {
+		l.roots[name] = true
+	}
+	return l
+}
+
+func (l *linter) report(pos ast.Pos, sev Severity, check, format string, args ...any) {
+	l.diags = append(l.diags, Diagnostic{
+		Pos:      pos,
+		Severity: sev,
+		Check:    check,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// duplicateRules reports the rules defined more than once.
+func (l *linter) duplicateRules() {
+	for _, rule := range l.grammar.Rules {
+		first := l.rules[rule.Name.Val]
+		if first != rule {
+			l.report(rule.Pos(), Error, DuplicateRule, "rule %s already defined at %d:%d",
+				rule.Name.Val, first.Pos().Line, first.Pos().Col)
+		}
+	}
+}
+
+// unusedRules reports the rules that cannot be reached from the roots.
+func (l *linter) unusedRules() {
+	reached := make(map[string]bool, len(l.rules))
+	var visit func(name string)
+	visit = func(name string) {
+		if reached[name] {
+			return
+		}
+		reached[name] = true
+		for _, ref := range l.refs[name] {
+			visit(ref)
+		}
+	}
+	for name := range l.roots {
+		visit(name)
+	}
+
+	for _, rule := range l.grammar.Rules {
+		if !reached[rule.Name.Val] {
+			l.report(rule.Pos(), Warning, UnusedRule, "rule %s is never used", rule.Name.Val)
+			// report it once, even if it is defined more than once
+			reached[rule.Name.Val] = true
+		}
+	}
+}
+
+// unusedLabels reports the labels that are neither referenced by a code
+// block of their rule nor by a back-reference.
+func (l *linter) unusedLabels() {
+	for _, rule := range l.grammar.Rules {
+		var labels []*ast.LabeledExpr
+		used := make(map[string]bool)
+		useCode := func(code *ast.CodeBlock) {
+			if code != nil {
+				for _, ident := range codeIdents(code.Val) {
+					used[ident] = true
+				}
+			}
+		}
+		ast.Inspect(rule.Expr, func(expr ast.Expression) bool {
+			switch expr := expr.(type) {
+			case *ast.LabeledExpr:
+				labels = append(labels, expr)
+			case *ast.BackRefExpr:
+				used[expr.Label.Val] = true
+			case *ast.ActionExpr:
+				useCode(expr.Code)
+			case *ast.AndCodeExpr:
+				useCode(expr.Code)
+			case *ast.NotCodeExpr:
+				useCode(expr.Code)
+			case *ast.CodeExpr:
+				useCode(expr.Code)
+			case *ast.PrecedenceExpr:
+				for _, level := range expr.Levels {
+					for _, op := range level.Operators {
+						useCode(op.Code)
+					}
+				}
+			}
+			return true
+		})
+
+		for _, lab := range labels {
+			if !used[lab.Label.Val] {
+				l.report(lab.Pos(), Warning, UnusedLabel, "label %s of rule %s is never used",
+					lab.Label.Val, rule.Name.Val)
+			}
+		}
+	}
+}
+
+// codeIdents returns the identifiers of the Go code block.
+func codeIdents(code string) []string {
+	var s scanner.Scanner
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(code))
+	s.Init(file, []byte(code), nil, 0)
+
+	var idents []string
+	for {
+		_, tok, lit := s.Scan()
+		if tok == token.EOF {
+			return idents
+		}
+		if tok == token.IDENT {
+			idents = append(idents, lit)
+		}
+	}
+}
+
+// singleUseRules reports the rules referenced only once, by another rule,
+// that could be annotated with @inline.
+func (l *linter) singleUseRules() {
+	uses := make(map[string][]string)
+	for _, rule := range l.grammar.Rules {
+		for _, ref := range l.refs[rule.Name.Val] {
+			uses[ref] = append(uses[ref], rule.Name.Val)
+		}
+	}
+
+	for _, rule := range l.grammar.Rules {
+		name := rule.Name.Val
+		users := uses[name]
+		if len(users) != 1 || users[0] == name || l.roots[name] || l.rules[name] != rule {
+			continue
+		}
+		if rule.DisplayName != nil || hasAnnotation(rule, noInlineAnnotations) || hasLabel(rule) {
+			continue
+		}
+		l.report(rule.Pos(), Info, SingleUseRule, "rule %s is only used by rule %s and could be inlined",
+			name, users[0])
+	}
+}
+
+func hasAnnotation(rule *ast.Rule, names []string) bool {
+	for _, name := range names {
+		if rule.HasAnnotation(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasLabel(rule *ast.Rule) bool {
+	found := false
+	ast.Inspect(rule.Expr, func(expr ast.Expression) bool {
+		if _, ok := expr.(*ast.LabeledExpr); ok {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// uselessDisplayNames reports the display names of the rules that never
+// fail, as a display name is only used in the error messages.
+func (l *linter) uselessDisplayNames() {
+	for _, rule := range l.grammar.Rules {
+		if rule.DisplayName == nil {
+			continue
+		}
+		if l.neverFails(rule.Expr, make(map[string]bool)) {
+			l.report(rule.DisplayName.Pos(), Warning, UselessDisplayName,
+				"display name of rule %s is never shown, the rule never fails", rule.Name.Val)
+		}
+	}
+}
+
+// neverFails returns true if the expression matches any input. The rules
+// being visited are assumed to fail, to stop at recursive references.
+func (l *linter) neverFails(expr ast.Expression, visiting map[string]bool) bool {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		return l.neverFails(expr.Expr, visiting)
+	case *ast.ChoiceExpr:
+		for _, alt := range expr.Alternatives {
+			if l.neverFails(alt, visiting) {
+				return true
+			}
+		}
+		return false
+	case *ast.CodeExpr:
+		return true
+	case *ast.LabeledExpr:
+		return l.neverFails(expr.Expr, visiting)
+	case *ast.LitMatcher:
+		return expr.Val == ""
+	case *ast.RepeatExpr:
+		return expr.Min == 0 || l.neverFails(expr.Expr, visiting)
+	case *ast.RuleRefExpr:
+		rule := l.rules[expr.Name.Val]
+		if rule == nil || visiting[rule.Name.Val] {
+			return false
+		}
+		visiting[rule.Name.Val] = true
+		defer delete(visiting, rule.Name.Val)
+		return l.neverFails(rule.Expr, visiting)
+	case *ast.SeparatedExpr:
+		return expr.Min == 0 || l.neverFails(expr.Expr, visiting)
+	case *ast.SeqExpr:
+		for _, e := range expr.Exprs {
+			if !l.neverFails(e, visiting) {
+				return false
+			}
+		}
+		return true
+	case *ast.TriviaExpr:
+		return l.neverFails(expr.Trivia, visiting) && l.neverFails(expr.Expr, visiting)
+	case *ast.ZeroOrMoreExpr, *ast.ZeroOrOneExpr:
+		return true
+	}
+	return false
+}
+
+// unrecoveredThrows reports the throw expressions whose label is not
+// handled by any recovery expression of the grammar.
+func (l *linter) unrecoveredThrows() {
+	recovered := make(map[string]bool)
+	var throws []*ast.ThrowExpr
+	ast.Inspect(l.grammar, func(expr ast.Expression) bool {
+		switch expr := expr.(type) {
+		case *ast.RecoveryExpr:
+			for _, label := range expr.Labels {
+				recovered[string(label)] = true
+			}
+		case *ast.ThrowExpr:
+			throws = append(throws, expr)
+		}
+		return true
+	})
+
+	for _, throw := range throws {
+		if !recovered[throw.Label] {
+			l.report(throw.Pos(), Warning, UnrecoveredThrow,
+				"label %s is thrown but never recovered", throw.Label)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package lint_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/builder"
+	"github.com/oskoi/pigeon/lint"
+)
+
+func parseAnnotated(t *testing.T, text string, annotations map[string]string) *ast.Grammar {
+	t.Helper()
+
+	p := bootstrap.NewParser()
+	grammar, err := p.Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rule := range grammar.Rules {
+		if name, ok := annotations[rule.Name.Val]; ok {
+			rule.Annotations = append(rule.Annotations, ast.NewIdentifier(rule.Pos(), name))
+		}
+	}
+	return grammar
+}
+
+func messages(diags []lint.Diagnostic) string {
+	msgs := make([]string, len(diags))
+	for i, d := range diags {
+		msgs[i] = string(d.Severity) + " " + d.Check + ": " + d.Message
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name        string
+		text        string
+		annotations map[string]string
+		entrypoints []string
+		want        []string
+	}{
+		{
+			name: "clean",
+			text: `
+			start = a:expr b:expr { return a, b }
+			expr = [0-9]+ / "(" expr ")"
+			`,
+		},
+		{
+			name: "duplicate",
+			text: `
+			start = expr expr
+			expr = 'a'
+			expr = 'b'
+			`,
+			want: []string{
+				"error duplicate-rule: rule expr already defined at 3:4",
+			},
+		},
+		{
+			name: "unused",
+			text: `
+			start = a a
+			a = 'a'
+			b = c
+			c = b 'c'
+			d = 'd'
+			e = 'e'
+			`,
+			annotations: map[string]string{"e": builder.TriviaAnnotation},
+			entrypoints: []string{"d"},
+			want: []string{
+				"warning unused-rule: rule b is never used",
+				"warning unused-rule: rule c is never used",
+			},
+		},
+		{
+			name: "unused label",
+			text: `
+			start = a:'a' b:'b' c:'c' e:'e' { return c.(int) + a.(int) + b.(int), nil }
+			`,
+			want: []string{
+				"warning unused-label: label e of rule start is never used",
+			},
+		},
+		{
+			name: "unused label in other rule",
+			text: `
+			start = a:'a' x { return a, nil }
+			x = a:'x'
+			`,
+			want: []string{
+				"warning unused-label: label a of rule x is never used",
+			},
+		},
+		{
+			name: "single use",
+			text: `
+			start = a b c d b
+			a = 'a'
+			b = 'b'
+			c = 'c'
+			d "d" = 'd'
+			`,
+			annotations: map[string]string{"c": builder.MemoAnnotation},
+			want: []string{
+				"info single-use-rule: rule a is only used by rule start and could be inlined",
+			},
+		},
+		{
+			name: "useless display name",
+			text: `
+			start "start" = a b
+			a "a" = 'a'* ("b" / "")
+			b "b" = ( 'b' / c ) 'c'?
+			c "c" = b
+			`,
+			want: []string{
+				"warning useless-display-name: display name of rule a is never shown, the rule never fails",
+			},
+		},
+		{
+			name: "shadowed by prefix",
+			text: `
+			start = "<" / "<=" / ">" / "=" / "=="i / "a"i / "AB" / "b" / "Bc"i
+			`,
+			want: []string{
+				"warning shadowed-alternative: alternative 2 can never match, alternative 1 matches \"<\" first",
+				"warning shadowed-alternative: alternative 5 can never match, alternative 4 matches \"=\" first",
+				"warning shadowed-alternative: alternative 7 can never match, alternative 6 matches \"a\" first",
+			},
+		},
+		{
+			name: "shadowed through rules",
+			text: `
+			start = op / le / lt / ( "<<" ) { return nil, nil }
+			op = lt
+			lt = "<"
+			le = x:"<=" _ { return x, nil }
+			_ = " "*
+			`,
+			want: []string{
+				"warning shadowed-alternative: alternative 2 can never match, alternative 1 matches \"<\" first",
+				"warning shadowed-alternative: alternative 3 can never match, alternative 1 matches \"<\" first",
+				"warning shadowed-alternative: alternative 4 can never match, alternative 1 matches \"<\" first",
+			},
+		},
+		{
+			name: "shadowed by always matching",
+			text: `
+			start = ( "a" / "b"? / "c" / d ) ( "e" / "f" "g" / "f" )
+			d = "d"
+			`,
+			want: []string{
+				"warning shadowed-alternative: alternative 3 can never match, alternative 2 always matches",
+				"warning shadowed-alternative: alternative 4 can never match, alternative 2 always matches",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			grammar := parseAnnotated(t, tc.text, tc.annotations)
+			// the single-use check is tested on its own
+			var diags []lint.Diagnostic
+			for _, d := range lint.Lint(grammar, tc.entrypoints...) {
+				if d.Check != lint.SingleUseRule || tc.name == "single use" {
+					diags = append(diags, d)
+				}
+			}
+			if got, want := messages(diags), strings.Join(tc.want, "\n"); got != want {
+				t.Errorf("want diagnostics:\n%s\ngot:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestLintAlternateEntrypointsOption(t *testing.T) {
+	t.Parallel()
+
+	grammar := parseAnnotated(t, `
+	start = 'a'
+	other = 'b'
+	`, nil)
+	if got := messages(lint.Lint(grammar)); got != "warning unused-rule: rule other is never used" {
+		t.Errorf("want other to be unused, got:\n%s", got)
+	}
+
+	grammar.Options = append(grammar.Options, &ast.Option{
+		Name:   ast.NewIdentifier(ast.Pos{}, "alternate-entrypoints"),
+		Values: []string{"other"},
+	})
+	if got := messages(lint.Lint(grammar)); got != "" {
+		t.Errorf("want no diagnostic, got:\n%s", got)
+	}
+}
+
+func TestLintUnrecoveredThrow(t *testing.T) {
+	t.Parallel()
+
+	pos := func(off int) ast.Pos { return ast.Pos{Line: 1, Col: off + 1, Off: off} }
+	throw := func(off int, label string) *ast.ThrowExpr {
+		throw := ast.NewThrowExpr(pos(off))
+		throw.Label = label
+		return throw
+	}
+
+	recovery := ast.NewRecoveryExpr(pos(0))
+	recovery.Expr = &ast.SeqExpr{Exprs: []ast.Expression{throw(1, "handled"), throw(2, "lost")}}
+	recovery.RecoverExpr = ast.NewAnyMatcher(pos(3), ".")
+	recovery.Labels = []ast.FailureLabel{"handled", "other"}
+
+	rule := ast.NewRule(pos(0), ast.NewIdentifier(pos(0), "start"))
+	rule.Expr = recovery
+	grammar := ast.NewGrammar(pos(0))
+	grammar.Rules = []*ast.Rule{rule}
+
+	diags := lint.Lint(grammar)
+	if got, want := messages(diags), "warning unrecovered-throw: label lost is thrown but never recovered"; got != want {
+		t.Errorf("want diagnostics:\n%s\ngot:\n%s", want, got)
+	}
+	if len(diags) == 1 && diags[0].Pos != pos(2) {
+		t.Errorf("want position %s, got %s", pos(2), diags[0].Pos)
+	}
+}
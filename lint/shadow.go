@@ -0,0 +1,135 @@
+package lint
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// ShadowedAlternatives runs the shadowed-alternative check only, it is
+// cheap enough to run every time a parser is generated.
+func ShadowedAlternatives(grammar *ast.Grammar) []Diagnostic {
+	l := newLinter(grammar, nil)
+	l.shadowedAlternatives()
+	return l.diags
+}
+
+// shadowedAlternatives reports the alternatives of the choice expressions
+// that can never match because an earlier alternative always matches, or
+// because an earlier alternative is a literal that is a prefix of the
+// literal the alternative starts with, e.g. "<" / "<=". The literals are
+// also found through the references to rules that only match a literal.
+func (l *linter) shadowedAlternatives() {
+	ast.Inspect(l.grammar, func(expr ast.Expression) bool {
+		choice, ok := expr.(*ast.ChoiceExpr)
+		if !ok {
+			return true
+		}
+
+		always := -1
+		for j, alt := range choice.Alternatives {
+			if always >= 0 {
+				l.report(alt.Pos(), Warning, ShadowedAlternative,
+					"alternative %d can never match, alternative %d always matches", j+1, always+1)
+				continue
+			}
+			if lit := l.leadingLit(alt, make(map[string]bool)); lit != nil {
+				for i, prev := range choice.Alternatives[:j] {
+					if plit := l.onlyLit(prev, make(map[string]bool)); plit != nil && litPrefix(plit, lit) {
+						l.report(alt.Pos(), Warning, ShadowedAlternative,
+							"alternative %d can never match, alternative %d matches %s first",
+							j+1, i+1, strconv.Quote(plit.Val))
+						break
+					}
+				}
+			}
+			if l.neverFails(alt, make(map[string]bool)) {
+				always = j
+			}
+		}
+		return true
+	})
+}
+
+// onlyLit returns the literal matched by expr if expr matches exactly
+// this literal and nothing else, or nil.
+func (l *linter) onlyLit(expr ast.Expression, visiting map[string]bool) *ast.LitMatcher {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		return l.onlyLit(expr.Expr, visiting)
+	case *ast.LabeledExpr:
+		return l.onlyLit(expr.Expr, visiting)
+	case *ast.LitMatcher:
+		// a keyword literal fails if a word character follows it
+		if !expr.Keyword {
+			return expr
+		}
+	case *ast.RuleRefExpr:
+		if rule := l.ruleToFollow(expr, visiting); rule != nil {
+			defer delete(visiting, rule.Name.Val)
+			return l.onlyLit(rule.Expr, visiting)
+		}
+	case *ast.SeqExpr:
+		if len(expr.Exprs) == 1 {
+			return l.onlyLit(expr.Exprs[0], visiting)
+		}
+	}
+	return nil
+}
+
+// leadingLit returns the literal that every match of expr starts with, or
+// nil.
+func (l *linter) leadingLit(expr ast.Expression, visiting map[string]bool) *ast.LitMatcher {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		return l.leadingLit(expr.Expr, visiting)
+	case *ast.LabeledExpr:
+		return l.leadingLit(expr.Expr, visiting)
+	case *ast.LitMatcher:
+		return expr
+	case *ast.RuleRefExpr:
+		if rule := l.ruleToFollow(expr, visiting); rule != nil {
+			defer delete(visiting, rule.Name.Val)
+			return l.leadingLit(rule.Expr, visiting)
+		}
+	case *ast.SeqExpr:
+		if len(expr.Exprs) > 0 {
+			return l.leadingLit(expr.Exprs[0], visiting)
+		}
+	}
+	return nil
+}
+
+// ruleToFollow returns the rule referenced by ref and marks it as being
+// visited, or nil if the rule is undefined or already being visited.
+func (l *linter) ruleToFollow(ref *ast.RuleRefExpr, visiting map[string]bool) *ast.Rule {
+	rule := l.rules[ref.Name.Val]
+	if rule == nil || visiting[rule.Name.Val] {
+		return nil
+	}
+	visiting[rule.Name.Val] = true
+	return rule
+}
+
+// litPrefix returns true if every input matched by the literal next
+// starts with a text matched by the literal prev.
+func litPrefix(prev, next *ast.LitMatcher) bool {
+	if prev.IgnoreCase {
+		pr, nr := []rune(prev.Val), []rune(next.Val)
+		return len(pr) <= len(nr) && strings.EqualFold(prev.Val, string(nr[:len(pr)]))
+	}
+	if !strings.HasPrefix(next.Val, prev.Val) {
+		return false
+	}
+	if next.IgnoreCase {
+		// next may match another case of the prefix, unless it has none
+		for _, r := range prev.Val {
+			if unicode.SimpleFold(r) != r {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/lsp"
+)
+
+// lspMain implements the lsp command, it runs the language server on
+// stdin and stdout.
+func lspMain(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" lsp", flag.ExitOnError)
+
+	var (
+		shortHelpFlag = fs.Bool("h", false, "show help page")
+		longHelpFlag  = fs.Bool("help", false, "show help page")
+	)
+
+	usage := func() {
+		fmt.Printf(lspUsagePage, os.Args[0])
+	}
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, "args parse error:\n", err)
+		exit(6)
+	}
+
+	if *shortHelpFlag || *longHelpFlag {
+		usage()
+		exit(0)
+	}
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "expected no argument, got %q\n", strings.Join(fs.Args(), " "))
+		usage()
+		exit(1)
+	}
+
+	server := lsp.NewServer(func(filename string, src []byte) (*ast.Grammar, error) {
+		g, err := Parse(filename, src)
+		if err != nil {
+			return nil, err
+		}
+		return g.(*ast.Grammar), nil
+	})
+	if err := server.Serve(os.Stdin, os.Stdout); errors.Is(err, lsp.ErrNoShutdown) {
+		exit(1)
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, "read error:\n", err)
+		exit(2)
+	}
+}
+
+var lspUsagePage = `usage: %s lsp [options]
+
+Lsp runs a language server for the PEG grammar files, that speaks the
+Language Server Protocol on stdin and stdout. It publishes the parse
+errors, the errors of the parser generation and the diagnostics of the
+lint command, and it supports:
+
+	- go to definition and find references of the rules,
+	- rename of the rules,
+	- hover, with the display name, the nullability and the comment
+	  of the rules,
+	- document symbols, a symbol per rule,
+	- formatting, as by the fmt command.
+
+The language server exits with the code 1 if the client exits without
+the shutdown request.
+
+	-h -help
+		display this help message.
+`
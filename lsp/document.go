@@ -0,0 +1,170 @@
+package lsp
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/oskoi/pigeon/ast"
+)
+
+// document is an open grammar file, with the grammar parsed from its
+// last version, nil if it has errors.
+type document struct {
+	uri     string
+	text    string
+	lines   []int // byte offsets of the start of the lines
+	grammar *ast.Grammar
+	idents  []ident
+	rules   map[string]*ast.Rule
+}
+
+// ident is an occurrence of a rule name, in the definition of the rule
+// or in a reference to the rule.
+type ident struct {
+	off  int
+	name string
+	def  bool
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri}
+	d.setText(text)
+	return d
+}
+
+func (d *document) setText(text string) {
+	d.text = text
+	d.lines = []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+	d.setGrammar(nil)
+}
+
+// setGrammar sets the grammar of the document and indexes the
+// occurrences of the rule names, sorted by offset.
+func (d *document) setGrammar(grammar *ast.Grammar) {
+	d.grammar = grammar
+	d.idents = nil
+	d.rules = make(map[string]*ast.Rule)
+	if grammar == nil {
+		return
+	}
+
+	for _, rule := range grammar.Rules {
+		if _, ok := d.rules[rule.Name.Val]; !ok {
+			d.rules[rule.Name.Val] = rule
+		}
+		d.idents = append(d.idents, ident{off: rule.Name.Pos().Off, name: rule.Name.Val, def: true})
+	}
+	ast.Inspect(grammar, func(expr ast.Expression) bool {
+		if ref, ok := expr.(*ast.RuleRefExpr); ok {
+			d.idents = append(d.idents, ident{off: ref.Name.Pos().Off, name: ref.Name.Val})
+		}
+		return true
+	})
+	sort.Slice(d.idents, func(i, j int) bool { return d.idents[i].off < d.idents[j].off })
+}
+
+// identAt returns the occurrence of a rule name at the offset, the end of
+// the name included, or false.
+func (d *document) identAt(off int) (ident, bool) {
+	i := sort.Search(len(d.idents), func(i int) bool { return d.idents[i].off+len(d.idents[i].name) >= off })
+	if i < len(d.idents) && d.idents[i].off <= off {
+		return d.idents[i], true
+	}
+	return ident{}, false
+}
+
+// occurrences returns the occurrences of the rule name, the definitions
+// included if defs is true.
+func (d *document) occurrences(name string, defs bool) []ident {
+	var ids []ident
+	for _, id := range d.idents {
+		if id.name == name && (defs || !id.def) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// position returns the position of the byte offset.
+func (d *document) position(off int) Position {
+	if off > len(d.text) {
+		off = len(d.text)
+	}
+	line := sort.Search(len(d.lines), func(i int) bool { return d.lines[i] > off }) - 1
+	var char int
+	for _, r := range d.text[d.lines[line]:off] {
+		char += utf16Len(r)
+	}
+	return Position{Line: line, Character: char}
+}
+
+// offset returns the byte offset of the position, clamped to its line.
+func (d *document) offset(p Position) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	off := d.lines[p.Line]
+	for char := 0; char < p.Character && off < len(d.text) && d.text[off] != '\n'; {
+		r, n := utf8.DecodeRuneInString(d.text[off:])
+		char += utf16Len(r)
+		off += n
+	}
+	return off
+}
+
+// rangeOf returns the range of n bytes from the offset.
+func (d *document) rangeOf(off, n int) Range {
+	return Range{Start: d.position(off), End: d.position(off + n)}
+}
+
+// identRange returns the range of the occurrence of a rule name.
+func (d *document) identRange(id ident) Range {
+	return d.rangeOf(id.off, len(id.name))
+}
+
+// ruleRange returns the range of the rule, from its annotations to the
+// last non-space character before the next rule.
+func (d *document) ruleRange(i int) Range {
+	start := d.grammar.Rules[i].Pos().Off
+	end := len(d.text)
+	if i+1 < len(d.grammar.Rules) {
+		end = d.grammar.Rules[i+1].Pos().Off
+	}
+	end = start + len(strings.TrimRightFunc(d.text[start:end], unicode.IsSpace))
+	return d.rangeOf(start, end-start)
+}
+
+// wordLen returns the length in bytes of the identifier at the offset, or
+// of the character at the offset if it is not an identifier.
+func (d *document) wordLen(off int) int {
+	n := 0
+	for off+n < len(d.text) {
+		r, size := utf8.DecodeRuneInString(d.text[off+n:])
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		n += size
+	}
+	if n == 0 && off < len(d.text) && d.text[off] != '\n' {
+		_, n = utf8.DecodeRuneInString(d.text[off:])
+	}
+	return n
+}
+
+// utf16Len returns the number of UTF-16 code units encoding r.
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
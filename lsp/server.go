@@ -0,0 +1,522 @@
+// Package lsp implements a language server for the PEG grammar files, it
+// speaks the Language Server Protocol over a pair of streams, e.g. stdin
+// and stdout.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/oskoi/pigeon/analysis"
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/builder"
+	"github.com/oskoi/pigeon/ebnf"
+	"github.com/oskoi/pigeon/format"
+	"github.com/oskoi/pigeon/lint"
+)
+
+// ErrNoShutdown is returned by Serve when the client sends the exit
+// notification without a prior shutdown request, or closes the stream.
+var ErrNoShutdown = errors.New("exit without shutdown")
+
+// ParseFunc parses the grammar of a file. Each line of the error starting
+// with a position of the form "LINE:COL (OFFSET): " is reported at that
+// position.
+type ParseFunc func(filename string, src []byte) (*ast.Grammar, error)
+
+// Server is a language server for the PEG grammar files. It publishes the
+// parse errors, the builder errors and the lint diagnostics of the open
+// documents, and answers the go-to-definition, find-references, rename,
+// hover, document symbols and formatting requests.
+type Server struct {
+	parse ParseFunc
+	docs  map[string]*document
+	w     io.Writer
+
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer creates a language server that uses parse to parse the
+// grammars.
+func NewServer(parse ParseFunc) *Server {
+	return &Server{parse: parse, docs: make(map[string]*document)}
+}
+
+// Serve reads the messages of the client from r and writes the responses
+// and the notifications to w, until the client sends the exit
+// notification. It returns nil if the client sent the shutdown request
+// first.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.w = w
+	br := bufio.NewReader(r)
+	for {
+		msg, err := readMessage(br)
+		if err == io.EOF {
+			if s.shutdown {
+				return nil
+			}
+			return ErrNoShutdown
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Error != nil {
+			if err := s.respond(json.RawMessage("null"), nil, msg.Error); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "" {
+			// a response to a request of the server, there is none
+			continue
+		}
+		if msg.Method == "exit" {
+			if s.shutdown {
+				return nil
+			}
+			return ErrNoShutdown
+		}
+
+		result, err := s.handle(msg.Method, msg.Params)
+		if msg.ID == nil {
+			// the errors of the notifications are not reported
+			continue
+		}
+		if err := s.respond(msg.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) respond(id json.RawMessage, result any, err error) error {
+	msg := &message{ID: id}
+	if err != nil {
+		var rerr *ResponseError
+		if !errors.As(err, &rerr) {
+			rerr = &ResponseError{Code: codeInternalError, Message: err.Error()}
+		}
+		msg.Error = rerr
+	} else {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		msg.Result = b
+	}
+	return writeMessage(s.w, msg)
+}
+
+func (s *Server) notify(method string, params any) error {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return writeMessage(s.w, &message{Method: method, Params: b})
+}
+
+// handle handles the request or the notification and returns its result.
+func (s *Server) handle(method string, params json.RawMessage) (any, error) {
+	switch {
+	case method == "initialize":
+		s.initialized = true
+		return s.initialize(), nil
+	case !s.initialized:
+		return nil, &ResponseError{Code: -32002, Message: "server not initialized"}
+	case s.shutdown:
+		return nil, &ResponseError{Code: codeInvalidRequest, Message: "server is shut down"}
+	}
+
+	switch method {
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var p DidOpenTextDocumentParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return nil, s.didOpen(p)
+	case "textDocument/didChange":
+		var p DidChangeTextDocumentParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return nil, s.didChange(p)
+	case "textDocument/didClose":
+		var p DidCloseTextDocumentParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return nil, s.didClose(p)
+	case "textDocument/definition":
+		var p TextDocumentPositionParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return s.definition(p)
+	case "textDocument/references":
+		var p ReferenceParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return s.references(p)
+	case "textDocument/prepareRename":
+		var p TextDocumentPositionParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return s.prepareRename(p)
+	case "textDocument/rename":
+		var p RenameParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return s.rename(p)
+	case "textDocument/hover":
+		var p TextDocumentPositionParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return s.hover(p)
+	case "textDocument/documentSymbol":
+		var p DocumentSymbolParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return s.documentSymbol(p)
+	case "textDocument/formatting":
+		var p DocumentFormattingParams
+		if err := decode(params, &p); err != nil {
+			return nil, err
+		}
+		return s.formatting(p)
+	}
+	return nil, &ResponseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", method)}
+}
+
+func decode(params json.RawMessage, v any) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &ResponseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) initialize() *InitializeResult {
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync:           2, // incremental
+			DefinitionProvider:         true,
+			ReferencesProvider:         true,
+			RenameProvider:             &RenameOptions{PrepareProvider: true},
+			HoverProvider:              true,
+			DocumentSymbolProvider:     true,
+			DocumentFormattingProvider: true,
+		},
+		ServerInfo: &ServerInfo{Name: "pigeon"},
+	}
+}
+
+func (s *Server) didOpen(p DidOpenTextDocumentParams) error {
+	doc := newDocument(p.TextDocument.URI, p.TextDocument.Text)
+	s.docs[doc.uri] = doc
+	return s.check(doc)
+}
+
+func (s *Server) didChange(p DidChangeTextDocumentParams) error {
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return err
+	}
+	for _, change := range p.ContentChanges {
+		if change.Range == nil {
+			doc.setText(change.Text)
+			continue
+		}
+		start, end := doc.offset(change.Range.Start), doc.offset(change.Range.End)
+		if end < start {
+			end = start
+		}
+		doc.setText(doc.text[:start] + change.Text + doc.text[end:])
+	}
+	return s.check(doc)
+}
+
+func (s *Server) didClose(p DidCloseTextDocumentParams) error {
+	delete(s.docs, p.TextDocument.URI)
+	return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         p.TextDocument.URI,
+		Diagnostics: []Diagnostic{},
+	})
+}
+
+func (s *Server) document(uri string) (*document, error) {
+	doc := s.docs[uri]
+	if doc == nil {
+		return nil, &ResponseError{Code: codeInvalidParams, Message: fmt.Sprintf("document not open: %s", uri)}
+	}
+	return doc, nil
+}
+
+// identAt returns the document and the occurrence of a rule name at the
+// position, or a nil document if there is none.
+func (s *Server) identAt(p TextDocumentPositionParams) (*document, ident, error) {
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, ident{}, err
+	}
+	id, ok := doc.identAt(doc.offset(p.Position))
+	if !ok {
+		return nil, ident{}, nil
+	}
+	return doc, id, nil
+}
+
+// check parses the document and publishes its diagnostics: the parse
+// errors, or the builder errors and the lint diagnostics of the grammar.
+func (s *Server) check(doc *document) error {
+	filename := doc.uri
+	if u, err := url.Parse(doc.uri); err == nil && u.Scheme == "file" {
+		filename = u.Path
+	}
+
+	diags := []Diagnostic{}
+	grammar, err := s.parse(filename, []byte(doc.text))
+	if err != nil {
+		diags = append(diags, doc.errorDiagnostics(err)...)
+	} else {
+		doc.setGrammar(grammar)
+		for _, d := range lint.Lint(grammar) {
+			diags = append(diags, Diagnostic{
+				Range:    doc.rangeOf(d.Pos.Off, doc.wordLen(d.Pos.Off)),
+				Severity: lintSeverities[d.Severity],
+				Code:     d.Check,
+				Source:   "pigeon",
+				Message:  d.Message,
+			})
+		}
+
+		// the builder modifies the grammar, it builds a copy
+		if g, err := s.parse(filename, []byte(doc.text)); err == nil {
+			if err := builder.BuildParser(io.Discard, g); errors.Is(err, builder.ErrHaveLeftRecursion) {
+				diags = append(diags, doc.leftRecursionDiagnostics()...)
+			} else if err != nil {
+				diags = append(diags, doc.errorDiagnostics(err)...)
+			}
+		}
+	}
+
+	return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         doc.uri,
+		Diagnostics: diags,
+	})
+}
+
+var lintSeverities = map[lint.Severity]DiagnosticSeverity{
+	lint.Error:   SeverityError,
+	lint.Warning: SeverityWarning,
+	lint.Info:    SeverityInformation,
+}
+
+// rxErrorPos matches the position of an error message.
+var rxErrorPos = regexp.MustCompile(`(\d+):(\d+) \((\d+)\): `)
+
+// errorDiagnostics returns a diagnostic per line of the error message, at
+// the position the line starts with or at the start of the document.
+func (d *document) errorDiagnostics(err error) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(err.Error(), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		off, msg := 0, line
+		if m := rxErrorPos.FindStringSubmatchIndex(line); m != nil {
+			off, _ = strconv.Atoi(line[m[6]:m[7]])
+			msg = line[m[1]:]
+		}
+		diags = append(diags, Diagnostic{
+			Range:    d.rangeOf(off, d.wordLen(off)),
+			Severity: SeverityError,
+			Source:   "pigeon",
+			Message:  msg,
+		})
+	}
+	return diags
+}
+
+// leftRecursionDiagnostics returns a diagnostic at the name of each
+// left-recursive rule, that the builder rejects.
+func (d *document) leftRecursionDiagnostics() []Diagnostic {
+	var diags []Diagnostic
+	for _, scc := range analysis.Analyze(d.grammar).LeftRecursion {
+		for _, name := range scc {
+			rule := d.rules[name]
+			diags = append(diags, Diagnostic{
+				Range:    d.identRange(ident{off: rule.Name.Pos().Off, name: name}),
+				Severity: SeverityError,
+				Source:   "pigeon",
+				Message:  fmt.Sprintf("left recursion: %s", strings.Join(scc, ", ")),
+			})
+		}
+	}
+	return diags
+}
+
+func (s *Server) definition(p TextDocumentPositionParams) (any, error) {
+	doc, id, err := s.identAt(p)
+	if doc == nil {
+		return nil, err
+	}
+	rule := doc.rules[id.name]
+	if rule == nil {
+		return nil, nil
+	}
+	return Location{
+		URI:   doc.uri,
+		Range: doc.identRange(ident{off: rule.Name.Pos().Off, name: id.name}),
+	}, nil
+}
+
+func (s *Server) references(p ReferenceParams) (any, error) {
+	doc, id, err := s.identAt(p.TextDocumentPositionParams)
+	if doc == nil {
+		return nil, err
+	}
+	locs := []Location{}
+	for _, occ := range doc.occurrences(id.name, p.Context.IncludeDeclaration) {
+		locs = append(locs, Location{URI: doc.uri, Range: doc.identRange(occ)})
+	}
+	return locs, nil
+}
+
+func (s *Server) prepareRename(p TextDocumentPositionParams) (any, error) {
+	doc, id, err := s.identAt(p)
+	if doc == nil || doc.rules[id.name] == nil {
+		return nil, err
+	}
+	return doc.identRange(id), nil
+}
+
+func (s *Server) rename(p RenameParams) (any, error) {
+	doc, id, err := s.identAt(p.TextDocumentPositionParams)
+	if doc == nil || doc.rules[id.name] == nil {
+		if err == nil {
+			err = &ResponseError{Code: codeRequestFailed, Message: "no rule at the position"}
+		}
+		return nil, err
+	}
+	if !isIdentifier(p.NewName) {
+		return nil, &ResponseError{Code: codeInvalidParams, Message: fmt.Sprintf("invalid rule name: %q", p.NewName)}
+	}
+	if p.NewName != id.name && doc.rules[p.NewName] != nil {
+		return nil, &ResponseError{Code: codeRequestFailed, Message: fmt.Sprintf("rule %s already exists", p.NewName)}
+	}
+
+	edits := []TextEdit{}
+	for _, occ := range doc.occurrences(id.name, true) {
+		edits = append(edits, TextEdit{Range: doc.identRange(occ), NewText: p.NewName})
+	}
+	return WorkspaceEdit{Changes: map[string][]TextEdit{doc.uri: edits}}, nil
+}
+
+// isIdentifier returns true if name is a valid rule name.
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+func (s *Server) hover(p TextDocumentPositionParams) (any, error) {
+	doc, id, err := s.identAt(p)
+	if doc == nil {
+		return nil, err
+	}
+	rule := doc.rules[id.name]
+	if rule == nil {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	b.WriteString("```peg\n")
+	b.WriteString(rule.Name.Val)
+	if rule.DisplayName != nil {
+		b.WriteString(" " + strconv.Quote(displayName(rule)))
+	}
+	b.WriteString("\n```\n")
+	for _, r := range analysis.Analyze(doc.grammar).Rules {
+		if r.Name == rule.Name.Val {
+			nullable := "no"
+			if r.Nullable {
+				nullable = "yes"
+			}
+			fmt.Fprintf(&b, "\nNullable: %s\n", nullable)
+			break
+		}
+	}
+	if comment := ebnf.RuleComment(rule, []byte(doc.text)); len(comment) > 0 {
+		b.WriteString("\n" + strings.Join(comment, "\n") + "\n")
+	}
+
+	rng := doc.identRange(id)
+	return Hover{Contents: MarkupContent{Kind: "markdown", Value: b.String()}, Range: &rng}, nil
+}
+
+func (s *Server) documentSymbol(p DocumentSymbolParams) (any, error) {
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	syms := []DocumentSymbol{}
+	if doc.grammar == nil {
+		return syms, nil
+	}
+	for i, rule := range doc.grammar.Rules {
+		sym := DocumentSymbol{
+			Name:           rule.Name.Val,
+			Kind:           SymbolKindFunction,
+			Range:          doc.ruleRange(i),
+			SelectionRange: doc.identRange(ident{off: rule.Name.Pos().Off, name: rule.Name.Val}),
+		}
+		if rule.DisplayName != nil {
+			sym.Detail = displayName(rule)
+		}
+		syms = append(syms, sym)
+	}
+	return syms, nil
+}
+
+// displayName returns the display name of the rule, unquoted.
+func displayName(rule *ast.Rule) string {
+	if name, err := strconv.Unquote(rule.DisplayName.Val); err == nil {
+		return name
+	}
+	return rule.DisplayName.Val
+}
+
+func (s *Server) formatting(p DocumentFormattingParams) (any, error) {
+	doc, err := s.document(p.TextDocument.URI)
+	if err != nil {
+		return nil, err
+	}
+	if doc.grammar == nil {
+		return nil, &ResponseError{Code: codeRequestFailed, Message: "the grammar has errors"}
+	}
+	formatted := string(format.Format(doc.grammar, []byte(doc.text)))
+	if formatted == doc.text {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{Range: doc.rangeOf(0, len(doc.text)), NewText: formatted}}, nil
+}
@@ -0,0 +1,346 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/lsp"
+)
+
+// client is a language client connected to a server through pipes.
+type client struct {
+	t    *testing.T
+	w    io.Writer
+	r    *bufio.Reader
+	id   int
+	done chan error
+}
+
+func parse(filename string, src []byte) (*ast.Grammar, error) {
+	return bootstrap.NewParser().Parse(filename, bytes.NewReader(src))
+}
+
+func newClient(t *testing.T) *client {
+	t.Helper()
+
+	sr, cw := io.Pipe()
+	cr, sw := io.Pipe()
+	c := &client{t: t, w: cw, r: bufio.NewReader(cr), done: make(chan error, 1)}
+	go func() {
+		err := lsp.NewServer(parse).Serve(sr, sw)
+		sw.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() { cw.Close() })
+
+	var res lsp.InitializeResult
+	if err := c.call("initialize", map[string]any{"capabilities": map[string]any{}}, &res); err != nil {
+		t.Fatal(err)
+	}
+	if !res.Capabilities.DefinitionProvider || !res.Capabilities.DocumentFormattingProvider {
+		t.Fatalf("want the definition and formatting capabilities, got %+v", res.Capabilities)
+	}
+	c.notify("initialized", struct{}{})
+	return c
+}
+
+type rpcMessage struct {
+	JSONRPC string             `json:"jsonrpc"`
+	ID      *int               `json:"id,omitempty"`
+	Method  string             `json:"method,omitempty"`
+	Params  any                `json:"params,omitempty"`
+	Result  json.RawMessage    `json:"result,omitempty"`
+	Error   *lsp.ResponseError `json:"error,omitempty"`
+}
+
+func (c *client) write(msg *rpcMessage) {
+	c.t.Helper()
+
+	msg.JSONRPC = "2.0"
+	b, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(b), b); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) read() *rpcMessage {
+	c.t.Helper()
+
+	header, err := textproto.NewReader(c.r).ReadMIMEHeader()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	n, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(c.r, b); err != nil {
+		c.t.Fatal(err)
+	}
+	var msg struct {
+		rpcMessage
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		c.t.Fatal(err)
+	}
+	msg.rpcMessage.Params = msg.Params
+	return &msg.rpcMessage
+}
+
+// call sends the request and decodes its result into result, it fails
+// the test if the server sends a notification first.
+func (c *client) call(method string, params, result any) *lsp.ResponseError {
+	c.t.Helper()
+
+	c.id++
+	id := c.id
+	c.write(&rpcMessage{ID: &id, Method: method, Params: params})
+	msg := c.read()
+	if msg.ID == nil || *msg.ID != id {
+		c.t.Fatalf("%s: want the response %d, got %+v", method, id, msg)
+	}
+	if msg.Error != nil {
+		return msg.Error
+	}
+	if err := json.Unmarshal(msg.Result, result); err != nil {
+		c.t.Fatalf("%s: %v", method, err)
+	}
+	return nil
+}
+
+func (c *client) notify(method string, params any) {
+	c.t.Helper()
+	c.write(&rpcMessage{Method: method, Params: params})
+}
+
+// diagnostics reads the diagnostics published by the server.
+func (c *client) diagnostics() lsp.PublishDiagnosticsParams {
+	c.t.Helper()
+
+	msg := c.read()
+	if msg.Method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("want diagnostics, got %+v", msg)
+	}
+	var p lsp.PublishDiagnosticsParams
+	if err := json.Unmarshal(msg.Params.(json.RawMessage), &p); err != nil {
+		c.t.Fatal(err)
+	}
+	return p
+}
+
+func (c *client) open(uri, text string) lsp.PublishDiagnosticsParams {
+	c.t.Helper()
+	c.notify("textDocument/didOpen", lsp.DidOpenTextDocumentParams{
+		TextDocument: lsp.TextDocumentItem{URI: uri, LanguageID: "peg", Version: 1, Text: text},
+	})
+	return c.diagnostics()
+}
+
+func (c *client) shutdown() {
+	c.t.Helper()
+
+	var res any
+	if err := c.call("shutdown", nil, &res); err != nil {
+		c.t.Fatal(err)
+	}
+	c.notify("exit", nil)
+	if err := <-c.done; err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func pos(uri string, line, char int) lsp.TextDocumentPositionParams {
+	return lsp.TextDocumentPositionParams{
+		TextDocument: lsp.TextDocumentIdentifier{URI: uri},
+		Position:     lsp.Position{Line: line, Character: char},
+	}
+}
+
+func rng(line, start, end int) lsp.Range {
+	return lsp.Range{Start: lsp.Position{Line: line, Character: start}, End: lsp.Position{Line: line, Character: end}}
+}
+
+const (
+	uri     = "file:///calc.peg"
+	grammar = `start = expr+ !.
+expr "expression" = term ('+' term)*
+term = [0-9]+ / '(' expr ')'
+`
+)
+
+func TestNavigation(t *testing.T) {
+	c := newClient(t)
+	if diags := c.open(uri, grammar); len(diags.Diagnostics) != 0 {
+		t.Fatalf("want no diagnostic, got %+v", diags.Diagnostics)
+	}
+
+	var loc lsp.Location
+	if err := c.call("textDocument/definition", pos(uri, 0, 10), &loc); err != nil {
+		t.Fatal(err)
+	}
+	if want := (lsp.Location{URI: uri, Range: rng(1, 0, 4)}); loc != want {
+		t.Errorf("definition: want %+v, got %+v", want, loc)
+	}
+
+	var locs []lsp.Location
+	params := lsp.ReferenceParams{TextDocumentPositionParams: pos(uri, 1, 2)}
+	params.Context.IncludeDeclaration = true
+	if err := c.call("textDocument/references", params, &locs); err != nil {
+		t.Fatal(err)
+	}
+	want := []lsp.Location{{URI: uri, Range: rng(0, 8, 12)}, {URI: uri, Range: rng(1, 0, 4)}, {URI: uri, Range: rng(2, 20, 24)}}
+	if fmt.Sprint(locs) != fmt.Sprint(want) {
+		t.Errorf("references: want %+v, got %+v", want, locs)
+	}
+
+	var hover lsp.Hover
+	if err := c.call("textDocument/hover", pos(uri, 1, 0), &hover); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`expr "expression"`, "Nullable: no"} {
+		if !strings.Contains(hover.Contents.Value, want) {
+			t.Errorf("hover: want %q in %q", want, hover.Contents.Value)
+		}
+	}
+	var none any
+	if err := c.call("textDocument/hover", pos(uri, 0, 6), &none); err != nil || none != nil {
+		t.Errorf("hover: want no result outside of a rule name, got %v, %v", none, err)
+	}
+
+	var syms []lsp.DocumentSymbol
+	if err := c.call("textDocument/documentSymbol", lsp.DocumentSymbolParams{TextDocument: lsp.TextDocumentIdentifier{URI: uri}}, &syms); err != nil {
+		t.Fatal(err)
+	}
+	if len(syms) != 3 || syms[1].Name != "expr" || syms[1].Detail != "expression" || syms[1].Range != rng(1, 0, 36) {
+		t.Errorf("symbols: got %+v", syms)
+	}
+
+	if err := c.call("textDocument/unknown", pos(uri, 0, 0), &none); err == nil || err.Code != -32601 {
+		t.Errorf("want method not found, got %v", err)
+	}
+	c.shutdown()
+}
+
+func TestRename(t *testing.T) {
+	c := newClient(t)
+	c.open(uri, grammar)
+
+	var edit lsp.WorkspaceEdit
+	if err := c.call("textDocument/rename", lsp.RenameParams{TextDocumentPositionParams: pos(uri, 2, 22), NewName: "sum"}, &edit); err != nil {
+		t.Fatal(err)
+	}
+	edits := edit.Changes[uri]
+	if len(edits) != 3 {
+		t.Fatalf("want 3 edits, got %+v", edits)
+	}
+	for _, e := range edits {
+		if e.NewText != "sum" {
+			t.Errorf("want sum, got %q", e.NewText)
+		}
+	}
+
+	for _, name := range []string{"term", "1x", ""} {
+		err := c.call("textDocument/rename", lsp.RenameParams{TextDocumentPositionParams: pos(uri, 0, 8), NewName: name}, &edit)
+		if err == nil {
+			t.Errorf("%q: want an error", name)
+		}
+	}
+
+	var r lsp.Range
+	if err := c.call("textDocument/prepareRename", pos(uri, 0, 12), &r); err != nil || r != rng(0, 8, 12) {
+		t.Errorf("prepareRename: want %+v, got %+v, %v", rng(0, 8, 12), r, err)
+	}
+	c.shutdown()
+}
+
+func TestDiagnostics(t *testing.T) {
+	c := newClient(t)
+
+	diags := c.open(uri, "start = a 'x'\n").Diagnostics
+	if len(diags) != 1 || diags[0].Severity != lsp.SeverityError || diags[0].Range != rng(0, 8, 9) ||
+		!strings.Contains(diags[0].Message, "undefined rule: a") {
+		t.Errorf("undefined rule: got %+v", diags)
+	}
+
+	// append a left-recursive definition of the rule
+	c.notify("textDocument/didChange", lsp.DidChangeTextDocumentParams{
+		TextDocument: lsp.VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{
+			{Range: &lsp.Range{Start: lsp.Position{Line: 1}, End: lsp.Position{Line: 1}}, Text: "a = a 'y' / 'z'\n"},
+		},
+	})
+	diags = c.diagnostics().Diagnostics
+	if len(diags) != 1 || diags[0].Range != rng(1, 0, 1) || diags[0].Message != "left recursion: a" {
+		t.Errorf("left recursion: got %+v", diags)
+	}
+
+	c.notify("textDocument/didChange", lsp.DidChangeTextDocumentParams{
+		TextDocument:   lsp.VersionedTextDocumentIdentifier{URI: uri, Version: 3},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: "start = 'a'\nother = 'b'\n\nbad = (\n"}},
+	})
+	diags = c.diagnostics().Diagnostics
+	if len(diags) == 0 || diags[0].Severity != lsp.SeverityError || diags[0].Range.Start.Line != 3 {
+		t.Errorf("parse error: got %+v", diags)
+	}
+
+	c.notify("textDocument/didChange", lsp.DidChangeTextDocumentParams{
+		TextDocument:   lsp.VersionedTextDocumentIdentifier{URI: uri, Version: 4},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: "start = 'a'\nother = 'b'\n"}},
+	})
+	diags = c.diagnostics().Diagnostics
+	if len(diags) != 1 || diags[0].Code != "unused-rule" || diags[0].Severity != lsp.SeverityWarning || diags[0].Range != rng(1, 0, 5) {
+		t.Errorf("lint: got %+v", diags)
+	}
+
+	c.notify("textDocument/didClose", lsp.DidCloseTextDocumentParams{TextDocument: lsp.TextDocumentIdentifier{URI: uri}})
+	if diags := c.diagnostics(); len(diags.Diagnostics) != 0 {
+		t.Errorf("close: want no diagnostic, got %+v", diags)
+	}
+	c.shutdown()
+}
+
+func TestFormatting(t *testing.T) {
+	c := newClient(t)
+	c.open(uri, "start=   'a'  b\nb='b'")
+
+	var edits []lsp.TextEdit
+	params := map[string]any{"textDocument": lsp.TextDocumentIdentifier{URI: uri}, "options": map[string]any{"tabSize": 4}}
+	if err := c.call("textDocument/formatting", params, &edits); err != nil {
+		t.Fatal(err)
+	}
+	if len(edits) != 1 || edits[0].Range != (lsp.Range{End: lsp.Position{Line: 1, Character: 5}}) {
+		t.Fatalf("want an edit of the whole document, got %+v", edits)
+	}
+
+	c.notify("textDocument/didChange", lsp.DidChangeTextDocumentParams{
+		TextDocument:   lsp.VersionedTextDocumentIdentifier{URI: uri, Version: 2},
+		ContentChanges: []lsp.TextDocumentContentChangeEvent{{Text: edits[0].NewText}},
+	})
+	c.diagnostics()
+	if err := c.call("textDocument/formatting", params, &edits); err != nil || len(edits) != 0 {
+		t.Errorf("want no edit of a formatted document, got %+v, %v", edits, err)
+	}
+	c.shutdown()
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	c := newClient(t)
+	c.notify("exit", nil)
+	if err := <-c.done; err != lsp.ErrNoShutdown {
+		t.Errorf("want %v, got %v", lsp.ErrNoShutdown, err)
+	}
+}
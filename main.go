@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -15,6 +16,9 @@ import (
 
 	"github.com/oskoi/pigeon/ast"
 	builderGo "github.com/oskoi/pigeon/builder"
+	"github.com/oskoi/pigeon/format"
+	"github.com/oskoi/pigeon/gocheck"
+	"github.com/oskoi/pigeon/lint"
 	// builderHx "github.com/oskoi/pigeon/builder_hx"
 )
 
@@ -35,35 +39,63 @@ func (r *ruleNamesFlag) Set(value string) error {
 	return nil
 }
 
+// commands maps the name of the subcommands to their implementation, called
+// with the arguments following the name.
+var commands = map[string]func(args []string){
+	"analyze":     analyzeMain,
+	"diagram":     diagramMain,
+	"ebnf":        ebnfMain,
+	"fmt":         fmtMain,
+	"gen-samples": genSamplesMain,
+	"lint":        lintMain,
+	"lsp":         lspMain,
+	"run":         runMain,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+
 	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
 
 	// define command-line flags
 	var (
-		dbgFlag            = fs.Bool("debug", false, "set debug mode")
-		shortHelpFlag      = fs.Bool("h", false, "show help page")
-		longHelpFlag       = fs.Bool("help", false, "show help page")
-		nolint             = fs.Bool("nolint", false, "add '// nolint: ...' comments to suppress warnings by gometalinter or golangci-lint")
-		noRecoverFlag      = fs.Bool("no-recover", false, "do not recover from panic")
-		outputFlag         = fs.String("o", "", "output file, defaults to stdout")
-		optimizeParserFlag = fs.Bool("optimize-parser", false, "generate optimized parser without Debug and Memoize options")
-		recvrNmFlag        = fs.String("receiver-name", "c", "receiver name for the generated methods")
-		noBuildFlag        = fs.Bool("x", false, "do not build, only parse")
+		dbgFlag       = fs.Bool("debug", false, "set debug mode")
+		shortHelpFlag = fs.Bool("h", false, "show help page")
+		longHelpFlag  = fs.Bool("help", false, "show help page")
+		noRecoverFlag = fs.Bool("no-recover", false, "do not recover from panic")
+		outputFlag    = fs.String("o", "", "output file, defaults to stdout")
+		noBuildFlag   = fs.Bool("x", false, "do not build, only parse and check the rule references")
 
 		cacheFlag = fs.Bool("cache", false, "cache parsing results")
 
-		grammarNameFlag        = fs.String("grammar-name", "g", "default is g, `var g = &grammar{ ... }")
-		runFuncPrefixFlag      = fs.String("run-func-prefix", "", "set prefix for generated function name: `(*parser).call_onXXX`. For multiple peg files")
-		grammarOnlyFlag        = fs.Bool("grammar-only", false, "use it when you have multiple peg files")
-		optimizeRefExprByIndex = fs.Bool("optimize-ref-expr-by-index", false, "generate optimized parser grammar find RefExpr by index (~10% increased)")
-		targetFlag             = fs.String("t", "go", "build target, default go")
-
-		// optimizeGrammar        = fs.Bool("optimize-grammar", false, "optimize the given grammar (EXPERIMENTAL FEATURE)")
+		targetFlag          = fs.String("t", "go", "build target, default go")
+		optimizeGrammarFlag = fs.Bool("optimize-grammar", false, "optimize the given grammar (EXPERIMENTAL FEATURE)")
+		dumpOptimizedFlag   = fs.Bool("dump-optimized", false, "print the optimized grammar instead of generating the parser")
+		typecheckFlag       = fs.Bool("typecheck", false, "type-check the generated parser and report the errors in the grammar")
+		lineDirectivesFlag  = fs.Bool("line-directives", false, "add //line directives to the grammar around the code blocks")
+		fuzzTestFlag        = fs.Bool("fuzz-test", false, "write a fuzz test of the parser alongside the output file")
+		fuzzCorpusFlag      = fs.String("fuzz-corpus", "", "directory of the inputs that seed the fuzz test, relative to the output directory")
 
 		altEntrypointsFlag ruleNamesFlag
 	)
 	fs.Var(&altEntrypointsFlag, "alternate-entrypoints", "comma-separated list of rule names that may be used as entrypoints")
 
+	// the flags of the builder options are passed to the builder by name
+	// when set, see builderOptions
+	fs.Bool("ascii-fold", false, "fold the case of ASCII letters only in case-insensitive matchers")
+	fs.Bool("nolint", false, "add '// nolint: ...' comments to suppress warnings by gometalinter or golangci-lint")
+	fs.Bool("optimize-parser", false, "generate optimized parser without Debug and Memoize options")
+	fs.String("receiver-name", "c", "receiver name for the generated methods")
+	fs.String("grammar-name", "g", "default is g, `var g = &grammar{ ... }")
+	fs.String("run-func-prefix", "", "set prefix for generated function name: `(*parser).call_onXXX`. For multiple peg files")
+	fs.Bool("grammar-only", false, "use it when you have multiple peg files")
+	fs.Bool("optimize-ref-expr-by-index", false, "generate optimized parser grammar find RefExpr by index (~10% increased)")
+
 	fs.Usage = usage
 	err := fs.Parse(os.Args[1:])
 	if err != nil {
@@ -106,8 +138,19 @@ func main() {
 		exit(3)
 	}
 
-	// validate alternate entrypoints
+	// apply the grammar's options, flags set on the command line take
+	// precedence
 	grammar := g.(*ast.Grammar)
+	if err := applyGrammarOptions(fs, nm, grammar); err != nil {
+		fmt.Fprintln(os.Stderr, "option error(s):\n", err)
+		exit(3)
+	}
+
+	if *fuzzTestFlag && *outputFlag == "" {
+		argError(1, "the fuzz test requires the -o flag")
+	}
+
+	// validate alternate entrypoints
 	rules := make(map[string]struct{}, len(grammar.Rules))
 	for _, rule := range grammar.Rules {
 		rules[rule.Name.Val] = struct{}{}
@@ -122,12 +165,40 @@ func main() {
 			exit(9)
 		}
 	}
+	// the rules annotated with @entry are alternate entrypoints too
+	altEntrypointsFlag = append(altEntrypointsFlag, builderGo.EntryRules(grammar)...)
 
-	if !*noBuildFlag {
-		// if *optimizeGrammar {
-		// ast.Optimize(grammar, altEntrypointsFlag...)
-		// }
+	// report the references to undefined rules, even if the parser is not
+	// built
+	if err := builderGo.CheckRuleRefs(grammar); err != nil {
+		fmt.Fprintln(os.Stderr, "grammar error(s):\n", err)
+		exit(3)
+	}
+
+	// report the alternatives of the choices that can never match, these
+	// are warnings only
+	for _, d := range lint.ShadowedAlternatives(grammar) {
+		fmt.Fprintf(os.Stderr, "%s:%s\n", nm, d)
+	}
+
+	if *optimizeGrammarFlag || *dumpOptimizedFlag {
+		optimizeGrammar(grammar, altEntrypointsFlag)
+	}
 
+	if *dumpOptimizedFlag && !*noBuildFlag {
+		out := output(*outputFlag)
+		if _, err := out.Write(format.Format(grammar, nil)); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:\n", err)
+			exit(7)
+		}
+		if err := out.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, "close file error:\n", err)
+			exit(8)
+		}
+		return
+	}
+
+	if !*noBuildFlag {
 		// generate parser
 		out := output(*outputFlag)
 		defer func() {
@@ -140,19 +211,23 @@ func main() {
 
 		outBuf := bytes.NewBuffer([]byte{})
 
-		curNmOpt := builderGo.ReceiverName(*recvrNmFlag)
-		optimizeParser := builderGo.Optimize(*optimizeParserFlag)
-		nolintOpt := builderGo.Nolint(*nolint)
-		refExprByIndex := builderGo.OptimizeRefExprByIndex(*optimizeRefExprByIndex)
-		runFuncPrefix := builderGo.RunFuncPrefix(*runFuncPrefixFlag)
-		grammarOnly := builderGo.GrammarOnly(*grammarOnlyFlag)
-		grammarName := builderGo.GrammarName(*grammarNameFlag)
+		opts := builderOptions(fs)
+		codeFuncs := make(map[string]*ast.CodeBlock)
+		var grammarFile, parserFile string
+		if *lineDirectivesFlag {
+			grammarFile, parserFile = lineDirectiveFiles(nm, *outputFlag)
+		}
+		lineDirectives := builderGo.LineDirectives(grammarFile, parserFile)
+		var fuzzBuf *bytes.Buffer
+		fuzzTest := builderGo.FuzzTest(nil, "")
+		if *fuzzTestFlag {
+			fuzzBuf = &bytes.Buffer{}
+			fuzzTest = builderGo.FuzzTest(fuzzBuf, *fuzzCorpusFlag)
+		}
 
 		if *targetFlag == "go" {
-			if err := builderGo.BuildParser(
-				outBuf, grammar, curNmOpt, optimizeParser,
-				runFuncPrefix, grammarOnly, grammarName,
-				nolintOpt, refExprByIndex); err != nil {
+			opts = append(opts, builderGo.CodeFuncs(codeFuncs), lineDirectives, fuzzTest)
+			if err := builderGo.BuildParser(outBuf, grammar, opts...); err != nil {
 				fmt.Fprintln(os.Stderr, "build error: ", err)
 				exit(5)
 			}
@@ -183,14 +258,53 @@ func main() {
 					fmt.Fprintln(os.Stderr, "write error: ", err)
 					exit(7)
 				}
+				// report the syntax errors of the code blocks in the
+				// grammar rather than in the unformatted output
+				smap := gocheck.NewSourceMap(nm, outBuf.Bytes(), grammar.Init, codeFuncs)
+				if errs := gocheck.Parse(*outputFlag, outBuf.Bytes(), smap); len(errs) > 0 {
+					err = checkErrors(errs)
+				}
 				fmt.Fprintln(os.Stderr, "format error: ", err)
 				exit(6)
 			}
+			if *lineDirectivesFlag {
+				formattedBuf = builderGo.ResolveLineDirectives(formattedBuf, parserFile)
+			}
 
 			if _, err := out.Write(formattedBuf); err != nil {
 				fmt.Fprintln(os.Stderr, "write error: ", err)
 				exit(7)
 			}
+
+			if fuzzBuf != nil {
+				formattedFuzz, err := imports.Process("filename", fuzzBuf.Bytes(), options)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "format error: ", err)
+					exit(6)
+				}
+				fuzzOut := output(fuzzTestFile(*outputFlag))
+				if _, err := fuzzOut.Write(formattedFuzz); err != nil {
+					fmt.Fprintln(os.Stderr, "write error: ", err)
+					exit(7)
+				}
+				if err := fuzzOut.Close(); err != nil {
+					fmt.Fprintln(os.Stderr, "close file error:\n", err)
+					exit(8)
+				}
+			}
+
+			if *typecheckFlag {
+				smap := gocheck.NewSourceMap(nm, formattedBuf, grammar.Init, codeFuncs)
+				errs, err := gocheck.Check(*outputFlag, formattedBuf, smap)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, "type-check error:\n", err)
+					exit(10)
+				}
+				if len(errs) > 0 {
+					fmt.Fprintln(os.Stderr, "type error(s):\n", checkErrors(errs))
+					exit(10)
+				}
+			}
 		} else {
 			if _, err := out.Write(outBuf.Bytes()); err != nil {
 				fmt.Fprintln(os.Stderr, "write error: ", err)
@@ -201,6 +315,7 @@ func main() {
 }
 
 var usagePage = `usage: %s [options] [GRAMMAR_FILE]
+       %[1]s COMMAND [options] [ARGS]
 
 Pigeon generates a parser based on a PEG grammar.
 
@@ -209,14 +324,36 @@ generated parser to stdout. If GRAMMAR_FILE is specified, the
 grammar is read from this file instead. If the -o flag is set,
 the generated code is written to this file instead.
 
+The alternatives of the choices of the grammar that can never match
+are reported as warnings on stderr, see the shadowed-alternative check
+of the lint command.
+
+	-ascii-fold
+		fold the case of ASCII letters only in case-insensitive literals
+		and character classes, instead of using the Unicode simple case
+		folding. Faster, but e.g. "k"i does not match the Kelvin sign.
 	-cache
 		cache parser results to avoid exponential parsing time in
 		pathological cases. Can make the parsing slower for typical
 		cases and uses more memory.
 	-debug
 		output debugging information while parsing the grammar.
+	-fuzz-corpus DIR
+		directory of the inputs, one per file, that seed the corpus of
+		the fuzz test, relative to the directory of the output file.
+	-fuzz-test
+		write a fuzz test of the parser alongside the output file, in
+		the file named after it with the _fuzz_test.go suffix. Its
+		FuzzParse function checks that the parser terminates without
+		panicking on any input, and that the positions of its errors
+		are within the input. Requires -o.
 	-h -help
 		display this help message.
+	-line-directives
+		add //line directives around the code blocks of the generated
+		parser, so that the compiler errors, the panics and the
+		debuggers refer to the grammar. The generated file is assumed
+		to be named after the grammar if -o is not set.
 	-nolint
 		add '// nolint: ...' comments for generated parser to suppress
 		warnings by gometalinter (https://github.com/alecthomas/gometalinter) or
@@ -228,14 +365,30 @@ the generated code is written to this file instead.
 		write the generated parser to OUTPUT_FILE. Defaults to stdout.
 	-optimize-ref-expr-by-index
 		generate optimized parser grammar find RefExpr by index (~10%% performance increased, cause more git line diff)
+	-optimize-grammar
+		optimize the grammar before generating the parser: inline the
+		rules that reference no other rule or that are referenced only
+		once, flatten the nested choices and sequences, combine the
+		character classes and the literals, and remove the rules that
+		cannot be reached from the entrypoints (EXPERIMENTAL FEATURE).
+	-dump-optimized
+		print the optimized grammar instead of generating the parser.
+		Implies -optimize-grammar.
 	-optimize-parser
 		generate optimized parser without Debug and Memoize options and
 		with some other optimizations applied.
 	-receiver-name NAME
 		use NAME as for the receiver name of the generated methods
 		for the grammar's code blocks. Defaults to "c".
+	-typecheck
+		type-check the generated parser with the other Go files of its
+		package in the directory of OUTPUT_FILE, or alone if -o is not
+		set, and report the errors of the code blocks at their position
+		in the grammar. The imported packages are type-checked from
+		source.
 	-x
-		do not generate the parser, only parse the grammar.
+		do not generate the parser, only parse the grammar and check
+		that every referenced rule is defined.
  	-alternate-entrypoints RULE[,RULE...]
 		comma-separated list of rule names that may be used as alternate
 		entrypoints for the parser, in addition to the first rule in the
@@ -249,15 +402,150 @@ the generated code is written to this file instead.
 	-target
 		build target, default go
 
+Most options can also be set in the @options block of the grammar,
+options set on the command line take precedence.
+
+The commands are:
+
+	analyze
+		print the FIRST and FOLLOW sets, the nullability and the left
+		recursion of the rules, see "%[1]s analyze -h".
+	diagram
+		render the grammar as railroad diagrams or as a graph of the
+		dependencies between its rules, see "%[1]s diagram -h".
+	ebnf
+		export the grammar as EBNF, see "%[1]s ebnf -h".
+	fmt
+		print the grammar in the canonical format, see "%[1]s fmt -h".
+	gen-samples
+		generate random inputs matched and rejected by the grammar, see
+		"%[1]s gen-samples -h".
+	lint
+		report the likely mistakes of the grammar, see "%[1]s lint -h".
+	lsp
+		run the language server on stdin and stdout, see "%[1]s lsp -h".
+	run
+		run the grammar on an input without generating its parser, see
+		"%[1]s run -h".
+
 See https://godoc.org/github.com/mna/pigeon for more information.
 This version is a fork: https://github.com/oskoi/pigeon
 `
 
+// optimizeGrammar optimizes the grammar for the parsing performance, see
+// ast.Optimize. The entrypoints and the rules annotated with @trivia or
+// @word, which are referenced by the generated parser, are kept.
+func optimizeGrammar(grammar *ast.Grammar, entrypoints []string) {
+	roots := append([]string(nil), entrypoints...)
+	for _, rule := range grammar.Rules {
+		if rule.HasAnnotation(builderGo.TriviaAnnotation) || rule.HasAnnotation(builderGo.WordAnnotation) {
+			roots = append(roots, rule.Name.Val)
+		}
+	}
+	ast.Optimize(grammar, roots...)
+}
+
 // usage prints the help page of the command-line tool.
 func usage() {
 	fmt.Printf(usagePage, os.Args[0])
 }
 
+// fuzzTestFile returns the name of the fuzz test of the parser generated
+// in outputFile.
+func fuzzTestFile(outputFile string) string {
+	return strings.TrimSuffix(outputFile, ".go") + "_fuzz_test.go"
+}
+
+// lineDirectiveFiles returns the names of the grammar and of the generated
+// file in the line directives, relative to the directory of the generated
+// file. The generated file is assumed to be in the current directory and
+// named after the grammar if it is written to stdout.
+func lineDirectiveFiles(grammarFile, outputFile string) (string, string) {
+	if outputFile == "" {
+		base := filepath.Base(grammarFile)
+		return filepath.ToSlash(grammarFile), strings.TrimSuffix(base, filepath.Ext(base)) + ".go"
+	}
+	// the paths must both be absolute or both be relative to be made
+	// relative to each other
+	if abs, err := filepath.Abs(grammarFile); err == nil {
+		grammarFile = abs
+		if dir, err := filepath.Abs(filepath.Dir(outputFile)); err == nil {
+			if rel, err := filepath.Rel(dir, abs); err == nil {
+				grammarFile = rel
+			}
+		}
+	}
+	return filepath.ToSlash(grammarFile), filepath.Base(outputFile)
+}
+
+// checkErrors returns the errors of the generated code as a single error,
+// one per line.
+func checkErrors(errs []gocheck.Error) error {
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return errors.New(strings.Join(msgs, "\n"))
+}
+
+// applyGrammarOptions checks the options defined in the grammar and sets
+// the flags of fs of the tool options, see builder.ToolOptions, to their
+// values, unless the flag was explicitly set on the command line. The
+// builder options are applied by the builder. It returns an error listing
+// the position of every invalid option.
+func applyGrammarOptions(fs *flag.FlagSet, filename string, grammar *ast.Grammar) error {
+	var errs []string
+	if _, err := builderGo.GrammarOptions(grammar); err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			errs = append(errs, filename+":"+line)
+		}
+	}
+
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		setFlags[f.Name] = true
+	})
+
+	seen := make(map[string]bool)
+	for _, opt := range grammar.Options {
+		name := opt.Name.Val
+		if !builderGo.ToolOptions[name] || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if len(opt.Values) > 1 && name != "alternate-entrypoints" {
+			errs = append(errs, fmt.Sprintf("%s:%s: option %q expects a single value", filename, opt.Pos(), name))
+			continue
+		}
+		if setFlags[name] {
+			continue
+		}
+		value := strings.Join(opt.Values, ",")
+		if err := fs.Set(name, value); err != nil {
+			errs = append(errs, fmt.Sprintf("%s:%s: invalid value %q for option %q", filename, opt.Pos(), value, name))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// builderOptions returns the builder options of the flags of fs explicitly
+// set on the command line, which take precedence over the options block
+// of the grammar.
+func builderOptions(fs *flag.FlagSet) []builderGo.Option {
+	var opts []builderGo.Option
+	fs.Visit(func(f *flag.Flag) {
+		// the values of the flags are valid values of the options
+		if opt, _ := builderGo.NamedOption(f.Name, f.Value.String()); opt != nil {
+			opts = append(opts, opt)
+		}
+	})
+	return opts
+}
+
 // argError prints an error message to stderr, prints the command usage
 // and exits with the specified exit code.
 func argError(exitCode int, msg string, args ...any) {
@@ -1,32 +1,51 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/interp"
 )
 
 func TestMain(t *testing.T) {
-	stdout, stderr := os.Stdout, os.Stderr
-	os.Stdout, _ = os.Open(os.DevNull)
-	os.Stderr, _ = os.Open(os.DevNull)
-	defer func() {
-		exit = os.Exit
-		os.Stdout = stdout
-		os.Stderr = stderr
-	}()
-	exit = func(code int) {
-		panic(code)
-	}
+	silenceMain(t)
 
 	cases := []struct {
 		args string
 		code int
 	}{
-		{args: "", code: 3},            // stdin: no match found
-		{args: "-h", code: 0},          // help
-		{args: "FILE1 FILE2", code: 1}, // want only 1 non-flag arg
-		{args: "-x", code: 3},          // stdin: no match found
+		{args: "", code: 3},                  // stdin: no match found
+		{args: "-h", code: 0},                // help
+		{args: "FILE1 FILE2", code: 1},       // want only 1 non-flag arg
+		{args: "-x", code: 3},                // stdin: no match found
+		{args: "-dump-optimized", code: 3},   // stdin: no match found
+		{args: "lint", code: 3},              // stdin: no match found
+		{args: "lint -h", code: 0},           // help
+		{args: "lint A B", code: 1},          // want only 1 non-flag arg
+		{args: "fmt -h", code: 0},            // help
+		{args: "fmt -w", code: 1},            // -w with stdin
+		{args: "fmt A", code: 2},             // no such file
+		{args: "diagram -h", code: 0},        // help
+		{args: "diagram A B", code: 1},       // want only 1 non-flag arg
+		{args: "diagram -format x", code: 1}, // unknown format
+		{args: "ebnf -h", code: 0},           // help
+		{args: "ebnf -style x", code: 1},     // unknown style
+		{args: "analyze -h", code: 0},        // help
+		{args: "analyze A B", code: 1},       // want only 1 non-flag arg
+		{args: "lsp -h", code: 0},            // help
+		{args: "lsp A", code: 1},             // want no non-flag arg
+		{args: "run -h", code: 0},            // help
+		{args: "run", code: 1},               // want 1 or 2 non-flag args
+		{args: "run A", code: 2},             // no such file
+		{args: "gen-samples -h", code: 0},    // help
+		{args: "gen-samples A B", code: 1},   // want only 1 non-flag arg
+		{args: "gen-samples -n -1", code: 1}, // negative count
 	}
 
 	for _, tc := range cases {
@@ -39,6 +58,27 @@ func TestMain(t *testing.T) {
 	}
 }
 
+// silenceMain discards the output of the command-line tool and makes its
+// exit panic with the exit code, recovered by runMainRecover, until the end
+// of the test.
+func silenceMain(t *testing.T) {
+	t.Helper()
+
+	stdout, stderr := os.Stdout, os.Stderr
+	os.Stdout, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	os.Stderr, _ = os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	t.Cleanup(func() {
+		exit = os.Exit
+		os.Stdout.Close()
+		os.Stderr.Close()
+		os.Stdout = stdout
+		os.Stderr = stderr
+	})
+	exit = func(code int) {
+		panic(code)
+	}
+}
+
 func runMainRecover() (code int) {
 	defer func() {
 		if e := recover(); e != nil {
@@ -52,3 +92,311 @@ func runMainRecover() (code int) {
 	main()
 	return 0
 }
+
+func TestApplyGrammarOptions(t *testing.T) {
+	src := `@options {
+	receiver-name = p
+	nolint = true
+	typecheck = true
+	optimize-grammar = true
+	alternate-entrypoints = b, c
+}
+a = b / c
+b = "b"
+c = "c"
+`
+	g, err := Parse("file", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("pigeon", flag.ContinueOnError)
+	recvName := fs.String("receiver-name", "c", "")
+	nolint := fs.Bool("nolint", false, "")
+	typecheck := fs.Bool("typecheck", false, "")
+	optimizeGrammar := fs.Bool("optimize-grammar", false, "")
+	var altEntrypoints ruleNamesFlag
+	fs.Var(&altEntrypoints, "alternate-entrypoints", "")
+	if err := fs.Parse([]string{"-optimize-grammar=false"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := applyGrammarOptions(fs, "file", g.(*ast.Grammar)); err != nil {
+		t.Fatal(err)
+	}
+	// the builder options are applied by the builder
+	if *recvName != "c" {
+		t.Errorf("want receiver-name %q, got %q", "c", *recvName)
+	}
+	if *nolint {
+		t.Errorf("want nolint %t, got %t", false, *nolint)
+	}
+	if !*typecheck {
+		t.Errorf("want typecheck %t, got %t", true, *typecheck)
+	}
+	if *optimizeGrammar {
+		t.Errorf("want optimize-grammar set on the command line %t, got %t", false, *optimizeGrammar)
+	}
+	if want := []string{"b", "c"}; !reflect.DeepEqual([]string(altEntrypoints), want) {
+		t.Errorf("want alternate-entrypoints %v, got %v", want, altEntrypoints)
+	}
+}
+
+func TestApplyGrammarOptionsErrors(t *testing.T) {
+	src := `@options {
+	unknown = "x"
+	nolint = maybe
+	grammar-name = a, b
+	nolint = true
+	typecheck = maybe
+}
+a = "a"
+`
+	g, err := Parse("file", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("pigeon", flag.ContinueOnError)
+	fs.Bool("typecheck", false, "")
+
+	err = applyGrammarOptions(fs, "file", g.(*ast.Grammar))
+	want := `file:2:2 (12): unknown option "unknown"
+file:3:2 (27): invalid value "maybe" for option "nolint"
+file:4:2 (43): option "grammar-name" expects a single value
+file:5:2 (64): option "nolint" set more than once
+file:6:2 (79): invalid value "maybe" for option "typecheck"`
+	if err == nil || err.Error() != want {
+		t.Errorf("want error:\n%s\ngot:\n%v", want, err)
+	}
+}
+
+func TestLintMain(t *testing.T) {
+	silenceMain(t)
+
+	dir := t.TempDir()
+	cases := []struct {
+		grammar string
+		args    string
+		code    int
+	}{
+		{grammar: "start = 'a'\n", code: 0},
+		{grammar: "start = 'a'\nother = 'b'\n", code: 1},
+		{grammar: "start = 'a'\nother = 'b'\n", args: "-alternate-entrypoints other", code: 0},
+		{grammar: "start = a a\na = 'a'\n", code: 0},
+		{grammar: "start = 'a' b\nb \"b\" = 'b'?\n", args: "-json", code: 1},
+	}
+
+	for i, tc := range cases {
+		file := filepath.Join(dir, fmt.Sprintf("%d.peg", i))
+		if err := os.WriteFile(file, []byte(tc.grammar), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		os.Args = append(append([]string{"pigeon", "lint"}, strings.Fields(tc.args)...), file)
+
+		got := runMainRecover()
+		if got != tc.code {
+			t.Errorf("%q %s: want code %d, got %d", tc.grammar, tc.args, tc.code, got)
+		}
+	}
+}
+
+func TestDumpOptimized(t *testing.T) {
+	silenceMain(t)
+
+	dir := t.TempDir()
+	cases := []struct {
+		grammar string
+		args    string
+		want    string
+	}{
+		{grammar: "start = a 'c'\na = 'a' b b\nb = 'b'\n", want: "start ← \"abbc\"\n"},
+		{grammar: "start = 'a'\nother = 'b'\n", want: "start ← \"a\"\n"},
+		{grammar: "start = 'a'\nother = 'b'\n", args: "-alternate-entrypoints other", want: "start ← \"a\"\n\nother ← \"b\"\n"},
+	}
+
+	for i, tc := range cases {
+		file := filepath.Join(dir, fmt.Sprintf("%d.peg", i))
+		if err := os.WriteFile(file, []byte(tc.grammar), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		out := filepath.Join(dir, fmt.Sprintf("%d.out", i))
+		os.Args = append(append([]string{"pigeon", "-dump-optimized", "-o", out}, strings.Fields(tc.args)...), file)
+
+		if code := runMainRecover(); code != 0 {
+			t.Errorf("%q %s: want code 0, got %d", tc.grammar, tc.args, code)
+			continue
+		}
+		b, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := string(b); got != tc.want {
+			t.Errorf("%q %s: want\n%s\ngot\n%s", tc.grammar, tc.args, tc.want, got)
+		}
+	}
+}
+
+func TestOptimizeGrammarMatches(t *testing.T) {
+	cases := []struct {
+		grammar string
+		inputs  []string
+	}{
+		{
+			// the labels of an inlined rule would shadow the labels of the
+			// caller
+			grammar: "Start ← x:<\"a\"> Tag $x !.\nTag ← x:<[bc]> $x\n",
+			inputs:  []string{"abba", "acca", "abb", "abbb", "abca"},
+		},
+		{
+			// the trivia is not inserted in a @token rule
+			grammar: "Start ← Pair !.\n@token Pair ← Inner '!'\nInner ← Word Word\n@token Word ← [a-z]+\n@trivia _ ← ' '*\n",
+			inputs:  []string{"abcd!", "ab cd!", " ab  cd!", "ab cd !"},
+		},
+		{
+			grammar: "Start ← A B+ !.\nA ← 'a' C\nB ← 'b' / C\nC ← [cd]\n",
+			inputs:  []string{"acb", "adcdb", "ab", "acbe"},
+		},
+	}
+	for _, tc := range cases {
+		run := func(optimize bool) []bool {
+			g, err := Parse("", []byte(tc.grammar))
+			if err != nil {
+				t.Fatal(err)
+			}
+			grammar := g.(*ast.Grammar)
+			if optimize {
+				optimizeGrammar(grammar, nil)
+			}
+			in, err := interp.New(grammar)
+			if err != nil {
+				t.Fatal(err)
+			}
+			matches := make([]bool, len(tc.inputs))
+			for i, input := range tc.inputs {
+				res, err := in.Run("", []byte(input))
+				if err != nil {
+					t.Fatal(err)
+				}
+				matches[i] = res.Match != nil
+			}
+			return matches
+		}
+
+		want, got := run(false), run(true)
+		for i, input := range tc.inputs {
+			if got[i] != want[i] {
+				t.Errorf("%q: %q: want match %t with the optimized grammar, got %t", tc.grammar, input, want[i], got[i])
+			}
+		}
+	}
+}
+
+func TestTypecheck(t *testing.T) {
+	silenceMain(t)
+
+	dir := t.TempDir()
+	data := "package p\n\ntype ParserCustomData struct{}\n"
+	if err := os.WriteFile(filepath.Join(dir, "data.go"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		grammar string
+		code    int
+	}{
+		{grammar: "{\npackage p\n}\nstart = 'a' { return 1 }\n", code: 0},
+		{grammar: "{\npackage p\n}\nstart = 'a' { return undefined }\n", code: 10},
+		{grammar: "{\npackage p\n}\nstart = 'a' { return := }\n", code: 6},
+	}
+
+	for i, tc := range cases {
+		file := filepath.Join(dir, fmt.Sprintf("%d.peg", i))
+		if err := os.WriteFile(file, []byte(tc.grammar), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		os.Args = []string{"pigeon", "-typecheck", "-o", filepath.Join(dir, "parser.go"), file}
+
+		if code := runMainRecover(); code != tc.code {
+			t.Errorf("%q: want code %d, got %d", tc.grammar, tc.code, code)
+		}
+	}
+}
+
+func TestLineDirectiveFiles(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd = filepath.ToSlash(wd)
+
+	cases := []struct {
+		grammar, output     string
+		wantGrammar, wantGo string
+	}{
+		{grammar: "g.peg", wantGrammar: "g.peg", wantGo: "g.go"},
+		{grammar: "grammar/g.peg", wantGrammar: "grammar/g.peg", wantGo: "g.go"},
+		{grammar: "grammar/g.peg", output: "grammar/parser.go", wantGrammar: "g.peg", wantGo: "parser.go"},
+		{grammar: "grammar/g.peg", output: "parser/parser.go", wantGrammar: "../grammar/g.peg", wantGo: "parser.go"},
+		{grammar: "grammar/g.peg", output: wd + "/parser/parser.go", wantGrammar: "../grammar/g.peg", wantGo: "parser.go"},
+		{grammar: wd + "/grammar/g.peg", output: "parser/parser.go", wantGrammar: "../grammar/g.peg", wantGo: "parser.go"},
+	}
+	for _, tc := range cases {
+		gotGrammar, gotGo := lineDirectiveFiles(filepath.FromSlash(tc.grammar), filepath.FromSlash(tc.output))
+		if gotGrammar != tc.wantGrammar || gotGo != tc.wantGo {
+			t.Errorf("%s %s: want %s %s, got %s %s", tc.grammar, tc.output, tc.wantGrammar, tc.wantGo, gotGrammar, gotGo)
+		}
+	}
+}
+
+func TestWriteCorpus(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "testdata", "fuzz", "FuzzParse")
+	if err := writeCorpus(dir, [][]byte{[]byte("a\n\"b\""), []byte("")}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, entry.Name()+": "+string(b))
+	}
+	want := []string{
+		"90febc1539fc8a26: go test fuzz v1\n[]byte(\"a\\n\\\"b\\\"\")\n",
+		"e3b0c44298fc1c14: go test fuzz v1\n[]byte(\"\")\n",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want files:\n%q\ngot:\n%q", want, got)
+	}
+}
+
+func TestFuzzTest(t *testing.T) {
+	silenceMain(t)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "g.peg")
+	if err := os.WriteFile(file, []byte("{\npackage p\n}\nstart = 'a'\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Args = []string{"pigeon", "-fuzz-test", file}
+	if code := runMainRecover(); code != 1 {
+		t.Errorf("without -o: want code %d, got %d", 1, code)
+	}
+
+	os.Args = []string{"pigeon", "-fuzz-test", "-o", filepath.Join(dir, "parser.go"), file}
+	if code := runMainRecover(); code != 0 {
+		t.Fatalf("want code %d, got %d", 0, code)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "parser_fuzz_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "func FuzzParse(f *testing.F) {") {
+		t.Errorf("want the FuzzParse function, got:\n%s", b)
+	}
+}
@@ -7,15 +7,15 @@ import (
 )
 
 var invalidParseCases = map[string]string{
-	"":           `file:1:1 (0): no match found, expected: "/*", "//", "\n", "{", [ \t\r] or [\pL_]`,
+	"":           `file:1:1 (0): no match found, expected: "/*", "//", "@", "@options", "\n", "{", [ \t\r] or [\pL_]`,
 	"a":          `file:1:2 (1): no match found, expected: "'", "/*", "//", "<-", "=", "\"", "\n", "` + "`" + `", "←", "⟵", [ \t\r], [\pL_] or [\p{Nd}]`,
 	"abc":        `file:1:4 (3): no match found, expected: "'", "/*", "//", "<-", "=", "\"", "\n", "` + "`" + `", "←", "⟵", [ \t\r], [\pL_] or [\p{Nd}]`,
-	" ":          `file:1:2 (1): no match found, expected: "/*", "//", "\n", "{", [ \t\r] or [\pL_]`,
-	`a = +`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
-	`a = *`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
-	`a = ?`:      `file:1:5 (4): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
-	"a ←":        `file:1:4 (5): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
-	"a ← b\nb ←": `file:2:4 (13): no match found, expected: "!", "#", "%", "&", "'", "(", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", [ \t\r] or [\pL_]`,
+	" ":          `file:1:2 (1): no match found, expected: "/*", "//", "@", "@options", "\n", "{", [ \t\r] or [\pL_]`,
+	`a = +`:      `file:1:5 (4): no match found, expected: "!!", "!", "$", "%", "%precedence", "&", "&&", "'", "(", "*", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", "{", [ \t\r] or [\pL_]`,
+	`a = *`:      `file:1:6 (5): no match found, expected: "/*", "//", "\n", "{" or [ \t\r]`,
+	`a = ?`:      `file:1:5 (4): no match found, expected: "!!", "!", "$", "%", "%precedence", "&", "&&", "'", "(", "*", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", "{", [ \t\r] or [\pL_]`,
+	"a ←":        `file:1:4 (5): no match found, expected: "!!", "!", "$", "%", "%precedence", "&", "&&", "'", "(", "*", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", "{", [ \t\r] or [\pL_]`,
+	"a ← b\nb ←": `file:2:4 (13): no match found, expected: "!!", "!", "$", "%", "%precedence", "&", "&&", "'", "(", "*", ".", "/*", "//", "[", "\"", "\n", "` + "`" + `", "{", [ \t\r] or [\pL_]`,
 	"a ← nil:b":  "file:1:5 (6): rule Identifier: identifier is a reserved word",
 	"\xfe":       "file:1:1 (0): invalid encoding",
 	"{}{}":       `file:1:3 (2): no match found, expected: "/*", "//", ";", "\n", [ \t\r] or EOF`,
@@ -165,6 +165,23 @@ file:1:26 (25): rule ShortUnicodeEscape: invalid Unicode escape`,
 	`a = "\U0000DFFF"`: "file:1:7 (6): rule LongUnicodeEscape: invalid Unicode escape",
 	`a = "\U0000D800"`: "file:1:7 (6): rule LongUnicodeEscape: invalid Unicode escape",
 	`a = "\U0000D801"`: "file:1:7 (6): rule LongUnicodeEscape: invalid Unicode escape",
+
+	// unterminated nested character class
+	`a = [\pL--[b]`:      "file:1:5 (4): rule CharClassMatcher: character class not terminated",
+	`a = [\pL&&\p{Foo}]`: "file:1:13 (12): rule UnicodeClassEscape: invalid Unicode class escape",
+
+	// unknown rule annotation
+	"@tok a = b": "file:1:1 (0): rule RuleAnnotation: unknown rule annotation",
+
+	// precedence expression without levels
+	"a = %precedence b {}": `file:1:20 (19): no match found, expected: "/*", "//", "\n", "left", "postfix", "prefix", "right" or [ \t\r]`,
+
+	// invalid repetition bounds
+	`a = "a"{3,1}`: "file:1:8 (7): rule RepeatBounds: invalid repetition bounds: min greater than max",
+
+	// invalid option value
+	"@options { receiver-name = \"\\q\" }\na = \"a\"": `file:1:30 (29): rule DoubleStringEscape: invalid escape character
+file:1:28 (27): rule OptionValue: invalid option value`,
 }
 
 func TestInvalidParseCases(t *testing.T) {
@@ -420,6 +437,204 @@ var validParseCases = map[string]*ast.Grammar{
 			},
 		},
 	},
+	"a = b{2}": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.RepeatExpr{
+					Expr: &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+					Min:  2,
+					Max:  2,
+				},
+			},
+		},
+	},
+	"a = b{2,}": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.RepeatExpr{
+					Expr: &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+					Min:  2,
+					Max:  -1,
+				},
+			},
+		},
+	},
+	"a = b{ 2 , 3 }": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.RepeatExpr{
+					Expr: &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+					Min:  2,
+					Max:  3,
+				},
+			},
+		},
+	},
+	"a = b ++ \",\"": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.SeparatedExpr{
+					Expr:          &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+					Sep:           ast.NewLitMatcher(ast.Pos{}, ","),
+					Min:           1,
+					AllowTrailing: false,
+				},
+			},
+		},
+	},
+	"a = b**\",\"": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.SeparatedExpr{
+					Expr:          &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+					Sep:           ast.NewLitMatcher(ast.Pos{}, ","),
+					Min:           0,
+					AllowTrailing: false,
+				},
+			},
+		},
+	},
+	"a = b ++? \",\"": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.SeparatedExpr{
+					Expr:          &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+					Sep:           ast.NewLitMatcher(ast.Pos{}, ","),
+					Min:           1,
+					AllowTrailing: true,
+				},
+			},
+		},
+	},
+	"a = b\n  **? \",\"": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.SeparatedExpr{
+					Expr:          &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+					Sep:           ast.NewLitMatcher(ast.Pos{}, ","),
+					Min:           0,
+					AllowTrailing: true,
+				},
+			},
+		},
+	},
+	"a = %precedence b {\n\tleft '+' { return l } / '-'\n\tright \"^\"\n\tprefix '-'\n\tpostfix '!'\n}": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.PrecedenceExpr{
+					Atom: &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+					Levels: []*ast.PrecedenceLevel{
+						{
+							Kind: ast.LeftAssoc,
+							Operators: []*ast.Operator{
+								{Expr: ast.NewLitMatcher(ast.Pos{}, "+"), Code: ast.NewCodeBlock(ast.Pos{}, "{ return l }")},
+								{Expr: ast.NewLitMatcher(ast.Pos{}, "-")},
+							},
+						},
+						{
+							Kind:      ast.RightAssoc,
+							Operators: []*ast.Operator{{Expr: ast.NewLitMatcher(ast.Pos{}, "^")}},
+						},
+						{
+							Kind:      ast.Prefix,
+							Operators: []*ast.Operator{{Expr: ast.NewLitMatcher(ast.Pos{}, "-")}},
+						},
+						{
+							Kind:      ast.Postfix,
+							Operators: []*ast.Operator{{Expr: ast.NewLitMatcher(ast.Pos{}, "!")}},
+						},
+					},
+				},
+			},
+		},
+	},
+	"a = tag:<b> c $tag": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.SeqExpr{
+					Exprs: []ast.Expression{
+						&ast.LabeledExpr{
+							Label:       ast.NewIdentifier(ast.Pos{}, "tag"),
+							Expr:        &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+							TextCapture: true,
+						},
+						&ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "c")},
+						&ast.BackRefExpr{Label: ast.NewIdentifier(ast.Pos{}, "tag")},
+					},
+				},
+			},
+		},
+	},
+	`a = [\p{Greek}&&\p{Lu}--[ΑΩ]]i`: {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: ast.NewCharClassMatcher(ast.Pos{}, `[\p{Greek}&&\p{Lu}--[ΑΩ]]i`),
+			},
+		},
+	},
+	"{ init }\n@options {\n\treceiver-name = p\n\tnolint = true // comment\n\talternate-entrypoints = b, \"c\"\n}\na = b": {
+		Init: ast.NewCodeBlock(ast.Pos{}, "{ init }"),
+		Options: []*ast.Option{
+			{Name: ast.NewIdentifier(ast.Pos{}, "receiver-name"), Values: []string{"p"}},
+			{Name: ast.NewIdentifier(ast.Pos{}, "nolint"), Values: []string{"true"}},
+			{Name: ast.NewIdentifier(ast.Pos{}, "alternate-entrypoints"), Values: []string{"b", "c"}},
+		},
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.RuleRefExpr{Name: ast.NewIdentifier(ast.Pos{}, "b")},
+			},
+		},
+	},
+	"@token a = 'a'\n@trivia\n_ = ' '": {
+		Rules: []*ast.Rule{
+			{
+				Name:        ast.NewIdentifier(ast.Pos{}, "a"),
+				Annotations: []*ast.Identifier{ast.NewIdentifier(ast.Pos{}, "token")},
+				Expr:        ast.NewLitMatcher(ast.Pos{}, "a"),
+			},
+			{
+				Name:        ast.NewIdentifier(ast.Pos{}, "_"),
+				Annotations: []*ast.Identifier{ast.NewIdentifier(ast.Pos{}, "trivia")},
+				Expr:        ast.NewLitMatcher(ast.Pos{}, " "),
+			},
+		},
+	},
+	"a = \"if\"k / \"else\"ik\n@word\nw = [a-z]": {
+		Rules: []*ast.Rule{
+			{
+				Name: ast.NewIdentifier(ast.Pos{}, "a"),
+				Expr: &ast.ChoiceExpr{
+					Alternatives: []ast.Expression{
+						newKeywordLit("if", false),
+						newKeywordLit("else", true),
+					},
+				},
+			},
+			{
+				Name:        ast.NewIdentifier(ast.Pos{}, "w"),
+				Annotations: []*ast.Identifier{ast.NewIdentifier(ast.Pos{}, "word")},
+				Expr:        &ast.CharClassMatcher{Ranges: []rune{'a', 'z'}},
+			},
+		},
+	},
+}
+
+func newKeywordLit(val string, ignoreCase bool) *ast.LitMatcher {
+	lit := ast.NewLitMatcher(ast.Pos{}, val)
+	lit.IgnoreCase = ignoreCase
+	lit.Keyword = true
+	return lit
 }
 
 func TestValidParseCases(t *testing.T) {
@@ -17,6 +17,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/builder"
 )
 
 var g = &grammar{
@@ -56,18 +57,38 @@ var g = &grammar{
 						},
 						&labeledExpr{
 							pos:   position{line: 5, col: 46, offset: 65},
+							label: "options",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 5, col: 54, offset: 73},
+								expr: &seqExpr{
+									pos: position{line: 5, col: 56, offset: 75},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 5, col: 56, offset: 75},
+											name: "Options",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 5, col: 64, offset: 83},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 5, col: 70, offset: 89},
 							label: "rules",
 							expr: &oneOrMoreExpr{
-								pos: position{line: 5, col: 52, offset: 71},
+								pos: position{line: 5, col: 76, offset: 95},
 								expr: &seqExpr{
-									pos: position{line: 5, col: 54, offset: 73},
+									pos: position{line: 5, col: 78, offset: 97},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 5, col: 54, offset: 73},
+											pos:  position{line: 5, col: 78, offset: 97},
 											name: "Rule",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 5, col: 59, offset: 78},
+											pos:  position{line: 5, col: 83, offset: 102},
 											name: "__",
 										},
 									},
@@ -75,7 +96,7 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 5, col: 65, offset: 84},
+							pos:  position{line: 5, col: 89, offset: 108},
 							name: "EOF",
 						},
 					},
@@ -84,64 +105,284 @@ var g = &grammar{
 		},
 		{
 			name: "Initializer",
-			pos:  position{line: 24, col: 1, offset: 513},
+			pos:  position{line: 28, col: 1, offset: 678},
 			expr: &actionExpr{
-				pos: position{line: 24, col: 15, offset: 529},
+				pos: position{line: 28, col: 15, offset: 694},
 				run: (*parser).callonInitializer1,
 				expr: &seqExpr{
-					pos: position{line: 24, col: 15, offset: 529},
+					pos: position{line: 28, col: 15, offset: 694},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 24, col: 15, offset: 529},
+							pos:   position{line: 28, col: 15, offset: 694},
 							label: "code",
 							expr: &ruleRefExpr{
-								pos:  position{line: 24, col: 20, offset: 534},
+								pos:  position{line: 28, col: 20, offset: 699},
 								name: "CodeBlock",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 24, col: 30, offset: 544},
+							pos:  position{line: 28, col: 30, offset: 709},
 							name: "EOS",
 						},
 					},
 				},
 			},
 		},
+		{
+			name: "Options",
+			pos:  position{line: 32, col: 1, offset: 739},
+			expr: &actionExpr{
+				pos: position{line: 32, col: 11, offset: 751},
+				run: (*parser).callonOptions1,
+				expr: &seqExpr{
+					pos: position{line: 32, col: 11, offset: 751},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 32, col: 11, offset: 751},
+							val:        "@options",
+							ignoreCase: false,
+							want:       "\"@options\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 32, col: 22, offset: 762},
+							name: "__",
+						},
+						&litMatcher{
+							pos:        position{line: 32, col: 25, offset: 765},
+							val:        "{",
+							ignoreCase: false,
+							want:       "\"{\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 32, col: 29, offset: 769},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 32, col: 32, offset: 772},
+							label: "entries",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 32, col: 40, offset: 780},
+								expr: &seqExpr{
+									pos: position{line: 32, col: 42, offset: 782},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 32, col: 42, offset: 782},
+											name: "Option",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 32, col: 49, offset: 789},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 32, col: 55, offset: 795},
+							val:        "}",
+							ignoreCase: false,
+							want:       "\"}\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 32, col: 59, offset: 799},
+							name: "EOS",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Option",
+			pos:  position{line: 41, col: 1, offset: 1012},
+			expr: &actionExpr{
+				pos: position{line: 41, col: 10, offset: 1023},
+				run: (*parser).callonOption1,
+				expr: &seqExpr{
+					pos: position{line: 41, col: 10, offset: 1023},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 41, col: 10, offset: 1023},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 41, col: 15, offset: 1028},
+								name: "OptionName",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 41, col: 26, offset: 1039},
+							name: "__",
+						},
+						&litMatcher{
+							pos:        position{line: 41, col: 29, offset: 1042},
+							val:        "=",
+							ignoreCase: false,
+							want:       "\"=\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 41, col: 33, offset: 1046},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 41, col: 36, offset: 1049},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 41, col: 42, offset: 1055},
+								name: "OptionValue",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 41, col: 54, offset: 1067},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 41, col: 59, offset: 1072},
+								expr: &seqExpr{
+									pos: position{line: 41, col: 61, offset: 1074},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 41, col: 61, offset: 1074},
+											name: "_",
+										},
+										&litMatcher{
+											pos:        position{line: 41, col: 63, offset: 1076},
+											val:        ",",
+											ignoreCase: false,
+											want:       "\",\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 41, col: 67, offset: 1080},
+											name: "__",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 41, col: 70, offset: 1083},
+											name: "OptionValue",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "OptionName",
+			pos:  position{line: 51, col: 1, offset: 1365},
+			expr: &actionExpr{
+				pos: position{line: 51, col: 14, offset: 1380},
+				run: (*parser).callonOptionName1,
+				expr: &seqExpr{
+					pos: position{line: 51, col: 14, offset: 1380},
+					exprs: []any{
+						&ruleRefExpr{
+							pos:  position{line: 51, col: 14, offset: 1380},
+							name: "IdentifierStart",
+						},
+						&zeroOrMoreExpr{
+							pos: position{line: 51, col: 30, offset: 1396},
+							expr: &choiceExpr{
+								pos: position{line: 51, col: 32, offset: 1398},
+								alternatives: []any{
+									&ruleRefExpr{
+										pos:  position{line: 51, col: 32, offset: 1398},
+										name: "IdentifierPart",
+									},
+									&litMatcher{
+										pos:        position{line: 51, col: 49, offset: 1415},
+										val:        "-",
+										ignoreCase: false,
+										want:       "\"-\"",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "OptionValue",
+			pos:  position{line: 55, col: 1, offset: 1489},
+			expr: &choiceExpr{
+				pos: position{line: 55, col: 15, offset: 1505},
+				alternatives: []any{
+					&actionExpr{
+						pos: position{line: 55, col: 15, offset: 1505},
+						run: (*parser).callonOptionValue2,
+						expr: &labeledExpr{
+							pos:   position{line: 55, col: 15, offset: 1505},
+							label: "lit",
+							expr: &ruleRefExpr{
+								pos:  position{line: 55, col: 19, offset: 1509},
+								name: "StringLiteral",
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 61, col: 5, offset: 1683},
+						run: (*parser).callonOptionValue5,
+						expr: &ruleRefExpr{
+							pos:  position{line: 61, col: 5, offset: 1683},
+							name: "IdentifierName",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Rule",
-			pos:  position{line: 28, col: 1, offset: 574},
+			pos:  position{line: 65, col: 1, offset: 1734},
 			expr: &actionExpr{
-				pos: position{line: 28, col: 8, offset: 583},
+				pos: position{line: 65, col: 8, offset: 1743},
 				run: (*parser).callonRule1,
 				expr: &seqExpr{
-					pos: position{line: 28, col: 8, offset: 583},
+					pos: position{line: 65, col: 8, offset: 1743},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 28, col: 8, offset: 583},
+							pos:   position{line: 65, col: 8, offset: 1743},
+							label: "annotations",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 65, col: 20, offset: 1755},
+								expr: &seqExpr{
+									pos: position{line: 65, col: 22, offset: 1757},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 65, col: 22, offset: 1757},
+											name: "RuleAnnotation",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 65, col: 37, offset: 1772},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 65, col: 43, offset: 1778},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 28, col: 13, offset: 588},
+								pos:  position{line: 65, col: 48, offset: 1783},
 								name: "IdentifierName",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 28, offset: 603},
+							pos:  position{line: 65, col: 63, offset: 1798},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 28, col: 31, offset: 606},
+							pos:   position{line: 65, col: 66, offset: 1801},
 							label: "display",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 28, col: 39, offset: 614},
+								pos: position{line: 65, col: 74, offset: 1809},
 								expr: &seqExpr{
-									pos: position{line: 28, col: 41, offset: 616},
+									pos: position{line: 65, col: 76, offset: 1811},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 28, col: 41, offset: 616},
+											pos:  position{line: 65, col: 76, offset: 1811},
 											name: "StringLiteral",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 28, col: 55, offset: 630},
+											pos:  position{line: 65, col: 90, offset: 1825},
 											name: "__",
 										},
 									},
@@ -149,96 +390,123 @@ var g = &grammar{
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 61, offset: 636},
+							pos:  position{line: 65, col: 96, offset: 1831},
 							name: "RuleDefOp",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 71, offset: 646},
+							pos:  position{line: 65, col: 106, offset: 1841},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 28, col: 74, offset: 649},
+							pos:   position{line: 65, col: 109, offset: 1844},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 28, col: 79, offset: 654},
+								pos:  position{line: 65, col: 114, offset: 1849},
 								name: "Expression",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 28, col: 90, offset: 665},
+							pos:  position{line: 65, col: 125, offset: 1860},
 							name: "EOS",
 						},
 					},
 				},
 			},
 		},
+		{
+			name: "RuleAnnotation",
+			pos:  position{line: 81, col: 1, offset: 2284},
+			expr: &actionExpr{
+				pos: position{line: 81, col: 18, offset: 2303},
+				run: (*parser).callonRuleAnnotation1,
+				expr: &seqExpr{
+					pos: position{line: 81, col: 18, offset: 2303},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 81, col: 18, offset: 2303},
+							val:        "@",
+							ignoreCase: false,
+							want:       "\"@\"",
+						},
+						&labeledExpr{
+							pos:   position{line: 81, col: 22, offset: 2307},
+							label: "name",
+							expr: &ruleRefExpr{
+								pos:  position{line: 81, col: 27, offset: 2312},
+								name: "IdentifierName",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "Expression",
-			pos:  position{line: 41, col: 1, offset: 947},
+			pos:  position{line: 89, col: 1, offset: 2501},
 			expr: &ruleRefExpr{
-				pos:  position{line: 41, col: 14, offset: 962},
+				pos:  position{line: 89, col: 14, offset: 2516},
 				name: "RecoveryExpr",
 			},
 		},
 		{
 			name: "RecoveryExpr",
-			pos:  position{line: 43, col: 1, offset: 976},
+			pos:  position{line: 91, col: 1, offset: 2530},
 			expr: &actionExpr{
-				pos: position{line: 43, col: 16, offset: 993},
+				pos: position{line: 91, col: 16, offset: 2547},
 				run: (*parser).callonRecoveryExpr1,
 				expr: &seqExpr{
-					pos: position{line: 43, col: 16, offset: 993},
+					pos: position{line: 91, col: 16, offset: 2547},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 43, col: 16, offset: 993},
+							pos:   position{line: 91, col: 16, offset: 2547},
 							label: "expr",
 							expr: &ruleRefExpr{
-								pos:  position{line: 43, col: 21, offset: 998},
+								pos:  position{line: 91, col: 21, offset: 2552},
 								name: "ChoiceExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 43, col: 32, offset: 1009},
+							pos:   position{line: 91, col: 32, offset: 2563},
 							label: "recoverExprs",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 43, col: 45, offset: 1022},
+								pos: position{line: 91, col: 45, offset: 2576},
 								expr: &seqExpr{
-									pos: position{line: 43, col: 47, offset: 1024},
+									pos: position{line: 91, col: 47, offset: 2578},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 47, offset: 1024},
+											pos:  position{line: 91, col: 47, offset: 2578},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 43, col: 50, offset: 1027},
+											pos:        position{line: 91, col: 50, offset: 2581},
 											val:        "//{",
 											ignoreCase: false,
 											want:       "\"//{\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 56, offset: 1033},
+											pos:  position{line: 91, col: 56, offset: 2587},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 59, offset: 1036},
+											pos:  position{line: 91, col: 59, offset: 2590},
 											name: "Labels",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 66, offset: 1043},
+											pos:  position{line: 91, col: 66, offset: 2597},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 43, col: 69, offset: 1046},
+											pos:        position{line: 91, col: 69, offset: 2600},
 											val:        "}",
 											ignoreCase: false,
 											want:       "\"}\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 73, offset: 1050},
+											pos:  position{line: 91, col: 73, offset: 2604},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 43, col: 76, offset: 1053},
+											pos:  position{line: 91, col: 76, offset: 2607},
 											name: "ChoiceExpr",
 										},
 									},
@@ -251,45 +519,45 @@ var g = &grammar{
 		},
 		{
 			name: "Labels",
-			pos:  position{line: 58, col: 1, offset: 1449},
+			pos:  position{line: 106, col: 1, offset: 3003},
 			expr: &actionExpr{
-				pos: position{line: 58, col: 10, offset: 1460},
+				pos: position{line: 106, col: 10, offset: 3014},
 				run: (*parser).callonLabels1,
 				expr: &seqExpr{
-					pos: position{line: 58, col: 10, offset: 1460},
+					pos: position{line: 106, col: 10, offset: 3014},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 58, col: 10, offset: 1460},
+							pos:   position{line: 106, col: 10, offset: 3014},
 							label: "label",
 							expr: &ruleRefExpr{
-								pos:  position{line: 58, col: 16, offset: 1466},
+								pos:  position{line: 106, col: 16, offset: 3020},
 								name: "IdentifierName",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 58, col: 31, offset: 1481},
+							pos:   position{line: 106, col: 31, offset: 3035},
 							label: "labels",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 58, col: 38, offset: 1488},
+								pos: position{line: 106, col: 38, offset: 3042},
 								expr: &seqExpr{
-									pos: position{line: 58, col: 40, offset: 1490},
+									pos: position{line: 106, col: 40, offset: 3044},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 58, col: 40, offset: 1490},
+											pos:  position{line: 106, col: 40, offset: 3044},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 58, col: 43, offset: 1493},
+											pos:        position{line: 106, col: 43, offset: 3047},
 											val:        ",",
 											ignoreCase: false,
 											want:       "\",\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 58, col: 47, offset: 1497},
+											pos:  position{line: 106, col: 47, offset: 3051},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 58, col: 50, offset: 1500},
+											pos:  position{line: 106, col: 50, offset: 3054},
 											name: "IdentifierName",
 										},
 									},
@@ -302,45 +570,45 @@ var g = &grammar{
 		},
 		{
 			name: "ChoiceExpr",
-			pos:  position{line: 67, col: 1, offset: 1819},
+			pos:  position{line: 115, col: 1, offset: 3373},
 			expr: &actionExpr{
-				pos: position{line: 67, col: 14, offset: 1834},
+				pos: position{line: 115, col: 14, offset: 3388},
 				run: (*parser).callonChoiceExpr1,
 				expr: &seqExpr{
-					pos: position{line: 67, col: 14, offset: 1834},
+					pos: position{line: 115, col: 14, offset: 3388},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 67, col: 14, offset: 1834},
+							pos:   position{line: 115, col: 14, offset: 3388},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 67, col: 20, offset: 1840},
+								pos:  position{line: 115, col: 20, offset: 3394},
 								name: "ActionSeqExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 67, col: 34, offset: 1854},
+							pos:   position{line: 115, col: 34, offset: 3408},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 67, col: 39, offset: 1859},
+								pos: position{line: 115, col: 39, offset: 3413},
 								expr: &seqExpr{
-									pos: position{line: 67, col: 41, offset: 1861},
+									pos: position{line: 115, col: 41, offset: 3415},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 67, col: 41, offset: 1861},
+											pos:  position{line: 115, col: 41, offset: 3415},
 											name: "__",
 										},
 										&litMatcher{
-											pos:        position{line: 67, col: 44, offset: 1864},
+											pos:        position{line: 115, col: 44, offset: 3418},
 											val:        "/",
 											ignoreCase: false,
 											want:       "\"/\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 67, col: 48, offset: 1868},
+											pos:  position{line: 115, col: 48, offset: 3422},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 67, col: 51, offset: 1871},
+											pos:  position{line: 115, col: 51, offset: 3425},
 											name: "ActionSeqExpr",
 										},
 									},
@@ -353,35 +621,35 @@ var g = &grammar{
 		},
 		{
 			name: "ActionSeqExpr",
-			pos:  position{line: 82, col: 1, offset: 2269},
+			pos:  position{line: 130, col: 1, offset: 3823},
 			expr: &actionExpr{
-				pos: position{line: 82, col: 17, offset: 2287},
+				pos: position{line: 130, col: 17, offset: 3841},
 				run: (*parser).callonActionSeqExpr1,
 				expr: &seqExpr{
-					pos: position{line: 82, col: 17, offset: 2287},
+					pos: position{line: 130, col: 17, offset: 3841},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 82, col: 17, offset: 2287},
+							pos:   position{line: 130, col: 17, offset: 3841},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 82, col: 23, offset: 2293},
+								pos:  position{line: 130, col: 23, offset: 3847},
 								name: "ActionExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 82, col: 34, offset: 2304},
+							pos:   position{line: 130, col: 34, offset: 3858},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 82, col: 39, offset: 2309},
+								pos: position{line: 130, col: 39, offset: 3863},
 								expr: &seqExpr{
-									pos: position{line: 82, col: 41, offset: 2311},
+									pos: position{line: 130, col: 41, offset: 3865},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 82, col: 41, offset: 2311},
+											pos:  position{line: 130, col: 41, offset: 3865},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 82, col: 44, offset: 2314},
+											pos:  position{line: 130, col: 44, offset: 3868},
 											name: "ActionExpr",
 										},
 									},
@@ -394,38 +662,38 @@ var g = &grammar{
 		},
 		{
 			name: "ActionExpr",
-			pos:  position{line: 95, col: 1, offset: 2654},
+			pos:  position{line: 143, col: 1, offset: 4208},
 			expr: &choiceExpr{
-				pos: position{line: 95, col: 14, offset: 2669},
+				pos: position{line: 143, col: 14, offset: 4223},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 95, col: 14, offset: 2669},
+						pos: position{line: 143, col: 14, offset: 4223},
 						run: (*parser).callonActionExpr2,
 						expr: &seqExpr{
-							pos: position{line: 95, col: 14, offset: 2669},
+							pos: position{line: 143, col: 14, offset: 4223},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 95, col: 14, offset: 2669},
+									pos:   position{line: 143, col: 14, offset: 4223},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 95, col: 19, offset: 2674},
+										pos:  position{line: 143, col: 19, offset: 4228},
 										name: "SeqExpr",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 95, col: 27, offset: 2682},
+									pos:   position{line: 143, col: 27, offset: 4236},
 									label: "code",
 									expr: &zeroOrOneExpr{
-										pos: position{line: 95, col: 32, offset: 2687},
+										pos: position{line: 143, col: 32, offset: 4241},
 										expr: &seqExpr{
-											pos: position{line: 95, col: 34, offset: 2689},
+											pos: position{line: 143, col: 34, offset: 4243},
 											exprs: []any{
 												&ruleRefExpr{
-													pos:  position{line: 95, col: 34, offset: 2689},
+													pos:  position{line: 143, col: 34, offset: 4243},
 													name: "__",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 95, col: 37, offset: 2692},
+													pos:  position{line: 143, col: 37, offset: 4246},
 													name: "CodeBlock",
 												},
 											},
@@ -436,20 +704,20 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 107, col: 5, offset: 2957},
+						pos: position{line: 155, col: 5, offset: 4511},
 						run: (*parser).callonActionExpr11,
 						expr: &seqExpr{
-							pos: position{line: 107, col: 5, offset: 2957},
+							pos: position{line: 155, col: 5, offset: 4511},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 107, col: 5, offset: 2957},
+									pos:  position{line: 155, col: 5, offset: 4511},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 107, col: 8, offset: 2960},
+									pos:   position{line: 155, col: 8, offset: 4514},
 									label: "code",
 									expr: &ruleRefExpr{
-										pos:  position{line: 107, col: 13, offset: 2965},
+										pos:  position{line: 155, col: 13, offset: 4519},
 										name: "CodeBlock",
 									},
 								},
@@ -461,35 +729,35 @@ var g = &grammar{
 		},
 		{
 			name: "SeqExpr",
-			pos:  position{line: 113, col: 1, offset: 3082},
+			pos:  position{line: 161, col: 1, offset: 4636},
 			expr: &actionExpr{
-				pos: position{line: 113, col: 11, offset: 3094},
+				pos: position{line: 161, col: 11, offset: 4648},
 				run: (*parser).callonSeqExpr1,
 				expr: &seqExpr{
-					pos: position{line: 113, col: 11, offset: 3094},
+					pos: position{line: 161, col: 11, offset: 4648},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 113, col: 11, offset: 3094},
+							pos:   position{line: 161, col: 11, offset: 4648},
 							label: "first",
 							expr: &ruleRefExpr{
-								pos:  position{line: 113, col: 17, offset: 3100},
+								pos:  position{line: 161, col: 17, offset: 4654},
 								name: "LabeledExpr",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 113, col: 29, offset: 3112},
+							pos:   position{line: 161, col: 29, offset: 4666},
 							label: "rest",
 							expr: &zeroOrMoreExpr{
-								pos: position{line: 113, col: 34, offset: 3117},
+								pos: position{line: 161, col: 34, offset: 4671},
 								expr: &seqExpr{
-									pos: position{line: 113, col: 36, offset: 3119},
+									pos: position{line: 161, col: 36, offset: 4673},
 									exprs: []any{
 										&ruleRefExpr{
-											pos:  position{line: 113, col: 36, offset: 3119},
+											pos:  position{line: 161, col: 36, offset: 4673},
 											name: "__",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 113, col: 39, offset: 3122},
+											pos:  position{line: 161, col: 39, offset: 4676},
 											name: "LabeledExpr",
 										},
 									},
@@ -502,62 +770,62 @@ var g = &grammar{
 		},
 		{
 			name: "LabeledExpr",
-			pos:  position{line: 126, col: 1, offset: 3463},
+			pos:  position{line: 174, col: 1, offset: 5017},
 			expr: &choiceExpr{
-				pos: position{line: 126, col: 15, offset: 3479},
+				pos: position{line: 174, col: 15, offset: 5033},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 126, col: 15, offset: 3479},
+						pos: position{line: 174, col: 15, offset: 5033},
 						run: (*parser).callonLabeledExpr2,
 						expr: &seqExpr{
-							pos: position{line: 126, col: 15, offset: 3479},
+							pos: position{line: 174, col: 15, offset: 5033},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 126, col: 15, offset: 3479},
+									pos:   position{line: 174, col: 15, offset: 5033},
 									label: "label",
 									expr: &ruleRefExpr{
-										pos:  position{line: 126, col: 21, offset: 3485},
+										pos:  position{line: 174, col: 21, offset: 5039},
 										name: "Identifier",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 126, col: 32, offset: 3496},
+									pos:  position{line: 174, col: 32, offset: 5050},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 126, col: 35, offset: 3499},
+									pos:        position{line: 174, col: 35, offset: 5053},
 									val:        ":",
 									ignoreCase: false,
 									want:       "\":\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 126, col: 39, offset: 3503},
+									pos:  position{line: 174, col: 39, offset: 5057},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 126, col: 42, offset: 3506},
+									pos:        position{line: 174, col: 42, offset: 5060},
 									val:        "<",
 									ignoreCase: false,
 									want:       "\"<\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 126, col: 46, offset: 3510},
+									pos:  position{line: 174, col: 46, offset: 5064},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 126, col: 49, offset: 3513},
+									pos:   position{line: 174, col: 49, offset: 5067},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 126, col: 54, offset: 3518},
+										pos:  position{line: 174, col: 54, offset: 5072},
 										name: "PrefixedExpr",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 126, col: 67, offset: 3531},
+									pos:  position{line: 174, col: 67, offset: 5085},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 126, col: 70, offset: 3534},
+									pos:        position{line: 174, col: 70, offset: 5088},
 									val:        ">",
 									ignoreCase: false,
 									want:       "\">\"",
@@ -566,38 +834,38 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 133, col: 5, offset: 3725},
+						pos: position{line: 181, col: 5, offset: 5279},
 						run: (*parser).callonLabeledExpr15,
 						expr: &seqExpr{
-							pos: position{line: 133, col: 5, offset: 3725},
+							pos: position{line: 181, col: 5, offset: 5279},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 133, col: 5, offset: 3725},
+									pos:   position{line: 181, col: 5, offset: 5279},
 									label: "label",
 									expr: &ruleRefExpr{
-										pos:  position{line: 133, col: 11, offset: 3731},
+										pos:  position{line: 181, col: 11, offset: 5285},
 										name: "Identifier",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 133, col: 22, offset: 3742},
+									pos:  position{line: 181, col: 22, offset: 5296},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 133, col: 25, offset: 3745},
+									pos:        position{line: 181, col: 25, offset: 5299},
 									val:        ":",
 									ignoreCase: false,
 									want:       "\":\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 133, col: 29, offset: 3749},
+									pos:  position{line: 181, col: 29, offset: 5303},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 133, col: 32, offset: 3752},
+									pos:   position{line: 181, col: 32, offset: 5306},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 133, col: 37, offset: 3757},
+										pos:  position{line: 181, col: 37, offset: 5311},
 										name: "PrefixedExpr",
 									},
 								},
@@ -605,11 +873,11 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 139, col: 5, offset: 3930},
+						pos:  position{line: 187, col: 5, offset: 5484},
 						name: "PrefixedExpr",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 139, col: 20, offset: 3945},
+						pos:  position{line: 187, col: 20, offset: 5499},
 						name: "ThrowExpr",
 					},
 				},
@@ -617,33 +885,33 @@ var g = &grammar{
 		},
 		{
 			name: "PrefixedExpr",
-			pos:  position{line: 141, col: 1, offset: 3956},
+			pos:  position{line: 189, col: 1, offset: 5510},
 			expr: &choiceExpr{
-				pos: position{line: 141, col: 16, offset: 3973},
+				pos: position{line: 189, col: 16, offset: 5527},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 141, col: 16, offset: 3973},
+						pos: position{line: 189, col: 16, offset: 5527},
 						run: (*parser).callonPrefixedExpr2,
 						expr: &seqExpr{
-							pos: position{line: 141, col: 16, offset: 3973},
+							pos: position{line: 189, col: 16, offset: 5527},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 141, col: 16, offset: 3973},
+									pos:   position{line: 189, col: 16, offset: 5527},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 141, col: 19, offset: 3976},
+										pos:  position{line: 189, col: 19, offset: 5530},
 										name: "PrefixedOp",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 141, col: 30, offset: 3987},
+									pos:  position{line: 189, col: 30, offset: 5541},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 141, col: 33, offset: 3990},
+									pos:   position{line: 189, col: 33, offset: 5544},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 141, col: 38, offset: 3995},
+										pos:  position{line: 189, col: 38, offset: 5549},
 										name: "SuffixedExpr",
 									},
 								},
@@ -651,7 +919,7 @@ var g = &grammar{
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 161, col: 5, offset: 4489},
+						pos:  position{line: 209, col: 5, offset: 6043},
 						name: "SuffixedExpr",
 					},
 				},
@@ -659,33 +927,33 @@ var g = &grammar{
 		},
 		{
 			name: "PrefixedOp",
-			pos:  position{line: 163, col: 1, offset: 4503},
+			pos:  position{line: 211, col: 1, offset: 6057},
 			expr: &actionExpr{
-				pos: position{line: 163, col: 14, offset: 4518},
+				pos: position{line: 211, col: 14, offset: 6072},
 				run: (*parser).callonPrefixedOp1,
 				expr: &choiceExpr{
-					pos: position{line: 163, col: 16, offset: 4520},
+					pos: position{line: 211, col: 16, offset: 6074},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 163, col: 16, offset: 4520},
+							pos:        position{line: 211, col: 16, offset: 6074},
 							val:        "&&",
 							ignoreCase: false,
 							want:       "\"&&\"",
 						},
 						&litMatcher{
-							pos:        position{line: 163, col: 23, offset: 4527},
+							pos:        position{line: 211, col: 23, offset: 6081},
 							val:        "!!",
 							ignoreCase: false,
 							want:       "\"!!\"",
 						},
 						&litMatcher{
-							pos:        position{line: 163, col: 30, offset: 4534},
+							pos:        position{line: 211, col: 30, offset: 6088},
 							val:        "&",
 							ignoreCase: false,
 							want:       "\"&\"",
 						},
 						&litMatcher{
-							pos:        position{line: 163, col: 36, offset: 4540},
+							pos:        position{line: 211, col: 36, offset: 6094},
 							val:        "!",
 							ignoreCase: false,
 							want:       "\"!\"",
@@ -696,37 +964,103 @@ var g = &grammar{
 		},
 		{
 			name: "SuffixedExpr",
-			pos:  position{line: 167, col: 1, offset: 4582},
+			pos:  position{line: 215, col: 1, offset: 6136},
 			expr: &choiceExpr{
-				pos: position{line: 167, col: 16, offset: 4599},
+				pos: position{line: 215, col: 16, offset: 6153},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 167, col: 16, offset: 4599},
+						pos: position{line: 215, col: 16, offset: 6153},
 						run: (*parser).callonSuffixedExpr2,
 						expr: &seqExpr{
-							pos: position{line: 167, col: 16, offset: 4599},
+							pos: position{line: 215, col: 16, offset: 6153},
+							exprs: []any{
+								&labeledExpr{
+									pos:   position{line: 215, col: 16, offset: 6153},
+									label: "expr",
+									expr: &ruleRefExpr{
+										pos:  position{line: 215, col: 21, offset: 6158},
+										name: "PrimaryExpr",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 215, col: 33, offset: 6170},
+									name: "__",
+								},
+								&labeledExpr{
+									pos:   position{line: 215, col: 36, offset: 6173},
+									label: "op",
+									expr: &ruleRefExpr{
+										pos:  position{line: 215, col: 39, offset: 6176},
+										name: "SeparatorOp",
+									},
+								},
+								&ruleRefExpr{
+									pos:  position{line: 215, col: 51, offset: 6188},
+									name: "__",
+								},
+								&labeledExpr{
+									pos:   position{line: 215, col: 54, offset: 6191},
+									label: "sep",
+									expr: &ruleRefExpr{
+										pos:  position{line: 215, col: 58, offset: 6195},
+										name: "PrimaryExpr",
+									},
+								},
+							},
+						},
+					},
+					&actionExpr{
+						pos: position{line: 226, col: 5, offset: 6515},
+						run: (*parser).callonSuffixedExpr12,
+						expr: &seqExpr{
+							pos: position{line: 226, col: 5, offset: 6515},
 							exprs: []any{
 								&labeledExpr{
-									pos:   position{line: 167, col: 16, offset: 4599},
+									pos:   position{line: 226, col: 5, offset: 6515},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 167, col: 21, offset: 4604},
+										pos:  position{line: 226, col: 10, offset: 6520},
 										name: "PrimaryExpr",
 									},
 								},
 								&labeledExpr{
-									pos:   position{line: 167, col: 33, offset: 4616},
+									pos:   position{line: 226, col: 22, offset: 6532},
 									label: "op",
 									expr: &ruleRefExpr{
-										pos:  position{line: 167, col: 36, offset: 4619},
+										pos:  position{line: 226, col: 25, offset: 6535},
 										name: "SuffixedOp",
 									},
 								},
 							},
 						},
 					},
+					&actionExpr{
+						pos: position{line: 245, col: 5, offset: 7065},
+						run: (*parser).callonSuffixedExpr18,
+						expr: &seqExpr{
+							pos: position{line: 245, col: 5, offset: 7065},
+							exprs: []any{
+								&labeledExpr{
+									pos:   position{line: 245, col: 5, offset: 7065},
+									label: "expr",
+									expr: &ruleRefExpr{
+										pos:  position{line: 245, col: 10, offset: 7070},
+										name: "PrimaryExpr",
+									},
+								},
+								&labeledExpr{
+									pos:   position{line: 245, col: 22, offset: 7082},
+									label: "bounds",
+									expr: &ruleRefExpr{
+										pos:  position{line: 245, col: 29, offset: 7089},
+										name: "RepeatBounds",
+									},
+								},
+							},
+						},
+					},
 					&ruleRefExpr{
-						pos:  position{line: 186, col: 5, offset: 5149},
+						pos:  position{line: 252, col: 5, offset: 7294},
 						name: "PrimaryExpr",
 					},
 				},
@@ -734,27 +1068,27 @@ var g = &grammar{
 		},
 		{
 			name: "SuffixedOp",
-			pos:  position{line: 188, col: 1, offset: 5162},
+			pos:  position{line: 254, col: 1, offset: 7307},
 			expr: &actionExpr{
-				pos: position{line: 188, col: 14, offset: 5177},
+				pos: position{line: 254, col: 14, offset: 7322},
 				run: (*parser).callonSuffixedOp1,
 				expr: &choiceExpr{
-					pos: position{line: 188, col: 16, offset: 5179},
+					pos: position{line: 254, col: 16, offset: 7324},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 188, col: 16, offset: 5179},
+							pos:        position{line: 254, col: 16, offset: 7324},
 							val:        "?",
 							ignoreCase: false,
 							want:       "\"?\"",
 						},
 						&litMatcher{
-							pos:        position{line: 188, col: 22, offset: 5185},
+							pos:        position{line: 254, col: 22, offset: 7330},
 							val:        "*",
 							ignoreCase: false,
 							want:       "\"*\"",
 						},
 						&litMatcher{
-							pos:        position{line: 188, col: 28, offset: 5191},
+							pos:        position{line: 254, col: 28, offset: 7336},
 							val:        "+",
 							ignoreCase: false,
 							want:       "\"+\"",
@@ -763,62 +1097,198 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "SeparatorOp",
+			pos:  position{line: 258, col: 1, offset: 7378},
+			expr: &actionExpr{
+				pos: position{line: 258, col: 15, offset: 7394},
+				run: (*parser).callonSeparatorOp1,
+				expr: &seqExpr{
+					pos: position{line: 258, col: 15, offset: 7394},
+					exprs: []any{
+						&choiceExpr{
+							pos: position{line: 258, col: 17, offset: 7396},
+							alternatives: []any{
+								&litMatcher{
+									pos:        position{line: 258, col: 17, offset: 7396},
+									val:        "++",
+									ignoreCase: false,
+									want:       "\"++\"",
+								},
+								&litMatcher{
+									pos:        position{line: 258, col: 24, offset: 7403},
+									val:        "**",
+									ignoreCase: false,
+									want:       "\"**\"",
+								},
+							},
+						},
+						&zeroOrOneExpr{
+							pos: position{line: 258, col: 31, offset: 7410},
+							expr: &litMatcher{
+								pos:        position{line: 258, col: 31, offset: 7410},
+								val:        "?",
+								ignoreCase: false,
+								want:       "\"?\"",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "RepeatBounds",
+			pos:  position{line: 262, col: 1, offset: 7451},
+			expr: &actionExpr{
+				pos: position{line: 262, col: 16, offset: 7468},
+				run: (*parser).callonRepeatBounds1,
+				expr: &seqExpr{
+					pos: position{line: 262, col: 16, offset: 7468},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 262, col: 16, offset: 7468},
+							val:        "{",
+							ignoreCase: false,
+							want:       "\"{\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 262, col: 20, offset: 7472},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 262, col: 22, offset: 7474},
+							label: "min",
+							expr: &ruleRefExpr{
+								pos:  position{line: 262, col: 26, offset: 7478},
+								name: "DecimalInt",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 262, col: 37, offset: 7489},
+							name: "_",
+						},
+						&labeledExpr{
+							pos:   position{line: 262, col: 39, offset: 7491},
+							label: "max",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 262, col: 43, offset: 7495},
+								expr: &seqExpr{
+									pos: position{line: 262, col: 45, offset: 7497},
+									exprs: []any{
+										&litMatcher{
+											pos:        position{line: 262, col: 45, offset: 7497},
+											val:        ",",
+											ignoreCase: false,
+											want:       "\",\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 262, col: 49, offset: 7501},
+											name: "_",
+										},
+										&zeroOrOneExpr{
+											pos: position{line: 262, col: 51, offset: 7503},
+											expr: &ruleRefExpr{
+												pos:  position{line: 262, col: 51, offset: 7503},
+												name: "DecimalInt",
+											},
+										},
+										&ruleRefExpr{
+											pos:  position{line: 262, col: 63, offset: 7515},
+											name: "_",
+										},
+									},
+								},
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 262, col: 68, offset: 7520},
+							val:        "}",
+							ignoreCase: false,
+							want:       "\"}\"",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "DecimalInt",
+			pos:  position{line: 277, col: 1, offset: 7933},
+			expr: &actionExpr{
+				pos: position{line: 277, col: 14, offset: 7948},
+				run: (*parser).callonDecimalInt1,
+				expr: &oneOrMoreExpr{
+					pos: position{line: 277, col: 14, offset: 7948},
+					expr: &ruleRefExpr{
+						pos:  position{line: 277, col: 14, offset: 7948},
+						name: "DecimalDigit",
+					},
+				},
+			},
+		},
 		{
 			name: "PrimaryExpr",
-			pos:  position{line: 192, col: 1, offset: 5233},
+			pos:  position{line: 281, col: 1, offset: 8007},
 			expr: &choiceExpr{
-				pos: position{line: 192, col: 15, offset: 5249},
+				pos: position{line: 281, col: 15, offset: 8023},
 				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 192, col: 15, offset: 5249},
+						pos:  position{line: 281, col: 15, offset: 8023},
 						name: "LitMatcher",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 192, col: 28, offset: 5262},
+						pos:  position{line: 281, col: 28, offset: 8036},
 						name: "CharClassMatcher",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 192, col: 47, offset: 5281},
+						pos:  position{line: 281, col: 47, offset: 8055},
 						name: "AnyMatcher",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 192, col: 60, offset: 5294},
+						pos:  position{line: 281, col: 60, offset: 8068},
 						name: "RuleRefExpr",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 192, col: 74, offset: 5308},
+						pos:  position{line: 281, col: 74, offset: 8082},
+						name: "BackRefExpr",
+					},
+					&ruleRefExpr{
+						pos:  position{line: 281, col: 88, offset: 8096},
 						name: "SemanticPredExpr",
 					},
+					&ruleRefExpr{
+						pos:  position{line: 281, col: 107, offset: 8115},
+						name: "PrecedenceExpr",
+					},
 					&actionExpr{
-						pos: position{line: 192, col: 93, offset: 5327},
-						run: (*parser).callonPrimaryExpr7,
+						pos: position{line: 281, col: 124, offset: 8132},
+						run: (*parser).callonPrimaryExpr9,
 						expr: &seqExpr{
-							pos: position{line: 192, col: 93, offset: 5327},
+							pos: position{line: 281, col: 124, offset: 8132},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 192, col: 93, offset: 5327},
+									pos:        position{line: 281, col: 124, offset: 8132},
 									val:        "(",
 									ignoreCase: false,
 									want:       "\"(\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 192, col: 97, offset: 5331},
+									pos:  position{line: 281, col: 128, offset: 8136},
 									name: "__",
 								},
 								&labeledExpr{
-									pos:   position{line: 192, col: 100, offset: 5334},
+									pos:   position{line: 281, col: 131, offset: 8139},
 									label: "expr",
 									expr: &ruleRefExpr{
-										pos:  position{line: 192, col: 105, offset: 5339},
+										pos:  position{line: 281, col: 136, offset: 8144},
 										name: "Expression",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 192, col: 116, offset: 5350},
+									pos:  position{line: 281, col: 147, offset: 8155},
 									name: "__",
 								},
 								&litMatcher{
-									pos:        position{line: 192, col: 119, offset: 5353},
+									pos:        position{line: 281, col: 150, offset: 8158},
 									val:        ")",
 									ignoreCase: false,
 									want:       "\")\"",
@@ -829,50 +1299,274 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "PrecedenceExpr",
+			pos:  position{line: 284, col: 1, offset: 8187},
+			expr: &actionExpr{
+				pos: position{line: 284, col: 18, offset: 8206},
+				run: (*parser).callonPrecedenceExpr1,
+				expr: &seqExpr{
+					pos: position{line: 284, col: 18, offset: 8206},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 284, col: 18, offset: 8206},
+							val:        "%precedence",
+							ignoreCase: false,
+							want:       "\"%precedence\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 284, col: 32, offset: 8220},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 284, col: 35, offset: 8223},
+							label: "atom",
+							expr: &ruleRefExpr{
+								pos:  position{line: 284, col: 40, offset: 8228},
+								name: "PrimaryExpr",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 284, col: 52, offset: 8240},
+							name: "__",
+						},
+						&litMatcher{
+							pos:        position{line: 284, col: 55, offset: 8243},
+							val:        "{",
+							ignoreCase: false,
+							want:       "\"{\"",
+						},
+						&ruleRefExpr{
+							pos:  position{line: 284, col: 59, offset: 8247},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 284, col: 62, offset: 8250},
+							label: "levels",
+							expr: &oneOrMoreExpr{
+								pos: position{line: 284, col: 69, offset: 8257},
+								expr: &seqExpr{
+									pos: position{line: 284, col: 71, offset: 8259},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 284, col: 71, offset: 8259},
+											name: "PrecedenceLevel",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 284, col: 87, offset: 8275},
+											name: "__",
+										},
+									},
+								},
+							},
+						},
+						&litMatcher{
+							pos:        position{line: 284, col: 93, offset: 8281},
+							val:        "}",
+							ignoreCase: false,
+							want:       "\"}\"",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "PrecedenceLevel",
+			pos:  position{line: 292, col: 1, offset: 8526},
+			expr: &actionExpr{
+				pos: position{line: 292, col: 19, offset: 8546},
+				run: (*parser).callonPrecedenceLevel1,
+				expr: &seqExpr{
+					pos: position{line: 292, col: 19, offset: 8546},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 292, col: 19, offset: 8546},
+							label: "kind",
+							expr: &ruleRefExpr{
+								pos:  position{line: 292, col: 24, offset: 8551},
+								name: "OperatorKind",
+							},
+						},
+						&ruleRefExpr{
+							pos:  position{line: 292, col: 37, offset: 8564},
+							name: "__",
+						},
+						&labeledExpr{
+							pos:   position{line: 292, col: 40, offset: 8567},
+							label: "first",
+							expr: &ruleRefExpr{
+								pos:  position{line: 292, col: 46, offset: 8573},
+								name: "Operator",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 292, col: 55, offset: 8582},
+							label: "rest",
+							expr: &zeroOrMoreExpr{
+								pos: position{line: 292, col: 60, offset: 8587},
+								expr: &seqExpr{
+									pos: position{line: 292, col: 62, offset: 8589},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 292, col: 62, offset: 8589},
+											name: "__",
+										},
+										&litMatcher{
+											pos:        position{line: 292, col: 65, offset: 8592},
+											val:        "/",
+											ignoreCase: false,
+											want:       "\"/\"",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 292, col: 69, offset: 8596},
+											name: "__",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 292, col: 72, offset: 8599},
+											name: "Operator",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "OperatorKind",
+			pos:  position{line: 300, col: 1, offset: 8908},
+			expr: &actionExpr{
+				pos: position{line: 300, col: 16, offset: 8925},
+				run: (*parser).callonOperatorKind1,
+				expr: &seqExpr{
+					pos: position{line: 300, col: 16, offset: 8925},
+					exprs: []any{
+						&choiceExpr{
+							pos: position{line: 300, col: 18, offset: 8927},
+							alternatives: []any{
+								&litMatcher{
+									pos:        position{line: 300, col: 18, offset: 8927},
+									val:        "left",
+									ignoreCase: false,
+									want:       "\"left\"",
+								},
+								&litMatcher{
+									pos:        position{line: 300, col: 27, offset: 8936},
+									val:        "right",
+									ignoreCase: false,
+									want:       "\"right\"",
+								},
+								&litMatcher{
+									pos:        position{line: 300, col: 37, offset: 8946},
+									val:        "prefix",
+									ignoreCase: false,
+									want:       "\"prefix\"",
+								},
+								&litMatcher{
+									pos:        position{line: 300, col: 48, offset: 8957},
+									val:        "postfix",
+									ignoreCase: false,
+									want:       "\"postfix\"",
+								},
+							},
+						},
+						&notExpr{
+							pos: position{line: 300, col: 60, offset: 8969},
+							expr: &ruleRefExpr{
+								pos:  position{line: 300, col: 61, offset: 8970},
+								name: "IdentifierPart",
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "Operator",
+			pos:  position{line: 303, col: 1, offset: 9020},
+			expr: &actionExpr{
+				pos: position{line: 303, col: 12, offset: 9033},
+				run: (*parser).callonOperator1,
+				expr: &seqExpr{
+					pos: position{line: 303, col: 12, offset: 9033},
+					exprs: []any{
+						&labeledExpr{
+							pos:   position{line: 303, col: 12, offset: 9033},
+							label: "expr",
+							expr: &ruleRefExpr{
+								pos:  position{line: 303, col: 17, offset: 9038},
+								name: "PrimaryExpr",
+							},
+						},
+						&labeledExpr{
+							pos:   position{line: 303, col: 29, offset: 9050},
+							label: "code",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 303, col: 34, offset: 9055},
+								expr: &seqExpr{
+									pos: position{line: 303, col: 36, offset: 9057},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 303, col: 36, offset: 9057},
+											name: "__",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 303, col: 39, offset: 9060},
+											name: "CodeBlock",
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "RuleRefExpr",
-			pos:  position{line: 195, col: 1, offset: 5382},
+			pos:  position{line: 311, col: 1, offset: 9248},
 			expr: &actionExpr{
-				pos: position{line: 195, col: 15, offset: 5398},
+				pos: position{line: 311, col: 15, offset: 9264},
 				run: (*parser).callonRuleRefExpr1,
 				expr: &seqExpr{
-					pos: position{line: 195, col: 15, offset: 5398},
+					pos: position{line: 311, col: 15, offset: 9264},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 195, col: 15, offset: 5398},
+							pos:   position{line: 311, col: 15, offset: 9264},
 							label: "name",
 							expr: &ruleRefExpr{
-								pos:  position{line: 195, col: 20, offset: 5403},
+								pos:  position{line: 311, col: 20, offset: 9269},
 								name: "IdentifierName",
 							},
 						},
 						&notExpr{
-							pos: position{line: 195, col: 35, offset: 5418},
+							pos: position{line: 311, col: 35, offset: 9284},
 							expr: &seqExpr{
-								pos: position{line: 195, col: 38, offset: 5421},
+								pos: position{line: 311, col: 38, offset: 9287},
 								exprs: []any{
 									&ruleRefExpr{
-										pos:  position{line: 195, col: 38, offset: 5421},
+										pos:  position{line: 311, col: 38, offset: 9287},
 										name: "__",
 									},
 									&zeroOrOneExpr{
-										pos: position{line: 195, col: 41, offset: 5424},
+										pos: position{line: 311, col: 41, offset: 9290},
 										expr: &seqExpr{
-											pos: position{line: 195, col: 43, offset: 5426},
+											pos: position{line: 311, col: 43, offset: 9292},
 											exprs: []any{
 												&ruleRefExpr{
-													pos:  position{line: 195, col: 43, offset: 5426},
+													pos:  position{line: 311, col: 43, offset: 9292},
 													name: "StringLiteral",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 195, col: 57, offset: 5440},
+													pos:  position{line: 311, col: 57, offset: 9306},
 													name: "__",
 												},
 											},
 										},
 									},
 									&ruleRefExpr{
-										pos:  position{line: 195, col: 63, offset: 5446},
+										pos:  position{line: 311, col: 63, offset: 9312},
 										name: "RuleDefOp",
 									},
 								},
@@ -882,32 +1576,59 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "BackRefExpr",
+			pos:  position{line: 316, col: 1, offset: 9428},
+			expr: &actionExpr{
+				pos: position{line: 316, col: 15, offset: 9444},
+				run: (*parser).callonBackRefExpr1,
+				expr: &seqExpr{
+					pos: position{line: 316, col: 15, offset: 9444},
+					exprs: []any{
+						&litMatcher{
+							pos:        position{line: 316, col: 15, offset: 9444},
+							val:        "$",
+							ignoreCase: false,
+							want:       "\"$\"",
+						},
+						&labeledExpr{
+							pos:   position{line: 316, col: 19, offset: 9448},
+							label: "label",
+							expr: &ruleRefExpr{
+								pos:  position{line: 316, col: 25, offset: 9454},
+								name: "IdentifierName",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "SemanticPredExpr",
-			pos:  position{line: 200, col: 1, offset: 5562},
+			pos:  position{line: 321, col: 1, offset: 9575},
 			expr: &actionExpr{
-				pos: position{line: 200, col: 20, offset: 5583},
+				pos: position{line: 321, col: 20, offset: 9596},
 				run: (*parser).callonSemanticPredExpr1,
 				expr: &seqExpr{
-					pos: position{line: 200, col: 20, offset: 5583},
+					pos: position{line: 321, col: 20, offset: 9596},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 200, col: 20, offset: 5583},
+							pos:   position{line: 321, col: 20, offset: 9596},
 							label: "op",
 							expr: &ruleRefExpr{
-								pos:  position{line: 200, col: 23, offset: 5586},
+								pos:  position{line: 321, col: 23, offset: 9599},
 								name: "SemanticPredOp",
 							},
 						},
 						&ruleRefExpr{
-							pos:  position{line: 200, col: 38, offset: 5601},
+							pos:  position{line: 321, col: 38, offset: 9614},
 							name: "__",
 						},
 						&labeledExpr{
-							pos:   position{line: 200, col: 41, offset: 5604},
+							pos:   position{line: 321, col: 41, offset: 9617},
 							label: "code",
 							expr: &ruleRefExpr{
-								pos:  position{line: 200, col: 46, offset: 5609},
+								pos:  position{line: 321, col: 46, offset: 9622},
 								name: "CodeBlock",
 							},
 						},
@@ -917,27 +1638,27 @@ var g = &grammar{
 		},
 		{
 			name: "SemanticPredOp",
-			pos:  position{line: 221, col: 1, offset: 6068},
+			pos:  position{line: 342, col: 1, offset: 10081},
 			expr: &actionExpr{
-				pos: position{line: 221, col: 18, offset: 6087},
+				pos: position{line: 342, col: 18, offset: 10100},
 				run: (*parser).callonSemanticPredOp1,
 				expr: &choiceExpr{
-					pos: position{line: 221, col: 20, offset: 6089},
+					pos: position{line: 342, col: 20, offset: 10102},
 					alternatives: []any{
 						&litMatcher{
-							pos:        position{line: 221, col: 20, offset: 6089},
+							pos:        position{line: 342, col: 20, offset: 10102},
 							val:        "&",
 							ignoreCase: false,
 							want:       "\"&\"",
 						},
 						&litMatcher{
-							pos:        position{line: 221, col: 26, offset: 6095},
+							pos:        position{line: 342, col: 26, offset: 10108},
 							val:        "!",
 							ignoreCase: false,
 							want:       "\"!\"",
 						},
 						&litMatcher{
-							pos:        position{line: 221, col: 32, offset: 6101},
+							pos:        position{line: 342, col: 32, offset: 10114},
 							val:        "*",
 							ignoreCase: false,
 							want:       "\"*\"",
@@ -948,30 +1669,30 @@ var g = &grammar{
 		},
 		{
 			name: "RuleDefOp",
-			pos:  position{line: 225, col: 1, offset: 6143},
+			pos:  position{line: 346, col: 1, offset: 10156},
 			expr: &choiceExpr{
-				pos: position{line: 225, col: 13, offset: 6157},
+				pos: position{line: 346, col: 13, offset: 10170},
 				alternatives: []any{
 					&litMatcher{
-						pos:        position{line: 225, col: 13, offset: 6157},
+						pos:        position{line: 346, col: 13, offset: 10170},
 						val:        "=",
 						ignoreCase: false,
 						want:       "\"=\"",
 					},
 					&litMatcher{
-						pos:        position{line: 225, col: 19, offset: 6163},
+						pos:        position{line: 346, col: 19, offset: 10176},
 						val:        "<-",
 						ignoreCase: false,
 						want:       "\"<-\"",
 					},
 					&litMatcher{
-						pos:        position{line: 225, col: 26, offset: 6170},
+						pos:        position{line: 346, col: 26, offset: 10183},
 						val:        "←",
 						ignoreCase: false,
 						want:       "\"←\"",
 					},
 					&litMatcher{
-						pos:        position{line: 225, col: 37, offset: 6181},
+						pos:        position{line: 346, col: 37, offset: 10194},
 						val:        "⟵",
 						ignoreCase: false,
 						want:       "\"⟵\"",
@@ -981,23 +1702,23 @@ var g = &grammar{
 		},
 		{
 			name: "SourceChar",
-			pos:  position{line: 227, col: 1, offset: 6191},
+			pos:  position{line: 348, col: 1, offset: 10204},
 			expr: &anyMatcher{
-				line: 227, col: 14, offset: 6206,
+				line: 348, col: 14, offset: 10219,
 			},
 		},
 		{
 			name: "Comment",
-			pos:  position{line: 228, col: 1, offset: 6208},
+			pos:  position{line: 349, col: 1, offset: 10221},
 			expr: &choiceExpr{
-				pos: position{line: 228, col: 11, offset: 6220},
+				pos: position{line: 349, col: 11, offset: 10233},
 				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 228, col: 11, offset: 6220},
+						pos:  position{line: 349, col: 11, offset: 10233},
 						name: "MultiLineComment",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 228, col: 30, offset: 6239},
+						pos:  position{line: 349, col: 30, offset: 10252},
 						name: "SingleLineComment",
 					},
 				},
@@ -1005,39 +1726,39 @@ var g = &grammar{
 		},
 		{
 			name: "MultiLineComment",
-			pos:  position{line: 229, col: 1, offset: 6257},
+			pos:  position{line: 350, col: 1, offset: 10270},
 			expr: &seqExpr{
-				pos: position{line: 229, col: 20, offset: 6278},
+				pos: position{line: 350, col: 20, offset: 10291},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 229, col: 20, offset: 6278},
+						pos:        position{line: 350, col: 20, offset: 10291},
 						val:        "/*",
 						ignoreCase: false,
 						want:       "\"/*\"",
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 229, col: 25, offset: 6283},
+						pos: position{line: 350, col: 25, offset: 10296},
 						expr: &seqExpr{
-							pos: position{line: 229, col: 27, offset: 6285},
+							pos: position{line: 350, col: 27, offset: 10298},
 							exprs: []any{
 								&notExpr{
-									pos: position{line: 229, col: 27, offset: 6285},
+									pos: position{line: 350, col: 27, offset: 10298},
 									expr: &litMatcher{
-										pos:        position{line: 229, col: 28, offset: 6286},
+										pos:        position{line: 350, col: 28, offset: 10299},
 										val:        "*/",
 										ignoreCase: false,
 										want:       "\"*/\"",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 229, col: 33, offset: 6291},
+									pos:  position{line: 350, col: 33, offset: 10304},
 									name: "SourceChar",
 								},
 							},
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 229, col: 47, offset: 6305},
+						pos:        position{line: 350, col: 47, offset: 10318},
 						val:        "*/",
 						ignoreCase: false,
 						want:       "\"*/\"",
@@ -1047,48 +1768,48 @@ var g = &grammar{
 		},
 		{
 			name: "MultiLineCommentNoLineTerminator",
-			pos:  position{line: 230, col: 1, offset: 6310},
+			pos:  position{line: 351, col: 1, offset: 10323},
 			expr: &seqExpr{
-				pos: position{line: 230, col: 36, offset: 6347},
+				pos: position{line: 351, col: 36, offset: 10360},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 230, col: 36, offset: 6347},
+						pos:        position{line: 351, col: 36, offset: 10360},
 						val:        "/*",
 						ignoreCase: false,
 						want:       "\"/*\"",
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 230, col: 41, offset: 6352},
+						pos: position{line: 351, col: 41, offset: 10365},
 						expr: &seqExpr{
-							pos: position{line: 230, col: 43, offset: 6354},
+							pos: position{line: 351, col: 43, offset: 10367},
 							exprs: []any{
 								&notExpr{
-									pos: position{line: 230, col: 43, offset: 6354},
+									pos: position{line: 351, col: 43, offset: 10367},
 									expr: &choiceExpr{
-										pos: position{line: 230, col: 46, offset: 6357},
+										pos: position{line: 351, col: 46, offset: 10370},
 										alternatives: []any{
 											&litMatcher{
-												pos:        position{line: 230, col: 46, offset: 6357},
+												pos:        position{line: 351, col: 46, offset: 10370},
 												val:        "*/",
 												ignoreCase: false,
 												want:       "\"*/\"",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 230, col: 53, offset: 6364},
+												pos:  position{line: 351, col: 53, offset: 10377},
 												name: "EOL",
 											},
 										},
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 230, col: 59, offset: 6370},
+									pos:  position{line: 351, col: 59, offset: 10383},
 									name: "SourceChar",
 								},
 							},
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 230, col: 73, offset: 6384},
+						pos:        position{line: 351, col: 73, offset: 10397},
 						val:        "*/",
 						ignoreCase: false,
 						want:       "\"*/\"",
@@ -1098,39 +1819,39 @@ var g = &grammar{
 		},
 		{
 			name: "SingleLineComment",
-			pos:  position{line: 231, col: 1, offset: 6389},
+			pos:  position{line: 352, col: 1, offset: 10402},
 			expr: &seqExpr{
-				pos: position{line: 231, col: 21, offset: 6411},
+				pos: position{line: 352, col: 21, offset: 10424},
 				exprs: []any{
 					&notExpr{
-						pos: position{line: 231, col: 21, offset: 6411},
+						pos: position{line: 352, col: 21, offset: 10424},
 						expr: &litMatcher{
-							pos:        position{line: 231, col: 23, offset: 6413},
+							pos:        position{line: 352, col: 23, offset: 10426},
 							val:        "//{",
 							ignoreCase: false,
 							want:       "\"//{\"",
 						},
 					},
 					&litMatcher{
-						pos:        position{line: 231, col: 30, offset: 6420},
+						pos:        position{line: 352, col: 30, offset: 10433},
 						val:        "//",
 						ignoreCase: false,
 						want:       "\"//\"",
 					},
 					&zeroOrMoreExpr{
-						pos: position{line: 231, col: 35, offset: 6425},
+						pos: position{line: 352, col: 35, offset: 10438},
 						expr: &seqExpr{
-							pos: position{line: 231, col: 37, offset: 6427},
+							pos: position{line: 352, col: 37, offset: 10440},
 							exprs: []any{
 								&notExpr{
-									pos: position{line: 231, col: 37, offset: 6427},
+									pos: position{line: 352, col: 37, offset: 10440},
 									expr: &ruleRefExpr{
-										pos:  position{line: 231, col: 38, offset: 6428},
+										pos:  position{line: 352, col: 38, offset: 10441},
 										name: "EOL",
 									},
 								},
 								&ruleRefExpr{
-									pos:  position{line: 231, col: 42, offset: 6432},
+									pos:  position{line: 352, col: 42, offset: 10445},
 									name: "SourceChar",
 								},
 							},
@@ -1141,15 +1862,15 @@ var g = &grammar{
 		},
 		{
 			name: "Identifier",
-			pos:  position{line: 233, col: 1, offset: 6447},
+			pos:  position{line: 354, col: 1, offset: 10460},
 			expr: &actionExpr{
-				pos: position{line: 233, col: 14, offset: 6462},
+				pos: position{line: 354, col: 14, offset: 10475},
 				run: (*parser).callonIdentifier1,
 				expr: &labeledExpr{
-					pos:   position{line: 233, col: 14, offset: 6462},
+					pos:   position{line: 354, col: 14, offset: 10475},
 					label: "ident",
 					expr: &ruleRefExpr{
-						pos:  position{line: 233, col: 20, offset: 6468},
+						pos:  position{line: 354, col: 20, offset: 10481},
 						name: "IdentifierName",
 					},
 				},
@@ -1157,21 +1878,21 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierName",
-			pos:  position{line: 241, col: 1, offset: 6687},
+			pos:  position{line: 362, col: 1, offset: 10700},
 			expr: &actionExpr{
-				pos: position{line: 241, col: 18, offset: 6706},
+				pos: position{line: 362, col: 18, offset: 10719},
 				run: (*parser).callonIdentifierName1,
 				expr: &seqExpr{
-					pos: position{line: 241, col: 18, offset: 6706},
+					pos: position{line: 362, col: 18, offset: 10719},
 					exprs: []any{
 						&ruleRefExpr{
-							pos:  position{line: 241, col: 18, offset: 6706},
+							pos:  position{line: 362, col: 18, offset: 10719},
 							name: "IdentifierStart",
 						},
 						&zeroOrMoreExpr{
-							pos: position{line: 241, col: 34, offset: 6722},
+							pos: position{line: 362, col: 34, offset: 10735},
 							expr: &ruleRefExpr{
-								pos:  position{line: 241, col: 34, offset: 6722},
+								pos:  position{line: 362, col: 34, offset: 10735},
 								name: "IdentifierPart",
 							},
 						},
@@ -1181,9 +1902,9 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierStart",
-			pos:  position{line: 244, col: 1, offset: 6804},
+			pos:  position{line: 365, col: 1, offset: 10817},
 			expr: &charClassMatcher{
-				pos:        position{line: 244, col: 19, offset: 6824},
+				pos:        position{line: 365, col: 19, offset: 10837},
 				val:        "[\\pL_]",
 				chars:      []rune{'_'},
 				classes:    []*unicode.RangeTable{rangeTable("L")},
@@ -1193,16 +1914,16 @@ var g = &grammar{
 		},
 		{
 			name: "IdentifierPart",
-			pos:  position{line: 245, col: 1, offset: 6831},
+			pos:  position{line: 366, col: 1, offset: 10844},
 			expr: &choiceExpr{
-				pos: position{line: 245, col: 18, offset: 6850},
+				pos: position{line: 366, col: 18, offset: 10863},
 				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 245, col: 18, offset: 6850},
+						pos:  position{line: 366, col: 18, offset: 10863},
 						name: "IdentifierStart",
 					},
 					&charClassMatcher{
-						pos:        position{line: 245, col: 36, offset: 6868},
+						pos:        position{line: 366, col: 36, offset: 10881},
 						val:        "[\\p{Nd}]",
 						classes:    []*unicode.RangeTable{rangeTable("Nd")},
 						ignoreCase: false,
@@ -1213,68 +1934,81 @@ var g = &grammar{
 		},
 		{
 			name: "LitMatcher",
-			pos:  position{line: 247, col: 1, offset: 6878},
+			pos:  position{line: 368, col: 1, offset: 10891},
 			expr: &actionExpr{
-				pos: position{line: 247, col: 14, offset: 6893},
+				pos: position{line: 368, col: 14, offset: 10906},
 				run: (*parser).callonLitMatcher1,
 				expr: &seqExpr{
-					pos: position{line: 247, col: 14, offset: 6893},
+					pos: position{line: 368, col: 14, offset: 10906},
 					exprs: []any{
 						&labeledExpr{
-							pos:   position{line: 247, col: 14, offset: 6893},
+							pos:   position{line: 368, col: 14, offset: 10906},
 							label: "lit",
 							expr: &ruleRefExpr{
-								pos:  position{line: 247, col: 18, offset: 6897},
+								pos:  position{line: 368, col: 18, offset: 10910},
 								name: "StringLiteral",
 							},
 						},
 						&labeledExpr{
-							pos:   position{line: 247, col: 32, offset: 6911},
+							pos:   position{line: 368, col: 32, offset: 10924},
 							label: "ignore",
 							expr: &zeroOrOneExpr{
-								pos: position{line: 247, col: 39, offset: 6918},
+								pos: position{line: 368, col: 39, offset: 10931},
 								expr: &litMatcher{
-									pos:        position{line: 247, col: 39, offset: 6918},
+									pos:        position{line: 368, col: 39, offset: 10931},
 									val:        "i",
 									ignoreCase: false,
 									want:       "\"i\"",
 								},
 							},
 						},
+						&labeledExpr{
+							pos:   position{line: 368, col: 44, offset: 10936},
+							label: "keyword",
+							expr: &zeroOrOneExpr{
+								pos: position{line: 368, col: 52, offset: 10944},
+								expr: &litMatcher{
+									pos:        position{line: 368, col: 52, offset: 10944},
+									val:        "k",
+									ignoreCase: false,
+									want:       "\"k\"",
+								},
+							},
+						},
 					},
 				},
 			},
 		},
 		{
 			name: "StringLiteral",
-			pos:  position{line: 260, col: 1, offset: 7317},
+			pos:  position{line: 382, col: 1, offset: 11374},
 			expr: &choiceExpr{
-				pos: position{line: 260, col: 17, offset: 7335},
+				pos: position{line: 382, col: 17, offset: 11392},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 260, col: 17, offset: 7335},
+						pos: position{line: 382, col: 17, offset: 11392},
 						run: (*parser).callonStringLiteral2,
 						expr: &choiceExpr{
-							pos: position{line: 260, col: 19, offset: 7337},
+							pos: position{line: 382, col: 19, offset: 11394},
 							alternatives: []any{
 								&seqExpr{
-									pos: position{line: 260, col: 19, offset: 7337},
+									pos: position{line: 382, col: 19, offset: 11394},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 260, col: 19, offset: 7337},
+											pos:        position{line: 382, col: 19, offset: 11394},
 											val:        "\"",
 											ignoreCase: false,
 											want:       "\"\\\"\"",
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 260, col: 23, offset: 7341},
+											pos: position{line: 382, col: 23, offset: 11398},
 											expr: &ruleRefExpr{
-												pos:  position{line: 260, col: 23, offset: 7341},
+												pos:  position{line: 382, col: 23, offset: 11398},
 												name: "DoubleStringChar",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 260, col: 41, offset: 7359},
+											pos:        position{line: 382, col: 41, offset: 11416},
 											val:        "\"",
 											ignoreCase: false,
 											want:       "\"\\\"\"",
@@ -1282,20 +2016,20 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 260, col: 47, offset: 7365},
+									pos: position{line: 382, col: 47, offset: 11422},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 260, col: 47, offset: 7365},
+											pos:        position{line: 382, col: 47, offset: 11422},
 											val:        "'",
 											ignoreCase: false,
 											want:       "\"'\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 260, col: 51, offset: 7369},
+											pos:  position{line: 382, col: 51, offset: 11426},
 											name: "SingleStringChar",
 										},
 										&litMatcher{
-											pos:        position{line: 260, col: 68, offset: 7386},
+											pos:        position{line: 382, col: 68, offset: 11443},
 											val:        "'",
 											ignoreCase: false,
 											want:       "\"'\"",
@@ -1303,23 +2037,23 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 260, col: 74, offset: 7392},
+									pos: position{line: 382, col: 74, offset: 11449},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 260, col: 74, offset: 7392},
+											pos:        position{line: 382, col: 74, offset: 11449},
 											val:        "`",
 											ignoreCase: false,
 											want:       "\"`\"",
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 260, col: 78, offset: 7396},
+											pos: position{line: 382, col: 78, offset: 11453},
 											expr: &ruleRefExpr{
-												pos:  position{line: 260, col: 78, offset: 7396},
+												pos:  position{line: 382, col: 78, offset: 11453},
 												name: "RawStringChar",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 260, col: 93, offset: 7411},
+											pos:        position{line: 382, col: 93, offset: 11468},
 											val:        "`",
 											ignoreCase: false,
 											want:       "\"`\"",
@@ -1330,36 +2064,36 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 262, col: 5, offset: 7484},
+						pos: position{line: 384, col: 5, offset: 11541},
 						run: (*parser).callonStringLiteral18,
 						expr: &choiceExpr{
-							pos: position{line: 262, col: 7, offset: 7486},
+							pos: position{line: 384, col: 7, offset: 11543},
 							alternatives: []any{
 								&seqExpr{
-									pos: position{line: 262, col: 9, offset: 7488},
+									pos: position{line: 384, col: 9, offset: 11545},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 262, col: 9, offset: 7488},
+											pos:        position{line: 384, col: 9, offset: 11545},
 											val:        "\"",
 											ignoreCase: false,
 											want:       "\"\\\"\"",
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 262, col: 13, offset: 7492},
+											pos: position{line: 384, col: 13, offset: 11549},
 											expr: &ruleRefExpr{
-												pos:  position{line: 262, col: 13, offset: 7492},
+												pos:  position{line: 384, col: 13, offset: 11549},
 												name: "DoubleStringChar",
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 262, col: 33, offset: 7512},
+											pos: position{line: 384, col: 33, offset: 11569},
 											alternatives: []any{
 												&ruleRefExpr{
-													pos:  position{line: 262, col: 33, offset: 7512},
+													pos:  position{line: 384, col: 33, offset: 11569},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 262, col: 39, offset: 7518},
+													pos:  position{line: 384, col: 39, offset: 11575},
 													name: "EOF",
 												},
 											},
@@ -1367,30 +2101,30 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 262, col: 51, offset: 7530},
+									pos: position{line: 384, col: 51, offset: 11587},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 262, col: 51, offset: 7530},
+											pos:        position{line: 384, col: 51, offset: 11587},
 											val:        "'",
 											ignoreCase: false,
 											want:       "\"'\"",
 										},
 										&zeroOrOneExpr{
-											pos: position{line: 262, col: 55, offset: 7534},
+											pos: position{line: 384, col: 55, offset: 11591},
 											expr: &ruleRefExpr{
-												pos:  position{line: 262, col: 55, offset: 7534},
+												pos:  position{line: 384, col: 55, offset: 11591},
 												name: "SingleStringChar",
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 262, col: 75, offset: 7554},
+											pos: position{line: 384, col: 75, offset: 11611},
 											alternatives: []any{
 												&ruleRefExpr{
-													pos:  position{line: 262, col: 75, offset: 7554},
+													pos:  position{line: 384, col: 75, offset: 11611},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 262, col: 81, offset: 7560},
+													pos:  position{line: 384, col: 81, offset: 11617},
 													name: "EOF",
 												},
 											},
@@ -1398,23 +2132,23 @@ var g = &grammar{
 									},
 								},
 								&seqExpr{
-									pos: position{line: 262, col: 91, offset: 7570},
+									pos: position{line: 384, col: 91, offset: 11627},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 262, col: 91, offset: 7570},
+											pos:        position{line: 384, col: 91, offset: 11627},
 											val:        "`",
 											ignoreCase: false,
 											want:       "\"`\"",
 										},
 										&zeroOrMoreExpr{
-											pos: position{line: 262, col: 95, offset: 7574},
+											pos: position{line: 384, col: 95, offset: 11631},
 											expr: &ruleRefExpr{
-												pos:  position{line: 262, col: 95, offset: 7574},
+												pos:  position{line: 384, col: 95, offset: 11631},
 												name: "RawStringChar",
 											},
 										},
 										&ruleRefExpr{
-											pos:  position{line: 262, col: 110, offset: 7589},
+											pos:  position{line: 384, col: 110, offset: 11646},
 											name: "EOF",
 										},
 									},
@@ -1427,54 +2161,54 @@ var g = &grammar{
 		},
 		{
 			name: "DoubleStringChar",
-			pos:  position{line: 266, col: 1, offset: 7691},
+			pos:  position{line: 388, col: 1, offset: 11748},
 			expr: &choiceExpr{
-				pos: position{line: 266, col: 20, offset: 7712},
+				pos: position{line: 388, col: 20, offset: 11769},
 				alternatives: []any{
 					&seqExpr{
-						pos: position{line: 266, col: 20, offset: 7712},
+						pos: position{line: 388, col: 20, offset: 11769},
 						exprs: []any{
 							&notExpr{
-								pos: position{line: 266, col: 20, offset: 7712},
+								pos: position{line: 388, col: 20, offset: 11769},
 								expr: &choiceExpr{
-									pos: position{line: 266, col: 23, offset: 7715},
+									pos: position{line: 388, col: 23, offset: 11772},
 									alternatives: []any{
 										&litMatcher{
-											pos:        position{line: 266, col: 23, offset: 7715},
+											pos:        position{line: 388, col: 23, offset: 11772},
 											val:        "\"",
 											ignoreCase: false,
 											want:       "\"\\\"\"",
 										},
 										&litMatcher{
-											pos:        position{line: 266, col: 29, offset: 7721},
+											pos:        position{line: 388, col: 29, offset: 11778},
 											val:        "\\",
 											ignoreCase: false,
 											want:       "\"\\\\\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 266, col: 36, offset: 7728},
+											pos:  position{line: 388, col: 36, offset: 11785},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 266, col: 42, offset: 7734},
+								pos:  position{line: 388, col: 42, offset: 11791},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 266, col: 55, offset: 7747},
+						pos: position{line: 388, col: 55, offset: 11804},
 						exprs: []any{
 							&litMatcher{
-								pos:        position{line: 266, col: 55, offset: 7747},
+								pos:        position{line: 388, col: 55, offset: 11804},
 								val:        "\\",
 								ignoreCase: false,
 								want:       "\"\\\\\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 266, col: 60, offset: 7752},
+								pos:  position{line: 388, col: 60, offset: 11809},
 								name: "DoubleStringEscape",
 							},
 						},
@@ -1484,54 +2218,54 @@ var g = &grammar{
 		},
 		{
 			name: "SingleStringChar",
-			pos:  position{line: 267, col: 1, offset: 7771},
+			pos:  position{line: 389, col: 1, offset: 11828},
 			expr: &choiceExpr{
-				pos: position{line: 267, col: 20, offset: 7792},
+				pos: position{line: 389, col: 20, offset: 11849},
 				alternatives: []any{
 					&seqExpr{
-						pos: position{line: 267, col: 20, offset: 7792},
+						pos: position{line: 389, col: 20, offset: 11849},
 						exprs: []any{
 							&notExpr{
-								pos: position{line: 267, col: 20, offset: 7792},
+								pos: position{line: 389, col: 20, offset: 11849},
 								expr: &choiceExpr{
-									pos: position{line: 267, col: 23, offset: 7795},
+									pos: position{line: 389, col: 23, offset: 11852},
 									alternatives: []any{
 										&litMatcher{
-											pos:        position{line: 267, col: 23, offset: 7795},
+											pos:        position{line: 389, col: 23, offset: 11852},
 											val:        "'",
 											ignoreCase: false,
 											want:       "\"'\"",
 										},
 										&litMatcher{
-											pos:        position{line: 267, col: 29, offset: 7801},
+											pos:        position{line: 389, col: 29, offset: 11858},
 											val:        "\\",
 											ignoreCase: false,
 											want:       "\"\\\\\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 267, col: 36, offset: 7808},
+											pos:  position{line: 389, col: 36, offset: 11865},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 267, col: 42, offset: 7814},
+								pos:  position{line: 389, col: 42, offset: 11871},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 267, col: 55, offset: 7827},
+						pos: position{line: 389, col: 55, offset: 11884},
 						exprs: []any{
 							&litMatcher{
-								pos:        position{line: 267, col: 55, offset: 7827},
+								pos:        position{line: 389, col: 55, offset: 11884},
 								val:        "\\",
 								ignoreCase: false,
 								want:       "\"\\\\\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 267, col: 60, offset: 7832},
+								pos:  position{line: 389, col: 60, offset: 11889},
 								name: "SingleStringEscape",
 							},
 						},
@@ -1541,21 +2275,21 @@ var g = &grammar{
 		},
 		{
 			name: "RawStringChar",
-			pos:  position{line: 268, col: 1, offset: 7851},
+			pos:  position{line: 390, col: 1, offset: 11908},
 			expr: &seqExpr{
-				pos: position{line: 268, col: 17, offset: 7869},
+				pos: position{line: 390, col: 17, offset: 11926},
 				exprs: []any{
 					&notExpr{
-						pos: position{line: 268, col: 17, offset: 7869},
+						pos: position{line: 390, col: 17, offset: 11926},
 						expr: &litMatcher{
-							pos:        position{line: 268, col: 18, offset: 7870},
+							pos:        position{line: 390, col: 18, offset: 11927},
 							val:        "`",
 							ignoreCase: false,
 							want:       "\"`\"",
 						},
 					},
 					&ruleRefExpr{
-						pos:  position{line: 268, col: 22, offset: 7874},
+						pos:  position{line: 390, col: 22, offset: 11931},
 						name: "SourceChar",
 					},
 				},
@@ -1563,41 +2297,41 @@ var g = &grammar{
 		},
 		{
 			name: "DoubleStringEscape",
-			pos:  position{line: 270, col: 1, offset: 7886},
+			pos:  position{line: 392, col: 1, offset: 11943},
 			expr: &choiceExpr{
-				pos: position{line: 270, col: 22, offset: 7909},
+				pos: position{line: 392, col: 22, offset: 11966},
 				alternatives: []any{
 					&choiceExpr{
-						pos: position{line: 270, col: 24, offset: 7911},
+						pos: position{line: 392, col: 24, offset: 11968},
 						alternatives: []any{
 							&litMatcher{
-								pos:        position{line: 270, col: 24, offset: 7911},
+								pos:        position{line: 392, col: 24, offset: 11968},
 								val:        "\"",
 								ignoreCase: false,
 								want:       "\"\\\"\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 270, col: 30, offset: 7917},
+								pos:  position{line: 392, col: 30, offset: 11974},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 271, col: 7, offset: 7946},
+						pos: position{line: 393, col: 7, offset: 12003},
 						run: (*parser).callonDoubleStringEscape5,
 						expr: &choiceExpr{
-							pos: position{line: 271, col: 9, offset: 7948},
+							pos: position{line: 393, col: 9, offset: 12005},
 							alternatives: []any{
 								&ruleRefExpr{
-									pos:  position{line: 271, col: 9, offset: 7948},
+									pos:  position{line: 393, col: 9, offset: 12005},
 									name: "SourceChar",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 271, col: 22, offset: 7961},
+									pos:  position{line: 393, col: 22, offset: 12018},
 									name: "EOL",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 271, col: 28, offset: 7967},
+									pos:  position{line: 393, col: 28, offset: 12024},
 									name: "EOF",
 								},
 							},
@@ -1608,41 +2342,41 @@ var g = &grammar{
 		},
 		{
 			name: "SingleStringEscape",
-			pos:  position{line: 274, col: 1, offset: 8032},
+			pos:  position{line: 396, col: 1, offset: 12089},
 			expr: &choiceExpr{
-				pos: position{line: 274, col: 22, offset: 8055},
+				pos: position{line: 396, col: 22, offset: 12112},
 				alternatives: []any{
 					&choiceExpr{
-						pos: position{line: 274, col: 24, offset: 8057},
+						pos: position{line: 396, col: 24, offset: 12114},
 						alternatives: []any{
 							&litMatcher{
-								pos:        position{line: 274, col: 24, offset: 8057},
+								pos:        position{line: 396, col: 24, offset: 12114},
 								val:        "'",
 								ignoreCase: false,
 								want:       "\"'\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 274, col: 30, offset: 8063},
+								pos:  position{line: 396, col: 30, offset: 12120},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 275, col: 7, offset: 8092},
+						pos: position{line: 397, col: 7, offset: 12149},
 						run: (*parser).callonSingleStringEscape5,
 						expr: &choiceExpr{
-							pos: position{line: 275, col: 9, offset: 8094},
+							pos: position{line: 397, col: 9, offset: 12151},
 							alternatives: []any{
 								&ruleRefExpr{
-									pos:  position{line: 275, col: 9, offset: 8094},
+									pos:  position{line: 397, col: 9, offset: 12151},
 									name: "SourceChar",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 275, col: 22, offset: 8107},
+									pos:  position{line: 397, col: 22, offset: 12164},
 									name: "EOL",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 275, col: 28, offset: 8113},
+									pos:  position{line: 397, col: 28, offset: 12170},
 									name: "EOF",
 								},
 							},
@@ -1653,28 +2387,28 @@ var g = &grammar{
 		},
 		{
 			name: "CommonEscapeSequence",
-			pos:  position{line: 279, col: 1, offset: 8179},
+			pos:  position{line: 401, col: 1, offset: 12236},
 			expr: &choiceExpr{
-				pos: position{line: 279, col: 24, offset: 8204},
+				pos: position{line: 401, col: 24, offset: 12261},
 				alternatives: []any{
 					&ruleRefExpr{
-						pos:  position{line: 279, col: 24, offset: 8204},
+						pos:  position{line: 401, col: 24, offset: 12261},
 						name: "SingleCharEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 279, col: 43, offset: 8223},
+						pos:  position{line: 401, col: 43, offset: 12280},
 						name: "OctalEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 279, col: 57, offset: 8237},
+						pos:  position{line: 401, col: 57, offset: 12294},
 						name: "HexEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 279, col: 69, offset: 8249},
+						pos:  position{line: 401, col: 69, offset: 12306},
 						name: "LongUnicodeEscape",
 					},
 					&ruleRefExpr{
-						pos:  position{line: 279, col: 89, offset: 8269},
+						pos:  position{line: 401, col: 89, offset: 12326},
 						name: "ShortUnicodeEscape",
 					},
 				},
@@ -1682,54 +2416,54 @@ var g = &grammar{
 		},
 		{
 			name: "SingleCharEscape",
-			pos:  position{line: 280, col: 1, offset: 8288},
+			pos:  position{line: 402, col: 1, offset: 12345},
 			expr: &choiceExpr{
-				pos: position{line: 280, col: 20, offset: 8309},
+				pos: position{line: 402, col: 20, offset: 12366},
 				alternatives: []any{
 					&litMatcher{
-						pos:        position{line: 280, col: 20, offset: 8309},
+						pos:        position{line: 402, col: 20, offset: 12366},
 						val:        "a",
 						ignoreCase: false,
 						want:       "\"a\"",
 					},
 					&litMatcher{
-						pos:        position{line: 280, col: 26, offset: 8315},
+						pos:        position{line: 402, col: 26, offset: 12372},
 						val:        "b",
 						ignoreCase: false,
 						want:       "\"b\"",
 					},
 					&litMatcher{
-						pos:        position{line: 280, col: 32, offset: 8321},
+						pos:        position{line: 402, col: 32, offset: 12378},
 						val:        "n",
 						ignoreCase: false,
 						want:       "\"n\"",
 					},
 					&litMatcher{
-						pos:        position{line: 280, col: 38, offset: 8327},
+						pos:        position{line: 402, col: 38, offset: 12384},
 						val:        "f",
 						ignoreCase: false,
 						want:       "\"f\"",
 					},
 					&litMatcher{
-						pos:        position{line: 280, col: 44, offset: 8333},
+						pos:        position{line: 402, col: 44, offset: 12390},
 						val:        "r",
 						ignoreCase: false,
 						want:       "\"r\"",
 					},
 					&litMatcher{
-						pos:        position{line: 280, col: 50, offset: 8339},
+						pos:        position{line: 402, col: 50, offset: 12396},
 						val:        "t",
 						ignoreCase: false,
 						want:       "\"t\"",
 					},
 					&litMatcher{
-						pos:        position{line: 280, col: 56, offset: 8345},
+						pos:        position{line: 402, col: 56, offset: 12402},
 						val:        "v",
 						ignoreCase: false,
 						want:       "\"v\"",
 					},
 					&litMatcher{
-						pos:        position{line: 280, col: 62, offset: 8351},
+						pos:        position{line: 402, col: 62, offset: 12408},
 						val:        "\\",
 						ignoreCase: false,
 						want:       "\"\\\\\"",
@@ -1739,50 +2473,50 @@ var g = &grammar{
 		},
 		{
 			name: "OctalEscape",
-			pos:  position{line: 281, col: 1, offset: 8356},
+			pos:  position{line: 403, col: 1, offset: 12413},
 			expr: &choiceExpr{
-				pos: position{line: 281, col: 15, offset: 8372},
+				pos: position{line: 403, col: 15, offset: 12429},
 				alternatives: []any{
 					&seqExpr{
-						pos: position{line: 281, col: 15, offset: 8372},
+						pos: position{line: 403, col: 15, offset: 12429},
 						exprs: []any{
 							&ruleRefExpr{
-								pos:  position{line: 281, col: 15, offset: 8372},
+								pos:  position{line: 403, col: 15, offset: 12429},
 								name: "OctalDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 281, col: 26, offset: 8383},
+								pos:  position{line: 403, col: 26, offset: 12440},
 								name: "OctalDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 281, col: 37, offset: 8394},
+								pos:  position{line: 403, col: 37, offset: 12451},
 								name: "OctalDigit",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 282, col: 7, offset: 8411},
+						pos: position{line: 404, col: 7, offset: 12468},
 						run: (*parser).callonOctalEscape6,
 						expr: &seqExpr{
-							pos: position{line: 282, col: 7, offset: 8411},
+							pos: position{line: 404, col: 7, offset: 12468},
 							exprs: []any{
 								&ruleRefExpr{
-									pos:  position{line: 282, col: 7, offset: 8411},
+									pos:  position{line: 404, col: 7, offset: 12468},
 									name: "OctalDigit",
 								},
 								&choiceExpr{
-									pos: position{line: 282, col: 20, offset: 8424},
+									pos: position{line: 404, col: 20, offset: 12481},
 									alternatives: []any{
 										&ruleRefExpr{
-											pos:  position{line: 282, col: 20, offset: 8424},
+											pos:  position{line: 404, col: 20, offset: 12481},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 282, col: 33, offset: 8437},
+											pos:  position{line: 404, col: 33, offset: 12494},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 282, col: 39, offset: 8443},
+											pos:  position{line: 404, col: 39, offset: 12500},
 											name: "EOF",
 										},
 									},
@@ -1795,54 +2529,54 @@ var g = &grammar{
 		},
 		{
 			name: "HexEscape",
-			pos:  position{line: 285, col: 1, offset: 8504},
+			pos:  position{line: 407, col: 1, offset: 12561},
 			expr: &choiceExpr{
-				pos: position{line: 285, col: 13, offset: 8518},
+				pos: position{line: 407, col: 13, offset: 12575},
 				alternatives: []any{
 					&seqExpr{
-						pos: position{line: 285, col: 13, offset: 8518},
+						pos: position{line: 407, col: 13, offset: 12575},
 						exprs: []any{
 							&litMatcher{
-								pos:        position{line: 285, col: 13, offset: 8518},
+								pos:        position{line: 407, col: 13, offset: 12575},
 								val:        "x",
 								ignoreCase: false,
 								want:       "\"x\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 285, col: 17, offset: 8522},
+								pos:  position{line: 407, col: 17, offset: 12579},
 								name: "HexDigit",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 285, col: 26, offset: 8531},
+								pos:  position{line: 407, col: 26, offset: 12588},
 								name: "HexDigit",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 286, col: 7, offset: 8546},
+						pos: position{line: 408, col: 7, offset: 12603},
 						run: (*parser).callonHexEscape6,
 						expr: &seqExpr{
-							pos: position{line: 286, col: 7, offset: 8546},
+							pos: position{line: 408, col: 7, offset: 12603},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 286, col: 7, offset: 8546},
+									pos:        position{line: 408, col: 7, offset: 12603},
 									val:        "x",
 									ignoreCase: false,
 									want:       "\"x\"",
 								},
 								&choiceExpr{
-									pos: position{line: 286, col: 13, offset: 8552},
+									pos: position{line: 408, col: 13, offset: 12609},
 									alternatives: []any{
 										&ruleRefExpr{
-											pos:  position{line: 286, col: 13, offset: 8552},
+											pos:  position{line: 408, col: 13, offset: 12609},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 286, col: 26, offset: 8565},
+											pos:  position{line: 408, col: 26, offset: 12622},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 286, col: 32, offset: 8571},
+											pos:  position{line: 408, col: 32, offset: 12628},
 											name: "EOF",
 										},
 									},
@@ -1855,82 +2589,82 @@ var g = &grammar{
 		},
 		{
 			name: "LongUnicodeEscape",
-			pos:  position{line: 289, col: 1, offset: 8638},
+			pos:  position{line: 411, col: 1, offset: 12695},
 			expr: &choiceExpr{
-				pos: position{line: 290, col: 5, offset: 8664},
+				pos: position{line: 412, col: 5, offset: 12721},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 290, col: 5, offset: 8664},
+						pos: position{line: 412, col: 5, offset: 12721},
 						run: (*parser).callonLongUnicodeEscape2,
 						expr: &seqExpr{
-							pos: position{line: 290, col: 5, offset: 8664},
+							pos: position{line: 412, col: 5, offset: 12721},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 290, col: 5, offset: 8664},
+									pos:        position{line: 412, col: 5, offset: 12721},
 									val:        "U",
 									ignoreCase: false,
 									want:       "\"U\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 290, col: 9, offset: 8668},
+									pos:  position{line: 412, col: 9, offset: 12725},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 290, col: 18, offset: 8677},
+									pos:  position{line: 412, col: 18, offset: 12734},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 290, col: 27, offset: 8686},
+									pos:  position{line: 412, col: 27, offset: 12743},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 290, col: 36, offset: 8695},
+									pos:  position{line: 412, col: 36, offset: 12752},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 290, col: 45, offset: 8704},
+									pos:  position{line: 412, col: 45, offset: 12761},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 290, col: 54, offset: 8713},
+									pos:  position{line: 412, col: 54, offset: 12770},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 290, col: 63, offset: 8722},
+									pos:  position{line: 412, col: 63, offset: 12779},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 290, col: 72, offset: 8731},
+									pos:  position{line: 412, col: 72, offset: 12788},
 									name: "HexDigit",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 293, col: 7, offset: 8833},
+						pos: position{line: 415, col: 7, offset: 12890},
 						run: (*parser).callonLongUnicodeEscape13,
 						expr: &seqExpr{
-							pos: position{line: 293, col: 7, offset: 8833},
+							pos: position{line: 415, col: 7, offset: 12890},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 293, col: 7, offset: 8833},
+									pos:        position{line: 415, col: 7, offset: 12890},
 									val:        "U",
 									ignoreCase: false,
 									want:       "\"U\"",
 								},
 								&choiceExpr{
-									pos: position{line: 293, col: 13, offset: 8839},
+									pos: position{line: 415, col: 13, offset: 12896},
 									alternatives: []any{
 										&ruleRefExpr{
-											pos:  position{line: 293, col: 13, offset: 8839},
+											pos:  position{line: 415, col: 13, offset: 12896},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 293, col: 26, offset: 8852},
+											pos:  position{line: 415, col: 26, offset: 12909},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 293, col: 32, offset: 8858},
+											pos:  position{line: 415, col: 32, offset: 12915},
 											name: "EOF",
 										},
 									},
@@ -1943,66 +2677,66 @@ var g = &grammar{
 		},
 		{
 			name: "ShortUnicodeEscape",
-			pos:  position{line: 296, col: 1, offset: 8921},
+			pos:  position{line: 418, col: 1, offset: 12978},
 			expr: &choiceExpr{
-				pos: position{line: 297, col: 5, offset: 8948},
+				pos: position{line: 419, col: 5, offset: 13005},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 297, col: 5, offset: 8948},
+						pos: position{line: 419, col: 5, offset: 13005},
 						run: (*parser).callonShortUnicodeEscape2,
 						expr: &seqExpr{
-							pos: position{line: 297, col: 5, offset: 8948},
+							pos: position{line: 419, col: 5, offset: 13005},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 297, col: 5, offset: 8948},
+									pos:        position{line: 419, col: 5, offset: 13005},
 									val:        "u",
 									ignoreCase: false,
 									want:       "\"u\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 297, col: 9, offset: 8952},
+									pos:  position{line: 419, col: 9, offset: 13009},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 297, col: 18, offset: 8961},
+									pos:  position{line: 419, col: 18, offset: 13018},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 297, col: 27, offset: 8970},
+									pos:  position{line: 419, col: 27, offset: 13027},
 									name: "HexDigit",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 297, col: 36, offset: 8979},
+									pos:  position{line: 419, col: 36, offset: 13036},
 									name: "HexDigit",
 								},
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 300, col: 7, offset: 9081},
+						pos: position{line: 422, col: 7, offset: 13138},
 						run: (*parser).callonShortUnicodeEscape9,
 						expr: &seqExpr{
-							pos: position{line: 300, col: 7, offset: 9081},
+							pos: position{line: 422, col: 7, offset: 13138},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 300, col: 7, offset: 9081},
+									pos:        position{line: 422, col: 7, offset: 13138},
 									val:        "u",
 									ignoreCase: false,
 									want:       "\"u\"",
 								},
 								&choiceExpr{
-									pos: position{line: 300, col: 13, offset: 9087},
+									pos: position{line: 422, col: 13, offset: 13144},
 									alternatives: []any{
 										&ruleRefExpr{
-											pos:  position{line: 300, col: 13, offset: 9087},
+											pos:  position{line: 422, col: 13, offset: 13144},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 300, col: 26, offset: 9100},
+											pos:  position{line: 422, col: 26, offset: 13157},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 300, col: 32, offset: 9106},
+											pos:  position{line: 422, col: 32, offset: 13163},
 											name: "EOF",
 										},
 									},
@@ -2015,9 +2749,9 @@ var g = &grammar{
 		},
 		{
 			name: "OctalDigit",
-			pos:  position{line: 304, col: 1, offset: 9170},
+			pos:  position{line: 426, col: 1, offset: 13227},
 			expr: &charClassMatcher{
-				pos:        position{line: 304, col: 14, offset: 9185},
+				pos:        position{line: 426, col: 14, offset: 13242},
 				val:        "[0-7]",
 				ranges:     []rune{'0', '7'},
 				ignoreCase: false,
@@ -2026,9 +2760,9 @@ var g = &grammar{
 		},
 		{
 			name: "DecimalDigit",
-			pos:  position{line: 305, col: 1, offset: 9191},
+			pos:  position{line: 427, col: 1, offset: 13248},
 			expr: &charClassMatcher{
-				pos:        position{line: 305, col: 16, offset: 9208},
+				pos:        position{line: 427, col: 16, offset: 13265},
 				val:        "[0-9]",
 				ranges:     []rune{'0', '9'},
 				ignoreCase: false,
@@ -2037,9 +2771,9 @@ var g = &grammar{
 		},
 		{
 			name: "HexDigit",
-			pos:  position{line: 306, col: 1, offset: 9214},
+			pos:  position{line: 428, col: 1, offset: 13271},
 			expr: &charClassMatcher{
-				pos:        position{line: 306, col: 12, offset: 9227},
+				pos:        position{line: 428, col: 12, offset: 13284},
 				val:        "[0-9a-f]i",
 				ranges:     []rune{'0', '9', 'a', 'f'},
 				ignoreCase: true,
@@ -2048,63 +2782,55 @@ var g = &grammar{
 		},
 		{
 			name: "CharClassMatcher",
-			pos:  position{line: 308, col: 1, offset: 9238},
+			pos:  position{line: 430, col: 1, offset: 13295},
 			expr: &choiceExpr{
-				pos: position{line: 308, col: 20, offset: 9259},
+				pos: position{line: 430, col: 20, offset: 13316},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 308, col: 20, offset: 9259},
+						pos: position{line: 430, col: 20, offset: 13316},
 						run: (*parser).callonCharClassMatcher2,
 						expr: &seqExpr{
-							pos: position{line: 308, col: 20, offset: 9259},
+							pos: position{line: 430, col: 20, offset: 13316},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 308, col: 20, offset: 9259},
+									pos:        position{line: 430, col: 20, offset: 13316},
 									val:        "[",
 									ignoreCase: false,
 									want:       "\"[\"",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 308, col: 24, offset: 9263},
-									expr: &choiceExpr{
-										pos: position{line: 308, col: 26, offset: 9265},
-										alternatives: []any{
+									pos: position{line: 430, col: 24, offset: 13320},
+									expr: &ruleRefExpr{
+										pos:  position{line: 430, col: 24, offset: 13320},
+										name: "ClassItem",
+									},
+								},
+								&zeroOrMoreExpr{
+									pos: position{line: 430, col: 35, offset: 13331},
+									expr: &seqExpr{
+										pos: position{line: 430, col: 37, offset: 13333},
+										exprs: []any{
 											&ruleRefExpr{
-												pos:  position{line: 308, col: 26, offset: 9265},
-												name: "ClassCharRange",
+												pos:  position{line: 430, col: 37, offset: 13333},
+												name: "ClassSetOp",
 											},
 											&ruleRefExpr{
-												pos:  position{line: 308, col: 43, offset: 9282},
-												name: "ClassChar",
-											},
-											&seqExpr{
-												pos: position{line: 308, col: 55, offset: 9294},
-												exprs: []any{
-													&litMatcher{
-														pos:        position{line: 308, col: 55, offset: 9294},
-														val:        "\\",
-														ignoreCase: false,
-														want:       "\"\\\\\"",
-													},
-													&ruleRefExpr{
-														pos:  position{line: 308, col: 60, offset: 9299},
-														name: "UnicodeClassEscape",
-													},
-												},
+												pos:  position{line: 430, col: 48, offset: 13344},
+												name: "ClassSetOperand",
 											},
 										},
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 308, col: 82, offset: 9321},
+									pos:        position{line: 430, col: 67, offset: 13363},
 									val:        "]",
 									ignoreCase: false,
 									want:       "\"]\"",
 								},
 								&zeroOrOneExpr{
-									pos: position{line: 308, col: 86, offset: 9325},
+									pos: position{line: 430, col: 71, offset: 13367},
 									expr: &litMatcher{
-										pos:        position{line: 308, col: 86, offset: 9325},
+										pos:        position{line: 430, col: 71, offset: 13367},
 										val:        "i",
 										ignoreCase: false,
 										want:       "\"i\"",
@@ -2114,45 +2840,45 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 312, col: 5, offset: 9432},
-						run: (*parser).callonCharClassMatcher15,
+						pos: position{line: 434, col: 5, offset: 13474},
+						run: (*parser).callonCharClassMatcher14,
 						expr: &seqExpr{
-							pos: position{line: 312, col: 5, offset: 9432},
+							pos: position{line: 434, col: 5, offset: 13474},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 312, col: 5, offset: 9432},
+									pos:        position{line: 434, col: 5, offset: 13474},
 									val:        "[",
 									ignoreCase: false,
 									want:       "\"[\"",
 								},
 								&zeroOrMoreExpr{
-									pos: position{line: 312, col: 9, offset: 9436},
+									pos: position{line: 434, col: 9, offset: 13478},
 									expr: &seqExpr{
-										pos: position{line: 312, col: 11, offset: 9438},
+										pos: position{line: 434, col: 11, offset: 13480},
 										exprs: []any{
 											&notExpr{
-												pos: position{line: 312, col: 11, offset: 9438},
+												pos: position{line: 434, col: 11, offset: 13480},
 												expr: &ruleRefExpr{
-													pos:  position{line: 312, col: 14, offset: 9441},
+													pos:  position{line: 434, col: 14, offset: 13483},
 													name: "EOL",
 												},
 											},
 											&ruleRefExpr{
-												pos:  position{line: 312, col: 20, offset: 9447},
+												pos:  position{line: 434, col: 20, offset: 13489},
 												name: "SourceChar",
 											},
 										},
 									},
 								},
 								&choiceExpr{
-									pos: position{line: 312, col: 36, offset: 9463},
+									pos: position{line: 434, col: 36, offset: 13505},
 									alternatives: []any{
 										&ruleRefExpr{
-											pos:  position{line: 312, col: 36, offset: 9463},
+											pos:  position{line: 434, col: 36, offset: 13505},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 312, col: 42, offset: 9469},
+											pos:  position{line: 434, col: 42, offset: 13511},
 											name: "EOF",
 										},
 									},
@@ -2163,24 +2889,201 @@ var g = &grammar{
 				},
 			},
 		},
+		{
+			name: "ClassItem",
+			pos:  position{line: 438, col: 1, offset: 13621},
+			expr: &seqExpr{
+				pos: position{line: 438, col: 13, offset: 13635},
+				exprs: []any{
+					&notExpr{
+						pos: position{line: 438, col: 13, offset: 13635},
+						expr: &ruleRefExpr{
+							pos:  position{line: 438, col: 14, offset: 13636},
+							name: "ClassSetOp",
+						},
+					},
+					&choiceExpr{
+						pos: position{line: 438, col: 27, offset: 13649},
+						alternatives: []any{
+							&ruleRefExpr{
+								pos:  position{line: 438, col: 27, offset: 13649},
+								name: "ClassCharRange",
+							},
+							&ruleRefExpr{
+								pos:  position{line: 438, col: 44, offset: 13666},
+								name: "ClassChar",
+							},
+							&seqExpr{
+								pos: position{line: 438, col: 56, offset: 13678},
+								exprs: []any{
+									&litMatcher{
+										pos:        position{line: 438, col: 56, offset: 13678},
+										val:        "\\",
+										ignoreCase: false,
+										want:       "\"\\\\\"",
+									},
+									&ruleRefExpr{
+										pos:  position{line: 438, col: 61, offset: 13683},
+										name: "UnicodeClassEscape",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ClassSetOp",
+			pos:  position{line: 439, col: 1, offset: 13704},
+			expr: &seqExpr{
+				pos: position{line: 439, col: 14, offset: 13719},
+				exprs: []any{
+					&choiceExpr{
+						pos: position{line: 439, col: 16, offset: 13721},
+						alternatives: []any{
+							&litMatcher{
+								pos:        position{line: 439, col: 16, offset: 13721},
+								val:        "--",
+								ignoreCase: false,
+								want:       "\"--\"",
+							},
+							&litMatcher{
+								pos:        position{line: 439, col: 23, offset: 13728},
+								val:        "&&",
+								ignoreCase: false,
+								want:       "\"&&\"",
+							},
+						},
+					},
+					&andExpr{
+						pos: position{line: 439, col: 30, offset: 13735},
+						expr: &choiceExpr{
+							pos: position{line: 439, col: 33, offset: 13738},
+							alternatives: []any{
+								&litMatcher{
+									pos:        position{line: 439, col: 33, offset: 13738},
+									val:        "[",
+									ignoreCase: false,
+									want:       "\"[\"",
+								},
+								&litMatcher{
+									pos:        position{line: 439, col: 39, offset: 13744},
+									val:        "\\p",
+									ignoreCase: false,
+									want:       "\"\\\\p\"",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "ClassSetOperand",
+			pos:  position{line: 440, col: 1, offset: 13752},
+			expr: &choiceExpr{
+				pos: position{line: 440, col: 19, offset: 13772},
+				alternatives: []any{
+					&seqExpr{
+						pos: position{line: 440, col: 19, offset: 13772},
+						exprs: []any{
+							&litMatcher{
+								pos:        position{line: 440, col: 19, offset: 13772},
+								val:        "[",
+								ignoreCase: false,
+								want:       "\"[\"",
+							},
+							&zeroOrMoreExpr{
+								pos: position{line: 440, col: 23, offset: 13776},
+								expr: &ruleRefExpr{
+									pos:  position{line: 440, col: 23, offset: 13776},
+									name: "ClassItem",
+								},
+							},
+							&zeroOrMoreExpr{
+								pos: position{line: 440, col: 34, offset: 13787},
+								expr: &seqExpr{
+									pos: position{line: 440, col: 36, offset: 13789},
+									exprs: []any{
+										&ruleRefExpr{
+											pos:  position{line: 440, col: 36, offset: 13789},
+											name: "ClassSetOp",
+										},
+										&ruleRefExpr{
+											pos:  position{line: 440, col: 47, offset: 13800},
+											name: "ClassSetOperand",
+										},
+									},
+								},
+							},
+							&litMatcher{
+								pos:        position{line: 440, col: 66, offset: 13819},
+								val:        "]",
+								ignoreCase: false,
+								want:       "\"]\"",
+							},
+						},
+					},
+					&oneOrMoreExpr{
+						pos: position{line: 440, col: 72, offset: 13825},
+						expr: &ruleRefExpr{
+							pos:  position{line: 440, col: 72, offset: 13825},
+							name: "ClassItem",
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "ClassCharRange",
-			pos:  position{line: 316, col: 1, offset: 9579},
+			pos:  position{line: 441, col: 1, offset: 13836},
 			expr: &seqExpr{
-				pos: position{line: 316, col: 18, offset: 9598},
+				pos: position{line: 441, col: 18, offset: 13855},
 				exprs: []any{
 					&ruleRefExpr{
-						pos:  position{line: 316, col: 18, offset: 9598},
+						pos:  position{line: 441, col: 18, offset: 13855},
 						name: "ClassChar",
 					},
 					&litMatcher{
-						pos:        position{line: 316, col: 28, offset: 9608},
+						pos:        position{line: 441, col: 28, offset: 13865},
 						val:        "-",
 						ignoreCase: false,
 						want:       "\"-\"",
 					},
+					&notExpr{
+						pos: position{line: 441, col: 32, offset: 13869},
+						expr: &seqExpr{
+							pos: position{line: 441, col: 35, offset: 13872},
+							exprs: []any{
+								&litMatcher{
+									pos:        position{line: 441, col: 35, offset: 13872},
+									val:        "-",
+									ignoreCase: false,
+									want:       "\"-\"",
+								},
+								&choiceExpr{
+									pos: position{line: 441, col: 41, offset: 13878},
+									alternatives: []any{
+										&litMatcher{
+											pos:        position{line: 441, col: 41, offset: 13878},
+											val:        "[",
+											ignoreCase: false,
+											want:       "\"[\"",
+										},
+										&litMatcher{
+											pos:        position{line: 441, col: 47, offset: 13884},
+											val:        "\\p",
+											ignoreCase: false,
+											want:       "\"\\\\p\"",
+										},
+									},
+								},
+							},
+						},
+					},
 					&ruleRefExpr{
-						pos:  position{line: 316, col: 32, offset: 9612},
+						pos:  position{line: 441, col: 57, offset: 13894},
 						name: "ClassChar",
 					},
 				},
@@ -2188,54 +3091,54 @@ var g = &grammar{
 		},
 		{
 			name: "ClassChar",
-			pos:  position{line: 317, col: 1, offset: 9622},
+			pos:  position{line: 442, col: 1, offset: 13904},
 			expr: &choiceExpr{
-				pos: position{line: 317, col: 13, offset: 9636},
+				pos: position{line: 442, col: 13, offset: 13918},
 				alternatives: []any{
 					&seqExpr{
-						pos: position{line: 317, col: 13, offset: 9636},
+						pos: position{line: 442, col: 13, offset: 13918},
 						exprs: []any{
 							&notExpr{
-								pos: position{line: 317, col: 13, offset: 9636},
+								pos: position{line: 442, col: 13, offset: 13918},
 								expr: &choiceExpr{
-									pos: position{line: 317, col: 16, offset: 9639},
+									pos: position{line: 442, col: 16, offset: 13921},
 									alternatives: []any{
 										&litMatcher{
-											pos:        position{line: 317, col: 16, offset: 9639},
+											pos:        position{line: 442, col: 16, offset: 13921},
 											val:        "]",
 											ignoreCase: false,
 											want:       "\"]\"",
 										},
 										&litMatcher{
-											pos:        position{line: 317, col: 22, offset: 9645},
+											pos:        position{line: 442, col: 22, offset: 13927},
 											val:        "\\",
 											ignoreCase: false,
 											want:       "\"\\\\\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 317, col: 29, offset: 9652},
+											pos:  position{line: 442, col: 29, offset: 13934},
 											name: "EOL",
 										},
 									},
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 317, col: 35, offset: 9658},
+								pos:  position{line: 442, col: 35, offset: 13940},
 								name: "SourceChar",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 317, col: 48, offset: 9671},
+						pos: position{line: 442, col: 48, offset: 13953},
 						exprs: []any{
 							&litMatcher{
-								pos:        position{line: 317, col: 48, offset: 9671},
+								pos:        position{line: 442, col: 48, offset: 13953},
 								val:        "\\",
 								ignoreCase: false,
 								want:       "\"\\\\\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 317, col: 53, offset: 9676},
+								pos:  position{line: 442, col: 53, offset: 13958},
 								name: "CharClassEscape",
 							},
 						},
@@ -2245,53 +3148,53 @@ var g = &grammar{
 		},
 		{
 			name: "CharClassEscape",
-			pos:  position{line: 318, col: 1, offset: 9692},
+			pos:  position{line: 443, col: 1, offset: 13974},
 			expr: &choiceExpr{
-				pos: position{line: 318, col: 19, offset: 9712},
+				pos: position{line: 443, col: 19, offset: 13994},
 				alternatives: []any{
 					&choiceExpr{
-						pos: position{line: 318, col: 21, offset: 9714},
+						pos: position{line: 443, col: 21, offset: 13996},
 						alternatives: []any{
 							&litMatcher{
-								pos:        position{line: 318, col: 21, offset: 9714},
+								pos:        position{line: 443, col: 21, offset: 13996},
 								val:        "]",
 								ignoreCase: false,
 								want:       "\"]\"",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 318, col: 27, offset: 9720},
+								pos:  position{line: 443, col: 27, offset: 14002},
 								name: "CommonEscapeSequence",
 							},
 						},
 					},
 					&actionExpr{
-						pos: position{line: 319, col: 7, offset: 9749},
+						pos: position{line: 444, col: 7, offset: 14031},
 						run: (*parser).callonCharClassEscape5,
 						expr: &seqExpr{
-							pos: position{line: 319, col: 7, offset: 9749},
+							pos: position{line: 444, col: 7, offset: 14031},
 							exprs: []any{
 								&notExpr{
-									pos: position{line: 319, col: 7, offset: 9749},
+									pos: position{line: 444, col: 7, offset: 14031},
 									expr: &litMatcher{
-										pos:        position{line: 319, col: 8, offset: 9750},
+										pos:        position{line: 444, col: 8, offset: 14032},
 										val:        "p",
 										ignoreCase: false,
 										want:       "\"p\"",
 									},
 								},
 								&choiceExpr{
-									pos: position{line: 319, col: 14, offset: 9756},
+									pos: position{line: 444, col: 14, offset: 14038},
 									alternatives: []any{
 										&ruleRefExpr{
-											pos:  position{line: 319, col: 14, offset: 9756},
+											pos:  position{line: 444, col: 14, offset: 14038},
 											name: "SourceChar",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 319, col: 27, offset: 9769},
+											pos:  position{line: 444, col: 27, offset: 14051},
 											name: "EOL",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 319, col: 33, offset: 9775},
+											pos:  position{line: 444, col: 33, offset: 14057},
 											name: "EOF",
 										},
 									},
@@ -2304,51 +3207,51 @@ var g = &grammar{
 		},
 		{
 			name: "UnicodeClassEscape",
-			pos:  position{line: 323, col: 1, offset: 9841},
+			pos:  position{line: 448, col: 1, offset: 14123},
 			expr: &seqExpr{
-				pos: position{line: 323, col: 22, offset: 9864},
+				pos: position{line: 448, col: 22, offset: 14146},
 				exprs: []any{
 					&litMatcher{
-						pos:        position{line: 323, col: 22, offset: 9864},
+						pos:        position{line: 448, col: 22, offset: 14146},
 						val:        "p",
 						ignoreCase: false,
 						want:       "\"p\"",
 					},
 					&choiceExpr{
-						pos: position{line: 324, col: 7, offset: 9876},
+						pos: position{line: 449, col: 7, offset: 14158},
 						alternatives: []any{
 							&ruleRefExpr{
-								pos:  position{line: 324, col: 7, offset: 9876},
+								pos:  position{line: 449, col: 7, offset: 14158},
 								name: "SingleCharUnicodeClass",
 							},
 							&actionExpr{
-								pos: position{line: 325, col: 7, offset: 9905},
+								pos: position{line: 450, col: 7, offset: 14187},
 								run: (*parser).callonUnicodeClassEscape5,
 								expr: &seqExpr{
-									pos: position{line: 325, col: 7, offset: 9905},
+									pos: position{line: 450, col: 7, offset: 14187},
 									exprs: []any{
 										&notExpr{
-											pos: position{line: 325, col: 7, offset: 9905},
+											pos: position{line: 450, col: 7, offset: 14187},
 											expr: &litMatcher{
-												pos:        position{line: 325, col: 8, offset: 9906},
+												pos:        position{line: 450, col: 8, offset: 14188},
 												val:        "{",
 												ignoreCase: false,
 												want:       "\"{\"",
 											},
 										},
 										&choiceExpr{
-											pos: position{line: 325, col: 14, offset: 9912},
+											pos: position{line: 450, col: 14, offset: 14194},
 											alternatives: []any{
 												&ruleRefExpr{
-													pos:  position{line: 325, col: 14, offset: 9912},
+													pos:  position{line: 450, col: 14, offset: 14194},
 													name: "SourceChar",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 325, col: 27, offset: 9925},
+													pos:  position{line: 450, col: 27, offset: 14207},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 325, col: 33, offset: 9931},
+													pos:  position{line: 450, col: 33, offset: 14213},
 													name: "EOF",
 												},
 											},
@@ -2357,27 +3260,27 @@ var g = &grammar{
 								},
 							},
 							&actionExpr{
-								pos: position{line: 326, col: 7, offset: 10002},
+								pos: position{line: 451, col: 7, offset: 14284},
 								run: (*parser).callonUnicodeClassEscape13,
 								expr: &seqExpr{
-									pos: position{line: 326, col: 7, offset: 10002},
+									pos: position{line: 451, col: 7, offset: 14284},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 326, col: 7, offset: 10002},
+											pos:        position{line: 451, col: 7, offset: 14284},
 											val:        "{",
 											ignoreCase: false,
 											want:       "\"{\"",
 										},
 										&labeledExpr{
-											pos:   position{line: 326, col: 11, offset: 10006},
+											pos:   position{line: 451, col: 11, offset: 14288},
 											label: "ident",
 											expr: &ruleRefExpr{
-												pos:  position{line: 326, col: 17, offset: 10012},
+												pos:  position{line: 451, col: 17, offset: 14294},
 												name: "IdentifierName",
 											},
 										},
 										&litMatcher{
-											pos:        position{line: 326, col: 32, offset: 10027},
+											pos:        position{line: 451, col: 32, offset: 14309},
 											val:        "}",
 											ignoreCase: false,
 											want:       "\"}\"",
@@ -2386,36 +3289,36 @@ var g = &grammar{
 								},
 							},
 							&actionExpr{
-								pos: position{line: 332, col: 7, offset: 10204},
+								pos: position{line: 457, col: 7, offset: 14486},
 								run: (*parser).callonUnicodeClassEscape19,
 								expr: &seqExpr{
-									pos: position{line: 332, col: 7, offset: 10204},
+									pos: position{line: 457, col: 7, offset: 14486},
 									exprs: []any{
 										&litMatcher{
-											pos:        position{line: 332, col: 7, offset: 10204},
+											pos:        position{line: 457, col: 7, offset: 14486},
 											val:        "{",
 											ignoreCase: false,
 											want:       "\"{\"",
 										},
 										&ruleRefExpr{
-											pos:  position{line: 332, col: 11, offset: 10208},
+											pos:  position{line: 457, col: 11, offset: 14490},
 											name: "IdentifierName",
 										},
 										&choiceExpr{
-											pos: position{line: 332, col: 28, offset: 10225},
+											pos: position{line: 457, col: 28, offset: 14507},
 											alternatives: []any{
 												&litMatcher{
-													pos:        position{line: 332, col: 28, offset: 10225},
+													pos:        position{line: 457, col: 28, offset: 14507},
 													val:        "]",
 													ignoreCase: false,
 													want:       "\"]\"",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 332, col: 34, offset: 10231},
+													pos:  position{line: 457, col: 34, offset: 14513},
 													name: "EOL",
 												},
 												&ruleRefExpr{
-													pos:  position{line: 332, col: 40, offset: 10237},
+													pos:  position{line: 457, col: 40, offset: 14519},
 													name: "EOF",
 												},
 											},
@@ -2430,9 +3333,9 @@ var g = &grammar{
 		},
 		{
 			name: "SingleCharUnicodeClass",
-			pos:  position{line: 336, col: 1, offset: 10320},
+			pos:  position{line: 461, col: 1, offset: 14602},
 			expr: &charClassMatcher{
-				pos:        position{line: 336, col: 26, offset: 10347},
+				pos:        position{line: 461, col: 26, offset: 14629},
 				val:        "[LMNCPZS]",
 				chars:      []rune{'L', 'M', 'N', 'C', 'P', 'Z', 'S'},
 				ignoreCase: false,
@@ -2441,12 +3344,12 @@ var g = &grammar{
 		},
 		{
 			name: "AnyMatcher",
-			pos:  position{line: 338, col: 1, offset: 10358},
+			pos:  position{line: 463, col: 1, offset: 14640},
 			expr: &actionExpr{
-				pos: position{line: 338, col: 14, offset: 10373},
+				pos: position{line: 463, col: 14, offset: 14655},
 				run: (*parser).callonAnyMatcher1,
 				expr: &litMatcher{
-					pos:        position{line: 338, col: 14, offset: 10373},
+					pos:        position{line: 463, col: 14, offset: 14655},
 					val:        ".",
 					ignoreCase: false,
 					want:       "\".\"",
@@ -2455,38 +3358,38 @@ var g = &grammar{
 		},
 		{
 			name: "ThrowExpr",
-			pos:  position{line: 343, col: 1, offset: 10448},
+			pos:  position{line: 468, col: 1, offset: 14730},
 			expr: &choiceExpr{
-				pos: position{line: 343, col: 13, offset: 10462},
+				pos: position{line: 468, col: 13, offset: 14744},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 343, col: 13, offset: 10462},
+						pos: position{line: 468, col: 13, offset: 14744},
 						run: (*parser).callonThrowExpr2,
 						expr: &seqExpr{
-							pos: position{line: 343, col: 13, offset: 10462},
+							pos: position{line: 468, col: 13, offset: 14744},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 343, col: 13, offset: 10462},
+									pos:        position{line: 468, col: 13, offset: 14744},
 									val:        "%",
 									ignoreCase: false,
 									want:       "\"%\"",
 								},
 								&litMatcher{
-									pos:        position{line: 343, col: 17, offset: 10466},
+									pos:        position{line: 468, col: 17, offset: 14748},
 									val:        "{",
 									ignoreCase: false,
 									want:       "\"{\"",
 								},
 								&labeledExpr{
-									pos:   position{line: 343, col: 21, offset: 10470},
+									pos:   position{line: 468, col: 21, offset: 14752},
 									label: "label",
 									expr: &ruleRefExpr{
-										pos:  position{line: 343, col: 27, offset: 10476},
+										pos:  position{line: 468, col: 27, offset: 14758},
 										name: "IdentifierName",
 									},
 								},
 								&litMatcher{
-									pos:        position{line: 343, col: 42, offset: 10491},
+									pos:        position{line: 468, col: 42, offset: 14773},
 									val:        "}",
 									ignoreCase: false,
 									want:       "\"}\"",
@@ -2495,29 +3398,29 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 347, col: 5, offset: 10599},
+						pos: position{line: 472, col: 5, offset: 14881},
 						run: (*parser).callonThrowExpr9,
 						expr: &seqExpr{
-							pos: position{line: 347, col: 5, offset: 10599},
+							pos: position{line: 472, col: 5, offset: 14881},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 347, col: 5, offset: 10599},
+									pos:        position{line: 472, col: 5, offset: 14881},
 									val:        "%",
 									ignoreCase: false,
 									want:       "\"%\"",
 								},
 								&litMatcher{
-									pos:        position{line: 347, col: 9, offset: 10603},
+									pos:        position{line: 472, col: 9, offset: 14885},
 									val:        "{",
 									ignoreCase: false,
 									want:       "\"{\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 347, col: 13, offset: 10607},
+									pos:  position{line: 472, col: 13, offset: 14889},
 									name: "IdentifierName",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 347, col: 28, offset: 10622},
+									pos:  position{line: 472, col: 28, offset: 14904},
 									name: "EOF",
 								},
 							},
@@ -2528,28 +3431,28 @@ var g = &grammar{
 		},
 		{
 			name: "CodeBlock",
-			pos:  position{line: 351, col: 1, offset: 10693},
+			pos:  position{line: 476, col: 1, offset: 14975},
 			expr: &choiceExpr{
-				pos: position{line: 351, col: 13, offset: 10707},
+				pos: position{line: 476, col: 13, offset: 14989},
 				alternatives: []any{
 					&actionExpr{
-						pos: position{line: 351, col: 13, offset: 10707},
+						pos: position{line: 476, col: 13, offset: 14989},
 						run: (*parser).callonCodeBlock2,
 						expr: &seqExpr{
-							pos: position{line: 351, col: 13, offset: 10707},
+							pos: position{line: 476, col: 13, offset: 14989},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 351, col: 13, offset: 10707},
+									pos:        position{line: 476, col: 13, offset: 14989},
 									val:        "{",
 									ignoreCase: false,
 									want:       "\"{\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 351, col: 17, offset: 10711},
+									pos:  position{line: 476, col: 17, offset: 14993},
 									name: "Code",
 								},
 								&litMatcher{
-									pos:        position{line: 351, col: 22, offset: 10716},
+									pos:        position{line: 476, col: 22, offset: 14998},
 									val:        "}",
 									ignoreCase: false,
 									want:       "\"}\"",
@@ -2558,23 +3461,23 @@ var g = &grammar{
 						},
 					},
 					&actionExpr{
-						pos: position{line: 355, col: 5, offset: 10815},
+						pos: position{line: 480, col: 5, offset: 15097},
 						run: (*parser).callonCodeBlock7,
 						expr: &seqExpr{
-							pos: position{line: 355, col: 5, offset: 10815},
+							pos: position{line: 480, col: 5, offset: 15097},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 355, col: 5, offset: 10815},
+									pos:        position{line: 480, col: 5, offset: 15097},
 									val:        "{",
 									ignoreCase: false,
 									want:       "\"{\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 355, col: 9, offset: 10819},
+									pos:  position{line: 480, col: 9, offset: 15101},
 									name: "Code",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 355, col: 14, offset: 10824},
+									pos:  position{line: 480, col: 14, offset: 15106},
 									name: "EOF",
 								},
 							},
@@ -2585,32 +3488,32 @@ var g = &grammar{
 		},
 		{
 			name: "Code",
-			pos:  position{line: 359, col: 1, offset: 10889},
+			pos:  position{line: 484, col: 1, offset: 15171},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 359, col: 8, offset: 10898},
+				pos: position{line: 484, col: 8, offset: 15180},
 				expr: &choiceExpr{
-					pos: position{line: 359, col: 10, offset: 10900},
+					pos: position{line: 484, col: 10, offset: 15182},
 					alternatives: []any{
 						&oneOrMoreExpr{
-							pos: position{line: 359, col: 10, offset: 10900},
+							pos: position{line: 484, col: 10, offset: 15182},
 							expr: &choiceExpr{
-								pos: position{line: 359, col: 12, offset: 10902},
+								pos: position{line: 484, col: 12, offset: 15184},
 								alternatives: []any{
 									&ruleRefExpr{
-										pos:  position{line: 359, col: 12, offset: 10902},
+										pos:  position{line: 484, col: 12, offset: 15184},
 										name: "Comment",
 									},
 									&ruleRefExpr{
-										pos:  position{line: 359, col: 22, offset: 10912},
+										pos:  position{line: 484, col: 22, offset: 15194},
 										name: "CodeStringLiteral",
 									},
 									&seqExpr{
-										pos: position{line: 359, col: 42, offset: 10932},
+										pos: position{line: 484, col: 42, offset: 15214},
 										exprs: []any{
 											&notExpr{
-												pos: position{line: 359, col: 42, offset: 10932},
+												pos: position{line: 484, col: 42, offset: 15214},
 												expr: &charClassMatcher{
-													pos:        position{line: 359, col: 43, offset: 10933},
+													pos:        position{line: 484, col: 43, offset: 15215},
 													val:        "[{}]",
 													chars:      []rune{'{', '}'},
 													ignoreCase: false,
@@ -2618,7 +3521,7 @@ var g = &grammar{
 												},
 											},
 											&ruleRefExpr{
-												pos:  position{line: 359, col: 48, offset: 10938},
+												pos:  position{line: 484, col: 48, offset: 15220},
 												name: "SourceChar",
 											},
 										},
@@ -2627,20 +3530,20 @@ var g = &grammar{
 							},
 						},
 						&seqExpr{
-							pos: position{line: 359, col: 64, offset: 10954},
+							pos: position{line: 484, col: 64, offset: 15236},
 							exprs: []any{
 								&litMatcher{
-									pos:        position{line: 359, col: 64, offset: 10954},
+									pos:        position{line: 484, col: 64, offset: 15236},
 									val:        "{",
 									ignoreCase: false,
 									want:       "\"{\"",
 								},
 								&ruleRefExpr{
-									pos:  position{line: 359, col: 68, offset: 10958},
+									pos:  position{line: 484, col: 68, offset: 15240},
 									name: "Code",
 								},
 								&litMatcher{
-									pos:        position{line: 359, col: 73, offset: 10963},
+									pos:        position{line: 484, col: 73, offset: 15245},
 									val:        "}",
 									ignoreCase: false,
 									want:       "\"}\"",
@@ -2653,38 +3556,38 @@ var g = &grammar{
 		},
 		{
 			name: "CodeStringLiteral",
-			pos:  position{line: 361, col: 1, offset: 10971},
+			pos:  position{line: 486, col: 1, offset: 15253},
 			expr: &choiceExpr{
-				pos: position{line: 361, col: 21, offset: 10993},
+				pos: position{line: 486, col: 21, offset: 15275},
 				alternatives: []any{
 					&seqExpr{
-						pos: position{line: 361, col: 21, offset: 10993},
+						pos: position{line: 486, col: 21, offset: 15275},
 						exprs: []any{
 							&litMatcher{
-								pos:        position{line: 361, col: 21, offset: 10993},
+								pos:        position{line: 486, col: 21, offset: 15275},
 								val:        "\"",
 								ignoreCase: false,
 								want:       "\"\\\"\"",
 							},
 							&zeroOrMoreExpr{
-								pos: position{line: 361, col: 25, offset: 10997},
+								pos: position{line: 486, col: 25, offset: 15279},
 								expr: &choiceExpr{
-									pos: position{line: 361, col: 26, offset: 10998},
+									pos: position{line: 486, col: 26, offset: 15280},
 									alternatives: []any{
 										&litMatcher{
-											pos:        position{line: 361, col: 26, offset: 10998},
+											pos:        position{line: 486, col: 26, offset: 15280},
 											val:        "\\\"",
 											ignoreCase: false,
 											want:       "\"\\\\\\\"\"",
 										},
 										&litMatcher{
-											pos:        position{line: 361, col: 33, offset: 11005},
+											pos:        position{line: 486, col: 33, offset: 15287},
 											val:        "\\\\",
 											ignoreCase: false,
 											want:       "\"\\\\\\\\\"",
 										},
 										&charClassMatcher{
-											pos:        position{line: 361, col: 40, offset: 11012},
+											pos:        position{line: 486, col: 40, offset: 15294},
 											val:        "[^\"\\r\\n]",
 											chars:      []rune{'"', '\r', '\n'},
 											ignoreCase: false,
@@ -2694,7 +3597,7 @@ var g = &grammar{
 								},
 							},
 							&litMatcher{
-								pos:        position{line: 361, col: 51, offset: 11023},
+								pos:        position{line: 486, col: 51, offset: 15305},
 								val:        "\"",
 								ignoreCase: false,
 								want:       "\"\\\"\"",
@@ -2702,18 +3605,18 @@ var g = &grammar{
 						},
 					},
 					&seqExpr{
-						pos: position{line: 362, col: 21, offset: 11049},
+						pos: position{line: 487, col: 21, offset: 15331},
 						exprs: []any{
 							&litMatcher{
-								pos:        position{line: 362, col: 21, offset: 11049},
+								pos:        position{line: 487, col: 21, offset: 15331},
 								val:        "`",
 								ignoreCase: false,
 								want:       "\"`\"",
 							},
 							&zeroOrMoreExpr{
-								pos: position{line: 362, col: 25, offset: 11053},
+								pos: position{line: 487, col: 25, offset: 15335},
 								expr: &charClassMatcher{
-									pos:        position{line: 362, col: 25, offset: 11053},
+									pos:        position{line: 487, col: 25, offset: 15335},
 									val:        "[^`]",
 									chars:      []rune{'`'},
 									ignoreCase: false,
@@ -2721,7 +3624,7 @@ var g = &grammar{
 								},
 							},
 							&litMatcher{
-								pos:        position{line: 362, col: 31, offset: 11059},
+								pos:        position{line: 487, col: 31, offset: 15341},
 								val:        "`",
 								ignoreCase: false,
 								want:       "\"`\"",
@@ -2729,33 +3632,33 @@ var g = &grammar{
 						},
 					},
 					&seqExpr{
-						pos: position{line: 363, col: 21, offset: 11085},
+						pos: position{line: 488, col: 21, offset: 15367},
 						exprs: []any{
 							&litMatcher{
-								pos:        position{line: 363, col: 21, offset: 11085},
+								pos:        position{line: 488, col: 21, offset: 15367},
 								val:        "'",
 								ignoreCase: false,
 								want:       "\"'\"",
 							},
 							&choiceExpr{
-								pos: position{line: 363, col: 27, offset: 11091},
+								pos: position{line: 488, col: 27, offset: 15373},
 								alternatives: []any{
 									&litMatcher{
-										pos:        position{line: 363, col: 27, offset: 11091},
+										pos:        position{line: 488, col: 27, offset: 15373},
 										val:        "\\'",
 										ignoreCase: false,
 										want:       "\"\\\\'\"",
 									},
 									&litMatcher{
-										pos:        position{line: 363, col: 34, offset: 11098},
+										pos:        position{line: 488, col: 34, offset: 15380},
 										val:        "\\\\",
 										ignoreCase: false,
 										want:       "\"\\\\\\\\\"",
 									},
 									&oneOrMoreExpr{
-										pos: position{line: 363, col: 41, offset: 11105},
+										pos: position{line: 488, col: 41, offset: 15387},
 										expr: &charClassMatcher{
-											pos:        position{line: 363, col: 41, offset: 11105},
+											pos:        position{line: 488, col: 41, offset: 15387},
 											val:        "[^']",
 											chars:      []rune{'\''},
 											ignoreCase: false,
@@ -2765,7 +3668,7 @@ var g = &grammar{
 								},
 							},
 							&litMatcher{
-								pos:        position{line: 363, col: 48, offset: 11112},
+								pos:        position{line: 488, col: 48, offset: 15394},
 								val:        "'",
 								ignoreCase: false,
 								want:       "\"'\"",
@@ -2777,22 +3680,22 @@ var g = &grammar{
 		},
 		{
 			name: "__",
-			pos:  position{line: 365, col: 1, offset: 11118},
+			pos:  position{line: 490, col: 1, offset: 15400},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 365, col: 6, offset: 11125},
+				pos: position{line: 490, col: 6, offset: 15407},
 				expr: &choiceExpr{
-					pos: position{line: 365, col: 8, offset: 11127},
+					pos: position{line: 490, col: 8, offset: 15409},
 					alternatives: []any{
 						&ruleRefExpr{
-							pos:  position{line: 365, col: 8, offset: 11127},
+							pos:  position{line: 490, col: 8, offset: 15409},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 365, col: 21, offset: 11140},
+							pos:  position{line: 490, col: 21, offset: 15422},
 							name: "EOL",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 365, col: 27, offset: 11146},
+							pos:  position{line: 490, col: 27, offset: 15428},
 							name: "Comment",
 						},
 					},
@@ -2801,18 +3704,18 @@ var g = &grammar{
 		},
 		{
 			name: "_",
-			pos:  position{line: 366, col: 1, offset: 11157},
+			pos:  position{line: 491, col: 1, offset: 15439},
 			expr: &zeroOrMoreExpr{
-				pos: position{line: 366, col: 5, offset: 11163},
+				pos: position{line: 491, col: 5, offset: 15445},
 				expr: &choiceExpr{
-					pos: position{line: 366, col: 7, offset: 11165},
+					pos: position{line: 491, col: 7, offset: 15447},
 					alternatives: []any{
 						&ruleRefExpr{
-							pos:  position{line: 366, col: 7, offset: 11165},
+							pos:  position{line: 491, col: 7, offset: 15447},
 							name: "Whitespace",
 						},
 						&ruleRefExpr{
-							pos:  position{line: 366, col: 20, offset: 11178},
+							pos:  position{line: 491, col: 20, offset: 15460},
 							name: "MultiLineCommentNoLineTerminator",
 						},
 					},
@@ -2821,9 +3724,9 @@ var g = &grammar{
 		},
 		{
 			name: "Whitespace",
-			pos:  position{line: 368, col: 1, offset: 11215},
+			pos:  position{line: 493, col: 1, offset: 15497},
 			expr: &charClassMatcher{
-				pos:        position{line: 368, col: 14, offset: 11230},
+				pos:        position{line: 493, col: 14, offset: 15512},
 				val:        "[ \\t\\r]",
 				chars:      []rune{' ', '\t', '\r'},
 				ignoreCase: false,
@@ -2832,9 +3735,9 @@ var g = &grammar{
 		},
 		{
 			name: "EOL",
-			pos:  position{line: 369, col: 1, offset: 11238},
+			pos:  position{line: 494, col: 1, offset: 15520},
 			expr: &litMatcher{
-				pos:        position{line: 369, col: 7, offset: 11246},
+				pos:        position{line: 494, col: 7, offset: 15528},
 				val:        "\n",
 				ignoreCase: false,
 				want:       "\"\\n\"",
@@ -2842,19 +3745,19 @@ var g = &grammar{
 		},
 		{
 			name: "EOS",
-			pos:  position{line: 370, col: 1, offset: 11251},
+			pos:  position{line: 495, col: 1, offset: 15533},
 			expr: &choiceExpr{
-				pos: position{line: 370, col: 7, offset: 11259},
+				pos: position{line: 495, col: 7, offset: 15541},
 				alternatives: []any{
 					&seqExpr{
-						pos: position{line: 370, col: 7, offset: 11259},
+						pos: position{line: 495, col: 7, offset: 15541},
 						exprs: []any{
 							&ruleRefExpr{
-								pos:  position{line: 370, col: 7, offset: 11259},
+								pos:  position{line: 495, col: 7, offset: 15541},
 								name: "__",
 							},
 							&litMatcher{
-								pos:        position{line: 370, col: 10, offset: 11262},
+								pos:        position{line: 495, col: 10, offset: 15544},
 								val:        ";",
 								ignoreCase: false,
 								want:       "\";\"",
@@ -2862,34 +3765,34 @@ var g = &grammar{
 						},
 					},
 					&seqExpr{
-						pos: position{line: 370, col: 16, offset: 11268},
+						pos: position{line: 495, col: 16, offset: 15550},
 						exprs: []any{
 							&ruleRefExpr{
-								pos:  position{line: 370, col: 16, offset: 11268},
+								pos:  position{line: 495, col: 16, offset: 15550},
 								name: "_",
 							},
 							&zeroOrOneExpr{
-								pos: position{line: 370, col: 18, offset: 11270},
+								pos: position{line: 495, col: 18, offset: 15552},
 								expr: &ruleRefExpr{
-									pos:  position{line: 370, col: 18, offset: 11270},
+									pos:  position{line: 495, col: 18, offset: 15552},
 									name: "SingleLineComment",
 								},
 							},
 							&ruleRefExpr{
-								pos:  position{line: 370, col: 37, offset: 11289},
+								pos:  position{line: 495, col: 37, offset: 15571},
 								name: "EOL",
 							},
 						},
 					},
 					&seqExpr{
-						pos: position{line: 370, col: 43, offset: 11295},
+						pos: position{line: 495, col: 43, offset: 15577},
 						exprs: []any{
 							&ruleRefExpr{
-								pos:  position{line: 370, col: 43, offset: 11295},
+								pos:  position{line: 495, col: 43, offset: 15577},
 								name: "__",
 							},
 							&ruleRefExpr{
-								pos:  position{line: 370, col: 46, offset: 11298},
+								pos:  position{line: 495, col: 46, offset: 15580},
 								name: "EOF",
 							},
 						},
@@ -2899,26 +3802,30 @@ var g = &grammar{
 		},
 		{
 			name: "EOF",
-			pos:  position{line: 372, col: 1, offset: 11303},
+			pos:  position{line: 497, col: 1, offset: 15585},
 			expr: &notExpr{
-				pos: position{line: 372, col: 7, offset: 11311},
+				pos: position{line: 497, col: 7, offset: 15593},
 				expr: &anyMatcher{
-					line: 372, col: 8, offset: 11312,
+					line: 497, col: 8, offset: 15594,
 				},
 			},
 		},
 	},
 }
 
-func (c *current) onGrammar1(initializer, rules any) (any, error) {
+func (c *current) onGrammar1(initializer, options, rules any) (any, error) {
 	pos := c.astPos()
 
-	// create the grammar, assign its initializer
+	// create the grammar, assign its initializer and options
 	g := ast.NewGrammar(pos)
 	initSlice := toAnySlice(initializer)
 	if len(initSlice) > 0 {
 		g.Init = initSlice[0].(*ast.CodeBlock)
 	}
+	optionsSlice := toAnySlice(options)
+	if len(optionsSlice) > 0 {
+		g.Options = optionsSlice[0].([]*ast.Option)
+	}
 
 	rulesSlice := toAnySlice(rules)
 	g.Rules = make([]*ast.Rule, len(rulesSlice))
@@ -2932,7 +3839,7 @@ func (c *current) onGrammar1(initializer, rules any) (any, error) {
 func (p *parser) callonGrammar1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onGrammar1(stack["initializer"], stack["rules"])
+	return p.cur.onGrammar1(stack["initializer"], stack["options"], stack["rules"])
 }
 
 func (c *current) onInitializer1(code any) (any, error) {
@@ -2945,10 +3852,78 @@ func (p *parser) callonInitializer1() (any, error) {
 	return p.cur.onInitializer1(stack["code"])
 }
 
-func (c *current) onRule1(name, display, expr any) (any, error) {
+func (c *current) onOptions1(entries any) (any, error) {
+	entriesSlice := toAnySlice(entries)
+	opts := make([]*ast.Option, len(entriesSlice))
+	for i, duo := range entriesSlice {
+		opts[i] = duo.([]any)[0].(*ast.Option)
+	}
+	return opts, nil
+}
+
+func (p *parser) callonOptions1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOptions1(stack["entries"])
+}
+
+func (c *current) onOption1(name, first, rest any) (any, error) {
+	opt := ast.NewOption(c.astPos(), name.(*ast.Identifier))
+	opt.Values = []string{first.(string)}
+	restSlice := toAnySlice(rest)
+	for _, sl := range restSlice {
+		opt.Values = append(opt.Values, sl.([]any)[3].(string))
+	}
+	return opt, nil
+}
+
+func (p *parser) callonOption1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOption1(stack["name"], stack["first"], stack["rest"])
+}
+
+func (c *current) onOptionName1() (any, error) {
+	return ast.NewIdentifier(c.astPos(), string(c.text)), nil
+}
+
+func (p *parser) callonOptionName1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOptionName1()
+}
+
+func (c *current) onOptionValue2(lit any) (any, error) {
+	s, err := strconv.Unquote(lit.(*ast.StringLit).Val)
+	if err != nil {
+		return "", errors.New("invalid option value")
+	}
+	return s, nil
+}
+
+func (p *parser) callonOptionValue2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOptionValue2(stack["lit"])
+}
+
+func (c *current) onOptionValue5() (any, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonOptionValue5() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOptionValue5()
+}
+
+func (c *current) onRule1(annotations, name, display, expr any) (any, error) {
 	pos := c.astPos()
 
 	rule := ast.NewRule(pos, name.(*ast.Identifier))
+	for _, duo := range toAnySlice(annotations) {
+		rule.Annotations = append(rule.Annotations, duo.([]any)[0].(*ast.Identifier))
+	}
 	displaySlice := toAnySlice(display)
 	if len(displaySlice) > 0 {
 		rule.DisplayName = displaySlice[0].(*ast.StringLit)
@@ -2961,7 +3936,21 @@ func (c *current) onRule1(name, display, expr any) (any, error) {
 func (p *parser) callonRule1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onRule1(stack["name"], stack["display"], stack["expr"])
+	return p.cur.onRule1(stack["annotations"], stack["name"], stack["display"], stack["expr"])
+}
+
+func (c *current) onRuleAnnotation1(name any) (any, error) {
+	ident := name.(*ast.Identifier)
+	if !builder.RuleAnnotations[ident.Val] {
+		return ident, errors.New("unknown rule annotation")
+	}
+	return ident, nil
+}
+
+func (p *parser) callonRuleAnnotation1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRuleAnnotation1(stack["name"])
 }
 
 func (c *current) onRecoveryExpr1(expr, recoverExprs any) (any, error) {
@@ -3158,7 +4147,26 @@ func (p *parser) callonPrefixedOp1() (any, error) {
 	return p.cur.onPrefixedOp1()
 }
 
-func (c *current) onSuffixedExpr2(expr, op any) (any, error) {
+func (c *current) onSuffixedExpr2(expr, op, sep any) (any, error) {
+	pos := c.astPos()
+	opStr := op.(string)
+	list := ast.NewSeparatedExpr(pos)
+	list.Expr = expr.(ast.Expression)
+	list.Sep = sep.(ast.Expression)
+	if strings.HasPrefix(opStr, "++") {
+		list.Min = 1
+	}
+	list.AllowTrailing = strings.HasSuffix(opStr, "?")
+	return list, nil
+}
+
+func (p *parser) callonSuffixedExpr2() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSuffixedExpr2(stack["expr"], stack["op"], stack["sep"])
+}
+
+func (c *current) onSuffixedExpr12(expr, op any) (any, error) {
 	pos := c.astPos()
 	opStr := op.(string)
 	switch opStr {
@@ -3179,10 +4187,25 @@ func (c *current) onSuffixedExpr2(expr, op any) (any, error) {
 	}
 }
 
-func (p *parser) callonSuffixedExpr2() (any, error) {
+func (p *parser) callonSuffixedExpr12() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onSuffixedExpr2(stack["expr"], stack["op"])
+	return p.cur.onSuffixedExpr12(stack["expr"], stack["op"])
+}
+
+func (c *current) onSuffixedExpr18(expr, bounds any) (any, error) {
+	pos := c.astPos()
+	minMax := bounds.([]int)
+	rep := ast.NewRepeatExpr(pos)
+	rep.Expr = expr.(ast.Expression)
+	rep.Min, rep.Max = minMax[0], minMax[1]
+	return rep, nil
+}
+
+func (p *parser) callonSuffixedExpr18() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSuffixedExpr18(stack["expr"], stack["bounds"])
 }
 
 func (c *current) onSuffixedOp1() (any, error) {
@@ -3195,14 +4218,111 @@ func (p *parser) callonSuffixedOp1() (any, error) {
 	return p.cur.onSuffixedOp1()
 }
 
-func (c *current) onPrimaryExpr7(expr any) (any, error) {
+func (c *current) onSeparatorOp1() (any, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonSeparatorOp1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onSeparatorOp1()
+}
+
+func (c *current) onRepeatBounds1(min, max any) (any, error) {
+	minVal := min.(int)
+	maxSlice := toAnySlice(max)
+	if len(maxSlice) == 0 {
+		return []int{minVal, minVal}, nil
+	}
+	if maxSlice[2] == nil {
+		return []int{minVal, -1}, nil
+	}
+	maxVal := maxSlice[2].(int)
+	if maxVal < minVal {
+		return []int{minVal, minVal}, errors.New("invalid repetition bounds: min greater than max")
+	}
+	return []int{minVal, maxVal}, nil
+}
+
+func (p *parser) callonRepeatBounds1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onRepeatBounds1(stack["min"], stack["max"])
+}
+
+func (c *current) onDecimalInt1() (any, error) {
+	return strconv.Atoi(string(c.text))
+}
+
+func (p *parser) callonDecimalInt1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onDecimalInt1()
+}
+
+func (c *current) onPrimaryExpr9(expr any) (any, error) {
 	return expr, nil
 }
 
-func (p *parser) callonPrimaryExpr7() (any, error) {
+func (p *parser) callonPrimaryExpr9() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrimaryExpr9(stack["expr"])
+}
+
+func (c *current) onPrecedenceExpr1(atom, levels any) (any, error) {
+	prec := ast.NewPrecedenceExpr(c.astPos())
+	prec.Atom = atom.(ast.Expression)
+	for _, duo := range toAnySlice(levels) {
+		prec.Levels = append(prec.Levels, duo.([]any)[0].(*ast.PrecedenceLevel))
+	}
+	return prec, nil
+}
+
+func (p *parser) callonPrecedenceExpr1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrecedenceExpr1(stack["atom"], stack["levels"])
+}
+
+func (c *current) onPrecedenceLevel1(kind, first, rest any) (any, error) {
+	level := ast.NewPrecedenceLevel(c.astPos(), ast.OperatorKind(kind.(string)))
+	level.Operators = []*ast.Operator{first.(*ast.Operator)}
+	for _, sl := range toAnySlice(rest) {
+		level.Operators = append(level.Operators, sl.([]any)[3].(*ast.Operator))
+	}
+	return level, nil
+}
+
+func (p *parser) callonPrecedenceLevel1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onPrecedenceLevel1(stack["kind"], stack["first"], stack["rest"])
+}
+
+func (c *current) onOperatorKind1() (any, error) {
+	return string(c.text), nil
+}
+
+func (p *parser) callonOperatorKind1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onOperatorKind1()
+}
+
+func (c *current) onOperator1(expr, code any) (any, error) {
+	op := ast.NewOperator(c.astPos())
+	op.Expr = expr.(ast.Expression)
+	if code != nil {
+		op.Code = code.([]any)[1].(*ast.CodeBlock)
+	}
+	return op, nil
+}
+
+func (p *parser) callonOperator1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onPrimaryExpr7(stack["expr"])
+	return p.cur.onOperator1(stack["expr"], stack["code"])
 }
 
 func (c *current) onRuleRefExpr1(name any) (any, error) {
@@ -3217,6 +4337,18 @@ func (p *parser) callonRuleRefExpr1() (any, error) {
 	return p.cur.onRuleRefExpr1(stack["name"])
 }
 
+func (c *current) onBackRefExpr1(label any) (any, error) {
+	ref := ast.NewBackRefExpr(c.astPos())
+	ref.Label = label.(*ast.Identifier)
+	return ref, nil
+}
+
+func (p *parser) callonBackRefExpr1() (any, error) {
+	stack := p.vstack[len(p.vstack)-1]
+	_ = stack
+	return p.cur.onBackRefExpr1(stack["label"])
+}
+
 func (c *current) onSemanticPredExpr1(op, code any) (any, error) {
 	switch op.(string) {
 	case "&":
@@ -3279,7 +4411,7 @@ func (p *parser) callonIdentifierName1() (any, error) {
 	return p.cur.onIdentifierName1()
 }
 
-func (c *current) onLitMatcher1(lit, ignore any) (any, error) {
+func (c *current) onLitMatcher1(lit, ignore, keyword any) (any, error) {
 	rawStr := lit.(*ast.StringLit).Val
 	s, err := strconv.Unquote(rawStr)
 	if err != nil {
@@ -3290,13 +4422,14 @@ func (c *current) onLitMatcher1(lit, ignore any) (any, error) {
 	}
 	m := ast.NewLitMatcher(c.astPos(), s)
 	m.IgnoreCase = ignore != nil
+	m.Keyword = keyword != nil
 	return m, nil
 }
 
 func (p *parser) callonLitMatcher1() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onLitMatcher1(stack["lit"], stack["ignore"])
+	return p.cur.onLitMatcher1(stack["lit"], stack["ignore"], stack["keyword"])
 }
 
 func (c *current) onStringLiteral2() (any, error) {
@@ -3413,14 +4546,14 @@ func (p *parser) callonCharClassMatcher2() (any, error) {
 	return p.cur.onCharClassMatcher2()
 }
 
-func (c *current) onCharClassMatcher15() (any, error) {
+func (c *current) onCharClassMatcher14() (any, error) {
 	return ast.NewCharClassMatcher(c.astPos(), "[]"), errors.New("character class not terminated")
 }
 
-func (p *parser) callonCharClassMatcher15() (any, error) {
+func (p *parser) callonCharClassMatcher14() (any, error) {
 	stack := p.vstack[len(p.vstack)-1]
 	_ = stack
-	return p.cur.onCharClassMatcher15()
+	return p.cur.onCharClassMatcher14()
 }
 
 func (c *current) onCharClassEscape5() (any, error) {
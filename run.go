@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/oskoi/pigeon/interp"
+)
+
+// runMain implements the run command, it runs the grammar on an input
+// without generating its parser.
+func runMain(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" run", flag.ExitOnError)
+
+	var (
+		cacheFlag      = fs.Bool("cache", false, "memoize the results of all the rules")
+		entrypointFlag = fs.String("entrypoint", "", "rule to run, defaults to the first rule")
+		shortHelpFlag  = fs.Bool("h", false, "show help page")
+		longHelpFlag   = fs.Bool("help", false, "show help page")
+		jsonFlag       = fs.Bool("json", false, "print the result as JSON")
+	)
+
+	usage := func() {
+		fmt.Printf(runUsagePage, os.Args[0])
+	}
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, "args parse error:\n", err)
+		exit(6)
+	}
+
+	if *shortHelpFlag || *longHelpFlag {
+		usage()
+		exit(0)
+	}
+
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fmt.Fprintf(os.Stderr, "expected one or two arguments, got %q\n", strings.Join(fs.Args(), " "))
+		usage()
+		exit(1)
+	}
+
+	_, grammar := parseGrammar(fs.Arg(0))
+	in, err := interp.New(grammar)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "grammar error:\n", err)
+		exit(3)
+	}
+	in.Memoize = *cacheFlag
+
+	nm, rc := input(fs.Arg(1))
+	src, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "read error:\n", err)
+		exit(2)
+	}
+
+	res, err := in.Run(*entrypointFlag, src)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(1)
+	}
+
+	if *jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		if err := enc.Encode(res); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:\n", err)
+			exit(7)
+		}
+	} else if _, err := os.Stdout.WriteString(resultText(nm, src, res)); err != nil {
+		fmt.Fprintln(os.Stderr, "write error:\n", err)
+		exit(7)
+	}
+	if res.Match == nil {
+		exit(1)
+	}
+}
+
+// resultText returns the textual representation of the result of running
+// a grammar on the input src read from nm: whether it matched, the
+// farthest failure if the input is not fully matched and the parse tree.
+func resultText(nm string, src []byte, res *interp.Result) string {
+	var b strings.Builder
+	switch {
+	case res.Match == nil:
+		b.WriteString("no match\n")
+	case res.Match.End.Offset < len(src):
+		fmt.Fprintf(&b, "match up to %s\n", res.Match.End)
+	default:
+		b.WriteString("match\n")
+	}
+	if res.Match == nil || res.Match.End.Offset < len(src) {
+		fmt.Fprintf(&b, "%s:%s\n", nm, res.Failure.Error())
+	}
+	if res.Match != nil {
+		b.WriteByte('\n')
+		writeTree(&b, src, res.Match, 0)
+	}
+	return b.String()
+}
+
+// maxNodeText is the maximum number of runes of the text of the nodes
+// printed in the parse tree.
+const maxNodeText = 40
+
+// writeTree writes the node as RULE START-END "TEXT", indented by depth,
+// followed by its children.
+func writeTree(b *strings.Builder, src []byte, node *interp.Node, depth int) {
+	text := string(src[node.Start.Offset:node.End.Offset])
+	if utf8.RuneCountInString(text) > maxNodeText {
+		text = string([]rune(text)[:maxNodeText]) + "..."
+	}
+	fmt.Fprintf(b, "%s%s %d:%d-%d:%d %s\n", strings.Repeat("\t", depth), node.Rule,
+		node.Start.Line, node.Start.Col, node.End.Line, node.End.Col, strconv.Quote(text))
+	for _, child := range node.Children {
+		writeTree(b, src, child, depth+1)
+	}
+}
+
+var runUsagePage = `usage: %s run [options] GRAMMAR_FILE [INPUT_FILE]
+
+Run runs a PEG grammar on an input without generating its parser, to try
+out a change of the grammar. The input is read from stdin if INPUT_FILE
+is not specified. The code blocks are not run: the actions have no value
+and the code predicates always succeed, so the grammars that depend on
+them to reject an input may match more than their parser.
+
+Run prints whether the input matches, the farthest position at which the
+grammar failed with the terminals expected there if the input does not
+match or is not fully matched, and the parse tree, a line per match of a
+rule as RULE START-END "TEXT", indented by the depth of the rule. The
+exit code is 1 if the input does not match.
+
+	-cache
+		memoize the results of all the rules, not only the ones
+		annotated with @memo.
+	-entrypoint RULE
+		rule to run, the first rule of the grammar by default.
+	-h -help
+		display this help message.
+	-json
+		print the result as a JSON object with the "match" member, the
+		parse tree, and the "failure" member, the farthest failure.
+`
@@ -0,0 +1,586 @@
+// Package sample generates random inputs from a PEG grammar: samples that
+// the grammar matches, and near misses, mutations of the samples that it
+// rejects. They seed the corpora of the fuzz tests and help comparing the
+// parsers of a language.
+//
+// The generator walks the grammar and ignores the lookahead expressions,
+// the code predicates and the boundaries of the keywords, the candidates
+// are then run by the interpreter of the grammar and rejected if it does
+// not match them entirely.
+package sample
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/interp"
+)
+
+// ErrNoSample is returned if none of the candidates is accepted, or
+// rejected for a near miss.
+var ErrNoSample = errors.New("no sample found")
+
+// infinite is the cost of the rules that only match infinite inputs.
+const infinite = math.MaxInt32
+
+// Generator generates the samples of a grammar.
+type Generator struct {
+	// MaxDepth is the depth of the rule references beyond which the
+	// shortest expansion of the rules is generated.
+	MaxDepth int
+	// MaxRepeat is the maximum count of the repetitions without upper
+	// bound.
+	MaxRepeat int
+	// MaxAttempts is the number of candidates tried for a sample or a near
+	// miss before returning ErrNoSample.
+	MaxAttempts int
+
+	in    *interp.Interpreter
+	rules map[string]*ast.Rule
+	first *ast.Rule
+	rand  *rand.Rand
+
+	// cost is the minimum depth of the rule references of a match of the
+	// rules
+	cost map[*ast.Rule]int
+	// alphabet is the set of runes of the literals and the character
+	// classes of the grammar
+	alphabet []rune
+	// vars are the text generated for the labels of the back-references,
+	// for each rule being generated
+	vars []map[string]string
+}
+
+// New returns a generator of the samples of the grammar, its random source
+// is seeded with seed. The grammar is resolved as for the generation of
+// its parser and is modified.
+func New(grammar *ast.Grammar, seed int64) (*Generator, error) {
+	in, err := interp.New(grammar)
+	if err != nil {
+		return nil, err
+	}
+	if len(grammar.Rules) == 0 {
+		return nil, errors.New("grammar has no rule")
+	}
+
+	g := &Generator{
+		MaxDepth:    6,
+		MaxRepeat:   3,
+		MaxAttempts: 100,
+		in:          in,
+		rules:       make(map[string]*ast.Rule, len(grammar.Rules)),
+		first:       grammar.Rules[0],
+		rand:        rand.New(rand.NewSource(seed)),
+	}
+	for _, rule := range grammar.Rules {
+		if _, ok := g.rules[rule.Name.Val]; !ok {
+			g.rules[rule.Name.Val] = rule
+		}
+	}
+	g.setCosts(grammar)
+	g.setAlphabet(grammar)
+	return g, nil
+}
+
+// Sample returns a random input matched entirely by the rule entrypoint,
+// the first rule of the grammar if empty.
+func (g *Generator) Sample(entrypoint string) ([]byte, error) {
+	rule, err := g.rule(entrypoint)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < g.MaxAttempts; i++ {
+		var buf []byte
+		g.vars = g.vars[:0]
+		if !g.ruleRef(&buf, rule, 0) {
+			continue
+		}
+		ok, err := g.accepts(rule.Name.Val, buf)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return buf, nil
+		}
+	}
+	return nil, ErrNoSample
+}
+
+// NearMiss returns a random mutation of sample that the rule entrypoint,
+// the first rule of the grammar if empty, does not match entirely: a rune
+// is deleted, inserted, replaced or swapped with the next one, or the
+// sample is truncated or a part of it is repeated.
+func (g *Generator) NearMiss(entrypoint string, sample []byte) ([]byte, error) {
+	rule, err := g.rule(entrypoint)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < g.MaxAttempts; i++ {
+		buf := g.mutate([]rune(string(sample)))
+		ok, err := g.accepts(rule.Name.Val, buf)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return buf, nil
+		}
+	}
+	return nil, ErrNoSample
+}
+
+func (g *Generator) rule(entrypoint string) (*ast.Rule, error) {
+	if entrypoint == "" {
+		return g.first, nil
+	}
+	rule, ok := g.rules[entrypoint]
+	if !ok {
+		return nil, fmt.Errorf("invalid entrypoint %q", entrypoint)
+	}
+	return rule, nil
+}
+
+// accepts returns true if the rule matches the whole input.
+func (g *Generator) accepts(rule string, input []byte) (bool, error) {
+	res, err := g.in.Run(rule, input)
+	if err != nil {
+		return false, err
+	}
+	return res.Match != nil && res.Match.End.Offset == len(input), nil
+}
+
+func (g *Generator) mutate(rs []rune) []byte {
+	i := 0
+	if len(rs) > 0 {
+		i = g.rand.Intn(len(rs))
+	}
+	switch op := g.rand.Intn(6); {
+	case len(rs) == 0 || op == 0:
+		// insert
+		rs = append(rs[:i], append([]rune{g.randRune()}, rs[i:]...)...)
+	case op == 1:
+		// delete
+		rs = append(rs[:i], rs[i+1:]...)
+	case op == 2:
+		// replace
+		rs[i] = g.randRune()
+	case op == 3:
+		// swap
+		if i+1 < len(rs) {
+			rs[i], rs[i+1] = rs[i+1], rs[i]
+		}
+	case op == 4:
+		// truncate
+		rs = rs[:i]
+	default:
+		// repeat
+		j := i + 1 + g.rand.Intn(len(rs)-i)
+		rs = append(rs[:j], append(append([]rune(nil), rs[i:j]...), rs[j:]...)...)
+	}
+	return []byte(string(rs))
+}
+
+// randRune returns a rune of the alphabet of the grammar or a printable
+// ASCII character.
+func (g *Generator) randRune() rune {
+	if len(g.alphabet) > 0 && g.rand.Intn(2) == 0 {
+		return g.alphabet[g.rand.Intn(len(g.alphabet))]
+	}
+	return rune(' ' + g.rand.Intn('~'-' '+1))
+}
+
+// ruleRef generates a match of the rule referenced at depth.
+func (g *Generator) ruleRef(buf *[]byte, rule *ast.Rule, depth int) bool {
+	if depth > g.MaxDepth && g.cost[rule] == infinite {
+		return false
+	}
+	g.vars = append(g.vars, nil)
+	ok := g.expr(buf, rule.Expr, depth+1)
+	g.vars = g.vars[:len(g.vars)-1]
+	return ok
+}
+
+// short returns true if the shortest expansion must be generated at
+// depth.
+func (g *Generator) short(depth int) bool {
+	return depth > g.MaxDepth
+}
+
+// count returns a random count of repetitions between min and max, or
+// without upper bound if max is negative.
+func (g *Generator) count(min, max int, depth int) int {
+	if g.short(depth) {
+		return min
+	}
+	if max < 0 {
+		max = min + g.MaxRepeat
+	}
+	return min + g.rand.Intn(max-min+1)
+}
+
+// expr appends a match of the expression to buf, it returns false if none
+// could be generated.
+func (g *Generator) expr(buf *[]byte, expr ast.Expression, depth int) bool {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		return g.expr(buf, expr.Expr, depth)
+	case *ast.AndCodeExpr, *ast.AndExpr, *ast.CodeExpr, *ast.NotCodeExpr, *ast.NotExpr, *ast.ThrowExpr:
+		return true
+	case *ast.AnyMatcher:
+		*buf = utf8.AppendRune(*buf, g.randRune())
+		return true
+	case *ast.BackRefExpr:
+		text, ok := g.vars[len(g.vars)-1][expr.Label.Val]
+		*buf = append(*buf, text...)
+		return ok
+	case *ast.CharClassMatcher:
+		rn, ok := g.charClass(expr)
+		*buf = utf8.AppendRune(*buf, rn)
+		return ok
+	case *ast.ChoiceExpr:
+		return g.expr(buf, g.choice(expr.Alternatives, depth), depth)
+	case *ast.LabeledExpr:
+		start := len(*buf)
+		if !g.expr(buf, expr.Expr, depth) {
+			return false
+		}
+		if expr.TextCapture && expr.BackRef {
+			vars := g.vars[len(g.vars)-1]
+			if vars == nil {
+				vars = make(map[string]string)
+				g.vars[len(g.vars)-1] = vars
+			}
+			vars[expr.Label.Val] = string((*buf)[start:])
+		}
+		return true
+	case *ast.LitMatcher:
+		for _, rn := range expr.Val {
+			if expr.IgnoreCase && g.rand.Intn(2) == 0 {
+				rn = unicode.SimpleFold(rn)
+			}
+			*buf = utf8.AppendRune(*buf, rn)
+		}
+		return true
+	case *ast.OneOrMoreExpr:
+		return g.repeat(buf, expr.Expr, g.count(1, -1, depth), depth)
+	case *ast.PrecedenceExpr:
+		return g.precedence(buf, expr, depth)
+	case *ast.RecoveryExpr:
+		return g.expr(buf, expr.Expr, depth)
+	case *ast.RepeatExpr:
+		return g.repeat(buf, expr.Expr, g.count(expr.Min, expr.Max, depth), depth)
+	case *ast.RuleRefExpr:
+		return g.ruleRef(buf, g.rules[expr.Name.Val], depth)
+	case *ast.SeparatedExpr:
+		n := g.count(expr.Min, -1, depth)
+		for i := 0; i < n; i++ {
+			if i > 0 && !g.expr(buf, expr.Sep, depth) {
+				return false
+			}
+			if !g.expr(buf, expr.Expr, depth) {
+				return false
+			}
+		}
+		if n > 0 && expr.AllowTrailing && !g.short(depth) && g.rand.Intn(2) == 0 {
+			return g.expr(buf, expr.Sep, depth)
+		}
+		return true
+	case *ast.SeqExpr:
+		for _, e := range expr.Exprs {
+			if !g.expr(buf, e, depth) {
+				return false
+			}
+		}
+		return true
+	case *ast.TriviaExpr:
+		return g.expr(buf, expr.Trivia, depth) && g.expr(buf, expr.Expr, depth)
+	case *ast.ZeroOrMoreExpr:
+		return g.repeat(buf, expr.Expr, g.count(0, -1, depth), depth)
+	case *ast.ZeroOrOneExpr:
+		return g.repeat(buf, expr.Expr, g.count(0, 1, depth), depth)
+	default:
+		panic(fmt.Sprintf("unknown expression type %T", expr))
+	}
+}
+
+func (g *Generator) repeat(buf *[]byte, expr ast.Expression, n, depth int) bool {
+	for i := 0; i < n; i++ {
+		if !g.expr(buf, expr, depth) {
+			return false
+		}
+	}
+	return true
+}
+
+// choice returns a random alternative, or one of the alternatives of
+// minimum cost if the shortest expansion must be generated.
+func (g *Generator) choice(alts []ast.Expression, depth int) ast.Expression {
+	if !g.short(depth) {
+		return alts[g.rand.Intn(len(alts))]
+	}
+	var cheapest []ast.Expression
+	min := infinite
+	for _, alt := range alts {
+		switch cost := g.exprCost(alt); {
+		case cost < min:
+			min = cost
+			cheapest = append(cheapest[:0], alt)
+		case cost == min:
+			cheapest = append(cheapest, alt)
+		}
+	}
+	return cheapest[g.rand.Intn(len(cheapest))]
+}
+
+// precedence generates an operand, optionally preceded by a prefix
+// operator, followed by random postfix operators and infix operators with
+// their right operand.
+func (g *Generator) precedence(buf *[]byte, expr *ast.PrecedenceExpr, depth int) bool {
+	ops := make(map[ast.OperatorKind][]*ast.Operator)
+	for _, level := range expr.Levels {
+		ops[level.Kind] = append(ops[level.Kind], level.Operators...)
+	}
+	infix := append(append([]*ast.Operator(nil), ops[ast.LeftAssoc]...), ops[ast.RightAssoc]...)
+
+	operand := func() bool {
+		if prefix := ops[ast.Prefix]; len(prefix) > 0 && !g.short(depth) && g.rand.Intn(3) == 0 {
+			if !g.expr(buf, prefix[g.rand.Intn(len(prefix))].Expr, depth) {
+				return false
+			}
+		}
+		return g.expr(buf, expr.Atom, depth)
+	}
+	if !operand() {
+		return false
+	}
+	for i := g.count(0, -1, depth); i > 0; i-- {
+		if postfix := ops[ast.Postfix]; len(postfix) > 0 && (len(infix) == 0 || g.rand.Intn(3) == 0) {
+			if !g.expr(buf, postfix[g.rand.Intn(len(postfix))].Expr, depth) {
+				return false
+			}
+			continue
+		}
+		if len(infix) == 0 {
+			break
+		}
+		if !g.expr(buf, infix[g.rand.Intn(len(infix))].Expr, depth) || !operand() {
+			return false
+		}
+	}
+	return true
+}
+
+// charClass returns a random rune of the character class, or a random
+// rune of the alphabet or a printable ASCII character that is not in the
+// class if it is inverted.
+func (g *Generator) charClass(chr *ast.CharClassMatcher) (rune, bool) {
+	if chr.Inverted {
+		for i := 0; i < g.MaxAttempts; i++ {
+			if rn := g.randRune(); !classMatch(chr, rn) {
+				return rn, true
+			}
+		}
+		return 0, false
+	}
+
+	var tables []*unicode.RangeTable
+	for _, name := range chr.UnicodeClasses {
+		tables = append(tables, unicodeClass(name))
+	}
+	if chr.Table != nil {
+		tables = append(tables, chr.Table)
+	}
+	n := len(chr.Chars) + len(chr.Ranges)/2 + len(tables)
+	if n == 0 {
+		return 0, false
+	}
+
+	var rn rune
+	switch i := g.rand.Intn(n); {
+	case i < len(chr.Chars):
+		rn = chr.Chars[i]
+	case i < len(chr.Chars)+len(chr.Ranges)/2:
+		i = 2 * (i - len(chr.Chars))
+		rn = chr.Ranges[i] + rune(g.rand.Intn(int(chr.Ranges[i+1]-chr.Ranges[i])+1))
+	default:
+		rn = g.tableRune(tables[i-len(chr.Chars)-len(chr.Ranges)/2])
+	}
+	if chr.IgnoreCase && g.rand.Intn(2) == 0 {
+		rn = unicode.SimpleFold(rn)
+	}
+	return rn, true
+}
+
+// tableRune returns a random rune of the range table, an ASCII character
+// half of the time if the table has some.
+func (g *Generator) tableRune(table *unicode.RangeTable) rune {
+	if g.rand.Intn(2) == 0 {
+		for i := 0; i < g.MaxAttempts; i++ {
+			if rn := rune(' ' + g.rand.Intn('~'-' '+1)); unicode.Is(table, rn) {
+				return rn
+			}
+		}
+	}
+	pick := func(lo, hi, stride rune) rune {
+		return lo + stride*rune(g.rand.Intn(int((hi-lo)/stride)+1))
+	}
+	i := g.rand.Intn(len(table.R16) + len(table.R32))
+	if i < len(table.R16) {
+		r := table.R16[i]
+		return pick(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+	}
+	r := table.R32[i-len(table.R16)]
+	return pick(rune(r.Lo), rune(r.Hi), rune(r.Stride))
+}
+
+// classMatch returns true if rn is one of the chars, ranges or Unicode
+// classes of the matcher, or one of the runes of its case folding orbit if
+// it ignores the case, regardless of the inversion.
+func classMatch(chr *ast.CharClassMatcher, rn rune) bool {
+	in := func(rn rune) bool {
+		for _, c := range chr.Chars {
+			if c == rn {
+				return true
+			}
+		}
+		for i := 0; i+1 < len(chr.Ranges); i += 2 {
+			if rn >= chr.Ranges[i] && rn <= chr.Ranges[i+1] {
+				return true
+			}
+		}
+		for _, name := range chr.UnicodeClasses {
+			if unicode.Is(unicodeClass(name), rn) {
+				return true
+			}
+		}
+		return chr.Table != nil && unicode.Is(chr.Table, rn)
+	}
+	if in(rn) {
+		return true
+	}
+	if chr.IgnoreCase {
+		for f := unicode.SimpleFold(rn); f != rn; f = unicode.SimpleFold(f) {
+			if in(f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unicodeClass returns the Unicode category, script or property named
+// name, the classes of the grammar are checked by the interpreter.
+func unicodeClass(name string) *unicode.RangeTable {
+	if table, ok := unicode.Categories[name]; ok {
+		return table
+	}
+	if table, ok := unicode.Scripts[name]; ok {
+		return table
+	}
+	return unicode.Properties[name]
+}
+
+// setCosts computes the cost of the rules, the minimum depth of the rule
+// references of their matches, as a fixed point: the rules that only
+// match infinite inputs keep an infinite cost.
+func (g *Generator) setCosts(grammar *ast.Grammar) {
+	g.cost = make(map[*ast.Rule]int, len(grammar.Rules))
+	for _, rule := range grammar.Rules {
+		g.cost[rule] = infinite
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, rule := range grammar.Rules {
+			if cost := g.exprCost(rule.Expr); cost != infinite && cost+1 < g.cost[rule] {
+				g.cost[rule] = cost + 1
+				changed = true
+			}
+		}
+	}
+}
+
+// exprCost returns the cost of the expression with the current costs of
+// the rules.
+func (g *Generator) exprCost(expr ast.Expression) int {
+	switch expr := expr.(type) {
+	case *ast.ActionExpr:
+		return g.exprCost(expr.Expr)
+	case *ast.ChoiceExpr:
+		min := infinite
+		for _, alt := range expr.Alternatives {
+			if cost := g.exprCost(alt); cost < min {
+				min = cost
+			}
+		}
+		return min
+	case *ast.LabeledExpr:
+		return g.exprCost(expr.Expr)
+	case *ast.OneOrMoreExpr:
+		return g.exprCost(expr.Expr)
+	case *ast.PrecedenceExpr:
+		return g.exprCost(expr.Atom)
+	case *ast.RecoveryExpr:
+		return g.exprCost(expr.Expr)
+	case *ast.RepeatExpr:
+		if expr.Min == 0 {
+			return 0
+		}
+		return g.exprCost(expr.Expr)
+	case *ast.RuleRefExpr:
+		return g.cost[g.rules[expr.Name.Val]]
+	case *ast.SeparatedExpr:
+		if expr.Min == 0 {
+			return 0
+		}
+		return g.exprCost(expr.Expr)
+	case *ast.SeqExpr:
+		max := 0
+		for _, e := range expr.Exprs {
+			if cost := g.exprCost(e); cost > max {
+				max = cost
+			}
+		}
+		return max
+	case *ast.TriviaExpr:
+		if cost := g.exprCost(expr.Trivia); cost > g.exprCost(expr.Expr) {
+			return cost
+		}
+		return g.exprCost(expr.Expr)
+	default:
+		// the terminals, the lookaheads, the code expressions and the
+		// optional expressions
+		return 0
+	}
+}
+
+// setAlphabet sets the alphabet of the grammar, the runes of its literals
+// and of the chars of its character classes.
+func (g *Generator) setAlphabet(grammar *ast.Grammar) {
+	seen := make(map[rune]bool)
+	add := func(rn rune) {
+		if !seen[rn] {
+			seen[rn] = true
+			g.alphabet = append(g.alphabet, rn)
+		}
+	}
+	ast.Inspect(grammar, func(expr ast.Expression) bool {
+		switch expr := expr.(type) {
+		case *ast.LitMatcher:
+			for _, rn := range expr.Val {
+				add(rn)
+			}
+		case *ast.CharClassMatcher:
+			for _, rn := range expr.Chars {
+				add(rn)
+			}
+			for _, rn := range expr.Ranges {
+				add(rn)
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,141 @@
+package sample_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/oskoi/pigeon/ast"
+	"github.com/oskoi/pigeon/bootstrap"
+	"github.com/oskoi/pigeon/interp"
+	"github.com/oskoi/pigeon/sample"
+)
+
+func parse(t *testing.T, text string) *ast.Grammar {
+	t.Helper()
+
+	grammar, err := bootstrap.NewParser().Parse("", strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grammar
+}
+
+// run returns true if the grammar of text matches the whole input.
+func run(t *testing.T, text string, input []byte) bool {
+	t.Helper()
+
+	in, err := interp.New(parse(t, text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := in.Run("", input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.Match != nil && res.Match.End.Offset == len(input)
+}
+
+const exprGrammar = `
+Expr <- Term (_ [+-] _ Term)*
+Term <- Factor (_ [*/] _ Factor)*
+Factor <- '(' _ Expr _ ')' / Number / Ident
+Number <- [0-9]+ ('.' [0-9]+)?
+Ident <- !Keyword [\pL_] [\pL\pN_]*
+Keyword <- "if" ![\pL\pN_]
+_ <- [ \t]*
+`
+
+func TestSample(t *testing.T) {
+	t.Parallel()
+
+	g, err := sample.New(parse(t, exprGrammar), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		b, err := g.Sample("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !run(t, exprGrammar, b) {
+			t.Errorf("sample %q is not matched", b)
+		}
+
+		miss, err := g.NearMiss("", b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run(t, exprGrammar, miss) {
+			t.Errorf("near miss %q of %q is matched", miss, b)
+		}
+	}
+}
+
+func TestSampleSeed(t *testing.T) {
+	t.Parallel()
+
+	samples := func() []string {
+		g, err := sample.New(parse(t, exprGrammar), 42)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list []string
+		for i := 0; i < 10; i++ {
+			b, err := g.Sample("Term")
+			if err != nil {
+				t.Fatal(err)
+			}
+			list = append(list, string(b))
+		}
+		return list
+	}
+	if a, b := samples(), samples(); strings.Join(a, "\n") != strings.Join(b, "\n") {
+		t.Errorf("want the same samples for the same seed, got:\n%q\n%q", a, b)
+	}
+}
+
+func TestSampleDepth(t *testing.T) {
+	t.Parallel()
+
+	// without the depth limit, the expansion of A is infinite on average
+	g, err := sample.New(parse(t, `
+A <- '(' A A A ')' / 'x'
+`), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.MaxDepth = 3
+	for i := 0; i < 20; i++ {
+		b, err := g.Sample("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Count(string(b), "(") > 1+3+9+27 {
+			t.Errorf("sample %q is deeper than the limit", b)
+		}
+	}
+}
+
+func TestSampleErrors(t *testing.T) {
+	t.Parallel()
+
+	g, err := sample.New(parse(t, `
+A <- B
+B <- 'b' B
+C <- 'c' !.
+`), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Sample(""); !errors.Is(err, sample.ErrNoSample) {
+		t.Errorf("infinite rule: want ErrNoSample, got %v", err)
+	}
+	if _, err := g.Sample("D"); err == nil {
+		t.Error("want an invalid entrypoint error")
+	}
+	b, err := g.Sample("C")
+	if err != nil || string(b) != "c" {
+		t.Errorf("want sample %q, got %q, %v", "c", b, err)
+	}
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/oskoi/pigeon/sample"
+)
+
+// genSamplesMain implements the gen-samples command, it generates random
+// inputs that the grammar matches and near misses that it rejects.
+func genSamplesMain(args []string) {
+	fs := flag.NewFlagSet(os.Args[0]+" gen-samples", flag.ExitOnError)
+
+	var (
+		corpusFlag     = fs.String("corpus", "", "write the samples as a fuzz test corpus in a directory")
+		entrypointFlag = fs.String("entrypoint", "", "rule to generate, defaults to the first rule")
+		shortHelpFlag  = fs.Bool("h", false, "show help page")
+		longHelpFlag   = fs.Bool("help", false, "show help page")
+		jsonFlag       = fs.Bool("json", false, "print the samples as JSON")
+		maxDepthFlag   = fs.Int("max-depth", 6, "depth of the rules beyond which the shortest expansion is generated")
+		maxRepeatFlag  = fs.Int("max-repeat", 3, "maximum count of the unbounded repetitions")
+		nearMissesFlag = fs.Int("near-misses", 10, "number of near misses")
+		nFlag          = fs.Int("n", 10, "number of samples")
+		seedFlag       = fs.Int64("seed", 1, "seed of the random generator")
+	)
+
+	usage := func() {
+		fmt.Printf(genSamplesUsagePage, os.Args[0])
+	}
+	fs.Usage = usage
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, "args parse error:\n", err)
+		exit(6)
+	}
+
+	if *shortHelpFlag || *longHelpFlag {
+		usage()
+		exit(0)
+	}
+
+	if fs.NArg() > 1 {
+		fmt.Fprintf(os.Stderr, "expected one argument, got %q\n", strings.Join(fs.Args(), " "))
+		usage()
+		exit(1)
+	}
+	if *nFlag < 0 || *nearMissesFlag < 0 || *maxDepthFlag < 0 || *maxRepeatFlag < 0 {
+		fmt.Fprintln(os.Stderr, "the counts must not be negative")
+		usage()
+		exit(1)
+	}
+
+	_, grammar := parseGrammar(fs.Arg(0))
+	gen, err := sample.New(grammar, *seedFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "grammar error:\n", err)
+		exit(3)
+	}
+	gen.MaxDepth = *maxDepthFlag
+	gen.MaxRepeat = *maxRepeatFlag
+
+	samples, nearMisses, err := genSamples(gen, *entrypointFlag, *nFlag, *nearMissesFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		exit(1)
+	}
+	if len(samples) < *nFlag || len(nearMisses) < *nearMissesFlag {
+		fmt.Fprintf(os.Stderr, "generated %d distinct samples and %d near misses only\n", len(samples), len(nearMisses))
+	}
+
+	if *corpusFlag != "" {
+		if err := writeCorpus(*corpusFlag, append(samples, nearMisses...)); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:\n", err)
+			exit(7)
+		}
+		return
+	}
+	if *jsonFlag {
+		list := func(inputs [][]byte) []string {
+			strs := make([]string, len(inputs))
+			for i, input := range inputs {
+				strs[i] = string(input)
+			}
+			return strs
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "\t")
+		v := struct {
+			Samples    []string `json:"samples"`
+			NearMisses []string `json:"nearMisses"`
+		}{list(samples), list(nearMisses)}
+		if err := enc.Encode(v); err != nil {
+			fmt.Fprintln(os.Stderr, "write error:\n", err)
+			exit(7)
+		}
+		return
+	}
+
+	var b strings.Builder
+	for _, input := range samples {
+		fmt.Fprintf(&b, "match %s\n", strconv.Quote(string(input)))
+	}
+	for _, input := range nearMisses {
+		fmt.Fprintf(&b, "no-match %s\n", strconv.Quote(string(input)))
+	}
+	if _, err := os.Stdout.WriteString(b.String()); err != nil {
+		fmt.Fprintln(os.Stderr, "write error:\n", err)
+		exit(7)
+	}
+}
+
+// genSamples returns up to n distinct samples and up to nearMisses
+// distinct near misses of the samples. The duplicates are retried a
+// bounded number of times, so that a grammar with few samples terminates.
+func genSamples(gen *sample.Generator, entrypoint string, n, nearMisses int) ([][]byte, [][]byte, error) {
+	seen := make(map[string]bool)
+	distinct := func(count int, next func() ([]byte, error)) ([][]byte, error) {
+		var list [][]byte
+		for tries := 0; len(list) < count && tries < 10*count; tries++ {
+			b, err := next()
+			if errors.Is(err, sample.ErrNoSample) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			if !seen[string(b)] {
+				seen[string(b)] = true
+				list = append(list, b)
+			}
+		}
+		return list, nil
+	}
+
+	samples, err := distinct(n, func() ([]byte, error) {
+		return gen.Sample(entrypoint)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(samples) == 0 {
+		if n > 0 {
+			return nil, nil, fmt.Errorf("no sample matched by the grammar could be generated")
+		}
+		return nil, nil, nil
+	}
+
+	i := 0
+	misses, err := distinct(nearMisses, func() ([]byte, error) {
+		i++
+		return gen.NearMiss(entrypoint, samples[i%len(samples)])
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return samples, misses, nil
+}
+
+// writeCorpus writes the inputs to dir in the format of the corpus files
+// of the fuzz tests, named after their hash.
+func writeCorpus(dir string, inputs [][]byte) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, input := range inputs {
+		name := fmt.Sprintf("%x", sha256.Sum256(input))[:16]
+		data := fmt.Sprintf("go test fuzz v1\n[]byte(%q)\n", input)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var genSamplesUsagePage = `usage: %s gen-samples [options] [GRAMMAR_FILE]
+
+Gen-samples generates random inputs from a PEG grammar: samples that the
+grammar matches entirely and near misses, mutations of the samples that
+it rejects, to seed the corpora of the fuzz tests and to compare the
+parsers of a language. The grammar is read from stdin if GRAMMAR_FILE is
+not specified.
+
+The samples are generated by walking the grammar, the lookahead
+expressions, the code predicates and the boundaries of the keywords are
+ignored, and the candidates that the grammar, run as by "%[1]s run", does
+not match are rejected. The code blocks are not run, so a sample may be
+rejected by the parser generated from the grammar.
+
+The samples and the near misses are printed one per line, as a Go string
+preceded by "match" or "no-match".
+
+	-corpus DIR
+		write the samples and the near misses as the corpus files of a
+		fuzz test in DIR, e.g. testdata/fuzz/FuzzParse, instead of
+		printing them.
+	-entrypoint RULE
+		rule to generate, the first rule of the grammar by default.
+	-h -help
+		display this help message.
+	-json
+		print the samples as a JSON object with the "samples" and the
+		"nearMisses" members.
+	-max-depth N
+		depth of the rule references beyond which the shortest
+		expansion of the rules is generated, 6 by default.
+	-max-repeat N
+		maximum count of the repetitions without upper bound, 3 by
+		default.
+	-n N
+		number of samples, 10 by default.
+	-near-misses N
+		number of near misses, 10 by default.
+	-seed N
+		seed of the random generator, 1 by default, the same seed
+		generates the same samples.
+`
@@ -0,0 +1,136 @@
+package annotations
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseData(t *testing.T, input string, opts ...option) (any, *ParserCustomData) {
+	t.Helper()
+
+	data := &ParserCustomData{}
+	p := newParser("", []byte(input), opts...)
+	p.setCustomData(data)
+	got, err := p.parse(g)
+	if err != nil {
+		t.Fatalf("%q: want no error, got %v", input, err)
+	}
+	return got, data
+}
+
+func TestAnnotations(t *testing.T) {
+	cases := []struct {
+		input string
+		opts  []option
+		want  any
+		data  ParserCustomData
+	}{
+		{
+			input: "1+-2",
+			want:  []any{"+", "1", "-2"},
+			data:  ParserCustomData{numbers: 2},
+		},
+		{
+			// @memo: Diff reuses the Number matched by Sum
+			input: "1-2",
+			want:  []any{"-", "1", "2"},
+			data:  ParserCustomData{numbers: 2},
+		},
+		{
+			input: "f(x)",
+			want:  []any{"call", "f", "x"},
+			data:  ParserCustomData{names: 1, idents: 1},
+		},
+		{
+			input: "f(x]",
+			want:  []any{"index", "f", "x"},
+			data:  ParserCustomData{names: 2, idents: 2},
+		},
+		{
+			// @nomemo: Ident is matched twice even if memoize is set
+			input: "f(x]",
+			opts:  []option{memoize(true)},
+			want:  []any{"index", "f", "x"},
+			data:  ParserCustomData{names: 1, idents: 2},
+		},
+	}
+	for _, tc := range cases {
+		got, data := parseData(t, tc.input, tc.opts...)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: want %#v, got %#v", tc.input, tc.want, got)
+		}
+		if *data != tc.data {
+			t.Errorf("%q: want %+v, got %+v", tc.input, tc.data, *data)
+		}
+	}
+}
+
+func TestEntry(t *testing.T) {
+	p := newParser("", []byte("-42"))
+	p.entrypoint = "Number"
+	got, err := p.parse(g)
+	if err != nil || got != "-42" {
+		t.Errorf("want -42, got %v (error %v)", got, err)
+	}
+}
+
+func TestInline(t *testing.T) {
+	for _, r := range g.rules {
+		if r.name != "Number" {
+			continue
+		}
+		if strings.Contains(fmtExpr(r.expr), "ruleRefExpr") {
+			t.Errorf("want inlined Sign and Digit in Number, got references")
+		}
+	}
+}
+
+func fmtExpr(expr any) string {
+	var buf bytes.Buffer
+	var walk func(any)
+	walk = func(expr any) {
+		switch expr := expr.(type) {
+		case *actionExpr:
+			walk(expr.expr)
+		case *seqExpr:
+			for _, e := range expr.exprs {
+				walk(e)
+			}
+		case *zeroOrOneExpr:
+			walk(expr.expr)
+		case *oneOrMoreExpr:
+			walk(expr.expr)
+		case *ruleRefExpr:
+			buf.WriteString("ruleRefExpr " + expr.name + "\n")
+		}
+	}
+	walk(expr)
+	return buf.String()
+}
+
+func TestNoTrace(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	_, _ = parse("", []byte("f(x)"), debug(true))
+	os.Stdout = stdout
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(out), "parseRule Name") {
+		t.Errorf("want Name in the debug output, got %s", out)
+	}
+	if strings.Contains(string(out), "parseRule Ident") {
+		t.Errorf("want no Ident in the debug output, got %s", out)
+	}
+}
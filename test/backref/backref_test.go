@@ -0,0 +1,60 @@
+package backref
+
+import (
+	"reflect"
+	"testing"
+)
+
+var validCases = map[string]any{
+	"<<EOF\nline\nEOF":               []any{"EOF", "line"},
+	"<<end\na\nen\nb end\nend":       []any{"end", "a\nen\nb end"},
+	"<<x\n\nx":                       []any{"x", ""},
+	"<a></a>":                        []any{"a", nil},
+	"<a>text</a>":                    []any{"a", []any{"text"}},
+	"<a><b>x</b>y<b></b></a>":        []any{"a", []any{[]any{"b", []any{"x"}}, "y", []any{"b", nil}}},
+	`r"abc"`:                         "abc",
+	`r#"a"b"#`:                       `a"b`,
+	`r##"a"#b"##`:                    `a"#b`,
+	"ab-ab":                          "ab-ab",
+	"rr-rr":                          "rr-rr",
+	"<outer><inner></inner></outer>": []any{"outer", []any{[]any{"inner", nil}}},
+}
+
+var invalidCases = []string{
+	"<<EOF\nline\nEO",
+	"<<EOF\nline\nEND",
+	"<a></b>",
+	"<a><b></a></b>",
+	"<a>x</aa>",
+	`r#"abc"`,
+	`r#"abc"##`,
+	"ab-abc",
+	"ab-a",
+}
+
+func TestBackRef(t *testing.T) {
+	for tc, exp := range validCases {
+		got, err := parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("%q: want %#v, got %#v", tc, exp, got)
+		}
+	}
+
+	for _, tc := range invalidCases {
+		if _, err := parse("", []byte(tc)); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+}
+
+func TestBackRefErrorMessage(t *testing.T) {
+	_, err := parse("", []byte("<abc>x</abd>"))
+	want := `1:9 (8): no match found, expected: "abc"`
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
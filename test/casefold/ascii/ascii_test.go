@@ -0,0 +1,46 @@
+package ascii
+
+import (
+	"testing"
+)
+
+var validCases = map[string]any{
+	"k":       "kelvin",
+	"K":       "kelvin",
+	"straße":  "strasse",
+	"STRAßE":  "strasse",
+	"σ":       "sigma",
+	"=i":      "dotted",
+	"=I":      "dotted",
+	"#abcXYZ": "class",
+	"@αβγ":    "greek",
+}
+
+var invalidCases = []string{
+	"K",
+	"STRAẞE",
+	"Σ",
+	"=İ",
+	"#K",
+	"#ſ",
+	"@αΒγ",
+}
+
+func TestASCIIFold(t *testing.T) {
+	for tc, exp := range validCases {
+		got, err := parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if got != exp {
+			t.Errorf("%q: want %v, got %v", tc, exp, got)
+		}
+	}
+
+	for _, tc := range invalidCases {
+		if _, err := parse("", []byte(tc)); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package casefold
+
+import (
+	"testing"
+)
+
+var validCases = map[string]any{
+	"k":       "kelvin",
+	"K":       "kelvin",
+	"K":       "kelvin",
+	"straße":  "strasse",
+	"STRAẞE":  "strasse",
+	"σ":       "sigma",
+	"Σ":       "sigma",
+	"ς":       "sigma",
+	"=i":      "dotted",
+	"=I":      "dotted",
+	"#abcXYZ": "class",
+	"#K":      "class",
+	"#ſ":      "class",
+	"@αΒγ":    "greek",
+	"@ΣςΩ":    "greek",
+}
+
+var invalidCases = []string{
+	"x",
+	"STRASSE",
+	"=İ",
+	"=ı",
+	"#é",
+	"@a",
+}
+
+func TestCaseFold(t *testing.T) {
+	for tc, exp := range validCases {
+		got, err := parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if got != exp {
+			t.Errorf("%q: want %v, got %v", tc, exp, got)
+		}
+	}
+
+	for _, tc := range invalidCases {
+		if _, err := parse("", []byte(tc)); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package classset
+
+import (
+	"testing"
+)
+
+var validCases = map[string]any{
+	"=bcd":    "consonants",
+	"=XYZ":    "consonants",
+	"=Nth":    "consonants",
+	"ΑΒΓ":     "upper greek",
+	"Ω":       "upper greek",
+	"!_":      "not digit",
+	"! -+_":   "not digit",
+	"#09afAF": "hex",
+}
+
+var invalidCases = []string{
+	"=bad",
+	"=E",
+	"ΑβΓ",
+	"ABC",
+	"!a",
+	"!1",
+	"!é",
+	"#g",
+	"#０",
+}
+
+func TestClassSet(t *testing.T) {
+	for tc, exp := range validCases {
+		got, err := parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if got != exp {
+			t.Errorf("%q: want %v, got %v", tc, exp, got)
+		}
+	}
+
+	for _, tc := range invalidCases {
+		if _, err := parse("", []byte(tc)); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package keyword
+
+import (
+	"reflect"
+	"testing"
+)
+
+var validCases = map[string]any{
+	"let x = 1":              []any{"let", "x", "1"},
+	"let iffy = 1":           []any{"let", "iffy", "1"},
+	"let letter = then_":     []any{"let", "letter", "then_"},
+	"if x then print y":      []any{"if", "x", []any{"print", "y"}},
+	"if iffy then let a = b": []any{"if", "iffy", []any{"let", "a", "b"}},
+	"PRINT printer":          []any{"print", "printer"},
+	"Print 42":               []any{"print", "42"},
+}
+
+var invalidCases = []string{
+	"letx = 1",
+	"iffy then print y",
+	"if x thenprint y",
+	"let if = 1",
+	"let then = 1",
+	"printx",
+	"PRINTx",
+}
+
+func TestKeyword(t *testing.T) {
+	for tc, exp := range validCases {
+		got, err := parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("%q: want %#v, got %#v", tc, exp, got)
+		}
+	}
+
+	for _, tc := range invalidCases {
+		if _, err := parse("", []byte(tc)); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+}
+
+func TestKeywordErrorMessage(t *testing.T) {
+	_, err := parse("", []byte("if x thenx print y"))
+	want := `1:6 (5): no match found, expected: "then" or [ \t]`
+	if err == nil || err.Error() != want {
+		t.Errorf("want error %q, got %v", want, err)
+	}
+}
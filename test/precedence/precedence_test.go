@@ -0,0 +1,58 @@
+package precedence
+
+import (
+	"reflect"
+	"testing"
+)
+
+var validCases = map[string]any{
+	"=1":             1,
+	"=1 + 2":         3,
+	"=1 - 2 - 3":     -4,
+	"=2 + 3 * 4":     14,
+	"=(2 + 3) * 4":   20,
+	"=8 / 2 / 2":     2,
+	"=2 ^ 3 ^ 2":     512,
+	"=-2 ^ 2":        -4,
+	"=--3":           3,
+	"=3! - 1":        5,
+	"=2 * 3!":        12,
+	"= 1 + -2 * 3 !": -11,
+	"a":              "a",
+	"a+b+c":          []any{[]any{"a", "+", "b"}, "+", "c"},
+	"a-b^c^d":        []any{"a", "-", []any{"b", "^", []any{"c", "^", "d"}}},
+	"-a^b":           []any{[]any{"-", "a"}, "^", "b"},
+	"-a!":            []any{"-", []any{"a", "!"}},
+	"a!!+b":          []any{[]any{[]any{"a", "!"}, "!"}, "+", "b"},
+}
+
+var invalidCases = []string{
+	"",
+	"=",
+	"=1 +",
+	"=1 2",
+	"=(1",
+	"+a",
+	"a+",
+	"a^",
+	"ab",
+}
+
+func TestPrecedence(t *testing.T) {
+	for tc, exp := range validCases {
+		got, err := parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("%q: want %#v, got %#v", tc, exp, got)
+		}
+	}
+
+	for _, tc := range invalidCases {
+		if _, err := parse("", []byte(tc)); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+}
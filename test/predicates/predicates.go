@@ -164,9 +164,11 @@ var g = &grammar{
 						&labeledExpr{
 							pos:   position{line: 22, col: 20, offset: 355},
 							label: "rest",
-							expr: &ruleRefExpr{
-								pos:  position{line: 22, col: 25, offset: 360},
-								name: "hij",
+							expr: &litMatcher{
+								pos:        position{line: 22, col: 25, offset: 360},
+								val:        "hij",
+								ignoreCase: false,
+								want:       "\"hij\"",
 							},
 						},
 					},
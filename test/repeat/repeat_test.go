@@ -0,0 +1,47 @@
+package repeat
+
+import (
+	"reflect"
+	"testing"
+)
+
+var validCases = map[string]any{
+	"\\u00e9":     "00e9",
+	"\\uABCD":     "ABCD",
+	"127.0.0.1":   []any{"127", "0", "0", "1"},
+	"10.255.3.42": []any{"10", "255", "3", "42"},
+	"bb":          2,
+	"bbbbb":       5,
+	"":            nil,
+	"c":           nil,
+	"cc":          nil,
+}
+
+var invalidCases = []string{
+	`\u00e`,
+	`\u00e9f`,
+	"127.0.0",
+	"127.0.0.1.2",
+	"1270.0.0.1",
+	"b",
+	"ccc",
+}
+
+func TestRepeat(t *testing.T) {
+	for tc, exp := range validCases {
+		got, err := parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("%q: want %#v, got %#v", tc, exp, got)
+		}
+	}
+
+	for _, tc := range invalidCases {
+		if _, err := parse("", []byte(tc)); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+}
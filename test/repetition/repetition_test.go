@@ -0,0 +1,68 @@
+package repetition
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRepetition(t *testing.T) {
+	cases := map[string][]string{
+		"":              nil,
+		"abc":           {"abc"},
+		"abc 12,3 de":   {"abc", "12,3", "de"},
+		"abc 123,4,567": {"abc", "123,4,567"},
+	}
+	for tc, exp := range cases {
+		got, err := parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("%q: want %#v, got %#v", tc, exp, got)
+		}
+	}
+
+	for _, tc := range []string{"1", "ab  cd", "12,", "ab,"} {
+		if _, err := parse("", []byte(tc)); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+}
+
+// TestRepetitionGuard parses with grammars built by hand, as the builder
+// rejects a repetition over an expression that can match the empty input,
+// to check that the parser stops such a repetition instead of looping.
+func TestRepetitionGuard(t *testing.T) {
+	optional := func() any {
+		return &zeroOrOneExpr{expr: &litMatcher{val: "a", want: `"a"`}}
+	}
+	eof := &notExpr{expr: &anyMatcher{}}
+
+	cases := []struct {
+		name string
+		expr any
+		in   string
+		ok   bool
+	}{
+		{name: "zero or more", expr: &zeroOrMoreExpr{expr: optional()}, in: "aaa", ok: true},
+		{name: "one or more", expr: &oneOrMoreExpr{expr: optional()}, in: "aa", ok: true},
+		{name: "one or more empty", expr: &oneOrMoreExpr{expr: optional()}, in: "", ok: true},
+		{name: "repeat min", expr: &repeatExpr{expr: optional(), min: 3, max: -1}, in: "a", ok: true},
+		{name: "repeat no match", expr: &repeatExpr{expr: optional(), min: 3, max: -1}, in: "b", ok: false},
+		{name: "separated", expr: &separatedExpr{expr: optional(), sep: &zeroOrOneExpr{expr: &litMatcher{val: ",", want: `","`}}}, in: "a,aa", ok: true},
+		{name: "not", expr: &zeroOrMoreExpr{expr: &notExpr{expr: &litMatcher{val: "b", want: `"b"`}}}, in: "", ok: true},
+	}
+
+	for _, tc := range cases {
+		g := &grammar{rules: []*rule{{
+			name: "Start",
+			expr: &seqExpr{exprs: []any{tc.expr, eof}},
+		}}}
+		p := newParser("", []byte(tc.in))
+		_, err := p.parse(g)
+		if ok := err == nil; ok != tc.ok {
+			t.Errorf("%s: %q: want ok %t, got error %v", tc.name, tc.in, tc.ok, err)
+		}
+	}
+}
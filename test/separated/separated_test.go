@@ -0,0 +1,50 @@
+package separated
+
+import (
+	"reflect"
+	"testing"
+)
+
+var validCases = map[string]any{
+	"f()":           []any{"f"},
+	"f(a)":          []any{"f", "a"},
+	"f(a, b ,c)":    []any{"f", "a", "b", "c"},
+	"[1]":           []any{1},
+	"[1, 2, 3]":     []any{1, 2, 3},
+	"[1, 2, 3, ]":   []any{1, 2, 3},
+	"[ 42 ,]":       []any{42},
+	"a":             []any{"a"},
+	"a.b.c":         []any{"a", "b", "c"},
+	"abc.def.ghijk": []any{"abc", "def", "ghijk"},
+}
+
+var invalidCases = []string{
+	"f(,)",
+	"f(a,)",
+	"f(a b)",
+	"[]",
+	"[,]",
+	"[1,,]",
+	"a.",
+	".a",
+	"a..b",
+}
+
+func TestSeparated(t *testing.T) {
+	for tc, exp := range validCases {
+		got, err := parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("%q: want %#v, got %#v", tc, exp, got)
+		}
+	}
+
+	for _, tc := range invalidCases {
+		if _, err := parse("", []byte(tc)); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+}
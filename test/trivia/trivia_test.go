@@ -0,0 +1,37 @@
+package trivia
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrivia(t *testing.T) {
+	cases := map[string]any{
+		"1": []any{
+			[]any{token{"1", 1}},
+		},
+		" 1 +  x ,(a+ /* c */ 22) ": []any{
+			[]any{token{"1", 2}, token{"x", 7}},
+			[]any{[]any{token{"a", 11}, token{"22", 22}}},
+		},
+		"\n\tab\n": []any{
+			[]any{token{"ab", 2}},
+		},
+	}
+	for tc, exp := range cases {
+		got, err := parse("", []byte(tc))
+		if err != nil {
+			t.Errorf("%q: want no error, got %v", tc, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, exp) {
+			t.Errorf("%q: want %#v, got %#v", tc, exp, got)
+		}
+	}
+
+	for _, tc := range []string{"1 +", "(1", "1 2", "a /* b"} {
+		if _, err := parse("", []byte(tc)); err == nil {
+			t.Errorf("%q: want error, got none", tc)
+		}
+	}
+}